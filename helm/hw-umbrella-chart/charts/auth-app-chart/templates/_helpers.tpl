@@ -67,4 +67,8 @@ Create the name of the service account to use
 
 {{- define "redis.svcname" -}}
 {{- printf "%s-%s-master" .Release.Name "redis" | trunc 63 | trimSuffix "-" -}}
-{{- end -}}
\ No newline at end of file
+{{- end -}}
+
+{{- define "rabbitmq.svcname" -}}
+{{- printf "%s-%s" .Release.Name "rabbitmq" | trunc 63 | trimSuffix "-" -}}
+{{- end -}}
@@ -67,4 +67,11 @@ Create the name of the service account to use
 
 {{- define "rabbitmq.svcname" -}}
 {{- printf "%s-%s" .Release.Name "rabbitmq" | trunc 63 | trimSuffix "-" -}}
-{{- end -}}
\ No newline at end of file
+{{- end -}}
+{{- define "smtp.host" -}}
+{{- if .Values.smtp.host -}}
+{{- .Values.smtp.host -}}
+{{- else -}}
+{{- printf "%s-smtp-sink" (include "notification-app-chart.fullname" .) | trunc 63 | trimSuffix "-" -}}
+{{- end -}}
+{{- end -}}
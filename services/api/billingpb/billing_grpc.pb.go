@@ -0,0 +1,177 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: billing.proto
+
+package billingpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// BillingServiceClient is the client API for BillingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BillingServiceClient interface {
+	ProcessLotPayment(ctx context.Context, in *ProcessLotPaymentRequest, opts ...grpc.CallOption) (*ProcessLotPaymentResponse, error)
+	PayListingFee(ctx context.Context, in *PayListingFeeRequest, opts ...grpc.CallOption) (*PayListingFeeResponse, error)
+	PreviewFees(ctx context.Context, in *PreviewFeesRequest, opts ...grpc.CallOption) (*PreviewFeesResponse, error)
+}
+
+type billingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBillingServiceClient(cc grpc.ClientConnInterface) BillingServiceClient {
+	return &billingServiceClient{cc}
+}
+
+func (c *billingServiceClient) ProcessLotPayment(ctx context.Context, in *ProcessLotPaymentRequest, opts ...grpc.CallOption) (*ProcessLotPaymentResponse, error) {
+	out := new(ProcessLotPaymentResponse)
+	err := c.cc.Invoke(ctx, "/billing.v1.BillingService/ProcessLotPayment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) PayListingFee(ctx context.Context, in *PayListingFeeRequest, opts ...grpc.CallOption) (*PayListingFeeResponse, error) {
+	out := new(PayListingFeeResponse)
+	err := c.cc.Invoke(ctx, "/billing.v1.BillingService/PayListingFee", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *billingServiceClient) PreviewFees(ctx context.Context, in *PreviewFeesRequest, opts ...grpc.CallOption) (*PreviewFeesResponse, error) {
+	out := new(PreviewFeesResponse)
+	err := c.cc.Invoke(ctx, "/billing.v1.BillingService/PreviewFees", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BillingServiceServer is the server API for BillingService service.
+// All implementations must embed UnimplementedBillingServiceServer
+// for forward compatibility
+type BillingServiceServer interface {
+	ProcessLotPayment(context.Context, *ProcessLotPaymentRequest) (*ProcessLotPaymentResponse, error)
+	PayListingFee(context.Context, *PayListingFeeRequest) (*PayListingFeeResponse, error)
+	PreviewFees(context.Context, *PreviewFeesRequest) (*PreviewFeesResponse, error)
+	mustEmbedUnimplementedBillingServiceServer()
+}
+
+// UnimplementedBillingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedBillingServiceServer struct {
+}
+
+func (UnimplementedBillingServiceServer) ProcessLotPayment(context.Context, *ProcessLotPaymentRequest) (*ProcessLotPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessLotPayment not implemented")
+}
+func (UnimplementedBillingServiceServer) PayListingFee(context.Context, *PayListingFeeRequest) (*PayListingFeeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PayListingFee not implemented")
+}
+func (UnimplementedBillingServiceServer) PreviewFees(context.Context, *PreviewFeesRequest) (*PreviewFeesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewFees not implemented")
+}
+func (UnimplementedBillingServiceServer) mustEmbedUnimplementedBillingServiceServer() {}
+
+// UnsafeBillingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BillingServiceServer will
+// result in compilation errors.
+type UnsafeBillingServiceServer interface {
+	mustEmbedUnimplementedBillingServiceServer()
+}
+
+func RegisterBillingServiceServer(s grpc.ServiceRegistrar, srv BillingServiceServer) {
+	s.RegisterService(&BillingService_ServiceDesc, srv)
+}
+
+func _BillingService_ProcessLotPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessLotPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).ProcessLotPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/billing.v1.BillingService/ProcessLotPayment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).ProcessLotPayment(ctx, req.(*ProcessLotPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_PayListingFee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PayListingFeeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).PayListingFee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/billing.v1.BillingService/PayListingFee",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).PayListingFee(ctx, req.(*PayListingFeeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BillingService_PreviewFees_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreviewFeesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BillingServiceServer).PreviewFees(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/billing.v1.BillingService/PreviewFees",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BillingServiceServer).PreviewFees(ctx, req.(*PreviewFeesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BillingService_ServiceDesc is the grpc.ServiceDesc for BillingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BillingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "billing.v1.BillingService",
+	HandlerType: (*BillingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ProcessLotPayment",
+			Handler:    _BillingService_ProcessLotPayment_Handler,
+		},
+		{
+			MethodName: "PayListingFee",
+			Handler:    _BillingService_PayListingFee_Handler,
+		},
+		{
+			MethodName: "PreviewFees",
+			Handler:    _BillingService_PreviewFees_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "billing.proto",
+}
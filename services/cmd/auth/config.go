@@ -1,8 +1,13 @@
 package main
 
 import (
+	"arch-homework/pkg/auth/infrastructure/oidc"
+
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
+
+	"encoding/json"
+	"time"
 )
 
 func parseEnv() (*config, error) {
@@ -16,12 +21,40 @@ func parseEnv() (*config, error) {
 	if c.RedisHost == "" || c.RedisPort == "" || c.RedisPassword == "" {
 		return c, errors.New("redis env params not set")
 	}
+	if c.OIDCProviders != "" {
+		if err := json.Unmarshal([]byte(c.OIDCProviders), &c.oidcProviderConfigs); err != nil {
+			return c, errors.Wrap(err, "failed to parse oidc providers")
+		}
+		for _, provider := range c.oidcProviderConfigs {
+			if provider.Name == "" || provider.Issuer == "" || provider.ClientID == "" {
+				return c, errors.New("oidc provider name, issuer and client id must be set")
+			}
+		}
+	}
 	return c, nil
 }
 
 type config struct {
 	ServicePort string `envconfig:"service_port" default:"8000"`
-	JWTSecret   string `envconfig:"jwt_secret" default:"secret"`
+	GRPCPort    string `envconfig:"grpc_port" default:"9000"`
+
+	// JWTSigningKeys - json object, which maps key id to PEM encoded RSA or Ed25519 private key
+	JWTSigningKeys  string `envconfig:"jwt_signing_keys"`
+	JWTSigningKeyID string `envconfig:"jwt_signing_key_id" default:"key-1"`
+
+	TOTPIssuer string `envconfig:"totp_issuer" default:"arch.homework"`
+	// TOTPEncryptionKey - base64 encoded 32 bytes key
+	TOTPEncryptionKey string `envconfig:"totp_encryption_key" default:"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="`
+
+	UserServiceHost     string        `envconfig:"user_host" default:"http://user-app:8000"`
+	UserServiceGRPCHost string        `envconfig:"user_grpc_host" default:"user-app:9000"`
+	GRPCCallTimeout     time.Duration `envconfig:"grpc_call_timeout" default:"2s"`
+
+	// OIDCProviders - json list of identity providers
+	OIDCProviders string `envconfig:"oidc_providers"`
+	// OIDCRedirectURL - provider name and callback path are appended
+	OIDCRedirectURL     string `envconfig:"oidc_redirect_url" default:"http://arch.homework/auth/api/v1/oidc"`
+	oidcProviderConfigs []oidc.ProviderConfig
 
 	DBHost     string `envconfig:"db_host" default:"localhost"`
 	DBPort     string `envconfig:"db_port" default:"5433"`
@@ -32,4 +65,22 @@ type config struct {
 	RedisHost     string `envconfig:"redis_host" default:"localhost"`
 	RedisPort     string `envconfig:"redis_port" default:"6379"`
 	RedisPassword string `envconfig:"redis_password" default:"redis-pwd"`
+
+	RMQHost     string `envconfig:"rmq_host" default:"localhost"`
+	RMQPort     string `envconfig:"rmq_port" default:"5552"`
+	RMQUser     string `envconfig:"rmq_user" default:"rmq_user"`
+	RMQPassword string `envconfig:"rmq_password" default:"rmq_pwd"`
+
+	PasswordHashMemory      uint32        `envconfig:"password_hash_memory" default:"65536"`
+	PasswordHashIterations  uint32        `envconfig:"password_hash_iterations" default:"3"`
+	PasswordHashParallelism uint8         `envconfig:"password_hash_parallelism" default:"2"`
+	PasswordResetTokenTTL   time.Duration `envconfig:"password_reset_token_ttl" default:"1h"`
+
+	LoginFailureWindow      time.Duration `envconfig:"login_failure_window" default:"15m"`
+	LoginDelayAfterFailures int           `envconfig:"login_delay_after_failures" default:"3"`
+	LoginBaseDelay          time.Duration `envconfig:"login_base_delay" default:"1s"`
+	LoginMaxDelay           time.Duration `envconfig:"login_max_delay" default:"30s"`
+	LoginMaxFailures        int           `envconfig:"login_max_failures" default:"10"`
+	LoginMaxIPFailures      int           `envconfig:"login_max_ip_failures" default:"100"`
+	LoginLockoutDuration    time.Duration `envconfig:"login_lockout_duration" default:"15m"`
 }
@@ -1,20 +1,32 @@
 package main
 
 import (
+	"arch-homework/api/authpb"
 	"arch-homework/pkg/auth/app"
 	"arch-homework/pkg/auth/infrastructure/encoding"
+	"arch-homework/pkg/auth/infrastructure/oidc"
 	"arch-homework/pkg/auth/infrastructure/postgres"
 	infraredis "arch-homework/pkg/auth/infrastructure/redis"
+	servergrpc "arch-homework/pkg/auth/infrastructure/transport/grpc"
 	serverhttp "arch-homework/pkg/auth/infrastructure/transport/http"
+	"arch-homework/pkg/auth/infrastructure/transport/userservice"
+	"arch-homework/pkg/common/app/streams"
+	"arch-homework/pkg/common/infrastructure/grpcclient"
+	"arch-homework/pkg/common/infrastructure/grpcserver"
 	"arch-homework/pkg/common/infrastructure/metrics"
 	commonpostgres "arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/common/infrastructure/storedevent"
+	infrastreams "arch-homework/pkg/common/infrastructure/streams"
 	"arch-homework/pkg/common/jwtauth"
 
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"io"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -22,6 +34,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -29,8 +42,14 @@ const (
 	WriteTimeout = time.Minute
 
 	sessionLifetime = time.Minute * 30
+
+	oidcRequestTimeout = time.Second * 10
+
+	keySetPath = "/.well-known/jwks.json"
 )
 
+const serviceName = "auth"
+
 func main() {
 	logger := logrus.New()
 	logger.SetFormatter(&logrus.JSONFormatter{})
@@ -49,6 +68,11 @@ func main() {
 
 	redisClient := initRedisClient(cfg)
 
+	rmqEnv, err := initRabbitMQEnv(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
 	metricsHandler, err := metrics.NewPrometheusMetricsHandler(serverhttp.NewEndpointLabelCollector())
 	if err != nil {
 		logger.Fatal(err)
@@ -56,9 +80,10 @@ func main() {
 
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	defer cancelFunc()
-	server := startServer(ctx, cfg, connector, redisClient, logger, metricsHandler)
+	server, grpcServer := startServer(ctx, cfg, connector, redisClient, rmqEnv, logger, metricsHandler)
 
 	waitForKillSignal(logger)
+	grpcServer.GracefulStop()
 	if err := server.Shutdown(context.Background()); err != nil {
 		logger.WithError(err).Fatal("http server shutdown failed")
 	}
@@ -86,6 +111,16 @@ func initRedisClient(cfg *config) *redis.Client {
 	})
 }
 
+func initRabbitMQEnv(cfg *config) (streams.Environment, error) {
+	return infrastreams.NewEnvironment(serviceName,
+		streams.Config{
+			Host:     cfg.RMQHost,
+			Port:     cfg.RMQPort,
+			User:     cfg.RMQUser,
+			Password: cfg.RMQPassword,
+		})
+}
+
 func waitForKillSignal(logger *logrus.Logger) {
 	sysKillSignal := make(chan os.Signal, 1)
 	signal.Notify(sysKillSignal, os.Interrupt, syscall.SIGTERM)
@@ -97,24 +132,79 @@ func startServer(
 	cfg *config,
 	connector commonpostgres.Connector,
 	redisClient *redis.Client,
+	rmqEnv streams.Environment,
 	logger *logrus.Logger,
 	metricsHandler metrics.PrometheusMetricsHandler,
-) *http.Server {
+) (*http.Server, *grpc.Server) {
 	httpAddress := ":" + cfg.ServicePort
 	if err := connector.WaitUntilReady(); err != nil {
 		logger.Fatal(err)
 	}
 	dbDep := postgres.NewDBDependency(connector.Client())
+	eventSender, err := storedevent.NewEventSender(ctx, postgres.NewEventStore(connector.Client()), rmqEnv, logger)
+	if err != nil {
+		logger.Fatal(err)
+	}
 
 	sessionClient := infraredis.NewSessionClient(redisClient, sessionLifetime)
 
-	userService := app.NewUserService(dbDep, encoding.NewPasswordEncoder())
-	tokenGenerator := jwtauth.NewTokenGenerator(cfg.JWTSecret)
-	userServer := serverhttp.NewServer(userService, sessionClient, tokenGenerator, logger)
+	passwordEncoder := encoding.NewPasswordEncoder(encoding.Argon2Params{
+		Memory:      cfg.PasswordHashMemory,
+		Iterations:  cfg.PasswordHashIterations,
+		Parallelism: cfg.PasswordHashParallelism,
+	})
+	userService := app.NewUserService(dbDep, passwordEncoder, sessionClient)
+	twoFactorService, err := initTwoFactorService(cfg, dbDep, redisClient)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	loginService := app.NewLoginService(userService, dbDep, infraredis.NewLoginAttemptStore(redisClient), eventSender, app.LoginThrottlePolicy{
+		Window:             cfg.LoginFailureWindow,
+		DelayAfterFailures: cfg.LoginDelayAfterFailures,
+		BaseDelay:          cfg.LoginBaseDelay,
+		MaxDelay:           cfg.LoginMaxDelay,
+		MaxLoginFailures:   cfg.LoginMaxFailures,
+		MaxIPFailures:      cfg.LoginMaxIPFailures,
+		LockoutDuration:    cfg.LoginLockoutDuration,
+	})
+	signingKeys, err := initSigningKeys(cfg, logger)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	tokenGenerator, err := jwtauth.NewTokenGenerator(signingKeys.Current)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	keySetHandler, err := jwtauth.NewKeySetHandler(signingKeys)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	passwordService := app.NewPasswordService(dbDep, passwordEncoder, sessionClient, eventSender, cfg.PasswordResetTokenTTL)
+	userSvcClient, err := initUserServiceClient(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	externalLoginService := app.NewExternalLoginService(dbDep, initIdentityProviders(cfg), infraredis.NewOAuthStateStore(redisClient), userSvcClient)
+	accessService := app.NewAccessService(dbDep, sessionClient)
+	apiKeyService := app.NewAPIKeyService(dbDep)
+	userServer := serverhttp.NewServer(
+		userService,
+		loginService,
+		twoFactorService,
+		passwordService,
+		externalLoginService,
+		accessService,
+		apiKeyService,
+		sessionClient,
+		tokenGenerator,
+		jwtauth.NewLocalTokenParser(signingKeys),
+		logger,
+	)
 
 	router := mux.NewRouter()
 	router.HandleFunc("/health", handleHealth).Methods(http.MethodGet)
 	router.HandleFunc("/ready", handleReady(connector)).Methods(http.MethodGet)
+	router.Handle(keySetPath, keySetHandler).Methods(http.MethodGet)
 	router.PathPrefix(serverhttp.PathPrefix).Handler(userServer.MakeHandler())
 	router.PathPrefix(serverhttp.PathPrefixInternal).Handler(userServer.MakeInternalHandler())
 
@@ -132,7 +222,58 @@ func startServer(
 		logger.Fatal(server.ListenAndServe())
 	}()
 
-	return server
+	grpcServer := grpcserver.NewServer(ctx, logger, connector.Ping)
+	authpb.RegisterAuthServiceServer(grpcServer, servergrpc.NewServer(userService, passwordService))
+	go func() {
+		logger.Fatal(grpcserver.Serve(grpcServer, cfg.GRPCPort))
+	}()
+
+	return server, grpcServer
+}
+
+func initTwoFactorService(cfg *config, dbDep app.DBDependency, redisClient *redis.Client) (*app.TwoFactorService, error) {
+	key, err := base64.StdEncoding.DecodeString(cfg.TOTPEncryptionKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode totp encryption key")
+	}
+	encryptor, err := encoding.NewSecretEncryptor(key)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewTwoFactorService(dbDep, encryptor, infraredis.NewLoginChallengeStore(redisClient), cfg.TOTPIssuer), nil
+}
+
+func initSigningKeys(cfg *config, logger *logrus.Logger) (*jwtauth.SigningKeys, error) {
+	if cfg.JWTSigningKeys == "" {
+		logger.Warn("jwt signing keys not set, temporary key is generated, tokens signed by other instances can't be verified")
+		return jwtauth.GenerateSigningKeys(cfg.JWTSigningKeyID)
+	}
+	var pemKeys map[string]string
+	if err := json.Unmarshal([]byte(cfg.JWTSigningKeys), &pemKeys); err != nil {
+		return nil, errors.Wrap(err, "failed to parse jwt signing keys")
+	}
+	return jwtauth.ParseSigningKeys(pemKeys, cfg.JWTSigningKeyID)
+}
+
+func initUserServiceClient(cfg *config) (app.UserServiceClient, error) {
+	if cfg.UserServiceGRPCHost == "" {
+		return userservice.NewClient(http.Client{}, cfg.UserServiceHost), nil
+	}
+	conn, err := grpcclient.Dial(grpcclient.Config{Host: cfg.UserServiceGRPCHost, CallTimeout: cfg.GRPCCallTimeout})
+	if err != nil {
+		return nil, err
+	}
+	return userservice.NewGRPCClient(conn), nil
+}
+
+func initIdentityProviders(cfg *config) map[app.IdentityProviderName]app.IdentityProvider {
+	httpClient := &http.Client{Timeout: oidcRequestTimeout}
+	providers := make(map[app.IdentityProviderName]app.IdentityProvider, len(cfg.oidcProviderConfigs))
+	for _, providerCfg := range cfg.oidcProviderConfigs {
+		redirectURL := strings.TrimSuffix(cfg.OIDCRedirectURL, "/") + "/" + providerCfg.Name + "/callback"
+		providers[app.IdentityProviderName(providerCfg.Name)] = oidc.NewProvider(providerCfg, redirectURL, httpClient)
+	}
+	return providers
 }
 
 func handleHealth(w http.ResponseWriter, _ *http.Request) {
@@ -1,8 +1,15 @@
 package main
 
 import (
+	"arch-homework/pkg/billing/app"
+
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
+
+	"math"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func parseEnv() (*config, error) {
@@ -21,7 +28,11 @@ func parseEnv() (*config, error) {
 
 type config struct {
 	ServicePort string `envconfig:"service_port" default:"8000"`
-	JWTSecret   string `envconfig:"jwt_secret" default:"secret"`
+	GRPCPort    string `envconfig:"grpc_port" default:"9000"`
+
+	AuthKeySetURL string `envconfig:"auth_jwks_url" default:"http://auth-app:8000/.well-known/jwks.json"`
+
+	LotServiceHost string `envconfig:"lot_host" default:"http://lot-app:8000"`
 
 	DBHost     string `envconfig:"db_host" default:"localhost"`
 	DBPort     string `envconfig:"db_port" default:"5433"`
@@ -33,4 +44,90 @@ type config struct {
 	RMQPort     string `envconfig:"rmq_port" default:"5552"`
 	RMQUser     string `envconfig:"rmq_user" default:"rmq_user"`
 	RMQPassword string `envconfig:"rmq_password" default:"rmq_pwd"`
+
+	ListingFee         float64 `envconfig:"listing_fee" default:"0"`
+	FinalValueFeeTiers string  `envconfig:"final_value_fee_tiers" default:""`
+	MinFinalValueFee   float64 `envconfig:"min_final_value_fee" default:"0"`
+	MaxFinalValueFee   float64 `envconfig:"max_final_value_fee" default:"0"`
+
+	MaxBlockedAmount float64 `envconfig:"max_blocked_amount" default:"0"`
+	MaxActiveBids    int     `envconfig:"max_active_bids" default:"0"`
+	MaxTopUpsPerHour int     `envconfig:"max_top_ups_per_hour" default:"0"`
+	MaxBidsPerHour   int     `envconfig:"max_bids_per_hour" default:"0"`
+
+	ReconciliationInterval    time.Duration `envconfig:"reconciliation_interval" default:"10m"`
+	ReconciliationMinBlockAge time.Duration `envconfig:"reconciliation_min_block_age" default:"30m"`
+}
+
+// final value fee tiers format - "threshold:percent,threshold:percent", e.g. "0:10,1000:5"
+func parseFeeSchedule(c *config) (app.FeeSchedule, error) {
+	listingFee, err := amountFromConfigValue(c.ListingFee)
+	if err != nil {
+		return app.FeeSchedule{}, errors.Wrap(err, "invalid listing fee")
+	}
+	var tiers []app.FeeTier
+	if c.FinalValueFeeTiers != "" {
+		for _, strTier := range strings.Split(c.FinalValueFeeTiers, ",") {
+			parts := strings.Split(strings.TrimSpace(strTier), ":")
+			if len(parts) != 2 {
+				return app.FeeSchedule{}, errors.Errorf("invalid final value fee tier '%s'", strTier)
+			}
+			threshold, err := strconv.ParseFloat(parts[0], 64)
+			if err != nil {
+				return app.FeeSchedule{}, errors.Wrapf(err, "invalid final value fee tier '%s'", strTier)
+			}
+			thresholdAmount, err := amountFromConfigValue(threshold)
+			if err != nil {
+				return app.FeeSchedule{}, errors.Wrapf(err, "invalid final value fee tier '%s'", strTier)
+			}
+			percent, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil || percent < 0 {
+				return app.FeeSchedule{}, errors.Errorf("invalid final value fee tier '%s'", strTier)
+			}
+			tiers = append(tiers, app.FeeTier{
+				Threshold:   thresholdAmount,
+				BasisPoints: uint64(math.Round(percent * 100)),
+			})
+		}
+	}
+	minFee, err := amountFromConfigValue(c.MinFinalValueFee)
+	if err != nil {
+		return app.FeeSchedule{}, errors.Wrap(err, "invalid min final value fee")
+	}
+	var maxFee *app.Amount
+	if c.MaxFinalValueFee != 0 {
+		fee, err := amountFromConfigValue(c.MaxFinalValueFee)
+		if err != nil {
+			return app.FeeSchedule{}, errors.Wrap(err, "invalid max final value fee")
+		}
+		maxFee = &fee
+	}
+	return app.NewFeeSchedule(listingFee, tiers, minFee, maxFee)
+}
+
+// zero value of any risk limit disables the limit
+func parseRiskLimits(c *config) (app.RiskLimits, error) {
+	if c.MaxActiveBids < 0 || c.MaxTopUpsPerHour < 0 || c.MaxBidsPerHour < 0 {
+		return app.RiskLimits{}, errors.New("risk limits can not be negative")
+	}
+	limits := app.RiskLimits{
+		MaxActiveBids:    c.MaxActiveBids,
+		MaxTopUpsPerHour: c.MaxTopUpsPerHour,
+		MaxBidsPerHour:   c.MaxBidsPerHour,
+	}
+	if c.MaxBlockedAmount != 0 {
+		amount, err := app.AmountFromFloat(c.MaxBlockedAmount)
+		if err != nil {
+			return app.RiskLimits{}, errors.Wrap(err, "invalid max blocked amount")
+		}
+		limits.MaxBlockedAmount = &amount
+	}
+	return limits, nil
+}
+
+func amountFromConfigValue(value float64) (app.Amount, error) {
+	if value == 0 {
+		return app.AmountFromRawValue(0), nil
+	}
+	return app.AmountFromFloat(value)
 }
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"arch-homework/api/billingpb"
 	"arch-homework/pkg/billing/app"
 	"arch-homework/pkg/billing/infrastructure/integrationevent"
+	"arch-homework/pkg/billing/infrastructure/lot"
+	billingmetrics "arch-homework/pkg/billing/infrastructure/metrics"
 	"arch-homework/pkg/billing/infrastructure/postgres"
+	servergrpc "arch-homework/pkg/billing/infrastructure/transport/grpc"
 	serverhttp "arch-homework/pkg/billing/infrastructure/transport/http"
 	"arch-homework/pkg/common/app/streams"
+	"arch-homework/pkg/common/infrastructure/grpcserver"
 	commonintegrationevent "arch-homework/pkg/common/infrastructure/integrationevent"
 	"arch-homework/pkg/common/infrastructure/metrics"
 	commonpostgres "arch-homework/pkg/common/infrastructure/postgres"
@@ -23,11 +28,14 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 const (
 	ReadTimeout  = time.Minute
 	WriteTimeout = time.Minute
+
+	authKeySetTimeout = time.Second * 5
 )
 
 const serviceName = "billing"
@@ -48,6 +56,15 @@ func main() {
 	}
 	defer connector.Close()
 
+	if len(os.Args) > 1 && os.Args[1] == reconcileCommand {
+		if err = reconcileLedger(connector, logger); err != nil {
+			logger.Error(err)
+			_ = connector.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
 	rmqEnv, err := initRabbitMQEnv(cfg)
 	if err != nil {
 		logger.Fatal(err)
@@ -58,9 +75,12 @@ func main() {
 		logger.Fatal(err)
 	}
 
-	server := startServer(cfg, connector, rmqEnv, logger, metricsHandler)
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	server, grpcServer := startServer(ctx, cfg, connector, rmqEnv, logger, metricsHandler)
 
 	waitForKillSignal(logger)
+	grpcServer.GracefulStop()
 	if err := server.Shutdown(context.Background()); err != nil {
 		logger.WithError(err).Fatal("http server shutdown failed")
 	}
@@ -98,31 +118,59 @@ func waitForKillSignal(logger *logrus.Logger) {
 }
 
 func startServer(
+	ctx context.Context,
 	cfg *config,
 	connector commonpostgres.Connector,
 	rmqEnv streams.Environment,
 	logger *logrus.Logger,
 	metricsHandler metrics.PrometheusMetricsHandler,
-) *http.Server {
+) (*http.Server, *grpc.Server) {
 	httpAddress := ":" + cfg.ServicePort
 	if err := connector.WaitUntilReady(); err != nil {
 		logger.Fatal(err)
 	}
 
+	feeSchedule, err := parseFeeSchedule(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	riskLimits, err := parseRiskLimits(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
 	trUnitFactory := postgres.NewTransactionalUnitFactory(connector.Client())
-	eventHandler := app.NewEventHandler(trUnitFactory, integrationevent.NewEventParser())
+	eventHandler := app.NewEventHandler(trUnitFactory, integrationevent.NewEventParser(), feeSchedule)
 
 	if err := commonintegrationevent.StartEventConsumer(rmqEnv, eventHandler, logger); err != nil {
 		logger.Fatal(err)
 	}
 
-	tokenParser := jwtauth.NewTokenParser(cfg.JWTSecret)
+	tokenParser := jwtauth.NewTokenParser(cfg.AuthKeySetURL, &http.Client{Timeout: authKeySetTimeout})
 	_ = tokenParser
 
-	billingQueryService := app.NewBillingQueryService(postgres.NewUserAccountEventRepository(connector.Client()))
-	billingService := app.NewBillingService(trUnitFactory)
+	billingQueryService := app.NewBillingQueryService(
+		postgres.NewUserAccountEventRepository(connector.Client()),
+		postgres.NewRiskRepository(connector.Client()),
+		feeSchedule,
+	)
+	billingService := app.NewBillingService(trUnitFactory, feeSchedule, app.NewRiskPolicy(riskLimits))
 	billingServer := serverhttp.NewServer(billingService, billingQueryService, tokenParser, logger)
 
+	reconciliationMetrics, err := billingmetrics.NewBlockedPaymentReconciliationMetrics()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	reconciliationService := app.NewBlockedPaymentReconciliationService(
+		postgres.NewUserAccountEventRepository(connector.Client()),
+		lot.NewClient(http.Client{}, cfg.LotServiceHost),
+		billingService,
+		reconciliationMetrics,
+		cfg.ReconciliationMinBlockAge,
+	)
+	app.StartBlockedPaymentReconciliationJob(ctx, reconciliationService, cfg.ReconciliationInterval, logger)
+
 	router := mux.NewRouter()
 	router.HandleFunc("/health", handleHealth).Methods(http.MethodGet)
 	router.HandleFunc("/ready", handleReady(connector)).Methods(http.MethodGet)
@@ -143,7 +191,13 @@ func startServer(
 		logger.Fatal(server.ListenAndServe())
 	}()
 
-	return server
+	grpcServer := grpcserver.NewServer(ctx, logger, connector.Ping)
+	billingpb.RegisterBillingServiceServer(grpcServer, servergrpc.NewServer(billingService, billingQueryService))
+	go func() {
+		logger.Fatal(grpcserver.Serve(grpcServer, cfg.GRPCPort))
+	}()
+
+	return server, grpcServer
 }
 
 func handleHealth(w http.ResponseWriter, _ *http.Request) {
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"arch-homework/pkg/billing/app"
+	"arch-homework/pkg/billing/infrastructure/postgres"
+	commonpostgres "arch-homework/pkg/common/infrastructure/postgres"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const reconcileCommand = "reconcile"
+
+var errLedgerInconsistent = errors.New("ledger is inconsistent with user account events")
+
+func reconcileLedger(connector commonpostgres.Connector, logger *logrus.Logger) error {
+	if err := connector.WaitUntilReady(); err != nil {
+		return err
+	}
+
+	client := connector.Client()
+	reconciliationService := app.NewLedgerReconciliationService(
+		postgres.NewUserAccountEventRepository(client),
+		postgres.NewLedgerRepository(client),
+	)
+	report, err := reconciliationService.Reconcile()
+	if err != nil {
+		return err
+	}
+
+	for _, entryID := range report.UnbalancedEntries {
+		logger.WithField("entry_id", entryID).Error("unbalanced journal entry")
+	}
+	for _, discrepancy := range report.Discrepancies {
+		logger.WithFields(logrus.Fields{
+			"account_type":   discrepancy.Account.Type,
+			"user_id":        discrepancy.Account.UserID,
+			"ledger_balance": discrepancy.LedgerBalance,
+			"state_balance":  discrepancy.StateBalance,
+		}).Error("ledger balance discrepancy")
+	}
+	logger.WithFields(logrus.Fields{
+		"checked_accounts":   report.CheckedAccounts,
+		"unbalanced_entries": len(report.UnbalancedEntries),
+		"discrepancies":      len(report.Discrepancies),
+	}).Info("ledger reconciliation finished")
+
+	if !report.Consistent() {
+		return errors.WithStack(errLedgerInconsistent)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
+
+	"time"
 )
 
 func parseEnv() (*config, error) {
@@ -21,10 +23,29 @@ func parseEnv() (*config, error) {
 
 type config struct {
 	ServicePort string `envconfig:"service_port" default:"8000"`
-	JWTSecret   string `envconfig:"jwt_secret" default:"secret"`
 
-	LotServiceHost  string `envconfig:"lot_host" default:"http://lot-app:8000"`
-	UserServiceHost string `envconfig:"user_host" default:"http://user-app:8000"`
+	AuthKeySetURL string `envconfig:"auth_jwks_url" default:"http://auth-app:8000/.well-known/jwks.json"`
+
+	LotServiceHost      string        `envconfig:"lot_host" default:"http://lot-app:8000"`
+	UserServiceHost     string        `envconfig:"user_host" default:"http://user-app:8000"`
+	BillingServiceHost  string        `envconfig:"billing_host" default:"http://billing-app:8000"`
+	UserServiceGRPCHost string        `envconfig:"user_grpc_host" default:"user-app:9000"`
+	GRPCCallTimeout     time.Duration `envconfig:"grpc_call_timeout" default:"2s"`
+
+	CarrierHost            string        `envconfig:"carrier_host"`
+	CarrierTimeout         time.Duration `envconfig:"carrier_timeout" default:"5s"`
+	TrackingUpdateInterval time.Duration `envconfig:"tracking_update_interval" default:"5m"`
+	// DeliveryAutoConfirmPeriod - zero disables auto confirmation of receipt
+	DeliveryAutoConfirmPeriod time.Duration `envconfig:"delivery_auto_confirm_period" default:"0s"`
+	// ShipByPeriod, ConfirmByPeriod - deadlines from lot finish, zero disables deadline
+	ShipByPeriod          time.Duration `envconfig:"ship_by_period" default:"0s"`
+	ConfirmByPeriod       time.Duration `envconfig:"confirm_by_period" default:"0s"`
+	DeadlineReminderLead  time.Duration `envconfig:"deadline_reminder_lead" default:"24h"`
+	DeadlineCheckInterval time.Duration `envconfig:"deadline_check_interval" default:"10m"`
+	// EnrichmentInterval - interval of filling names and address of deliveries created from lot.lot_won
+	EnrichmentInterval time.Duration `envconfig:"delivery_enrichment_interval" default:"10s"`
+	// LabelFontDir - directory with DejaVu Sans Condensed fonts for shipping labels, latin-1 core font is used if empty
+	LabelFontDir string `envconfig:"label_font_dir"`
 
 	DBHost     string `envconfig:"db_host" default:"localhost"`
 	DBPort     string `envconfig:"db_port" default:"5433"`
@@ -2,13 +2,19 @@ package main
 
 import (
 	"arch-homework/pkg/common/app/streams"
+	"arch-homework/pkg/common/infrastructure/grpcclient"
+	commonintegrationevent "arch-homework/pkg/common/infrastructure/integrationevent"
 	"arch-homework/pkg/common/infrastructure/metrics"
 	commonpostgres "arch-homework/pkg/common/infrastructure/postgres"
 	"arch-homework/pkg/common/infrastructure/storedevent"
 	infrastreams "arch-homework/pkg/common/infrastructure/streams"
 	"arch-homework/pkg/common/jwtauth"
 	"arch-homework/pkg/delivery/app"
+	"arch-homework/pkg/delivery/infrastructure/integrationevent"
 	"arch-homework/pkg/delivery/infrastructure/postgres"
+	"arch-homework/pkg/delivery/infrastructure/shippinglabel"
+	"arch-homework/pkg/delivery/infrastructure/transport/billingservice"
+	"arch-homework/pkg/delivery/infrastructure/transport/carrier"
 	serverhttp "arch-homework/pkg/delivery/infrastructure/transport/http"
 	"arch-homework/pkg/delivery/infrastructure/transport/lotservice"
 	"arch-homework/pkg/delivery/infrastructure/transport/userservice"
@@ -29,6 +35,8 @@ import (
 const (
 	ReadTimeout  = time.Minute
 	WriteTimeout = time.Minute
+
+	authKeySetTimeout = time.Second * 5
 )
 
 const serviceName = "delivery"
@@ -118,12 +126,44 @@ func startServer(
 		logger.Fatal(err)
 	}
 
-	userSvcClient := userservice.NewClient(http.Client{}, cfg.UserServiceHost)
+	userSvcClient, err := initUserServiceClient(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
 	lotSvcClient := lotservice.NewClient(http.Client{}, cfg.LotServiceHost)
 
-	deliveryService := app.NewDeliveryService(dbDep, eventStore, lotSvcClient, userSvcClient)
+	addressBookClient := userservice.NewAddressBookClient(http.Client{}, cfg.UserServiceHost)
+	labelRenderer, err := shippinglabel.NewRenderer(cfg.LabelFontDir)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	deliveryService := app.NewDeliveryService(
+		dbDep,
+		eventStore,
+		lotSvcClient,
+		userSvcClient,
+		addressBookClient,
+		billingservice.NewClient(http.Client{}, cfg.BillingServiceHost),
+		initCarrierClient(cfg, logger),
+		cfg.DeliveryAutoConfirmPeriod,
+		app.DeadlinePolicy{
+			ShipByPeriod:    cfg.ShipByPeriod,
+			ConfirmByPeriod: cfg.ConfirmByPeriod,
+			ReminderLead:    cfg.DeadlineReminderLead,
+		},
+		labelRenderer,
+	)
+	app.StartTrackingUpdateJob(ctx, deliveryService, cfg.TrackingUpdateInterval, logger)
+	app.StartDeadlineJob(ctx, deliveryService, cfg.DeadlineCheckInterval, logger)
+	app.StartEnrichmentJob(ctx, deliveryService, cfg.EnrichmentInterval, logger)
+	app.StartBackfillJob(ctx, deliveryService, cfg.EnrichmentInterval, logger)
+
+	eventHandler := app.NewEventHandler(dbDep, integrationevent.NewEventParser())
+	if err := commonintegrationevent.StartEventConsumer(rmqEnv, eventHandler, logger); err != nil {
+		logger.Fatal(err)
+	}
 
-	tokenParser := jwtauth.NewTokenParser(cfg.JWTSecret)
+	tokenParser := jwtauth.NewTokenParser(cfg.AuthKeySetURL, &http.Client{Timeout: authKeySetTimeout})
 
 	deliveryServer := serverhttp.NewServer(deliveryService, tokenParser, logger)
 
@@ -131,6 +171,7 @@ func startServer(
 	router.HandleFunc("/health", handleHealth).Methods(http.MethodGet)
 	router.HandleFunc("/ready", handleReady(connector)).Methods(http.MethodGet)
 	router.PathPrefix(serverhttp.PathPrefix).Handler(deliveryServer.MakeHandler())
+	router.PathPrefix(serverhttp.PathPrefixInternal).Handler(deliveryServer.MakeInternalHandler())
 
 	metricsHandler.AddMetricsHandler(router, "/metrics")
 	metricsHandler.AddCommonMetricsMiddleware(router)
@@ -149,6 +190,25 @@ func startServer(
 	return server
 }
 
+func initUserServiceClient(cfg *config) (app.UserServiceClient, error) {
+	if cfg.UserServiceGRPCHost == "" {
+		return userservice.NewClient(http.Client{}, cfg.UserServiceHost), nil
+	}
+	conn, err := grpcclient.Dial(grpcclient.Config{Host: cfg.UserServiceGRPCHost, CallTimeout: cfg.GRPCCallTimeout})
+	if err != nil {
+		return nil, err
+	}
+	return userservice.NewGRPCClient(conn), nil
+}
+
+func initCarrierClient(cfg *config, logger *logrus.Logger) app.CarrierClient {
+	if cfg.CarrierHost == "" {
+		logger.Warn("carrier host not set, fake carrier is used")
+		return carrier.NewFakeClient()
+	}
+	return carrier.NewClient(http.Client{Timeout: cfg.CarrierTimeout}, cfg.CarrierHost)
+}
+
 func handleHealth(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
@@ -3,6 +3,8 @@ package main
 import (
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
+
+	"time"
 )
 
 func parseEnv() (*config, error) {
@@ -21,10 +23,14 @@ func parseEnv() (*config, error) {
 
 type config struct {
 	ServicePort string `envconfig:"service_port" default:"8000"`
-	JWTSecret   string `envconfig:"jwt_secret" default:"secret"`
 
-	BillingServiceHost string `envconfig:"billing_host" default:"http://billing-app:8000"`
-	UserServiceHost    string `envconfig:"user_host" default:"http://user-app:8000"`
+	AuthKeySetURL string `envconfig:"auth_jwks_url" default:"http://auth-app:8000/.well-known/jwks.json"`
+
+	BillingServiceHost     string        `envconfig:"billing_host" default:"http://billing-app:8000"`
+	BillingServiceGRPCHost string        `envconfig:"billing_grpc_host" default:"billing-app:9000"`
+	UserServiceHost        string        `envconfig:"user_host" default:"http://user-app:8000"`
+	UserServiceGRPCHost    string        `envconfig:"user_grpc_host" default:"user-app:9000"`
+	GRPCCallTimeout        time.Duration `envconfig:"grpc_call_timeout" default:"2s"`
 
 	DBHost     string `envconfig:"db_host" default:"localhost"`
 	DBPort     string `envconfig:"db_port" default:"5433"`
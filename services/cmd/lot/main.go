@@ -2,6 +2,7 @@ package main
 
 import (
 	"arch-homework/pkg/common/app/streams"
+	"arch-homework/pkg/common/infrastructure/grpcclient"
 	commonintegrationevent "arch-homework/pkg/common/infrastructure/integrationevent"
 	"arch-homework/pkg/common/infrastructure/metrics"
 	commonpostgres "arch-homework/pkg/common/infrastructure/postgres"
@@ -31,6 +32,8 @@ import (
 const (
 	ReadTimeout  = time.Minute
 	WriteTimeout = time.Minute
+
+	authKeySetTimeout = time.Second * 5
 )
 
 const serviceName = "lot"
@@ -119,8 +122,14 @@ func startServer(
 	if err != nil {
 		logger.Fatal(err)
 	}
-	billingClient := billing.NewClient(http.Client{}, cfg.BillingServiceHost)
-	userClient := user.NewClient(http.Client{}, cfg.UserServiceHost)
+	billingClient, err := initBillingClient(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	userClient, err := initUserClient(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
 
 	eventHandler := app.NewEventHandler(dbDep, integrationevent.NewEventParser(), eventStore, billingClient)
 	if err := commonintegrationevent.StartEventConsumer(rmqEnv, eventHandler, logger); err != nil {
@@ -129,8 +138,9 @@ func startServer(
 
 	lotService := app.NewLotService(dbDep, dbDep, eventStore, billingClient)
 	lotQueryService := postgres.NewLotQueryService(connector.Client(), userClient)
-	tokenParser := jwtauth.NewTokenParser(cfg.JWTSecret)
-	lotServer := serverhttp.NewServer(lotService, lotQueryService, tokenParser, logger)
+	userDeletionService := app.NewUserDeletionService(dbDep, lotQueryService)
+	tokenParser := jwtauth.NewTokenParser(cfg.AuthKeySetURL, &http.Client{Timeout: authKeySetTimeout})
+	lotServer := serverhttp.NewServer(lotService, lotQueryService, userDeletionService, tokenParser, logger)
 
 	app.StartCompletedLotsHandler(ctx, lotService, logger)
 
@@ -157,6 +167,28 @@ func startServer(
 	return server
 }
 
+func initBillingClient(cfg *config) (app.BillingClient, error) {
+	if cfg.BillingServiceGRPCHost == "" {
+		return billing.NewClient(http.Client{}, cfg.BillingServiceHost), nil
+	}
+	conn, err := grpcclient.Dial(grpcclient.Config{Host: cfg.BillingServiceGRPCHost, CallTimeout: cfg.GRPCCallTimeout})
+	if err != nil {
+		return nil, err
+	}
+	return billing.NewGRPCClient(conn), nil
+}
+
+func initUserClient(cfg *config) (app.UserClient, error) {
+	if cfg.UserServiceGRPCHost == "" {
+		return user.NewClient(http.Client{}, cfg.UserServiceHost), nil
+	}
+	conn, err := grpcclient.Dial(grpcclient.Config{Host: cfg.UserServiceGRPCHost, CallTimeout: cfg.GRPCCallTimeout})
+	if err != nil {
+		return nil, err
+	}
+	return user.NewGRPCClient(conn), nil
+}
+
 func handleHealth(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
@@ -16,12 +16,17 @@ func parseEnv() (*config, error) {
 	if c.RMQHost == "" || c.RMQPort == "" || c.RMQUser == "" || c.RMQPassword == "" {
 		return c, errors.New("rabbit mq env params not set")
 	}
+	if c.SMTPHost == "" || c.SMTPPort == "" {
+		return c, errors.New("smtp env params not set")
+	}
 	return c, nil
 }
 
 type config struct {
 	ServicePort string `envconfig:"service_port" default:"8000"`
-	JWTSecret   string `envconfig:"jwt_secret" default:"secret"`
+
+	AuthKeySetURL   string `envconfig:"auth_jwks_url" default:"http://auth-app:8000/.well-known/jwks.json"`
+	AuthServiceHost string `envconfig:"auth_host" default:"http://auth-app:8000"`
 
 	DBHost     string `envconfig:"db_host" default:"localhost"`
 	DBPort     string `envconfig:"db_port" default:"5433"`
@@ -33,4 +38,13 @@ type config struct {
 	RMQPort     string `envconfig:"rmq_port" default:"5552"`
 	RMQUser     string `envconfig:"rmq_user" default:"rmq_user"`
 	RMQPassword string `envconfig:"rmq_password" default:"rmq_pwd"`
+
+	SMTPHost     string `envconfig:"smtp_host" default:"localhost"`
+	SMTPPort     string `envconfig:"smtp_port" default:"1025"`
+	SMTPUser     string `envconfig:"smtp_user"`
+	SMTPPassword string `envconfig:"smtp_password"`
+	SMTPFrom     string `envconfig:"smtp_from" default:"noreply@arch.homework"`
+
+	PasswordResetURL     string `envconfig:"password_reset_url" default:"http://arch.homework/password/reset"`
+	EmailVerificationURL string `envconfig:"email_verification_url" default:"http://arch.homework/email/verify"`
 }
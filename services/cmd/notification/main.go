@@ -8,6 +8,8 @@ import (
 	infrastreams "arch-homework/pkg/common/infrastructure/streams"
 	"arch-homework/pkg/common/jwtauth"
 	"arch-homework/pkg/notification/app"
+	"arch-homework/pkg/notification/infrastructure/auth"
+	"arch-homework/pkg/notification/infrastructure/email"
 	"arch-homework/pkg/notification/infrastructure/integrationevent"
 	"arch-homework/pkg/notification/infrastructure/postgres"
 	serverhttp "arch-homework/pkg/notification/infrastructure/transport/http"
@@ -28,6 +30,9 @@ import (
 const (
 	ReadTimeout  = time.Minute
 	WriteTimeout = time.Minute
+
+	authKeySetTimeout  = time.Second * 5
+	authRequestTimeout = time.Second * 5
 )
 
 const serviceName = "notification"
@@ -109,14 +114,26 @@ func startServer(
 		logger.Fatal(err)
 	}
 
+	emailSender, err := email.NewSMTPSender(email.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		User:     cfg.SMTPUser,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	})
+	if err != nil {
+		logger.Fatal(err)
+	}
+
 	trUnitFactory := postgres.NewTransactionalUnitFactory(connector.Client())
-	eventHandler := app.NewEventHandler(trUnitFactory, integrationevent.NewEventParser())
+	authClient := auth.NewClient(http.Client{Timeout: authRequestTimeout}, cfg.AuthServiceHost)
+	eventHandler := app.NewEventHandler(trUnitFactory, integrationevent.NewEventParser(), emailSender, authClient, cfg.PasswordResetURL, cfg.EmailVerificationURL)
 
 	if err := commonintegrationevent.StartEventConsumer(rmqEnv, eventHandler, logger); err != nil {
 		logger.Fatal(err)
 	}
 
-	tokenParser := jwtauth.NewTokenParser(cfg.JWTSecret)
+	tokenParser := jwtauth.NewTokenParser(cfg.AuthKeySetURL, &http.Client{Timeout: authKeySetTimeout})
 	_ = tokenParser
 
 	notificationRepo := postgres.NewNotificationRepository(connector.Client())
@@ -126,6 +143,7 @@ func startServer(
 	router.HandleFunc("/health", handleHealth).Methods(http.MethodGet)
 	router.HandleFunc("/ready", handleReady(connector)).Methods(http.MethodGet)
 	router.PathPrefix(serverhttp.PathPrefix).Handler(notificationServer.MakeHandler())
+	router.PathPrefix(serverhttp.PathPrefixInternal).Handler(notificationServer.MakeInternalHandler())
 
 	metricsHandler.AddMetricsHandler(router, "/metrics")
 	metricsHandler.AddCommonMetricsMiddleware(router)
@@ -3,6 +3,8 @@ package main
 import (
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
+
+	"time"
 )
 
 func parseEnv() (*config, error) {
@@ -13,14 +15,34 @@ func parseEnv() (*config, error) {
 	if c.DBHost == "" || c.DBPort == "" || c.DBName == "" || c.DBUser == "" || c.DBPassword == "" {
 		return c, errors.New("db env params not set")
 	}
+	// registration waiting for auth service longer than saga timeout would be found by recovery
+	if c.AuthRequestTimeout <= 0 || c.AuthRequestTimeout*2 > c.RegistrationSagaTimeout {
+		return c, errors.New("auth request timeout should be positive and at most half of registration saga timeout")
+	}
 	return c, nil
 }
 
 type config struct {
 	ServicePort string `envconfig:"service_port" default:"8000"`
-	JWTSecret   string `envconfig:"jwt_secret" default:"secret"`
+	GRPCPort    string `envconfig:"grpc_port" default:"9000"`
+
+	AuthKeySetURL string `envconfig:"auth_jwks_url" default:"http://auth-app:8000/.well-known/jwks.json"`
+
+	AuthServiceHost     string        `envconfig:"auth_host" default:"http://auth-app:8000"`
+	AuthServiceGRPCHost string        `envconfig:"auth_grpc_host" default:"auth-app:9000"`
+	GRPCCallTimeout     time.Duration `envconfig:"grpc_call_timeout" default:"2s"`
+	AuthRequestTimeout  time.Duration `envconfig:"auth_request_timeout" default:"10s"`
+
+	LotServiceHost          string `envconfig:"lot_host" default:"http://lot-app:8000"`
+	BillingServiceHost      string `envconfig:"billing_host" default:"http://billing-app:8000"`
+	DeliveryServiceHost     string `envconfig:"delivery_host" default:"http://delivery-app:8000"`
+	NotificationServiceHost string `envconfig:"notification_host" default:"http://notification-app:8000"`
+
+	EmailVerificationSecret   string        `envconfig:"email_verification_secret" default:"email-verification-secret"`
+	EmailVerificationTokenTTL time.Duration `envconfig:"email_verification_token_ttl" default:"24h"`
 
-	AuthServiceHost string `envconfig:"auth_host" default:"http://auth-app:8000"`
+	RegistrationSagaTimeout          time.Duration `envconfig:"registration_saga_timeout" default:"5m"`
+	RegistrationSagaRecoveryInterval time.Duration `envconfig:"registration_saga_recovery_interval" default:"1m"`
 
 	DBHost     string `envconfig:"db_host" default:"localhost"`
 	DBPort     string `envconfig:"db_port" default:"5433"`
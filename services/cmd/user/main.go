@@ -1,16 +1,24 @@
 package main
 
 import (
+	"arch-homework/api/userpb"
 	"arch-homework/pkg/common/app/streams"
+	"arch-homework/pkg/common/infrastructure/grpcclient"
+	"arch-homework/pkg/common/infrastructure/grpcserver"
 	"arch-homework/pkg/common/infrastructure/metrics"
 	commonpostgres "arch-homework/pkg/common/infrastructure/postgres"
 	"arch-homework/pkg/common/infrastructure/storedevent"
 	infrastreams "arch-homework/pkg/common/infrastructure/streams"
 	"arch-homework/pkg/common/jwtauth"
 	"arch-homework/pkg/user/app"
+	"arch-homework/pkg/user/infrastructure/addressvalidation"
+	"arch-homework/pkg/user/infrastructure/encoding"
 	"arch-homework/pkg/user/infrastructure/postgres"
 	"arch-homework/pkg/user/infrastructure/transport/authservice"
+	servergrpc "arch-homework/pkg/user/infrastructure/transport/grpc"
 	serverhttp "arch-homework/pkg/user/infrastructure/transport/http"
+	"arch-homework/pkg/user/infrastructure/transport/lotservice"
+	"arch-homework/pkg/user/infrastructure/transport/userdata"
 
 	"context"
 	"io"
@@ -23,11 +31,14 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 const (
 	ReadTimeout  = time.Minute
 	WriteTimeout = time.Minute
+
+	authKeySetTimeout = time.Second * 5
 )
 
 const serviceName = "user"
@@ -60,9 +71,10 @@ func main() {
 
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	defer cancelFunc()
-	server := startServer(ctx, cfg, connector, rmqEnv, logger, metricsHandler)
+	server, grpcServer := startServer(ctx, cfg, connector, rmqEnv, logger, metricsHandler)
 
 	waitForKillSignal(logger)
+	grpcServer.GracefulStop()
 	if err := server.Shutdown(context.Background()); err != nil {
 		logger.WithError(err).Fatal("http server shutdown failed")
 	}
@@ -106,7 +118,7 @@ func startServer(
 	rmqEnv streams.Environment,
 	logger *logrus.Logger,
 	metricsHandler metrics.PrometheusMetricsHandler,
-) *http.Server {
+) (*http.Server, *grpc.Server) {
 	httpAddress := ":" + cfg.ServicePort
 	if err := connector.WaitUntilReady(); err != nil {
 		logger.Fatal(err)
@@ -117,12 +129,36 @@ func startServer(
 		logger.Fatal(err)
 	}
 
-	authSvcClient := authservice.NewClient(http.Client{}, cfg.AuthServiceHost)
+	authSvcClient, err := initAuthServiceClient(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
 
-	userService := app.NewUserService(dbDep, eventStore, authSvcClient)
+	lotSvcClient := lotservice.NewClient(http.Client{}, cfg.LotServiceHost)
+	userDataSources := []app.UserDataSource{
+		userdata.NewHTTPSource("auth", http.Client{}, cfg.AuthServiceHost),
+		userdata.NewHTTPSource("billing", http.Client{}, cfg.BillingServiceHost),
+		userdata.NewHTTPSource("lot", http.Client{}, cfg.LotServiceHost),
+		userdata.NewHTTPSource("delivery", http.Client{}, cfg.DeliveryServiceHost),
+		userdata.NewHTTPSource("notification", http.Client{}, cfg.NotificationServiceHost),
+	}
 
-	tokenParser := jwtauth.NewTokenParser(cfg.JWTSecret)
-	userServer := serverhttp.NewServer(userService, tokenParser, logger)
+	userService := app.NewUserService(
+		dbDep,
+		eventStore,
+		authSvcClient,
+		authservice.NewUserFinder(http.Client{Timeout: cfg.AuthRequestTimeout}, cfg.AuthServiceHost),
+		lotSvcClient,
+		userDataSources,
+		encoding.NewEmailVerificationTokenSigner([]byte(cfg.EmailVerificationSecret)),
+		cfg.EmailVerificationTokenTTL,
+		cfg.RegistrationSagaTimeout,
+	)
+	app.StartRegistrationSagaRecoveryJob(ctx, userService, cfg.RegistrationSagaRecoveryInterval, logger)
+
+	tokenParser := jwtauth.NewTokenParser(cfg.AuthKeySetURL, &http.Client{Timeout: authKeySetTimeout})
+	addressBookService := app.NewAddressBookService(dbDep, addressvalidation.NewAddressValidator(addressvalidation.DefaultCountryValidators()))
+	userServer := serverhttp.NewServer(userService, addressBookService, tokenParser, logger)
 
 	router := mux.NewRouter()
 	router.HandleFunc("/health", handleHealth).Methods(http.MethodGet)
@@ -144,7 +180,24 @@ func startServer(
 		logger.Fatal(server.ListenAndServe())
 	}()
 
-	return server
+	grpcServer := grpcserver.NewServer(ctx, logger, connector.Ping)
+	userpb.RegisterUserServiceServer(grpcServer, servergrpc.NewServer(userService))
+	go func() {
+		logger.Fatal(grpcserver.Serve(grpcServer, cfg.GRPCPort))
+	}()
+
+	return server, grpcServer
+}
+
+func initAuthServiceClient(cfg *config) (app.AuthServiceClient, error) {
+	if cfg.AuthServiceGRPCHost == "" {
+		return authservice.NewClient(http.Client{Timeout: cfg.AuthRequestTimeout}, cfg.AuthServiceHost), nil
+	}
+	conn, err := grpcclient.Dial(grpcclient.Config{Host: cfg.AuthServiceGRPCHost, CallTimeout: cfg.GRPCCallTimeout})
+	if err != nil {
+		return nil, err
+	}
+	return authservice.NewGRPCClient(conn), nil
 }
 
 func handleHealth(w http.ResponseWriter, _ *http.Request) {
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"github.com/pkg/errors"
+
+	"time"
+)
+
+var ErrRoleNotFound = errors.New("role not found")
+var ErrUserSuspended = errors.New("user suspended")
+var ErrUserNotSuspended = errors.New("user is not suspended")
+var ErrReasonRequired = errors.New("reason required")
+
+// Role - set of permissions, roles and their permissions are seeded by migration
+type Role string
+type Permission string
+
+// UserAccess - roles and permissions of user are included in auth token
+type UserAccess struct {
+	Roles       []Role
+	Permissions []Permission
+}
+
+// UserSuspension - suspended user can't log in, reason and actor are kept for audit
+type UserSuspension struct {
+	UserID       UserID
+	Reason       string
+	SuspendedBy  UserID
+	CreationTime time.Time
+}
+
+type RoleRepositoryRead interface {
+	FindAllRoles() ([]Role, error)
+	FindRolesByUserID(userID UserID) ([]Role, error)
+	FindPermissionsByRoles(roles []Role) ([]Permission, error)
+}
+
+type RoleRepository interface {
+	RoleRepositoryRead
+	StoreUserRoles(userID UserID, roles []Role) error
+}
+
+type UserSuspensionRepositoryRead interface {
+	FindByUserID(userID UserID) (*UserSuspension, error)
+}
+
+type UserSuspensionRepository interface {
+	UserSuspensionRepositoryRead
+	Store(suspension *UserSuspension) error
+	Remove(userID UserID) error
+}
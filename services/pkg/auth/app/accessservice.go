@@ -0,0 +1,154 @@
+package app
+
+import (
+	"github.com/pkg/errors"
+
+	"strings"
+	"time"
+)
+
+func NewAccessService(dbDependency DBDependency, sessionClient SessionClient) *AccessService {
+	return &AccessService{
+		userRepo:       dbDependency.UserRepositoryRead(),
+		roleRepo:       dbDependency.RoleRepositoryRead(),
+		suspensionRepo: dbDependency.UserSuspensionRepositoryRead(),
+		trUnitFactory:  dbDependency,
+		sessionClient:  sessionClient,
+	}
+}
+
+// AccessService - manages roles of users and suspension of users by administrators
+type AccessService struct {
+	userRepo       UserRepositoryRead
+	roleRepo       RoleRepositoryRead
+	suspensionRepo UserSuspensionRepositoryRead
+	trUnitFactory  TransactionalUnitFactory
+	sessionClient  SessionClient
+}
+
+// FindAccess - ErrUserSuspended is returned for suspended user, so token isn't issued for him
+func (s *AccessService) FindAccess(userID UserID) (*UserAccess, error) {
+	if err := s.CheckNotSuspended(userID); err != nil {
+		return nil, err
+	}
+	roles, err := s.roleRepo.FindRolesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	access := UserAccess{Roles: roles}
+	if len(roles) == 0 {
+		return &access, nil
+	}
+	access.Permissions, err = s.roleRepo.FindPermissionsByRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
+
+func (s *AccessService) CheckNotSuspended(userID UserID) error {
+	suspension, err := s.FindSuspension(userID)
+	if err != nil {
+		return err
+	}
+	if suspension != nil {
+		return errors.Wrap(ErrUserSuspended, suspension.Reason)
+	}
+	return nil
+}
+
+func (s *AccessService) FindSuspension(userID UserID) (*UserSuspension, error) {
+	suspension, err := s.suspensionRepo.FindByUserID(userID)
+	if errors.Cause(err) == ErrUserNotSuspended {
+		return nil, nil
+	}
+	return suspension, err
+}
+
+func (s *AccessService) FindRoles(userID UserID) ([]Role, error) {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return nil, err
+	}
+	return s.roleRepo.FindRolesByUserID(userID)
+}
+
+// SetRoles - replaces all roles of user, new roles are included in tokens issued after change
+func (s *AccessService) SetRoles(userID UserID, roles []Role) error {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return err
+	}
+	allRoles, err := s.roleRepo.FindAllRoles()
+	if err != nil {
+		return err
+	}
+	uniqueRoles := make([]Role, 0, len(roles))
+	for _, role := range roles {
+		if !containsRole(allRoles, role) {
+			return errors.Wrapf(ErrRoleNotFound, "role '%s'", role)
+		}
+		if !containsRole(uniqueRoles, role) {
+			uniqueRoles = append(uniqueRoles, role)
+		}
+	}
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		return provider.RoleRepository().StoreUserRoles(userID, uniqueRoles)
+	})
+}
+
+// Suspend - all sessions of user are revoked, so suspension takes effect immediately
+func (s *AccessService) Suspend(userID, actorID UserID, reason string, now time.Time) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.WithStack(ErrReasonRequired)
+	}
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return err
+	}
+	err := s.executeInTransaction(func(provider RepositoryProvider) error {
+		return provider.UserSuspensionRepository().Store(&UserSuspension{
+			UserID:       userID,
+			Reason:       reason,
+			SuspendedBy:  actorID,
+			CreationTime: now,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return s.sessionClient.RemoveAllByUserID(userID)
+}
+
+func (s *AccessService) Unsuspend(userID UserID) error {
+	suspension, err := s.FindSuspension(userID)
+	if err != nil {
+		return err
+	}
+	if suspension == nil {
+		return errors.WithStack(ErrUserNotSuspended)
+	}
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		return provider.UserSuspensionRepository().Remove(userID)
+	})
+}
+
+func (s *AccessService) executeInTransaction(f func(RepositoryProvider) error) (err error) {
+	var trUnit TransactionalUnit
+	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	err = f(trUnit)
+	return err
+}
+
+func containsRole(roles []Role, role Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package app_test
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/auth/infrastructure/encoding"
+	"arch-homework/pkg/common/app/uuid"
+
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRolesDefinePermissions(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	service := app.NewAccessService(db, newTestSessionClient())
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+
+	access, err := service.FindAccess(userID)
+	assert.NoError(t, err)
+	assert.Empty(t, access.Roles)
+	assert.Empty(t, access.Permissions)
+
+	err = service.SetRoles(userID, []app.Role{"moderator", "admin", "moderator"})
+	assert.NoError(t, err)
+	access, err = service.FindAccess(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []app.Role{"moderator", "admin"}, access.Roles)
+	assert.ElementsMatch(t, []app.Permission{"user.suspend", "lot.close", "auth.manage_roles", "billing.adjust_balance"}, access.Permissions)
+
+	err = service.SetRoles(userID, []app.Role{"superuser"})
+	assert.Equal(t, app.ErrRoleNotFound, errors.Cause(err))
+	err = service.SetRoles(app.UserID(uuid.GenerateNew()), []app.Role{"admin"})
+	assert.Equal(t, app.ErrUserNotFound, errors.Cause(err))
+
+	assert.NoError(t, service.SetRoles(userID, nil))
+	roles, err := service.FindRoles(userID)
+	assert.NoError(t, err)
+	assert.Empty(t, roles)
+}
+
+func TestSuspendedUserLosesAccess(t *testing.T) {
+	db := newTestDB()
+	sessionClient := newTestSessionClient()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), sessionClient)
+	service := app.NewAccessService(db, sessionClient)
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	adminID, err := userService.Add("admin", "password")
+	assert.NoError(t, err)
+	assert.NoError(t, sessionClient.Store(app.Session{ID: app.SessionID(uuid.GenerateNew()), UserID: userID}))
+
+	err = service.Suspend(userID, adminID, " ", time.Now())
+	assert.Equal(t, app.ErrReasonRequired, errors.Cause(err))
+
+	err = service.Suspend(userID, adminID, "fraud", time.Now())
+	assert.NoError(t, err)
+	sessions, err := sessionClient.FindAllByUserID(userID)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+	_, err = service.FindAccess(userID)
+	assert.Equal(t, app.ErrUserSuspended, errors.Cause(err))
+	suspension, err := service.FindSuspension(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, "fraud", suspension.Reason)
+	assert.Equal(t, adminID, suspension.SuspendedBy)
+
+	assert.NoError(t, service.Unsuspend(userID))
+	assert.NoError(t, service.CheckNotSuspended(userID))
+	err = service.Unsuspend(userID)
+	assert.Equal(t, app.ErrUserNotSuspended, errors.Cause(err))
+}
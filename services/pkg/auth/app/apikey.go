@@ -0,0 +1,86 @@
+package app
+
+import (
+	"github.com/pkg/errors"
+
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const apiKeyPrefix = "ahk_"
+const apiKeyLen = 32
+const apiKeyVisiblePrefixLen = 12
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+var ErrInvalidAPIKey = errors.New("api key is invalid or expired")
+var ErrInvalidAPIKeyScope = errors.New("invalid api key scope")
+var ErrInvalidAPIKeyExpiration = errors.New("invalid api key expiration time")
+var ErrAPIKeyNameRequired = errors.New("api key name required")
+var ErrAPIKeyLimitExceeded = errors.New("api key limit exceeded")
+
+type APIKeyID string
+
+// APIKeyScope - api key grants access only to services and operations listed in its scopes
+type APIKeyScope string
+
+const (
+	APIKeyScopeLotRead      APIKeyScope = "lot:read"
+	APIKeyScopeLotWrite     APIKeyScope = "lot:write"
+	APIKeyScopeBillingRead  APIKeyScope = "billing:read"
+	APIKeyScopeBillingWrite APIKeyScope = "billing:write"
+)
+
+var apiKeyScopes = []APIKeyScope{APIKeyScopeLotRead, APIKeyScopeLotWrite, APIKeyScopeBillingRead, APIKeyScopeBillingWrite}
+
+// APIKey - only hash of key is stored, raw key is shown to user once on creation
+type APIKey struct {
+	ID             APIKeyID
+	UserID         UserID
+	Name           string
+	KeyHash        string
+	Prefix         string
+	Scopes         []APIKeyScope
+	CreationTime   time.Time
+	ExpirationTime time.Time
+}
+
+type APIKeyRepositoryRead interface {
+	FindByHash(keyHash string) (*APIKey, error)
+	FindAllByUserID(userID UserID) ([]APIKey, error)
+}
+
+type APIKeyRepository interface {
+	APIKeyRepositoryRead
+	Store(key *APIKey) error
+	Remove(userID UserID, id APIKeyID) error
+}
+
+func isValidAPIKeyScope(scope APIKeyScope) bool {
+	for _, s := range apiKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func generateAPIKey() (string, error) {
+	key := make([]byte, apiKeyLen)
+	if _, err := rand.Read(key); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+func isAPIKey(key string) bool {
+	return strings.HasPrefix(key, apiKeyPrefix)
+}
+
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
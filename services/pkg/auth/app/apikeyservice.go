@@ -0,0 +1,132 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/pkg/errors"
+
+	"strings"
+	"time"
+)
+
+const maxAPIKeyNameLen = 255
+const maxAPIKeyLifetime = 365 * 24 * time.Hour
+const maxAPIKeysPerUser = 20
+
+func NewAPIKeyService(dbDependency DBDependency) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:    dbDependency.APIKeyRepositoryRead(),
+		trUnitFactory: dbDependency,
+	}
+}
+
+// APIKeyService - personal api keys for programmatic access, key is exchanged for auth token like session
+type APIKeyService struct {
+	apiKeyRepo    APIKeyRepositoryRead
+	trUnitFactory TransactionalUnitFactory
+}
+
+// Create - returns raw key, which isn't stored and can't be shown again
+func (s *APIKeyService) Create(userID UserID, name string, scopes []APIKeyScope, expirationTime, now time.Time) (*APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.WithStack(ErrAPIKeyNameRequired)
+	}
+	if len(name) > maxAPIKeyNameLen {
+		name = name[:maxAPIKeyNameLen]
+	}
+	if len(scopes) == 0 {
+		return nil, "", errors.Wrap(ErrInvalidAPIKeyScope, "at least one scope required")
+	}
+	uniqueScopes := make([]APIKeyScope, 0, len(scopes))
+	for _, scope := range scopes {
+		if !isValidAPIKeyScope(scope) {
+			return nil, "", errors.Wrapf(ErrInvalidAPIKeyScope, "scope '%s'", scope)
+		}
+		if !containsAPIKeyScope(uniqueScopes, scope) {
+			uniqueScopes = append(uniqueScopes, scope)
+		}
+	}
+	if !expirationTime.After(now) || expirationTime.After(now.Add(maxAPIKeyLifetime)) {
+		return nil, "", errors.WithStack(ErrInvalidAPIKeyExpiration)
+	}
+
+	rawKey, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key := APIKey{
+		ID:             APIKeyID(uuid.GenerateNew()),
+		UserID:         userID,
+		Name:           name,
+		KeyHash:        hashAPIKey(rawKey),
+		Prefix:         rawKey[:apiKeyVisiblePrefixLen],
+		Scopes:         uniqueScopes,
+		CreationTime:   now,
+		ExpirationTime: expirationTime,
+	}
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		keys, err2 := provider.APIKeyRepository().FindAllByUserID(userID)
+		if err2 != nil {
+			return err2
+		}
+		if len(keys) >= maxAPIKeysPerUser {
+			return errors.WithStack(ErrAPIKeyLimitExceeded)
+		}
+		return provider.APIKeyRepository().Store(&key)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &key, rawKey, nil
+}
+
+func (s *APIKeyService) FindAll(userID UserID) ([]APIKey, error) {
+	return s.apiKeyRepo.FindAllByUserID(userID)
+}
+
+func (s *APIKeyService) Revoke(userID UserID, id APIKeyID) error {
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		return provider.APIKeyRepository().Remove(userID, id)
+	})
+}
+
+// Authenticate - unknown and expired keys are reported equally
+func (s *APIKeyService) Authenticate(rawKey string, now time.Time) (*APIKey, error) {
+	if !isAPIKey(rawKey) {
+		return nil, errors.WithStack(ErrInvalidAPIKey)
+	}
+	key, err := s.apiKeyRepo.FindByHash(hashAPIKey(rawKey))
+	if err != nil {
+		if errors.Cause(err) == ErrAPIKeyNotFound {
+			return nil, errors.WithStack(ErrInvalidAPIKey)
+		}
+		return nil, err
+	}
+	if !now.Before(key.ExpirationTime) {
+		return nil, errors.WithStack(ErrInvalidAPIKey)
+	}
+	return key, nil
+}
+
+func (s *APIKeyService) executeInTransaction(f func(RepositoryProvider) error) (err error) {
+	var trUnit TransactionalUnit
+	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	err = f(trUnit)
+	return err
+}
+
+func containsAPIKeyScope(scopes []APIKeyScope, scope APIKeyScope) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package app_test
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/app/uuid"
+
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatedAPIKeyAuthenticatesUntilExpiration(t *testing.T) {
+	db := newTestDB()
+	service := app.NewAPIKeyService(db)
+	userID := app.UserID(uuid.GenerateNew())
+	now := time.Now()
+
+	key, rawKey, err := service.Create(userID, " scripts ", []app.APIKeyScope{"lot:read", "billing:read", "lot:read"}, now.Add(time.Hour), now)
+	assert.NoError(t, err)
+	assert.Equal(t, "scripts", key.Name)
+	assert.Equal(t, []app.APIKeyScope{"lot:read", "billing:read"}, key.Scopes)
+	assert.True(t, strings.HasPrefix(rawKey, key.Prefix))
+	assert.NotContains(t, key.KeyHash, rawKey)
+
+	authenticated, err := service.Authenticate(rawKey, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, key.ID, authenticated.ID)
+	assert.Equal(t, userID, authenticated.UserID)
+
+	_, err = service.Authenticate(rawKey, now.Add(time.Hour))
+	assert.Equal(t, app.ErrInvalidAPIKey, errors.Cause(err))
+	_, err = service.Authenticate(rawKey+"x", now)
+	assert.Equal(t, app.ErrInvalidAPIKey, errors.Cause(err))
+}
+
+func TestAPIKeyValidation(t *testing.T) {
+	db := newTestDB()
+	service := app.NewAPIKeyService(db)
+	userID := app.UserID(uuid.GenerateNew())
+	now := time.Now()
+
+	_, _, err := service.Create(userID, "", []app.APIKeyScope{"lot:read"}, now.Add(time.Hour), now)
+	assert.Equal(t, app.ErrAPIKeyNameRequired, errors.Cause(err))
+	_, _, err = service.Create(userID, "key", nil, now.Add(time.Hour), now)
+	assert.Equal(t, app.ErrInvalidAPIKeyScope, errors.Cause(err))
+	_, _, err = service.Create(userID, "key", []app.APIKeyScope{"user:write"}, now.Add(time.Hour), now)
+	assert.Equal(t, app.ErrInvalidAPIKeyScope, errors.Cause(err))
+	_, _, err = service.Create(userID, "key", []app.APIKeyScope{"lot:read"}, now, now)
+	assert.Equal(t, app.ErrInvalidAPIKeyExpiration, errors.Cause(err))
+	_, _, err = service.Create(userID, "key", []app.APIKeyScope{"lot:read"}, now.AddDate(2, 0, 0), now)
+	assert.Equal(t, app.ErrInvalidAPIKeyExpiration, errors.Cause(err))
+}
+
+func TestRevokedAPIKeyIsRejected(t *testing.T) {
+	db := newTestDB()
+	service := app.NewAPIKeyService(db)
+	userID := app.UserID(uuid.GenerateNew())
+	now := time.Now()
+	key, rawKey, err := service.Create(userID, "key", []app.APIKeyScope{"billing:write"}, now.Add(time.Hour), now)
+	assert.NoError(t, err)
+
+	err = service.Revoke(app.UserID(uuid.GenerateNew()), key.ID)
+	assert.Equal(t, app.ErrAPIKeyNotFound, errors.Cause(err))
+
+	keys, err := service.FindAll(userID)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	assert.NoError(t, service.Revoke(userID, key.ID))
+	_, err = service.Authenticate(rawKey, now)
+	assert.Equal(t, app.ErrInvalidAPIKey, errors.Cause(err))
+	keys, err = service.FindAll(userID)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
@@ -1,11 +1,25 @@
 package app
 
+import "arch-homework/pkg/common/app/storedevent"
+
 type RepositoryProvider interface {
 	UserRepository() UserRepository
+	TwoFactorRepository() TwoFactorRepository
+	PasswordResetTokenRepository() PasswordResetTokenRepository
+	ExternalIdentityRepository() ExternalIdentityRepository
+	RoleRepository() RoleRepository
+	UserSuspensionRepository() UserSuspensionRepository
+	APIKeyRepository() APIKeyRepository
+	EventStore() storedevent.EventStore
 }
 
 type ReadRepositoryProvider interface {
 	UserRepositoryRead() UserRepositoryRead
+	TwoFactorRepositoryRead() TwoFactorRepositoryRead
+	ExternalIdentityRepositoryRead() ExternalIdentityRepositoryRead
+	RoleRepositoryRead() RoleRepositoryRead
+	UserSuspensionRepositoryRead() UserSuspensionRepositoryRead
+	APIKeyRepositoryRead() APIKeyRepositoryRead
 }
 
 type TransactionalUnit interface {
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/integrationevent"
+	"arch-homework/pkg/common/app/uuid"
+
+	"encoding/json"
+	"time"
+)
+
+const typeLoginLocked = "auth.login_locked"
+const typePasswordResetRequested = "auth.password_reset_requested"
+
+func NewLoginLockedEvent(userID UserID, login Login, lockedUntil time.Time) integrationevent.EventData {
+	body, _ := json.Marshal(loginLockedEventBody{
+		UserID:      string(userID),
+		Login:       string(login),
+		LockedUntil: lockedUntil.UTC().Format(time.RFC3339),
+	})
+
+	return integrationevent.EventData{
+		UID:  newUID(),
+		Type: typeLoginLocked,
+		Body: string(body),
+	}
+}
+
+func NewPasswordResetRequestedEvent(userID UserID, email string, tokenID PasswordResetTokenID, expiresAt time.Time) integrationevent.EventData {
+	body, _ := json.Marshal(passwordResetRequestedEventBody{
+		UserID:    string(userID),
+		Email:     email,
+		TokenID:   string(tokenID),
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+	})
+
+	return integrationevent.EventData{
+		UID:  newUID(),
+		Type: typePasswordResetRequested,
+		Body: string(body),
+	}
+}
+
+func newUID() integrationevent.EventUID {
+	return integrationevent.EventUID(uuid.GenerateNew())
+}
+
+type loginLockedEventBody struct {
+	UserID      string `json:"user_id"`
+	Login       string `json:"login"`
+	LockedUntil string `json:"locked_until"`
+}
+
+type passwordResetRequestedEventBody struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	TokenID   string `json:"token_id"`
+	ExpiresAt string `json:"expires_at"`
+}
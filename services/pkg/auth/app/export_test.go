@@ -0,0 +1,4 @@
+package app
+
+var TOTPCode = totpCode
+var TOTPStep = totpStep
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"github.com/pkg/errors"
+
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+)
+
+const oauthRandomLen = 32
+
+var ErrIdentityProviderNotFound = errors.New("identity provider not found")
+var ErrOAuthStateNotFound = errors.New("oauth state is invalid or expired")
+var ErrExternalAuthFailed = errors.New("external authentication failed")
+var ErrExternalIdentityNotFound = errors.New("external identity not found")
+var ErrExternalIdentityLinked = errors.New("external identity is linked to another user")
+var ErrExternalEmailNotVerified = errors.New("email is not provided or not verified by identity provider")
+var ErrExternalEmailAlreadyExists = errors.New("user with this email already exists, log in and link identity provider to the account")
+var ErrIdentityProviderAlreadyLinked = errors.New("identity provider is already linked to user")
+var ErrLastLoginMethod = errors.New("the only login method can't be unlinked, set password first")
+
+type IdentityProviderName string
+
+// ExternalIdentity - link of user to subject of external identity provider
+type ExternalIdentity struct {
+	Provider IdentityProviderName
+	Subject  string
+	UserID   UserID
+}
+
+// ExternalUserInfo - claims of verified id token
+type ExternalUserInfo struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+// IdentityProvider - OpenID Connect provider, authorization code flow with PKCE is used
+type IdentityProvider interface {
+	AuthCodeURL(state, nonce, codeChallenge string) (string, error)
+	// Exchange - exchanges authorization code to id token and verifies it, ErrExternalAuthFailed is returned if provider rejected the code or token is invalid
+	Exchange(code, codeVerifier, nonce string) (*ExternalUserInfo, error)
+}
+
+// ExternalLoginResult - Linked is set when identity provider was linked to logged in user, so new session isn't needed
+type ExternalLoginResult struct {
+	UserID UserID
+	Linked bool
+}
+
+// OAuthState - stored between redirect to identity provider and callback, LinkUserID is set when logged in user links identity provider
+type OAuthState struct {
+	State        string
+	Provider     IdentityProviderName
+	CodeVerifier string
+	Nonce        string
+	LinkUserID   *UserID
+}
+
+type OAuthStateStore interface {
+	Store(state OAuthState, ttl time.Duration) error
+	// Take - removes state and returns it, so callback can be processed only once
+	Take(state string) (*OAuthState, error)
+}
+
+type ExternalIdentityRepositoryRead interface {
+	FindByProviderAndSubject(provider IdentityProviderName, subject string) (*ExternalIdentity, error)
+	FindAllByUserID(userID UserID) ([]ExternalIdentity, error)
+}
+
+type ExternalIdentityRepository interface {
+	ExternalIdentityRepositoryRead
+	Store(identity *ExternalIdentity) error
+	Remove(provider IdentityProviderName, userID UserID) error
+}
+
+// UserServiceClient - users are registered through user service, so user profile and registration event are created
+type UserServiceClient interface {
+	RegisterExternalUser(login Login, firstName, lastName, email string) (UserID, error)
+}
+
+func generateOAuthRandom() (string, error) {
+	value := make([]byte, oauthRandomLen)
+	if _, err := rand.Read(value); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+// pkceCodeChallenge - S256 code challenge method (RFC 7636)
+func pkceCodeChallenge(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"github.com/pkg/errors"
+
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// OAuthStateTTL - time to complete login at identity provider
+const OAuthStateTTL = 10 * time.Minute
+
+func NewExternalLoginService(
+	dbDependency DBDependency,
+	providers map[IdentityProviderName]IdentityProvider,
+	stateStore OAuthStateStore,
+	userServiceClient UserServiceClient,
+) *ExternalLoginService {
+	return &ExternalLoginService{
+		userRepo:          dbDependency.UserRepositoryRead(),
+		readRepo:          dbDependency.ExternalIdentityRepositoryRead(),
+		trUnitFactory:     dbDependency,
+		providers:         providers,
+		stateStore:        stateStore,
+		userServiceClient: userServiceClient,
+	}
+}
+
+// ExternalLoginService - login with external OpenID Connect providers
+type ExternalLoginService struct {
+	userRepo          UserRepositoryRead
+	readRepo          ExternalIdentityRepositoryRead
+	trUnitFactory     TransactionalUnitFactory
+	providers         map[IdentityProviderName]IdentityProvider
+	stateStore        OAuthStateStore
+	userServiceClient UserServiceClient
+}
+
+// StartLogin - returns url of identity provider to redirect user to and state, which should be stored in browser of user
+// to bind callback to it. linkUserID is set to link provider to logged in user
+func (s *ExternalLoginService) StartLogin(providerName IdentityProviderName, linkUserID *UserID) (authURL string, stateValue string, err error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", errors.WithStack(ErrIdentityProviderNotFound)
+	}
+
+	state := OAuthState{
+		Provider:   providerName,
+		LinkUserID: linkUserID,
+	}
+	for _, value := range []*string{&state.State, &state.CodeVerifier, &state.Nonce} {
+		if *value, err = generateOAuthRandom(); err != nil {
+			return "", "", err
+		}
+	}
+
+	authURL, err = provider.AuthCodeURL(state.State, state.Nonce, pkceCodeChallenge(state.CodeVerifier))
+	if err != nil {
+		return "", "", err
+	}
+	if err = s.stateStore.Store(state, OAuthStateTTL); err != nil {
+		return "", "", err
+	}
+	return authURL, state.State, nil
+}
+
+// CompleteLogin - processes callback of identity provider. Linked user is returned,
+// if external identity isn't linked, it is linked to logged in user or new user is registered.
+// browserState - state stored in browser which started login, callback url passed to another user is rejected
+func (s *ExternalLoginService) CompleteLogin(providerName IdentityProviderName, stateValue, browserState, code string) (*ExternalLoginResult, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, errors.WithStack(ErrIdentityProviderNotFound)
+	}
+	if stateValue == "" || subtle.ConstantTimeCompare([]byte(stateValue), []byte(browserState)) != 1 {
+		return nil, errors.WithStack(ErrOAuthStateNotFound)
+	}
+	state, err := s.stateStore.Take(stateValue)
+	if err != nil {
+		return nil, err
+	}
+	if state.Provider != providerName {
+		return nil, errors.WithStack(ErrOAuthStateNotFound)
+	}
+
+	info, err := provider.Exchange(code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExternalLoginResult{Linked: state.LinkUserID != nil}
+
+	identity, err := s.readRepo.FindByProviderAndSubject(providerName, info.Subject)
+	if err == nil {
+		if state.LinkUserID != nil && *state.LinkUserID != identity.UserID {
+			return nil, errors.WithStack(ErrExternalIdentityLinked)
+		}
+		result.UserID = identity.UserID
+		return result, nil
+	}
+	if errors.Cause(err) != ErrExternalIdentityNotFound {
+		return nil, err
+	}
+
+	result.UserID, err = s.findOrRegisterUser(providerName, info, state.LinkUserID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		return provider.ExternalIdentityRepository().Store(&ExternalIdentity{
+			Provider: providerName,
+			Subject:  info.Subject,
+			UserID:   result.UserID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *ExternalLoginService) FindIdentities(userID UserID) ([]ExternalIdentity, error) {
+	return s.readRepo.FindAllByUserID(userID)
+}
+
+func (s *ExternalLoginService) Unlink(userID UserID, providerName IdentityProviderName) error {
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		user, err := provider.UserRepository().FindByID(userID)
+		if err != nil {
+			return err
+		}
+		repo := provider.ExternalIdentityRepository()
+		identities, err := repo.FindAllByUserID(userID)
+		if err != nil {
+			return err
+		}
+		linked := false
+		for _, identity := range identities {
+			linked = linked || identity.Provider == providerName
+		}
+		if !linked {
+			return errors.WithStack(ErrExternalIdentityNotFound)
+		}
+		if !user.HasPassword() && len(identities) == 1 {
+			return errors.WithStack(ErrLastLoginMethod)
+		}
+		return repo.Remove(providerName, userID)
+	})
+}
+
+func (s *ExternalLoginService) findOrRegisterUser(providerName IdentityProviderName, info *ExternalUserInfo, linkUserID *UserID) (UserID, error) {
+	if linkUserID != nil {
+		user, err := s.userRepo.FindByID(*linkUserID)
+		if err != nil {
+			return "", err
+		}
+		identities, err := s.readRepo.FindAllByUserID(user.UserID)
+		if err != nil {
+			return "", err
+		}
+		for _, identity := range identities {
+			if identity.Provider == providerName {
+				return "", errors.WithStack(ErrIdentityProviderAlreadyLinked)
+			}
+		}
+		return user.UserID, nil
+	}
+
+	// accounts are not linked by email automatically, otherwise account could be taken over
+	// through identity provider, which doesn't verify emails
+	if info.Email == "" || !info.EmailVerified {
+		return "", errors.WithStack(ErrExternalEmailNotVerified)
+	}
+	login := externalUserLogin(providerName, info.Subject)
+	// user could be registered by previous attempt, which failed to store the link
+	user, err := s.userRepo.FindByLogin(login)
+	if err == nil {
+		return user.UserID, nil
+	}
+	if errors.Cause(err) != ErrUserNotFound {
+		return "", err
+	}
+	return s.userServiceClient.RegisterExternalUser(login, info.FirstName, info.LastName, info.Email)
+}
+
+func (s *ExternalLoginService) executeInTransaction(f func(RepositoryProvider) error) (err error) {
+	var trUnit TransactionalUnit
+	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	err = f(trUnit)
+	return err
+}
+
+// externalUserLogin - login of registered user is derived from subject, so it is unique and stable
+func externalUserLogin(providerName IdentityProviderName, subject string) Login {
+	login := fmt.Sprintf("%s:%s", providerName, subject)
+	if len(login) > maxLoginLen {
+		hash := sha256.Sum256([]byte(subject))
+		login = fmt.Sprintf("%s:%s", providerName, hex.EncodeToString(hash[:]))
+	}
+	return Login(login)
+}
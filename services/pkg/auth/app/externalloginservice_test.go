@@ -0,0 +1,206 @@
+package app_test
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/auth/infrastructure/encoding"
+
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testProvider = app.IdentityProviderName("mock")
+
+func TestExternalLoginRegistersUserThroughUserService(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	userServiceClient := &testUserServiceClient{userService: userService}
+	provider := &testIdentityProvider{info: app.ExternalUserInfo{Subject: "subject-1", Email: "user@example.com", EmailVerified: true}}
+	service := newTestExternalLoginService(db, provider, userServiceClient)
+
+	result, err := loginWithProvider(service, provider, nil)
+	assert.NoError(t, err)
+	assert.False(t, result.Linked)
+	assert.Equal(t, 1, userServiceClient.registered)
+	user, err := userService.FindUserByID(result.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, app.Login("mock:subject-1"), user.Login)
+	assert.False(t, user.HasPassword())
+
+	again, err := loginWithProvider(service, provider, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, result.UserID, again.UserID)
+	assert.Equal(t, 1, userServiceClient.registered)
+
+	// password login isn't possible for user without password
+	_, err = userService.FindUserByLoginAndPassword("mock:subject-1", "")
+	assert.Equal(t, app.ErrInvalidPassword, errors.Cause(err))
+	err = service.Unlink(result.UserID, testProvider)
+	assert.Equal(t, app.ErrLastLoginMethod, errors.Cause(err))
+}
+
+func TestExternalLoginRequiresVerifiedEmail(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	userServiceClient := &testUserServiceClient{userService: userService}
+	provider := &testIdentityProvider{info: app.ExternalUserInfo{Subject: "subject-1", Email: "user@example.com"}}
+	service := newTestExternalLoginService(db, provider, userServiceClient)
+
+	_, err := loginWithProvider(service, provider, nil)
+	assert.Equal(t, app.ErrExternalEmailNotVerified, errors.Cause(err))
+	assert.Equal(t, 0, userServiceClient.registered)
+}
+
+func TestExternalIdentityLinkedToLoggedInUser(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	userServiceClient := &testUserServiceClient{userService: userService}
+	provider := &testIdentityProvider{info: app.ExternalUserInfo{Subject: "subject-1"}}
+	service := newTestExternalLoginService(db, provider, userServiceClient)
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	anotherUserID, err := userService.Add("another", "password")
+	assert.NoError(t, err)
+
+	result, err := loginWithProvider(service, provider, &userID)
+	assert.NoError(t, err)
+	assert.True(t, result.Linked)
+	assert.Equal(t, userID, result.UserID)
+	assert.Equal(t, 0, userServiceClient.registered)
+
+	_, err = loginWithProvider(service, provider, &anotherUserID)
+	assert.Equal(t, app.ErrExternalIdentityLinked, errors.Cause(err))
+
+	provider.info.Subject = "subject-2"
+	_, err = loginWithProvider(service, provider, &userID)
+	assert.Equal(t, app.ErrIdentityProviderAlreadyLinked, errors.Cause(err))
+
+	_, err = loginWithProvider(service, provider, nil)
+	assert.Equal(t, app.ErrExternalEmailNotVerified, errors.Cause(err))
+
+	assert.NoError(t, service.Unlink(userID, testProvider))
+	identities, err := service.FindIdentities(userID)
+	assert.NoError(t, err)
+	assert.Empty(t, identities)
+}
+
+func TestExternalLoginStateIsSingleUse(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	provider := &testIdentityProvider{info: app.ExternalUserInfo{Subject: "subject-1", Email: "user@example.com", EmailVerified: true}}
+	service := newTestExternalLoginService(db, provider, &testUserServiceClient{userService: userService})
+
+	authURL, browserState, err := service.StartLogin(testProvider, nil)
+	assert.NoError(t, err)
+	state := parseAuthURL(t, authURL).Get("state")
+	assert.Equal(t, state, browserState)
+
+	_, err = service.CompleteLogin("another", state, browserState, "code")
+	assert.Equal(t, app.ErrIdentityProviderNotFound, errors.Cause(err))
+	_, err = service.CompleteLogin(testProvider, state, browserState, "code")
+	assert.NoError(t, err)
+	_, err = service.CompleteLogin(testProvider, state, browserState, "code")
+	assert.Equal(t, app.ErrOAuthStateNotFound, errors.Cause(err))
+}
+
+func TestExternalLoginCallbackRejectedInAnotherBrowser(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	provider := &testIdentityProvider{info: app.ExternalUserInfo{Subject: "subject-1"}}
+	service := newTestExternalLoginService(db, provider, &testUserServiceClient{userService: userService})
+	attackerID, err := userService.Add("attacker", "password")
+	assert.NoError(t, err)
+
+	// attacker starts linking and passes callback url to victim, whose browser has no or another state
+	authURL, _, err := service.StartLogin(testProvider, &attackerID)
+	assert.NoError(t, err)
+	query := parseAuthURL(t, authURL)
+	provider.nonce = query.Get("nonce")
+	_, victimState, err := service.StartLogin(testProvider, nil)
+	assert.NoError(t, err)
+
+	for _, browserState := range []string{"", victimState} {
+		_, err = service.CompleteLogin(testProvider, query.Get("state"), browserState, "code")
+		assert.Equal(t, app.ErrOAuthStateNotFound, errors.Cause(err))
+	}
+	identities, err := service.FindIdentities(attackerID)
+	assert.NoError(t, err)
+	assert.Empty(t, identities)
+
+	_, err = service.CompleteLogin(testProvider, query.Get("state"), query.Get("state"), "code")
+	assert.NoError(t, err, "state isn't consumed by rejected callback")
+}
+
+func newTestExternalLoginService(db *testDB, provider app.IdentityProvider, client app.UserServiceClient) *app.ExternalLoginService {
+	providers := map[app.IdentityProviderName]app.IdentityProvider{testProvider: provider}
+	return app.NewExternalLoginService(db, providers, &testOAuthStateStore{states: map[string]app.OAuthState{}}, client)
+}
+
+func loginWithProvider(service *app.ExternalLoginService, provider *testIdentityProvider, linkUserID *app.UserID) (*app.ExternalLoginResult, error) {
+	authURL, browserState, err := service.StartLogin(testProvider, linkUserID)
+	if err != nil {
+		return nil, err
+	}
+	query, err := url.ParseQuery(authURL)
+	if err != nil {
+		return nil, err
+	}
+	provider.nonce = query.Get("nonce")
+	return service.CompleteLogin(testProvider, query.Get("state"), browserState, "code")
+}
+
+func parseAuthURL(t *testing.T, authURL string) url.Values {
+	query, err := url.ParseQuery(authURL)
+	assert.NoError(t, err)
+	return query
+}
+
+// testIdentityProvider - auth code url contains state and nonce, exchange checks that nonce was passed back
+type testIdentityProvider struct {
+	info  app.ExternalUserInfo
+	nonce string
+}
+
+func (p *testIdentityProvider) AuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	return url.Values{"state": {state}, "nonce": {nonce}, "code_challenge": {codeChallenge}}.Encode(), nil
+}
+
+func (p *testIdentityProvider) Exchange(code, codeVerifier, nonce string) (*app.ExternalUserInfo, error) {
+	if code == "" || codeVerifier == "" || (p.nonce != "" && nonce != p.nonce) {
+		return nil, errors.WithStack(app.ErrExternalAuthFailed)
+	}
+	info := p.info
+	return &info, nil
+}
+
+type testOAuthStateStore struct {
+	states map[string]app.OAuthState
+}
+
+func (s *testOAuthStateStore) Store(state app.OAuthState, _ time.Duration) error {
+	s.states[state.State] = state
+	return nil
+}
+
+func (s *testOAuthStateStore) Take(state string) (*app.OAuthState, error) {
+	value, ok := s.states[state]
+	if !ok {
+		return nil, errors.WithStack(app.ErrOAuthStateNotFound)
+	}
+	delete(s.states, state)
+	return &value, nil
+}
+
+// testUserServiceClient - user service registers user in auth service without password
+type testUserServiceClient struct {
+	userService *app.UserService
+	registered  int
+}
+
+func (c *testUserServiceClient) RegisterExternalUser(login app.Login, _, _, _ string) (app.UserID, error) {
+	c.registered++
+	return c.userService.Add(string(login), "")
+}
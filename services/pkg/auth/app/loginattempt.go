@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrLoginLocked = errors.New("login temporarily locked")
+var ErrLoginThrottled = errors.New("too many failed login attempts")
+
+type LoginAttemptKey string
+
+type LoginAttempts struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// LoginAttemptStore - failures are counted in sliding window, lock expires by itself
+type LoginAttemptStore interface {
+	FindAttempts(key LoginAttemptKey, since time.Time) (LoginAttempts, error)
+	AddFailure(key LoginAttemptKey, now time.Time, window time.Duration) (LoginAttempts, error)
+	ResetFailures(key LoginAttemptKey) error
+	FindLock(key LoginAttemptKey) (lockedUntil *time.Time, err error)
+	Lock(key LoginAttemptKey, until time.Time) error
+	Unlock(key LoginAttemptKey) error
+}
+
+// LoginThrottlePolicy - zero value of max failures means that lockout is disabled
+type LoginThrottlePolicy struct {
+	Window             time.Duration
+	DelayAfterFailures int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	MaxLoginFailures   int
+	MaxIPFailures      int
+	LockoutDuration    time.Duration
+}
+
+// Delay - progressive delay before next attempt, doubles on each failure after DelayAfterFailures
+func (p LoginThrottlePolicy) Delay(failures int) time.Duration {
+	if failures < p.DelayAfterFailures || p.BaseDelay == 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := p.DelayAfterFailures; i < failures; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+func LoginAttemptKeyForLogin(login Login) LoginAttemptKey {
+	return LoginAttemptKey(fmt.Sprintf("login:%s", string(login)))
+}
+
+func LoginAttemptKeyForIP(ip string) LoginAttemptKey {
+	return LoginAttemptKey(fmt.Sprintf("ip:%s", ip))
+}
+
+// RetryAfter - returns time after which rejected login attempt can be repeated
+func RetryAfter(err error) (time.Duration, bool) {
+	var e *retryAfterError
+	if errors.As(err, &e) {
+		return e.retryAfter, true
+	}
+	return 0, false
+}
+
+func newRetryAfterError(cause error, retryAfter time.Duration) error {
+	return &retryAfterError{cause: cause, retryAfter: retryAfter}
+}
+
+type retryAfterError struct {
+	cause      error
+	retryAfter time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", e.cause.Error(), e.retryAfter.Round(time.Second))
+}
+
+func (e *retryAfterError) Cause() error {
+	return e.cause
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/storedevent"
+
+	"github.com/pkg/errors"
+
+	"time"
+)
+
+func NewLoginService(
+	userService *UserService,
+	trUnitFactory TransactionalUnitFactory,
+	attemptStore LoginAttemptStore,
+	eventSender storedevent.Sender,
+	policy LoginThrottlePolicy,
+) *LoginService {
+	return &LoginService{
+		userService:   userService,
+		trUnitFactory: trUnitFactory,
+		attemptStore:  attemptStore,
+		eventSender:   eventSender,
+		policy:        policy,
+	}
+}
+
+// LoginService - protects password check from brute-force by login and by client ip
+type LoginService struct {
+	userService   *UserService
+	trUnitFactory TransactionalUnitFactory
+	attemptStore  LoginAttemptStore
+	eventSender   storedevent.Sender
+	policy        LoginThrottlePolicy
+}
+
+func (s *LoginService) Login(login, password, ip string, now time.Time) (*User, error) {
+	loginKey := LoginAttemptKeyForLogin(Login(login))
+	keys := []LoginAttemptKey{loginKey}
+	if ip != "" {
+		keys = append(keys, LoginAttemptKeyForIP(ip))
+	}
+
+	for _, key := range keys {
+		if err := s.checkAttemptAllowed(key, now); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.userService.FindUserByLoginAndPassword(login, password)
+	if err != nil {
+		if cause := errors.Cause(err); cause == ErrInvalidPassword || cause == ErrUserNotFound {
+			if err2 := s.registerFailure(Login(login), ip, now); err2 != nil {
+				return nil, err2
+			}
+		}
+		return nil, err
+	}
+
+	if err = s.attemptStore.ResetFailures(loginKey); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *LoginService) Unlock(login *Login, ip *string) error {
+	var keys []LoginAttemptKey
+	if login != nil {
+		keys = append(keys, LoginAttemptKeyForLogin(*login))
+	}
+	if ip != nil {
+		keys = append(keys, LoginAttemptKeyForIP(*ip))
+	}
+	for _, key := range keys {
+		if err := s.attemptStore.Unlock(key); err != nil {
+			return err
+		}
+		if err := s.attemptStore.ResetFailures(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LoginService) checkAttemptAllowed(key LoginAttemptKey, now time.Time) error {
+	lockedUntil, err := s.attemptStore.FindLock(key)
+	if err != nil {
+		return err
+	}
+	if lockedUntil != nil && lockedUntil.After(now) {
+		return newRetryAfterError(ErrLoginLocked, lockedUntil.Sub(now))
+	}
+
+	attempts, err := s.attemptStore.FindAttempts(key, now.Add(-s.policy.Window))
+	if err != nil {
+		return err
+	}
+	allowedTime := attempts.LastFailure.Add(s.policy.Delay(attempts.Failures))
+	if attempts.Failures > 0 && allowedTime.After(now) {
+		return newRetryAfterError(ErrLoginThrottled, allowedTime.Sub(now))
+	}
+	return nil
+}
+
+func (s *LoginService) registerFailure(login Login, ip string, now time.Time) error {
+	loginKey := LoginAttemptKeyForLogin(login)
+	attempts, err := s.attemptStore.AddFailure(loginKey, now, s.policy.Window)
+	if err != nil {
+		return err
+	}
+	if s.policy.MaxLoginFailures != 0 && attempts.Failures >= s.policy.MaxLoginFailures {
+		if err = s.lockLogin(login, now.Add(s.policy.LockoutDuration)); err != nil {
+			return err
+		}
+	}
+
+	if ip == "" {
+		return nil
+	}
+	ipKey := LoginAttemptKeyForIP(ip)
+	attempts, err = s.attemptStore.AddFailure(ipKey, now, s.policy.Window)
+	if err != nil {
+		return err
+	}
+	if s.policy.MaxIPFailures != 0 && attempts.Failures >= s.policy.MaxIPFailures {
+		if err = s.attemptStore.Lock(ipKey, now.Add(s.policy.LockoutDuration)); err != nil {
+			return err
+		}
+		return s.attemptStore.ResetFailures(ipKey)
+	}
+	return nil
+}
+
+func (s *LoginService) lockLogin(login Login, until time.Time) error {
+	key := LoginAttemptKeyForLogin(login)
+	if err := s.attemptStore.Lock(key, until); err != nil {
+		return err
+	}
+	if err := s.attemptStore.ResetFailures(key); err != nil {
+		return err
+	}
+
+	user, err := s.userService.FindUserByLogin(login)
+	if err != nil {
+		if errors.Cause(err) == ErrUserNotFound {
+			// nobody to warn about attempts to guess password for not existing login
+			return nil
+		}
+		return err
+	}
+
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		event := NewLoginLockedEvent(user.UserID, user.Login, until)
+		err2 := provider.EventStore().Add(event)
+		if err2 != nil {
+			return err2
+		}
+		s.eventSender.EventStored(event.UID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.eventSender.SendStoredEvents()
+	return nil
+}
+
+func (s *LoginService) executeInTransaction(f func(RepositoryProvider) error) (err error) {
+	var trUnit TransactionalUnit
+	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	err = f(trUnit)
+	return err
+}
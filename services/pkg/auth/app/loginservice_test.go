@@ -0,0 +1,176 @@
+package app_test
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/auth/infrastructure/encoding"
+	"arch-homework/pkg/common/app/integrationevent"
+
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPolicy = app.LoginThrottlePolicy{
+	Window:             time.Minute * 15,
+	DelayAfterFailures: 3,
+	BaseDelay:          time.Second,
+	MaxDelay:           time.Second * 4,
+	MaxLoginFailures:   6,
+	MaxIPFailures:      8,
+	LockoutDuration:    time.Minute * 15,
+}
+
+func TestLoginThrottlePolicyDelay(t *testing.T) {
+	expected := []time.Duration{0, 0, 0, time.Second, time.Second * 2, time.Second * 4, time.Second * 4}
+	for failures, delay := range expected {
+		assert.Equal(t, delay, testPolicy.Delay(failures), "failures %d", failures)
+	}
+}
+
+func TestLoginProgressiveDelayAndLockout(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	sender := &testEventSender{}
+	service := app.NewLoginService(userService, db, newTestAttemptStore(), sender, testPolicy)
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err = service.Login("user", "wrong password", "10.0.0.1", now)
+		assert.Equal(t, app.ErrInvalidPassword, errors.Cause(err))
+	}
+
+	_, err = service.Login("user", "password", "10.0.0.1", now)
+	assert.Equal(t, app.ErrLoginThrottled, errors.Cause(err), "attempt before delay expiration should be rejected")
+	retryAfter, ok := app.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, retryAfter)
+
+	for i := 3; i < testPolicy.MaxLoginFailures; i++ {
+		now = now.Add(testPolicy.MaxDelay)
+		_, err = service.Login("user", "wrong password", "10.0.0.1", now)
+		assert.Equal(t, app.ErrInvalidPassword, errors.Cause(err))
+	}
+	assert.Len(t, db.events, 1)
+	assert.Equal(t, "auth.login_locked", db.events[0].Type)
+	assert.Contains(t, db.events[0].Body, string(userID))
+	assert.Len(t, sender.storedUIDs, 1)
+
+	now = now.Add(time.Minute)
+	_, err = service.Login("user", "password", "10.0.0.2", now)
+	assert.Equal(t, app.ErrLoginLocked, errors.Cause(err), "lock by login should not depend on ip")
+	retryAfter, _ = app.RetryAfter(err)
+	assert.Equal(t, testPolicy.LockoutDuration-time.Minute, retryAfter)
+
+	login := app.Login("user")
+	assert.NoError(t, service.Unlock(&login, nil))
+	user, err := service.Login("user", "password", "10.0.0.2", now)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, user.UserID)
+}
+
+func TestLoginLockoutByIP(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	_, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	service := app.NewLoginService(userService, db, newTestAttemptStore(), &testEventSender{}, testPolicy)
+
+	now := time.Now()
+	for i := 0; i < testPolicy.MaxIPFailures; i++ {
+		now = now.Add(testPolicy.MaxDelay)
+		_, err = service.Login(string(rune('a'+i)), "password", "10.0.0.1", now)
+		assert.Equal(t, app.ErrUserNotFound, errors.Cause(err))
+	}
+	assert.Empty(t, db.events, "nobody to warn about not existing logins")
+
+	_, err = service.Login("user", "password", "10.0.0.1", now)
+	assert.Equal(t, app.ErrLoginLocked, errors.Cause(err))
+
+	_, err = service.Login("user", "password", "10.0.0.2", now)
+	assert.NoError(t, err)
+}
+
+func TestSuccessfulLoginResetsFailures(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	_, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	service := app.NewLoginService(userService, db, newTestAttemptStore(), &testEventSender{}, testPolicy)
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		_, err = service.Login("user", "wrong password", "", now)
+		assert.Equal(t, app.ErrInvalidPassword, errors.Cause(err))
+	}
+	_, err = service.Login("user", "password", "", now)
+	assert.NoError(t, err)
+
+	_, err = service.Login("user", "wrong password", "", now)
+	assert.Equal(t, app.ErrInvalidPassword, errors.Cause(err), "failures should be counted from scratch")
+}
+
+func newTestAttemptStore() *testAttemptStore {
+	return &testAttemptStore{
+		failures: make(map[app.LoginAttemptKey][]time.Time),
+		locks:    make(map[app.LoginAttemptKey]time.Time),
+	}
+}
+
+type testAttemptStore struct {
+	failures map[app.LoginAttemptKey][]time.Time
+	locks    map[app.LoginAttemptKey]time.Time
+}
+
+func (s *testAttemptStore) FindAttempts(key app.LoginAttemptKey, since time.Time) (app.LoginAttempts, error) {
+	var attempts app.LoginAttempts
+	for _, failureTime := range s.failures[key] {
+		if !failureTime.Before(since) {
+			attempts.Failures++
+			attempts.LastFailure = failureTime
+		}
+	}
+	return attempts, nil
+}
+
+func (s *testAttemptStore) AddFailure(key app.LoginAttemptKey, now time.Time, window time.Duration) (app.LoginAttempts, error) {
+	s.failures[key] = append(s.failures[key], now)
+	return s.FindAttempts(key, now.Add(-window))
+}
+
+func (s *testAttemptStore) ResetFailures(key app.LoginAttemptKey) error {
+	delete(s.failures, key)
+	return nil
+}
+
+func (s *testAttemptStore) FindLock(key app.LoginAttemptKey) (*time.Time, error) {
+	lockedUntil, ok := s.locks[key]
+	if !ok {
+		return nil, nil
+	}
+	return &lockedUntil, nil
+}
+
+func (s *testAttemptStore) Lock(key app.LoginAttemptKey, until time.Time) error {
+	s.locks[key] = until
+	return nil
+}
+
+func (s *testAttemptStore) Unlock(key app.LoginAttemptKey) error {
+	delete(s.locks, key)
+	return nil
+}
+
+type testEventSender struct {
+	storedUIDs []integrationevent.EventUID
+}
+
+func (s *testEventSender) EventStored(uid integrationevent.EventUID) {
+	s.storedUIDs = append(s.storedUIDs, uid)
+}
+
+func (s *testEventSender) SendStoredEvents() {
+}
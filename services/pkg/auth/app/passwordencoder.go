@@ -1,5 +1,8 @@
 package app
 
+// PasswordEncoder stores algorithm and its parameters together with password hash,
+// so passwords encoded by outdated algorithms can be verified and rehashed
 type PasswordEncoder interface {
-	Encode(rawPassword string, userID UserID) Password
+	Encode(rawPassword string, userID UserID) (Password, error)
+	Verify(rawPassword string, userID UserID, encodedPassword Password) (matched bool, needsRehash bool, err error)
 }
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/pkg/errors"
+
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+const passwordResetTokenLen = 32
+
+var ErrPasswordResetTokenNotFound = errors.New("password reset token is invalid or expired")
+
+type PasswordResetTokenID uuid.UUID
+
+// PasswordResetToken - only hash of token is stored, raw token is issued to notification service by ID
+// right before it is sent to user by email, so it isn't stored in events
+type PasswordResetToken struct {
+	ID        PasswordResetTokenID
+	TokenHash string
+	UserID    UserID
+	ExpiresAt time.Time
+}
+
+type PasswordResetTokenRepository interface {
+	Store(token *PasswordResetToken) error
+	// Take - removes token and returns it, so token can be used only once
+	Take(tokenHash string) (*PasswordResetToken, error)
+	// ReplaceHash - previously issued token becomes invalid
+	ReplaceHash(id PasswordResetTokenID, tokenHash string) (*PasswordResetToken, error)
+	RemoveAllByUserID(userID UserID) error
+}
+
+func generatePasswordResetToken() (string, error) {
+	token := make([]byte, passwordResetTokenLen)
+	if _, err := rand.Read(token); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+func hashPasswordResetToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/storedevent"
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/pkg/errors"
+
+	"time"
+)
+
+func NewPasswordService(
+	dbDependency DBDependency,
+	passwordEncoder PasswordEncoder,
+	sessionClient SessionClient,
+	eventSender storedevent.Sender,
+	resetTokenTTL time.Duration,
+) *PasswordService {
+	return &PasswordService{
+		readRepo:        dbDependency.UserRepositoryRead(),
+		trUnitFactory:   dbDependency,
+		passwordEncoder: passwordEncoder,
+		sessionClient:   sessionClient,
+		eventSender:     eventSender,
+		resetTokenTTL:   resetTokenTTL,
+	}
+}
+
+type PasswordService struct {
+	readRepo        UserRepositoryRead
+	trUnitFactory   TransactionalUnitFactory
+	passwordEncoder PasswordEncoder
+	sessionClient   SessionClient
+	eventSender     storedevent.Sender
+	resetTokenTTL   time.Duration
+}
+
+// ChangePassword - all sessions except current one are revoked, issued reset tokens become invalid
+func (s *PasswordService) ChangePassword(userID UserID, currentSessionID SessionID, currentPassword, newPassword string) error {
+	user, err := s.readRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.HasPassword() {
+		return errors.WithStack(ErrInvalidPassword)
+	}
+	matched, _, err := s.passwordEncoder.Verify(currentPassword, user.UserID, user.Password)
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return errors.WithStack(ErrInvalidPassword)
+	}
+
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		return s.setPassword(provider, user, newPassword)
+	})
+	if err != nil {
+		return err
+	}
+
+	sessions, err := s.sessionClient.FindAllByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+		if err = s.sessionClient.Remove(session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequestReset - creates single-use token, event contains only token ID,
+// raw token is issued by IssueResetToken to notification service, which delivers it to email
+func (s *PasswordService) RequestReset(userID UserID, email string, now time.Time) error {
+	if _, err := s.readRepo.FindByID(userID); err != nil {
+		return err
+	}
+	// token is replaced on issue, this one is never revealed
+	token, err := generatePasswordResetToken()
+	if err != nil {
+		return err
+	}
+	tokenID := PasswordResetTokenID(uuid.GenerateNew())
+	expiresAt := now.Add(s.resetTokenTTL)
+
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		err2 := provider.PasswordResetTokenRepository().Store(&PasswordResetToken{
+			ID:        tokenID,
+			TokenHash: hashPasswordResetToken(token),
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+		})
+		if err2 != nil {
+			return err2
+		}
+
+		event := NewPasswordResetRequestedEvent(userID, email, tokenID, expiresAt)
+		err2 = provider.EventStore().Add(event)
+		if err2 != nil {
+			return err2
+		}
+		s.eventSender.EventStored(event.UID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.eventSender.SendStoredEvents()
+	return nil
+}
+
+// IssueResetToken - generates new raw token for requested reset, previously issued token becomes invalid,
+// so repeated delivery of email after failure leaves only one valid link
+func (s *PasswordService) IssueResetToken(id PasswordResetTokenID, now time.Time) (string, error) {
+	token, err := generatePasswordResetToken()
+	if err != nil {
+		return "", err
+	}
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		resetToken, err2 := provider.PasswordResetTokenRepository().ReplaceHash(id, hashPasswordResetToken(token))
+		if err2 != nil {
+			return err2
+		}
+		if !now.Before(resetToken.ExpiresAt) {
+			return errors.WithStack(ErrPasswordResetTokenNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Reset - sets new password by reset token, all sessions of user are revoked
+func (s *PasswordService) Reset(token, newPassword string, now time.Time) error {
+	var userID UserID
+	err := s.executeInTransaction(func(provider RepositoryProvider) error {
+		resetToken, err2 := provider.PasswordResetTokenRepository().Take(hashPasswordResetToken(token))
+		if err2 != nil {
+			return err2
+		}
+		if !now.Before(resetToken.ExpiresAt) {
+			return errors.WithStack(ErrPasswordResetTokenNotFound)
+		}
+
+		user, err2 := provider.UserRepository().FindByID(resetToken.UserID)
+		if err2 != nil {
+			return err2
+		}
+		userID = user.UserID
+		return s.setPassword(provider, user, newPassword)
+	})
+	if err != nil {
+		return err
+	}
+	return s.sessionClient.RemoveAllByUserID(userID)
+}
+
+func (s *PasswordService) setPassword(provider RepositoryProvider, user *User, password string) error {
+	encodedPass, err := s.passwordEncoder.Encode(password, user.UserID)
+	if err != nil {
+		return err
+	}
+	user.Password = encodedPass
+	err = provider.UserRepository().Store(user)
+	if err != nil {
+		return err
+	}
+	return provider.PasswordResetTokenRepository().RemoveAllByUserID(user.UserID)
+}
+
+func (s *PasswordService) executeInTransaction(f func(RepositoryProvider) error) (err error) {
+	var trUnit TransactionalUnit
+	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	err = f(trUnit)
+	return err
+}
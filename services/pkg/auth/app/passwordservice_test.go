@@ -0,0 +1,133 @@
+package app_test
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/auth/infrastructure/encoding"
+	"arch-homework/pkg/common/app/uuid"
+
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangePasswordRevokesOtherSessions(t *testing.T) {
+	db := newTestDB()
+	sessionClient := newTestSessionClient()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), sessionClient)
+	service := app.NewPasswordService(db, encoding.NewPasswordEncoder(testParams), sessionClient, &testEventSender{}, time.Hour)
+
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	currentSessionID := app.SessionID(uuid.GenerateNew())
+	for _, id := range []app.SessionID{currentSessionID, app.SessionID(uuid.GenerateNew())} {
+		assert.NoError(t, sessionClient.Store(app.Session{ID: id, UserID: userID}))
+	}
+
+	err = service.ChangePassword(userID, currentSessionID, "wrong password", "new password")
+	assert.Equal(t, app.ErrInvalidPassword, errors.Cause(err))
+
+	assert.NoError(t, service.ChangePassword(userID, currentSessionID, "password", "new password"))
+	sessions, err := sessionClient.FindAllByUserID(userID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, currentSessionID, sessions[0].ID)
+
+	_, err = userService.FindUserByLoginAndPassword("user", "password")
+	assert.Equal(t, app.ErrInvalidPassword, err)
+	_, err = userService.FindUserByLoginAndPassword("user", "new password")
+	assert.NoError(t, err)
+}
+
+func TestPasswordResetTokenIsSingleUse(t *testing.T) {
+	db := newTestDB()
+	sessionClient := newTestSessionClient()
+	eventSender := &testEventSender{}
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), sessionClient)
+	service := app.NewPasswordService(db, encoding.NewPasswordEncoder(testParams), sessionClient, eventSender, time.Hour)
+	now := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	assert.NoError(t, sessionClient.Store(app.Session{ID: app.SessionID(uuid.GenerateNew()), UserID: userID}))
+
+	assert.NoError(t, service.RequestReset(userID, "user@example.com", now))
+	assert.Len(t, eventSender.storedUIDs, 1)
+	assert.Len(t, db.events, 1)
+	assert.Equal(t, "auth.password_reset_requested", db.events[0].Type)
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(db.events[0].Body), &body))
+	assert.Equal(t, "user@example.com", body["email"])
+	assert.NotContains(t, body, "token", "raw token should not be stored in event")
+
+	token, err := service.IssueResetToken(app.PasswordResetTokenID(body["token_id"]), now)
+	assert.NoError(t, err)
+	for tokenHash := range db.resetTokens {
+		assert.NotEqual(t, token, tokenHash, "raw token should not be stored")
+	}
+
+	err = service.Reset("unknown token", "new password", now)
+	assert.Equal(t, app.ErrPasswordResetTokenNotFound, errors.Cause(err))
+
+	assert.NoError(t, service.Reset(token, "new password", now.Add(time.Minute)))
+	_, err = userService.FindUserByLoginAndPassword("user", "new password")
+	assert.NoError(t, err)
+	sessions, err := sessionClient.FindAllByUserID(userID)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	err = service.Reset(token, "other password", now.Add(time.Minute))
+	assert.Equal(t, app.ErrPasswordResetTokenNotFound, errors.Cause(err))
+
+	// token isn't issued again after use
+	_, err = service.IssueResetToken(app.PasswordResetTokenID(body["token_id"]), now.Add(time.Minute))
+	assert.Equal(t, app.ErrPasswordResetTokenNotFound, errors.Cause(err))
+}
+
+func TestReissuedPasswordResetTokenReplacesPrevious(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	service := app.NewPasswordService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient(), &testEventSender{}, time.Hour)
+	now := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	assert.NoError(t, service.RequestReset(userID, "user@example.com", now))
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(db.events[0].Body), &body))
+	tokenID := app.PasswordResetTokenID(body["token_id"])
+
+	// notification service retries delivery after failed email sending
+	firstToken, err := service.IssueResetToken(tokenID, now)
+	assert.NoError(t, err)
+	secondToken, err := service.IssueResetToken(tokenID, now)
+	assert.NoError(t, err)
+
+	err = service.Reset(firstToken, "new password", now)
+	assert.Equal(t, app.ErrPasswordResetTokenNotFound, errors.Cause(err))
+	assert.NoError(t, service.Reset(secondToken, "new password", now))
+}
+
+func TestExpiredPasswordResetTokenRejected(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	service := app.NewPasswordService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient(), &testEventSender{}, time.Hour)
+	now := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	assert.NoError(t, service.RequestReset(userID, "user@example.com", now))
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(db.events[0].Body), &body))
+	token, err := service.IssueResetToken(app.PasswordResetTokenID(body["token_id"]), now)
+	assert.NoError(t, err)
+
+	_, err = service.IssueResetToken(app.PasswordResetTokenID(body["token_id"]), now.Add(time.Hour))
+	assert.Equal(t, app.ErrPasswordResetTokenNotFound, errors.Cause(err))
+	err = service.Reset(token, "new password", now.Add(time.Hour))
+	assert.Equal(t, app.ErrPasswordResetTokenNotFound, errors.Cause(err))
+	_, err = userService.FindUserByLoginAndPassword("user", "password")
+	assert.NoError(t, err)
+}
@@ -3,20 +3,28 @@ package app
 import (
 	"arch-homework/pkg/common/app/uuid"
 	"errors"
+	"time"
 )
 
-var ErrSessionNotFound = errors.New("user not found")
+var ErrSessionNotFound = errors.New("session not found")
 
 type SessionID uuid.UUID
 
 type Session struct {
-	ID     SessionID
-	UserID UserID
+	ID           SessionID
+	UserID       UserID
+	Device       string
+	IP           string
+	CreationTime time.Time
+	LastSeenTime time.Time
 }
 
+// SessionClient - keeps index of user sessions to revoke all of them at once
 type SessionClient interface {
 	Store(session Session) error
 	Remove(id SessionID) error
+	RemoveAllByUserID(userID UserID) error
 	FindByID(id SessionID) (*Session, error)
+	FindAllByUserID(userID UserID) ([]Session, error)
 	UpdateSessionTTL(id SessionID) error
 }
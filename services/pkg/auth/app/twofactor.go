@@ -0,0 +1,178 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/pkg/errors"
+
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	totpDigits           = 6
+	totpPeriod           = 30 * time.Second
+	totpAllowedSkewSteps = 1
+	totpSecretLength     = 20
+
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var ErrTwoFactorNotFound = errors.New("two-factor authentication not found")
+var ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+var ErrTwoFactorNotEnabled = errors.New("two-factor authentication not enabled")
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor authentication code")
+var ErrLoginChallengeNotFound = errors.New("login challenge not found or expired")
+
+// TwoFactor - TOTP settings of user, enrollment is pending until first valid code is confirmed
+type TwoFactor struct {
+	UserID          UserID
+	EncryptedSecret string
+	Enabled         bool
+	LastUsedStep    int64
+}
+
+type TwoFactorEnrollment struct {
+	Secret string
+	URI    string
+}
+
+type LoginChallengeID uuid.UUID
+
+// LoginChallenge - issued after successful first factor, exchanged for session after second factor
+type LoginChallenge struct {
+	ID       LoginChallengeID
+	UserID   UserID
+	Attempts int
+}
+
+// SecretEncryptor - TOTP secrets are stored encrypted
+type SecretEncryptor interface {
+	Encrypt(plain []byte) (string, error)
+	Decrypt(encrypted string) ([]byte, error)
+}
+
+type TwoFactorRepositoryRead interface {
+	FindByUserID(userID UserID) (*TwoFactor, error)
+}
+
+type TwoFactorRepository interface {
+	TwoFactorRepositoryRead
+	Store(twoFactor *TwoFactor) error
+	Remove(userID UserID) error
+	ReplaceRecoveryCodes(userID UserID, codeHashes []string) error
+	UseRecoveryCode(userID UserID, codeHash string) (used bool, err error)
+	// UseTOTPStep - step is used only if it is later than last used step, so concurrent requests can't use one code twice
+	UseTOTPStep(userID UserID, step int64) (used bool, err error)
+}
+
+type LoginChallengeStore interface {
+	Store(challenge LoginChallenge, ttl time.Duration) error
+	FindByID(id LoginChallengeID) (*LoginChallenge, error)
+	IncrementAttempts(id LoginChallengeID) (int, error)
+	Remove(id LoginChallengeID) error
+}
+
+func generateTOTPSecret() ([]byte, error) {
+	secret := make([]byte, totpSecretLength)
+	_, err := rand.Read(secret)
+	return secret, errors.WithStack(err)
+}
+
+func encodeTOTPSecret(secret []byte) string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+}
+
+func totpURI(issuer string, login Login, secret []byte) string {
+	label := url.PathEscape(issuer + ":" + string(login))
+	params := url.Values{}
+	params.Set("secret", encodeTOTPSecret(secret))
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+func totpStep(now time.Time) int64 {
+	return now.Unix() / int64(totpPeriod.Seconds())
+}
+
+// totpCode - HOTP value (RFC 4226) for time step counter (RFC 6238)
+func totpCode(secret []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	_, _ = mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// verifyTOTP - accepts codes of neighbour steps to tolerate clock drift,
+// code of already used step is rejected to prevent replay
+func verifyTOTP(secret []byte, code string, now time.Time, lastUsedStep int64) (int64, bool) {
+	current := totpStep(now)
+	for step := current - totpAllowedSkewSteps; step <= current+totpAllowedSkewSteps; step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func generateRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code := make([]byte, recoveryCodeLength)
+		for j := range code {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryCodeAlphabet))))
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			code[j] = recoveryCodeAlphabet[n.Int64()]
+		}
+		half := recoveryCodeLength / 2
+		codes = append(codes, string(code[:half])+"-"+string(code[half:]))
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode - recovery codes are random enough to be stored as plain sha256
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(normalized)))
+}
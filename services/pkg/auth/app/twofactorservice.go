@@ -0,0 +1,254 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/pkg/errors"
+
+	"time"
+)
+
+const (
+	loginChallengeTTL         = 5 * time.Minute
+	maxLoginChallengeAttempts = 5
+)
+
+func NewTwoFactorService(dbDependency DBDependency, encryptor SecretEncryptor, challengeStore LoginChallengeStore, issuer string) *TwoFactorService {
+	return &TwoFactorService{
+		userRepo:       dbDependency.UserRepositoryRead(),
+		readRepo:       dbDependency.TwoFactorRepositoryRead(),
+		trUnitFactory:  dbDependency,
+		encryptor:      encryptor,
+		challengeStore: challengeStore,
+		issuer:         issuer,
+	}
+}
+
+type TwoFactorService struct {
+	userRepo       UserRepositoryRead
+	readRepo       TwoFactorRepositoryRead
+	trUnitFactory  TransactionalUnitFactory
+	encryptor      SecretEncryptor
+	challengeStore LoginChallengeStore
+	issuer         string
+}
+
+// StartEnrollment - generates new secret, previous not confirmed enrollment is replaced
+func (s *TwoFactorService) StartEnrollment(userID UserID) (*TwoFactorEnrollment, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encryptedSecret, err := s.encryptor.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		repo := provider.TwoFactorRepository()
+		twoFactor, err2 := repo.FindByUserID(userID)
+		if err2 != nil && errors.Cause(err2) != ErrTwoFactorNotFound {
+			return err2
+		}
+		if twoFactor != nil && twoFactor.Enabled {
+			return errors.WithStack(ErrTwoFactorAlreadyEnabled)
+		}
+		return repo.Store(&TwoFactor{
+			UserID:          userID,
+			EncryptedSecret: encryptedSecret,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: encodeTOTPSecret(secret),
+		URI:    totpURI(s.issuer, user.Login, secret),
+	}, nil
+}
+
+// ConfirmEnrollment - enables two-factor authentication and returns recovery codes, which are shown only once
+func (s *TwoFactorService) ConfirmEnrollment(userID UserID, code string, now time.Time) ([]string, error) {
+	recoveryCodes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		repo := provider.TwoFactorRepository()
+		twoFactor, err2 := repo.FindByUserID(userID)
+		if err2 != nil {
+			if errors.Cause(err2) == ErrTwoFactorNotFound {
+				return errors.WithStack(ErrTwoFactorNotEnabled)
+			}
+			return err2
+		}
+		if twoFactor.Enabled {
+			return errors.WithStack(ErrTwoFactorAlreadyEnabled)
+		}
+		if err2 = s.verifyTOTPCode(repo, twoFactor, code, now); err2 != nil {
+			return err2
+		}
+		twoFactor.Enabled = true
+		if err2 = repo.Store(twoFactor); err2 != nil {
+			return err2
+		}
+
+		hashes := make([]string, 0, len(recoveryCodes))
+		for _, recoveryCode := range recoveryCodes {
+			hashes = append(hashes, hashRecoveryCode(recoveryCode))
+		}
+		return repo.ReplaceRecoveryCodes(userID, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+// Disable - requires valid TOTP or recovery code
+func (s *TwoFactorService) Disable(userID UserID, code string, now time.Time) error {
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		repo := provider.TwoFactorRepository()
+		twoFactor, err := s.findEnabled(repo, userID)
+		if err != nil {
+			return err
+		}
+		if err = s.verifyCode(repo, twoFactor, code, now); err != nil {
+			return err
+		}
+		return repo.Remove(userID)
+	})
+}
+
+func (s *TwoFactorService) IsEnabled(userID UserID) (bool, error) {
+	twoFactor, err := s.readRepo.FindByUserID(userID)
+	if err != nil {
+		if errors.Cause(err) == ErrTwoFactorNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.Enabled, nil
+}
+
+func (s *TwoFactorService) CreateLoginChallenge(userID UserID) (LoginChallengeID, error) {
+	challenge := LoginChallenge{
+		ID:     LoginChallengeID(uuid.GenerateNew()),
+		UserID: userID,
+	}
+	err := s.challengeStore.Store(challenge, loginChallengeTTL)
+	if err != nil {
+		return "", err
+	}
+	return challenge.ID, nil
+}
+
+// VerifyLoginChallenge - completes two-phase login, challenge is dropped after several invalid codes.
+// Attempt is counted before code is verified, so concurrent requests can't exceed attempts limit
+func (s *TwoFactorService) VerifyLoginChallenge(id LoginChallengeID, code string, now time.Time) (UserID, error) {
+	challenge, err := s.challengeStore.FindByID(id)
+	if err != nil {
+		return "", err
+	}
+	attempts, err := s.challengeStore.IncrementAttempts(id)
+	if err != nil {
+		return "", err
+	}
+	if attempts > maxLoginChallengeAttempts {
+		if err = s.challengeStore.Remove(id); err != nil {
+			return "", err
+		}
+		return "", errors.WithStack(ErrLoginChallengeNotFound)
+	}
+
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		repo := provider.TwoFactorRepository()
+		twoFactor, err2 := s.findEnabled(repo, challenge.UserID)
+		if err2 != nil {
+			return err2
+		}
+		return s.verifyCode(repo, twoFactor, code, now)
+	})
+	if err != nil {
+		if errors.Cause(err) == ErrInvalidTwoFactorCode && attempts >= maxLoginChallengeAttempts {
+			if err2 := s.challengeStore.Remove(id); err2 != nil {
+				return "", err2
+			}
+		}
+		return "", err
+	}
+
+	if err = s.challengeStore.Remove(id); err != nil {
+		return "", err
+	}
+	return challenge.UserID, nil
+}
+
+func (s *TwoFactorService) findEnabled(repo TwoFactorRepositoryRead, userID UserID) (*TwoFactor, error) {
+	twoFactor, err := repo.FindByUserID(userID)
+	if err != nil {
+		if errors.Cause(err) == ErrTwoFactorNotFound {
+			return nil, errors.WithStack(ErrTwoFactorNotEnabled)
+		}
+		return nil, err
+	}
+	if !twoFactor.Enabled {
+		return nil, errors.WithStack(ErrTwoFactorNotEnabled)
+	}
+	return twoFactor, nil
+}
+
+// verifyCode - code is either TOTP code or one-time recovery code
+func (s *TwoFactorService) verifyCode(repo TwoFactorRepository, twoFactor *TwoFactor, code string, now time.Time) error {
+	if isTOTPCode(code) {
+		return s.verifyTOTPCode(repo, twoFactor, code, now)
+	}
+
+	used, err := repo.UseRecoveryCode(twoFactor.UserID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.WithStack(ErrInvalidTwoFactorCode)
+	}
+	return nil
+}
+
+// verifyTOTPCode - step of code is marked as used in repository, code is rejected if concurrent request already used it
+func (s *TwoFactorService) verifyTOTPCode(repo TwoFactorRepository, twoFactor *TwoFactor, code string, now time.Time) error {
+	secret, err := s.encryptor.Decrypt(twoFactor.EncryptedSecret)
+	if err != nil {
+		return err
+	}
+	step, ok := verifyTOTP(secret, code, now, twoFactor.LastUsedStep)
+	if !ok {
+		return errors.WithStack(ErrInvalidTwoFactorCode)
+	}
+	used, err := repo.UseTOTPStep(twoFactor.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.WithStack(ErrInvalidTwoFactorCode)
+	}
+	twoFactor.LastUsedStep = step
+	return nil
+}
+
+func (s *TwoFactorService) executeInTransaction(f func(RepositoryProvider) error) (err error) {
+	var trUnit TransactionalUnit
+	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	err = f(trUnit)
+	return err
+}
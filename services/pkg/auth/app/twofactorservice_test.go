@@ -0,0 +1,257 @@
+package app_test
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/auth/infrastructure/encoding"
+
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	expected := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unixTime, code := range expected {
+		assert.Equal(t, code, app.TOTPCode(secret, app.TOTPStep(time.Unix(unixTime, 0))), "time %d", unixTime)
+	}
+}
+
+func TestTwoFactorEnrollmentAndLogin(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	service := newTestTwoFactorService(t, db)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	enrollment, err := service.StartEnrollment(userID)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/issuer:user?"))
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+	assert.NotContains(t, db.twoFactors[userID].EncryptedSecret, enrollment.Secret, "secret should be encrypted at rest")
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	assert.NoError(t, err)
+	codeAt := func(t time.Time) string {
+		return app.TOTPCode(secret, app.TOTPStep(t))
+	}
+
+	enabled, err := service.IsEnabled(userID)
+	assert.NoError(t, err)
+	assert.False(t, enabled, "not confirmed enrollment should not enable two-factor authentication")
+
+	_, err = service.ConfirmEnrollment(userID, codeAt(now.Add(time.Hour)), now)
+	assert.Equal(t, app.ErrInvalidTwoFactorCode, errors.Cause(err))
+	recoveryCodes, err := service.ConfirmEnrollment(userID, codeAt(now), now)
+	assert.NoError(t, err)
+	assert.Len(t, recoveryCodes, 10)
+	enabled, err = service.IsEnabled(userID)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	challengeID, err := service.CreateLoginChallenge(userID)
+	assert.NoError(t, err)
+	_, err = service.VerifyLoginChallenge(challengeID, codeAt(now), now)
+	assert.Equal(t, app.ErrInvalidTwoFactorCode, errors.Cause(err), "code should not be accepted twice")
+
+	now = now.Add(time.Second * 30)
+	verifiedUserID, err := service.VerifyLoginChallenge(challengeID, codeAt(now.Add(-time.Second*30)), now)
+	assert.Equal(t, app.ErrInvalidTwoFactorCode, errors.Cause(err))
+	verifiedUserID, err = service.VerifyLoginChallenge(challengeID, codeAt(now.Add(time.Second*30)), now)
+	assert.NoError(t, err, "code of next step should be accepted because of clock drift")
+	assert.Equal(t, userID, verifiedUserID)
+	_, err = service.VerifyLoginChallenge(challengeID, codeAt(now.Add(time.Minute)), now)
+	assert.Equal(t, app.ErrLoginChallengeNotFound, errors.Cause(err), "challenge should be used once")
+
+	challengeID, err = service.CreateLoginChallenge(userID)
+	assert.NoError(t, err)
+	verifiedUserID, err = service.VerifyLoginChallenge(challengeID, strings.ToUpper(recoveryCodes[0]), now)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, verifiedUserID)
+
+	challengeID, err = service.CreateLoginChallenge(userID)
+	assert.NoError(t, err)
+	_, err = service.VerifyLoginChallenge(challengeID, recoveryCodes[0], now)
+	assert.Equal(t, app.ErrInvalidTwoFactorCode, errors.Cause(err), "recovery code should be used once")
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, service.Disable(userID, codeAt(now), now))
+	enabled, err = service.IsEnabled(userID)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestLoginChallengeDroppedAfterInvalidCodes(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	service := newTestTwoFactorService(t, db)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	enrollment, err := service.StartEnrollment(userID)
+	assert.NoError(t, err)
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	assert.NoError(t, err)
+	_, err = service.ConfirmEnrollment(userID, app.TOTPCode(secret, app.TOTPStep(now)), now)
+	assert.NoError(t, err)
+
+	challengeID, err := service.CreateLoginChallenge(userID)
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err = service.VerifyLoginChallenge(challengeID, "000000", now)
+		assert.Error(t, err)
+	}
+	now = now.Add(time.Second * 30)
+	_, err = service.VerifyLoginChallenge(challengeID, app.TOTPCode(secret, app.TOTPStep(now)), now)
+	assert.Equal(t, app.ErrLoginChallengeNotFound, errors.Cause(err))
+}
+
+func TestConcurrentLoginChallengesAcceptTOTPCodeOnce(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	service := newTestTwoFactorService(t, db)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	secret := enableTestTwoFactor(t, service, userID, now)
+
+	const requestCount = 20
+	challengeIDs := make([]app.LoginChallengeID, 0, requestCount)
+	for i := 0; i < requestCount; i++ {
+		challengeID, err := service.CreateLoginChallenge(userID)
+		assert.NoError(t, err)
+		challengeIDs = append(challengeIDs, challengeID)
+	}
+
+	now = now.Add(time.Second * 30)
+	code := app.TOTPCode(secret, app.TOTPStep(now))
+	errs := make([]error, requestCount)
+	wg := sync.WaitGroup{}
+	for i, challengeID := range challengeIDs {
+		wg.Add(1)
+		go func(i int, challengeID app.LoginChallengeID) {
+			defer wg.Done()
+			_, errs[i] = service.VerifyLoginChallenge(challengeID, code, now)
+		}(i, challengeID)
+	}
+	wg.Wait()
+
+	accepted := 0
+	for _, err := range errs {
+		if err == nil {
+			accepted++
+			continue
+		}
+		assert.Equal(t, app.ErrInvalidTwoFactorCode, errors.Cause(err))
+	}
+	assert.Equal(t, 1, accepted, "code should be accepted by one of concurrent requests")
+}
+
+func TestConcurrentLoginChallengeAttemptsLimited(t *testing.T) {
+	db := newTestDB()
+	userService := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+	userID, err := userService.Add("user", "password")
+	assert.NoError(t, err)
+	service := newTestTwoFactorService(t, db)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	enableTestTwoFactor(t, service, userID, now)
+
+	challengeID, err := service.CreateLoginChallenge(userID)
+	assert.NoError(t, err)
+
+	const requestCount = 20
+	errs := make([]error, requestCount)
+	wg := sync.WaitGroup{}
+	for i := 0; i < requestCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = service.VerifyLoginChallenge(challengeID, fmt.Sprintf("%06d", i), now)
+		}(i)
+	}
+	wg.Wait()
+
+	verified := 0
+	for _, err := range errs {
+		if errors.Cause(err) == app.ErrLoginChallengeNotFound {
+			continue
+		}
+		verified++
+	}
+	assert.LessOrEqual(t, verified, 5, "codes should not be verified after attempts limit")
+	_, err = service.VerifyLoginChallenge(challengeID, "000000", now)
+	assert.Equal(t, app.ErrLoginChallengeNotFound, errors.Cause(err))
+}
+
+func enableTestTwoFactor(t *testing.T, service *app.TwoFactorService, userID app.UserID, now time.Time) []byte {
+	enrollment, err := service.StartEnrollment(userID)
+	assert.NoError(t, err)
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	assert.NoError(t, err)
+	_, err = service.ConfirmEnrollment(userID, app.TOTPCode(secret, app.TOTPStep(now)), now)
+	assert.NoError(t, err)
+	return secret
+}
+
+func newTestTwoFactorService(t *testing.T, db *testDB) *app.TwoFactorService {
+	encryptor, err := encoding.NewSecretEncryptor([]byte("0123456789abcdef0123456789abcdef"))
+	assert.NoError(t, err)
+	return app.NewTwoFactorService(db, encryptor, newTestChallengeStore(), "issuer")
+}
+
+func newTestChallengeStore() *testChallengeStore {
+	return &testChallengeStore{challenges: make(map[app.LoginChallengeID]app.LoginChallenge)}
+}
+
+type testChallengeStore struct {
+	mutex      sync.Mutex
+	challenges map[app.LoginChallengeID]app.LoginChallenge
+}
+
+func (s *testChallengeStore) Store(challenge app.LoginChallenge, _ time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.challenges[challenge.ID] = challenge
+	return nil
+}
+
+func (s *testChallengeStore) FindByID(id app.LoginChallengeID) (*app.LoginChallenge, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	challenge, ok := s.challenges[id]
+	if !ok {
+		return nil, app.ErrLoginChallengeNotFound
+	}
+	return &challenge, nil
+}
+
+func (s *testChallengeStore) IncrementAttempts(id app.LoginChallengeID) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	challenge, ok := s.challenges[id]
+	if !ok {
+		return 0, app.ErrLoginChallengeNotFound
+	}
+	challenge.Attempts++
+	s.challenges[id] = challenge
+	return challenge.Attempts, nil
+}
+
+func (s *testChallengeStore) Remove(id app.LoginChallengeID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.challenges, id)
+	return nil
+}
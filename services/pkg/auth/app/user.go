@@ -20,6 +20,10 @@ type User struct {
 	Password Password
 }
 
+func (u *User) HasPassword() bool {
+	return u.Password != ""
+}
+
 type UserRepositoryRead interface {
 	FindByID(id UserID) (*User, error)
 	FindByLogin(login Login) (*User, error)
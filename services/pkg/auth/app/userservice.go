@@ -8,11 +8,12 @@ import (
 
 const maxLoginLen = 255
 
-func NewUserService(dbDependency DBDependency, passwordEncoder PasswordEncoder) *UserService {
+func NewUserService(dbDependency DBDependency, passwordEncoder PasswordEncoder, sessionClient SessionClient) *UserService {
 	return &UserService{
 		readRepo:        dbDependency.UserRepositoryRead(),
 		trUnitFactory:   dbDependency,
 		passwordEncoder: passwordEncoder,
+		sessionClient:   sessionClient,
 	}
 }
 
@@ -20,8 +21,10 @@ type UserService struct {
 	readRepo        UserRepositoryRead
 	trUnitFactory   TransactionalUnitFactory
 	passwordEncoder PasswordEncoder
+	sessionClient   SessionClient
 }
 
+// Add - user registered without password can log in only by external identity provider
 func (s *UserService) Add(login, password string) (UserID, error) {
 	if err := s.checkLogin(Login(login)); err != nil {
 		return "", errors.WithStack(err)
@@ -29,9 +32,15 @@ func (s *UserService) Add(login, password string) (UserID, error) {
 
 	id := UserID(uuid.GenerateNew())
 	user := User{
-		UserID:   id,
-		Login:    Login(login),
-		Password: s.passwordEncoder.Encode(password, id),
+		UserID: id,
+		Login:  Login(login),
+	}
+	if password != "" {
+		encodedPass, err := s.passwordEncoder.Encode(password, id)
+		if err != nil {
+			return "", err
+		}
+		user.Password = encodedPass
 	}
 
 	err := s.executeInTransaction(func(provider RepositoryProvider) error {
@@ -44,27 +53,58 @@ func (s *UserService) Add(login, password string) (UserID, error) {
 }
 
 func (s *UserService) Remove(id UserID) error {
-	return s.executeInTransaction(func(provider RepositoryProvider) error {
+	err := s.executeInTransaction(func(provider RepositoryProvider) error {
 		return provider.UserRepository().Remove(id)
 	})
+	if err != nil {
+		return err
+	}
+	return s.sessionClient.RemoveAllByUserID(id)
 }
 
 func (s *UserService) FindUserByID(id UserID) (*User, error) {
 	return s.readRepo.FindByID(id)
 }
 
+func (s *UserService) FindUserByLogin(login Login) (*User, error) {
+	return s.readRepo.FindByLogin(login)
+}
+
 func (s *UserService) FindUserByLoginAndPassword(login, password string) (*User, error) {
 	user, err := s.readRepo.FindByLogin(Login(login))
 	if err != nil {
 		return nil, err
 	}
-	encodedPass := s.passwordEncoder.Encode(password, user.UserID)
-	if encodedPass != user.Password {
+	if !user.HasPassword() {
 		return nil, ErrInvalidPassword
 	}
+	matched, needsRehash, err := s.passwordEncoder.Verify(password, user.UserID, user.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !matched {
+		return nil, ErrInvalidPassword
+	}
+	if needsRehash {
+		if err = s.rehashPassword(user, password); err != nil {
+			return nil, err
+		}
+	}
 	return user, nil
 }
 
+// rehashPassword migrates password encoded by outdated algorithm, raw password is known only on successful login
+func (s *UserService) rehashPassword(user *User, password string) error {
+	encodedPass, err := s.passwordEncoder.Encode(password, user.UserID)
+	if err != nil {
+		return err
+	}
+	user.Password = encodedPass
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		return provider.UserRepository().Store(user)
+	})
+}
+
 func (s *UserService) checkLogin(login Login) error {
 	if len(login) > maxLoginLen {
 		return errors.Wrapf(ErrLoginTooLong, "max login length (%d symbols) exceeded", maxLoginLen)
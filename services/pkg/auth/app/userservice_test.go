@@ -0,0 +1,515 @@
+package app_test
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/auth/infrastructure/encoding"
+	"arch-homework/pkg/common/app/integrationevent"
+	"arch-homework/pkg/common/app/storedevent"
+	"arch-homework/pkg/common/app/uuid"
+
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testParams = encoding.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestLegacyPasswordMigratedOnLogin(t *testing.T) {
+	userID := app.UserID(uuid.GenerateNew())
+	legacyPassword := app.Password(fmt.Sprintf("%x", sha256.Sum256([]byte(string(userID)+"password"))))
+	db := newTestDB(app.User{UserID: userID, Login: "user", Password: legacyPassword})
+	service := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+
+	_, err := service.FindUserByLoginAndPassword("user", "wrong password")
+	assert.Equal(t, app.ErrInvalidPassword, err)
+	assert.Equal(t, legacyPassword, db.users["user"].Password, "password should not be rehashed on failed login")
+	assert.Equal(t, 0, db.storeCount)
+
+	user, err := service.FindUserByLoginAndPassword("user", "password")
+	assert.NoError(t, err)
+	assert.Equal(t, userID, user.UserID)
+	assert.True(t, strings.HasPrefix(string(db.users["user"].Password), "$argon2id$"))
+	assert.Equal(t, 1, db.storeCount)
+
+	// migrated password is verified by new algorithm without rehash
+	_, err = service.FindUserByLoginAndPassword("user", "password")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, db.storeCount)
+
+	_, err = service.FindUserByLoginAndPassword("user", "wrong password")
+	assert.Equal(t, app.ErrInvalidPassword, err)
+}
+
+func TestNewUserPasswordEncodedByCurrentAlgorithm(t *testing.T) {
+	db := newTestDB()
+	service := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), newTestSessionClient())
+
+	userID, err := service.Add("user", "password")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(db.users["user"].Password), "$argon2id$"))
+
+	user, err := service.FindUserByLoginAndPassword("user", "password")
+	assert.NoError(t, err)
+	assert.Equal(t, userID, user.UserID)
+	assert.Equal(t, 1, db.storeCount)
+}
+
+func TestRemoveUserRevokesAllSessions(t *testing.T) {
+	db := newTestDB()
+	sessionClient := newTestSessionClient()
+	service := app.NewUserService(db, encoding.NewPasswordEncoder(testParams), sessionClient)
+
+	userID, err := service.Add("user", "password")
+	assert.NoError(t, err)
+	otherUserID, err := service.Add("other", "password")
+	assert.NoError(t, err)
+	for _, id := range []app.UserID{userID, userID, otherUserID} {
+		assert.NoError(t, sessionClient.Store(app.Session{ID: app.SessionID(uuid.GenerateNew()), UserID: id}))
+	}
+
+	assert.NoError(t, service.Remove(userID))
+
+	sessions, err := sessionClient.FindAllByUserID(userID)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+	sessions, err = sessionClient.FindAllByUserID(otherUserID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+}
+
+func newTestSessionClient() *testSessionClient {
+	return &testSessionClient{sessions: make(map[app.SessionID]app.Session)}
+}
+
+type testSessionClient struct {
+	sessions map[app.SessionID]app.Session
+}
+
+func (c *testSessionClient) Store(session app.Session) error {
+	c.sessions[session.ID] = session
+	return nil
+}
+
+func (c *testSessionClient) Remove(id app.SessionID) error {
+	delete(c.sessions, id)
+	return nil
+}
+
+func (c *testSessionClient) RemoveAllByUserID(userID app.UserID) error {
+	for id, session := range c.sessions {
+		if session.UserID == userID {
+			delete(c.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (c *testSessionClient) FindByID(id app.SessionID) (*app.Session, error) {
+	session, ok := c.sessions[id]
+	if !ok {
+		return nil, app.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (c *testSessionClient) FindAllByUserID(userID app.UserID) ([]app.Session, error) {
+	var sessions []app.Session
+	for _, session := range c.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (c *testSessionClient) UpdateSessionTTL(app.SessionID) error {
+	return nil
+}
+
+func newTestDB(users ...app.User) *testDB {
+	db := &testDB{
+		users:         make(map[app.Login]app.User),
+		twoFactors:    make(map[app.UserID]app.TwoFactor),
+		recoveryCodes: make(map[app.UserID]map[string]bool),
+		resetTokens:   make(map[string]app.PasswordResetToken),
+		identities:    make(map[string]app.ExternalIdentity),
+		userRoles:     make(map[app.UserID][]app.Role),
+		suspensions:   make(map[app.UserID]app.UserSuspension),
+		apiKeys:       make(map[app.APIKeyID]app.APIKey),
+		rolePermissions: map[app.Role][]app.Permission{
+			"admin":     {"user.suspend", "auth.manage_roles", "lot.close", "billing.adjust_balance"},
+			"moderator": {"user.suspend", "lot.close"},
+		},
+	}
+	for _, user := range users {
+		db.users[user.Login] = user
+	}
+	return db
+}
+
+type testDB struct {
+	users         map[app.Login]app.User
+	storeCount    int
+	events        []integrationevent.EventData
+	twoFactors    map[app.UserID]app.TwoFactor
+	recoveryCodes map[app.UserID]map[string]bool
+	// twoFactorMutex - makes single statements of two-factor repository atomic like in database
+	twoFactorMutex sync.Mutex
+	resetTokens    map[string]app.PasswordResetToken
+	identities     map[string]app.ExternalIdentity
+
+	userRoles       map[app.UserID][]app.Role
+	suspensions     map[app.UserID]app.UserSuspension
+	rolePermissions map[app.Role][]app.Permission
+	apiKeys         map[app.APIKeyID]app.APIKey
+}
+
+func (db *testDB) NewTransactionalUnit() (app.TransactionalUnit, error) {
+	return db, nil
+}
+
+func (db *testDB) UserRepositoryRead() app.UserRepositoryRead {
+	return db
+}
+
+func (db *testDB) UserRepository() app.UserRepository {
+	return db
+}
+
+func (db *testDB) TwoFactorRepositoryRead() app.TwoFactorRepositoryRead {
+	return db.TwoFactorRepository()
+}
+
+func (db *testDB) TwoFactorRepository() app.TwoFactorRepository {
+	return testTwoFactorRepo{db: db}
+}
+
+func (db *testDB) PasswordResetTokenRepository() app.PasswordResetTokenRepository {
+	return testResetTokenRepo{db: db}
+}
+
+func (db *testDB) ExternalIdentityRepositoryRead() app.ExternalIdentityRepositoryRead {
+	return testExternalIdentityRepo{db: db}
+}
+
+func (db *testDB) ExternalIdentityRepository() app.ExternalIdentityRepository {
+	return testExternalIdentityRepo{db: db}
+}
+
+func (db *testDB) RoleRepositoryRead() app.RoleRepositoryRead {
+	return testRoleRepo{db: db}
+}
+
+func (db *testDB) RoleRepository() app.RoleRepository {
+	return testRoleRepo{db: db}
+}
+
+func (db *testDB) UserSuspensionRepositoryRead() app.UserSuspensionRepositoryRead {
+	return testSuspensionRepo{db: db}
+}
+
+func (db *testDB) UserSuspensionRepository() app.UserSuspensionRepository {
+	return testSuspensionRepo{db: db}
+}
+
+func (db *testDB) APIKeyRepositoryRead() app.APIKeyRepositoryRead {
+	return testAPIKeyRepo{db: db}
+}
+
+func (db *testDB) APIKeyRepository() app.APIKeyRepository {
+	return testAPIKeyRepo{db: db}
+}
+
+func (db *testDB) EventStore() storedevent.EventStore {
+	return db
+}
+
+func (db *testDB) Complete(err error) error {
+	return err
+}
+
+func (db *testDB) FindByID(id app.UserID) (*app.User, error) {
+	for _, user := range db.users {
+		if user.UserID == id {
+			return &user, nil
+		}
+	}
+	return nil, app.ErrUserNotFound
+}
+
+func (db *testDB) FindByLogin(login app.Login) (*app.User, error) {
+	user, ok := db.users[login]
+	if !ok {
+		return nil, app.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (db *testDB) Store(user *app.User) error {
+	db.users[user.Login] = *user
+	db.storeCount++
+	return nil
+}
+
+func (db *testDB) Remove(id app.UserID) error {
+	for login, user := range db.users {
+		if user.UserID == id {
+			delete(db.users, login)
+		}
+	}
+	return nil
+}
+
+func (db *testDB) Add(event integrationevent.EventData) error {
+	db.events = append(db.events, event)
+	return nil
+}
+
+func (db *testDB) ConfirmDelivery(storedevent.EventID) error {
+	return nil
+}
+
+func (db *testDB) FindByUIDs([]integrationevent.EventUID) ([]storedevent.Event, error) {
+	return nil, nil
+}
+
+func (db *testDB) FindAllUnconfirmedBefore(time.Time) ([]storedevent.Event, error) {
+	return nil, nil
+}
+
+type testTwoFactorRepo struct {
+	db *testDB
+}
+
+func (r testTwoFactorRepo) FindByUserID(userID app.UserID) (*app.TwoFactor, error) {
+	r.db.twoFactorMutex.Lock()
+	defer r.db.twoFactorMutex.Unlock()
+	twoFactor, ok := r.db.twoFactors[userID]
+	if !ok {
+		return nil, app.ErrTwoFactorNotFound
+	}
+	return &twoFactor, nil
+}
+
+func (r testTwoFactorRepo) Store(twoFactor *app.TwoFactor) error {
+	r.db.twoFactorMutex.Lock()
+	defer r.db.twoFactorMutex.Unlock()
+	r.db.twoFactors[twoFactor.UserID] = *twoFactor
+	return nil
+}
+
+func (r testTwoFactorRepo) Remove(userID app.UserID) error {
+	r.db.twoFactorMutex.Lock()
+	defer r.db.twoFactorMutex.Unlock()
+	delete(r.db.twoFactors, userID)
+	delete(r.db.recoveryCodes, userID)
+	return nil
+}
+
+func (r testTwoFactorRepo) ReplaceRecoveryCodes(userID app.UserID, codeHashes []string) error {
+	r.db.twoFactorMutex.Lock()
+	defer r.db.twoFactorMutex.Unlock()
+	codes := make(map[string]bool)
+	for _, codeHash := range codeHashes {
+		codes[codeHash] = false
+	}
+	r.db.recoveryCodes[userID] = codes
+	return nil
+}
+
+func (r testTwoFactorRepo) UseTOTPStep(userID app.UserID, step int64) (bool, error) {
+	r.db.twoFactorMutex.Lock()
+	defer r.db.twoFactorMutex.Unlock()
+	twoFactor, ok := r.db.twoFactors[userID]
+	if !ok || twoFactor.LastUsedStep >= step {
+		return false, nil
+	}
+	twoFactor.LastUsedStep = step
+	r.db.twoFactors[userID] = twoFactor
+	return true, nil
+}
+
+func (r testTwoFactorRepo) UseRecoveryCode(userID app.UserID, codeHash string) (bool, error) {
+	r.db.twoFactorMutex.Lock()
+	defer r.db.twoFactorMutex.Unlock()
+	used, ok := r.db.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.db.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
+
+type testResetTokenRepo struct {
+	db *testDB
+}
+
+func (r testResetTokenRepo) Store(token *app.PasswordResetToken) error {
+	r.db.resetTokens[token.TokenHash] = *token
+	return nil
+}
+
+func (r testResetTokenRepo) Take(tokenHash string) (*app.PasswordResetToken, error) {
+	token, ok := r.db.resetTokens[tokenHash]
+	if !ok {
+		return nil, app.ErrPasswordResetTokenNotFound
+	}
+	delete(r.db.resetTokens, tokenHash)
+	return &token, nil
+}
+
+func (r testResetTokenRepo) ReplaceHash(id app.PasswordResetTokenID, tokenHash string) (*app.PasswordResetToken, error) {
+	for oldHash, token := range r.db.resetTokens {
+		if token.ID == id {
+			delete(r.db.resetTokens, oldHash)
+			token.TokenHash = tokenHash
+			r.db.resetTokens[tokenHash] = token
+			return &token, nil
+		}
+	}
+	return nil, app.ErrPasswordResetTokenNotFound
+}
+
+func (r testResetTokenRepo) RemoveAllByUserID(userID app.UserID) error {
+	for tokenHash, token := range r.db.resetTokens {
+		if token.UserID == userID {
+			delete(r.db.resetTokens, tokenHash)
+		}
+	}
+	return nil
+}
+
+type testExternalIdentityRepo struct {
+	db *testDB
+}
+
+func (r testExternalIdentityRepo) FindByProviderAndSubject(provider app.IdentityProviderName, subject string) (*app.ExternalIdentity, error) {
+	identity, ok := r.db.identities[string(provider)+"|"+subject]
+	if !ok {
+		return nil, app.ErrExternalIdentityNotFound
+	}
+	return &identity, nil
+}
+
+func (r testExternalIdentityRepo) FindAllByUserID(userID app.UserID) ([]app.ExternalIdentity, error) {
+	var identities []app.ExternalIdentity
+	for _, identity := range r.db.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r testExternalIdentityRepo) Store(identity *app.ExternalIdentity) error {
+	r.db.identities[string(identity.Provider)+"|"+identity.Subject] = *identity
+	return nil
+}
+
+func (r testExternalIdentityRepo) Remove(provider app.IdentityProviderName, userID app.UserID) error {
+	for key, identity := range r.db.identities {
+		if identity.Provider == provider && identity.UserID == userID {
+			delete(r.db.identities, key)
+		}
+	}
+	return nil
+}
+
+type testRoleRepo struct {
+	db *testDB
+}
+
+func (r testRoleRepo) FindAllRoles() ([]app.Role, error) {
+	var roles []app.Role
+	for role := range r.db.rolePermissions {
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+func (r testRoleRepo) FindRolesByUserID(userID app.UserID) ([]app.Role, error) {
+	return r.db.userRoles[userID], nil
+}
+
+func (r testRoleRepo) FindPermissionsByRoles(roles []app.Role) ([]app.Permission, error) {
+	unique := make(map[app.Permission]bool)
+	var permissions []app.Permission
+	for _, role := range roles {
+		for _, permission := range r.db.rolePermissions[role] {
+			if !unique[permission] {
+				unique[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+func (r testRoleRepo) StoreUserRoles(userID app.UserID, roles []app.Role) error {
+	r.db.userRoles[userID] = roles
+	return nil
+}
+
+type testSuspensionRepo struct {
+	db *testDB
+}
+
+func (r testSuspensionRepo) FindByUserID(userID app.UserID) (*app.UserSuspension, error) {
+	suspension, ok := r.db.suspensions[userID]
+	if !ok {
+		return nil, app.ErrUserNotSuspended
+	}
+	return &suspension, nil
+}
+
+func (r testSuspensionRepo) Store(suspension *app.UserSuspension) error {
+	r.db.suspensions[suspension.UserID] = *suspension
+	return nil
+}
+
+func (r testSuspensionRepo) Remove(userID app.UserID) error {
+	delete(r.db.suspensions, userID)
+	return nil
+}
+
+type testAPIKeyRepo struct {
+	db *testDB
+}
+
+func (r testAPIKeyRepo) FindByHash(keyHash string) (*app.APIKey, error) {
+	for _, key := range r.db.apiKeys {
+		if key.KeyHash == keyHash {
+			return &key, nil
+		}
+	}
+	return nil, app.ErrAPIKeyNotFound
+}
+
+func (r testAPIKeyRepo) FindAllByUserID(userID app.UserID) ([]app.APIKey, error) {
+	var keys []app.APIKey
+	for _, key := range r.db.apiKeys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r testAPIKeyRepo) Store(key *app.APIKey) error {
+	r.db.apiKeys[key.ID] = *key
+	return nil
+}
+
+func (r testAPIKeyRepo) Remove(userID app.UserID, id app.APIKeyID) error {
+	key, ok := r.db.apiKeys[id]
+	if !ok || key.UserID != userID {
+		return app.ErrAPIKeyNotFound
+	}
+	delete(r.db.apiKeys, id)
+	return nil
+}
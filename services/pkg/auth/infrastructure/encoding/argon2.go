@@ -0,0 +1,88 @@
+package encoding
+
+import (
+	"arch-homework/pkg/auth/app"
+
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2idPrefix = "$argon2id$"
+	saltLength     = 16
+	keyLength      = 32
+)
+
+var errInvalidEncodedPassword = errors.New("invalid encoded password")
+
+// Argon2Params - memory is set in KiB
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func newArgon2PasswordEncoder(params Argon2Params) argon2PasswordEncoder {
+	return argon2PasswordEncoder{params: params}
+}
+
+type argon2PasswordEncoder struct {
+	params Argon2Params
+}
+
+// Encode returns hash in PHC string format: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func (e argon2PasswordEncoder) Encode(rawPassword string) (app.Password, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.WithStack(err)
+	}
+	hash := argon2.IDKey([]byte(rawPassword), salt, e.params.Iterations, e.params.Memory, e.params.Parallelism, keyLength)
+	return app.Password(fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		e.params.Memory,
+		e.params.Iterations,
+		e.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	)), nil
+}
+
+func (e argon2PasswordEncoder) Verify(rawPassword string, encodedPassword app.Password) (matched bool, needsRehash bool, err error) {
+	params, salt, hash, err := decodeArgon2Password(encodedPassword)
+	if err != nil {
+		return false, false, err
+	}
+	otherHash := argon2.IDKey([]byte(rawPassword), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(hash)))
+	if subtle.ConstantTimeCompare(hash, otherHash) != 1 {
+		return false, false, nil
+	}
+	return true, params != e.params, nil
+}
+
+func decodeArgon2Password(encodedPassword app.Password) (params Argon2Params, salt, hash []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(string(encodedPassword), argon2idPrefix), "$")
+	if len(parts) != 4 {
+		return params, nil, nil, errors.WithStack(errInvalidEncodedPassword)
+	}
+	var version int
+	if _, err = fmt.Sscanf(parts[0], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.Wrap(errInvalidEncodedPassword, "unsupported argon2 version")
+	}
+	if _, err = fmt.Sscanf(parts[1], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, errors.Wrap(errInvalidEncodedPassword, err.Error())
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[2]); err != nil {
+		return params, nil, nil, errors.Wrap(errInvalidEncodedPassword, err.Error())
+	}
+	if hash, err = base64.RawStdEncoding.DecodeString(parts[3]); err != nil {
+		return params, nil, nil, errors.Wrap(errInvalidEncodedPassword, err.Error())
+	}
+	return params, salt, hash, nil
+}
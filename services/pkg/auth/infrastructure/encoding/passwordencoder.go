@@ -1,20 +1,31 @@
 package encoding
 
 import (
-	"crypto/sha256"
-	"fmt"
-
 	"arch-homework/pkg/auth/app"
+
+	"strings"
 )
 
-func NewPasswordEncoder() app.PasswordEncoder {
-	return sha256PasswordEncoder{}
+func NewPasswordEncoder(params Argon2Params) app.PasswordEncoder {
+	return &passwordEncoder{
+		current: newArgon2PasswordEncoder(params),
+		legacy:  sha256PasswordEncoder{},
+	}
+}
+
+type passwordEncoder struct {
+	current argon2PasswordEncoder
+	legacy  sha256PasswordEncoder
 }
 
-type sha256PasswordEncoder struct {
+func (e *passwordEncoder) Encode(rawPassword string, _ app.UserID) (app.Password, error) {
+	return e.current.Encode(rawPassword)
 }
 
-func (m sha256PasswordEncoder) Encode(rawPassword string, userID app.UserID) app.Password {
-	data := []byte(string(userID) + rawPassword)
-	return app.Password(fmt.Sprintf("%x", sha256.Sum256(data)))
+func (e *passwordEncoder) Verify(rawPassword string, userID app.UserID, encodedPassword app.Password) (matched bool, needsRehash bool, err error) {
+	if strings.HasPrefix(string(encodedPassword), argon2idPrefix) {
+		return e.current.Verify(rawPassword, encodedPassword)
+	}
+	// passwords without algorithm prefix are encoded by legacy sha256 encoder
+	return e.legacy.Verify(rawPassword, userID, encodedPassword), true, nil
 }
@@ -0,0 +1,74 @@
+package encoding
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/stretchr/testify/assert"
+
+	"strings"
+	"testing"
+)
+
+var testParams = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+var testUserID = app.UserID(uuid.GenerateNew())
+
+func TestArgon2EncodeAndVerify(t *testing.T) {
+	encoder := NewPasswordEncoder(testParams)
+
+	encoded, err := encoder.Encode("password", testUserID)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(encoded), "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	otherEncoded, err := encoder.Encode("password", testUserID)
+	assert.NoError(t, err)
+	assert.NotEqual(t, encoded, otherEncoded, "salt should be random")
+
+	matched, needsRehash, err := encoder.Verify("password", testUserID, encoded)
+	assert.NoError(t, err)
+	assert.True(t, matched)
+	assert.False(t, needsRehash)
+
+	matched, _, err = encoder.Verify("wrong password", testUserID, encoded)
+	assert.NoError(t, err)
+	assert.False(t, matched)
+}
+
+func TestLegacySHA256PasswordNeedsRehash(t *testing.T) {
+	encoder := NewPasswordEncoder(testParams)
+	legacyEncoded := sha256PasswordEncoder{}.Encode("password", testUserID)
+
+	matched, needsRehash, err := encoder.Verify("password", testUserID, legacyEncoded)
+	assert.NoError(t, err)
+	assert.True(t, matched)
+	assert.True(t, needsRehash)
+
+	matched, _, err = encoder.Verify("password", app.UserID(uuid.GenerateNew()), legacyEncoded)
+	assert.NoError(t, err)
+	assert.False(t, matched, "legacy hash is salted with user id")
+}
+
+func TestChangedParamsNeedRehash(t *testing.T) {
+	encoded, err := NewPasswordEncoder(testParams).Encode("password", testUserID)
+	assert.NoError(t, err)
+
+	strongerParams := testParams
+	strongerParams.Iterations = 2
+	matched, needsRehash, err := NewPasswordEncoder(strongerParams).Verify("password", testUserID, encoded)
+	assert.NoError(t, err)
+	assert.True(t, matched)
+	assert.True(t, needsRehash)
+}
+
+func TestInvalidArgon2Password(t *testing.T) {
+	encoder := NewPasswordEncoder(testParams)
+	for _, encoded := range []app.Password{
+		"$argon2id$v=19$m=1024,t=1,p=1$salt",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=a,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$aGFzaA",
+	} {
+		_, _, err := encoder.Verify("password", testUserID, encoded)
+		assert.ErrorIs(t, err, errInvalidEncodedPassword, string(encoded))
+	}
+}
@@ -0,0 +1,62 @@
+package encoding
+
+import (
+	"arch-homework/pkg/auth/app"
+
+	"github.com/pkg/errors"
+
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+)
+
+const aesGCMPrefix = "v1:"
+
+var errInvalidEncryptedSecret = errors.New("invalid encrypted secret")
+
+// NewSecretEncryptor - AES-256-GCM, key version prefix allows to rotate algorithm later
+func NewSecretEncryptor(key []byte) (app.SecretEncryptor, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &secretEncryptor{aead: aead}, nil
+}
+
+type secretEncryptor struct {
+	aead cipher.AEAD
+}
+
+func (e *secretEncryptor) Encrypt(plain []byte) (string, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.WithStack(err)
+	}
+	sealed := e.aead.Seal(nonce, nonce, plain, nil)
+	return aesGCMPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (e *secretEncryptor) Decrypt(encrypted string) ([]byte, error) {
+	if !strings.HasPrefix(encrypted, aesGCMPrefix) {
+		return nil, errors.WithStack(errInvalidEncryptedSecret)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(encrypted, aesGCMPrefix))
+	if err != nil {
+		return nil, errors.Wrap(errInvalidEncryptedSecret, err.Error())
+	}
+	nonceSize := e.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.WithStack(errInvalidEncryptedSecret)
+	}
+	plain, err := e.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.Wrap(errInvalidEncryptedSecret, err.Error())
+	}
+	return plain, nil
+}
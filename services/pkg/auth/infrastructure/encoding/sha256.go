@@ -0,0 +1,22 @@
+package encoding
+
+import (
+	"arch-homework/pkg/auth/app"
+
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+)
+
+// sha256PasswordEncoder is legacy encoder, it is used only to verify passwords stored before migration to argon2id
+type sha256PasswordEncoder struct {
+}
+
+func (e sha256PasswordEncoder) Encode(rawPassword string, userID app.UserID) app.Password {
+	data := []byte(string(userID) + rawPassword)
+	return app.Password(fmt.Sprintf("%x", sha256.Sum256(data)))
+}
+
+func (e sha256PasswordEncoder) Verify(rawPassword string, userID app.UserID, encodedPassword app.Password) bool {
+	return subtle.ConstantTimeCompare([]byte(e.Encode(rawPassword, userID)), []byte(encodedPassword)) == 1
+}
@@ -0,0 +1,155 @@
+package oidc
+
+import (
+	"github.com/pkg/errors"
+
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	keySetTTL = time.Hour
+	// keySetMinRefreshInterval - limits refetching of key set, when token with unknown key id is received
+	keySetMinRefreshInterval = time.Minute
+)
+
+func newKeySet(httpClient *http.Client) *keySet {
+	return &keySet{httpClient: httpClient}
+}
+
+// keySet - cached json web key set of identity provider, keys are refetched when they are rotated
+type keySet struct {
+	httpClient *http.Client
+
+	mutex     sync.Mutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+func (s *keySet) key(jwksURI, kid string, now time.Time) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.keys == nil || now.Sub(s.fetchedAt) >= keySetTTL {
+		if err := s.refresh(jwksURI, now); err != nil {
+			return nil, err
+		}
+	}
+	key, ok := s.find(kid)
+	if !ok && now.Sub(s.fetchedAt) >= keySetMinRefreshInterval {
+		if err := s.refresh(jwksURI, now); err != nil {
+			return nil, err
+		}
+		key, ok = s.find(kid)
+	}
+	if !ok {
+		return nil, errors.Errorf("signing key '%s' not found", kid)
+	}
+	return key, nil
+}
+
+func (s *keySet) find(kid string) (interface{}, bool) {
+	// key id is optional, when key set contains only one key
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *keySet) refresh(jwksURI string, now time.Time) error {
+	var set jsonWebKeySet
+	if err := getJSON(s.httpClient, jwksURI, &set); err != nil {
+		return err
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// keys of unsupported types are skipped
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	s.keys = keys
+	s.fetchedAt = now
+	return nil
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("ec point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Errorf("unsupported key type '%s'", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,267 @@
+package oidc
+
+import (
+	"arch-homework/pkg/auth/app"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	discoveryTTL = time.Hour
+	// clockLeeway - allowed clock difference with identity provider
+	clockLeeway = time.Minute
+)
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+// signingMethods - symmetric algorithms are not accepted, since key would be the client secret
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type ProviderConfig struct {
+	Name         string   `json:"name"`
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	Scopes       []string `json:"scopes"`
+}
+
+// NewProvider - redirectURL is callback url registered in identity provider
+func NewProvider(cfg ProviderConfig, redirectURL string, httpClient *http.Client) app.IdentityProvider {
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+	return &provider{
+		cfg:         cfg,
+		scopes:      scopes,
+		redirectURL: redirectURL,
+		httpClient:  httpClient,
+		keySet:      newKeySet(httpClient),
+		now:         time.Now,
+	}
+}
+
+type provider struct {
+	cfg         ProviderConfig
+	scopes      []string
+	redirectURL string
+	httpClient  *http.Client
+	keySet      *keySet
+	now         func() time.Time
+
+	mutex       sync.Mutex
+	discovery   *discoveryDocument
+	discoveryAt time.Time
+}
+
+func (p *provider) AuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.redirectURL)
+	query.Set("scope", strings.Join(p.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), nil
+}
+
+func (p *provider) Exchange(code, codeVerifier, nonce string) (*app.ExternalUserInfo, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+	rawIDToken, err := p.requestIDToken(discovery.TokenEndpoint, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := p.verifyIDToken(discovery, rawIDToken, nonce)
+	if err != nil {
+		return nil, errors.Wrap(app.ErrExternalAuthFailed, err.Error())
+	}
+	return &app.ExternalUserInfo{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		FirstName:     claims.GivenName,
+		LastName:      claims.FamilyName,
+	}, nil
+}
+
+func (p *provider) requestIDToken(tokenEndpoint, code, codeVerifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+	req, err := http.NewRequest(http.MethodPost, tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	var tokenResp tokenResponse
+	_ = json.Unmarshal(body, &tokenResp)
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return "", errors.Wrapf(app.ErrExternalAuthFailed, "token request rejected: %s %s", tokenResp.Error, tokenResp.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("token request failed with status %d", resp.StatusCode)
+	}
+	if tokenResp.IDToken == "" {
+		return "", errors.Wrap(app.ErrExternalAuthFailed, "id token not returned")
+	}
+	return tokenResp.IDToken, nil
+}
+
+func (p *provider) verifyIDToken(discovery *discoveryDocument, rawIDToken, nonce string) (*idTokenClaims, error) {
+	var claims idTokenClaims
+	parser := jwt.Parser{ValidMethods: signingMethods, SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.keySet.key(discovery.JWKSURI, kid, p.now())
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	now := p.now()
+	switch {
+	case claims.Issuer != discovery.Issuer:
+		return nil, errors.New("invalid issuer")
+	case !claims.VerifyAudience(p.cfg.ClientID, true):
+		return nil, errors.New("invalid audience")
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID:
+		return nil, errors.New("invalid authorized party")
+	case claims.ExpiresAt == nil || !claims.VerifyExpiresAt(now.Add(-clockLeeway), true):
+		return nil, errors.New("token is expired")
+	case !claims.VerifyIssuedAt(now.Add(clockLeeway), false):
+		return nil, errors.New("token used before issued")
+	case claims.Nonce != nonce:
+		return nil, errors.New("invalid nonce")
+	case claims.Subject == "":
+		return nil, errors.New("subject is empty")
+	}
+	return &claims, nil
+}
+
+func (p *provider) getDiscovery() (*discoveryDocument, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.discovery != nil && p.now().Sub(p.discoveryAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+
+	discovery, err := p.fetchDiscovery()
+	if err != nil {
+		// outdated document is used while identity provider is unavailable
+		if p.discovery != nil {
+			return p.discovery, nil
+		}
+		return nil, err
+	}
+	p.discovery = discovery
+	p.discoveryAt = p.now()
+	return discovery, nil
+}
+
+func (p *provider) fetchDiscovery() (*discoveryDocument, error) {
+	discoveryURL := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	var discovery discoveryDocument
+	if err := getJSON(p.httpClient, discoveryURL, &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.Issuer != p.cfg.Issuer {
+		return nil, errors.Errorf("issuer '%s' in discovery document doesn't match '%s'", discovery.Issuer, p.cfg.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("discovery document is incomplete")
+	}
+	return &discovery, nil
+}
+
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("request to '%s' failed with status %d", url, resp.StatusCode)
+	}
+	return errors.WithStack(json.NewDecoder(resp.Body).Decode(v))
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string       `json:"nonce"`
+	AuthorizedParty string       `json:"azp"`
+	Email           string       `json:"email"`
+	EmailVerified   flexibleBool `json:"email_verified"`
+	GivenName       string       `json:"given_name"`
+	FamilyName      string       `json:"family_name"`
+}
+
+// flexibleBool - some providers send boolean claims as strings
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false", "null":
+		*b = false
+	default:
+		return errors.Errorf("invalid boolean value %s", string(data))
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package oidc
+
+import (
+	"arch-homework/pkg/auth/app"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID     = "arch-homework"
+	testClientSecret = "secret"
+	testRedirectURL  = "http://arch.homework/auth/api/v1/oidc/mock/callback"
+)
+
+func TestProviderCompletesCodeFlowWithPKCE(t *testing.T) {
+	server := newMockServer(t)
+	defer server.Close()
+	provider := NewProvider(server.config(), testRedirectURL, server.Client())
+
+	info, err := loginWithMock(server, provider, "nonce")
+	assert.NoError(t, err)
+	assert.Equal(t, "subject-1", info.Subject)
+	assert.Equal(t, "user@example.com", info.Email)
+	assert.True(t, info.EmailVerified)
+	assert.Equal(t, "John", info.FirstName)
+	assert.Equal(t, "Smith", info.LastName)
+	assert.Equal(t, 1, server.discoveryRequests)
+	assert.Equal(t, 1, server.keySetRequests)
+
+	_, err = loginWithMock(server, provider, "nonce")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, server.discoveryRequests)
+	assert.Equal(t, 1, server.keySetRequests)
+}
+
+func TestProviderRejectsInvalidCodeVerifier(t *testing.T) {
+	server := newMockServer(t)
+	defer server.Close()
+	provider := NewProvider(server.config(), testRedirectURL, server.Client())
+
+	code := authorize(t, server, provider, "state", "nonce", "verifier")
+	_, err := provider.Exchange(code, "another verifier", "nonce")
+	assert.Equal(t, app.ErrExternalAuthFailed, errors.Cause(err))
+}
+
+func TestProviderRejectsInvalidIDToken(t *testing.T) {
+	for name, modify := range map[string]func(claims *idTokenClaims){
+		"nonce":    func(claims *idTokenClaims) { claims.Nonce = "another nonce" },
+		"audience": func(claims *idTokenClaims) { claims.Audience = jwt.ClaimStrings{"another client"} },
+		"issuer":   func(claims *idTokenClaims) { claims.Issuer = "http://another.issuer" },
+		"expired": func(claims *idTokenClaims) {
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		},
+		"authorized party": func(claims *idTokenClaims) {
+			claims.Audience = jwt.ClaimStrings{testClientID, "another client"}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := newMockServer(t)
+			defer server.Close()
+			server.modifyClaims = modify
+			provider := NewProvider(server.config(), testRedirectURL, server.Client())
+
+			_, err := loginWithMock(server, provider, "nonce")
+			assert.Equal(t, app.ErrExternalAuthFailed, errors.Cause(err))
+		})
+	}
+}
+
+func TestProviderRejectsTokenSignedWithUnknownKey(t *testing.T) {
+	server := newMockServer(t)
+	defer server.Close()
+	p := NewProvider(server.config(), testRedirectURL, server.Client())
+	_, err := loginWithMock(server, p, "nonce")
+	assert.NoError(t, err)
+
+	// key isn't published, so token can't be verified even after key set is refetched
+	server.signingKey = newMockKey(t, "unpublished")
+	p.(*provider).now = func() time.Time { return time.Now().Add(keySetMinRefreshInterval) }
+	_, err = loginWithMock(server, p, "nonce")
+	assert.Equal(t, app.ErrExternalAuthFailed, errors.Cause(err))
+}
+
+func TestProviderRefetchesKeySetAfterKeyRotation(t *testing.T) {
+	server := newMockServer(t)
+	defer server.Close()
+	p := NewProvider(server.config(), testRedirectURL, server.Client())
+	_, err := loginWithMock(server, p, "nonce")
+	assert.NoError(t, err)
+
+	server.rotateKey(t)
+	// key set was fetched recently, so it isn't refetched
+	_, err = loginWithMock(server, p, "nonce")
+	assert.Equal(t, app.ErrExternalAuthFailed, errors.Cause(err))
+	assert.Equal(t, 1, server.keySetRequests)
+
+	p.(*provider).now = func() time.Time { return time.Now().Add(keySetMinRefreshInterval) }
+	_, err = loginWithMock(server, p, "nonce")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, server.keySetRequests)
+}
+
+func loginWithMock(server *mockServer, provider app.IdentityProvider, nonce string) (*app.ExternalUserInfo, error) {
+	code := authorize(server.t, server, provider, "state", nonce, "verifier")
+	return provider.Exchange(code, "verifier", nonce)
+}
+
+// authorize - emulates user, who follows redirect to identity provider and is redirected back with authorization code
+func authorize(t *testing.T, server *mockServer, provider app.IdentityProvider, state, nonce, codeVerifier string) string {
+	authURL, err := provider.AuthCodeURL(state, nonce, s256(codeVerifier))
+	assert.NoError(t, err)
+
+	client := server.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Get(authURL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	assert.NoError(t, err)
+	assert.Equal(t, testRedirectURL, location.Scheme+"://"+location.Host+location.Path)
+	assert.Equal(t, state, location.Query().Get("state"))
+	return location.Query().Get("code")
+}
+
+type mockAuthorization struct {
+	codeChallenge string
+	nonce         string
+}
+
+// mockServer - local OpenID Connect provider
+type mockServer struct {
+	*httptest.Server
+	t            *testing.T
+	signingKey   *mockKey
+	publishedKey *mockKey
+	modifyClaims func(claims *idTokenClaims)
+
+	mutex             sync.Mutex
+	codes             map[string]mockAuthorization
+	discoveryRequests int
+	keySetRequests    int
+}
+
+func newMockServer(t *testing.T) *mockServer {
+	key := newMockKey(t, "key-1")
+	server := &mockServer{
+		t:            t,
+		signingKey:   key,
+		publishedKey: key,
+		codes:        map[string]mockAuthorization{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", server.discovery)
+	mux.HandleFunc("/authorize", server.authorize)
+	mux.HandleFunc("/token", server.token)
+	mux.HandleFunc("/jwks", server.keySet)
+	server.Server = httptest.NewServer(mux)
+	return server
+}
+
+func (s *mockServer) config() ProviderConfig {
+	return ProviderConfig{
+		Name:         "mock",
+		Issuer:       s.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+	}
+}
+
+func (s *mockServer) rotateKey(t *testing.T) {
+	key := newMockKey(t, "key-2")
+	s.signingKey = key
+	s.publishedKey = key
+}
+
+func (s *mockServer) discovery(w http.ResponseWriter, _ *http.Request) {
+	s.mutex.Lock()
+	s.discoveryRequests++
+	s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *mockServer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != testClientID ||
+		query.Get("code_challenge_method") != "S256" || query.Get("scope") != "openid email profile" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	code := "code-" + query.Get("state")
+	s.mutex.Lock()
+	s.codes[code] = mockAuthorization{codeChallenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	s.mutex.Unlock()
+
+	redirectURL, _ := url.Parse(query.Get("redirect_uri"))
+	redirectURL.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	http.Redirect(w, r, redirectURL.String(), http.StatusFound)
+}
+
+func (s *mockServer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != testClientID || clientSecret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	s.mutex.Lock()
+	authorization, ok := s.codes[r.PostFormValue("code")]
+	delete(s.codes, r.PostFormValue("code"))
+	s.mutex.Unlock()
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("redirect_uri") != testRedirectURL ||
+		s256(r.PostFormValue("code_verifier")) != authorization.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := idTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.URL,
+			Subject:   "subject-1",
+			Audience:  jwt.ClaimStrings{testClientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Nonce:         authorization.nonce,
+		Email:         "user@example.com",
+		EmailVerified: true,
+		GivenName:     "John",
+		FamilyName:    "Smith",
+	}
+	if s.modifyClaims != nil {
+		s.modifyClaims(&claims)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.signingKey.kid
+	idToken, err := token.SignedString(s.signingKey.key)
+	assert.NoError(s.t, err)
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": idToken})
+}
+
+func (s *mockServer) keySet(w http.ResponseWriter, _ *http.Request) {
+	s.mutex.Lock()
+	s.keySetRequests++
+	s.mutex.Unlock()
+	publicKey := s.publishedKey.key.PublicKey
+	writeJSON(w, http.StatusOK, jsonWebKeySet{Keys: []jsonWebKey{{
+		Kid: s.publishedKey.kid,
+		Kty: "RSA",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+	}}})
+}
+
+type mockKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+func newMockKey(t *testing.T, kid string) *mockKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return &mockKey{kid: kid, key: key}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func s256(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/infrastructure/postgres"
+
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const apiKeyScopeSeparator = ","
+
+func NewAPIKeyRepository(client postgres.Client) app.APIKeyRepository {
+	return &apiKeyRepository{client: client}
+}
+
+type apiKeyRepository struct {
+	client postgres.Client
+}
+
+func (repo *apiKeyRepository) Store(key *app.APIKey) error {
+	const query = `
+			INSERT INTO api_key (id, user_id, name, key_hash, prefix, scopes, created_at, expires_at)
+			VALUES (:id, :user_id, :name, :key_hash, :prefix, :scopes, :created_at, :expires_at)
+		`
+
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	keyx := sqlxAPIKey{
+		ID:             string(key.ID),
+		UserID:         string(key.UserID),
+		Name:           key.Name,
+		KeyHash:        key.KeyHash,
+		Prefix:         key.Prefix,
+		Scopes:         strings.Join(scopes, apiKeyScopeSeparator),
+		CreationTime:   key.CreationTime.UTC(),
+		ExpirationTime: key.ExpirationTime.UTC(),
+	}
+
+	_, err := repo.client.NamedExec(query, &keyx)
+	return errors.WithStack(err)
+}
+
+func (repo *apiKeyRepository) Remove(userID app.UserID, id app.APIKeyID) error {
+	const query = `DELETE FROM api_key WHERE id = $1 AND user_id = $2`
+	result, err := repo.client.Exec(query, string(id), string(userID))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if count == 0 {
+		return errors.WithStack(app.ErrAPIKeyNotFound)
+	}
+	return nil
+}
+
+func (repo *apiKeyRepository) FindByHash(keyHash string) (*app.APIKey, error) {
+	const query = `SELECT id, user_id, name, key_hash, prefix, scopes, created_at, expires_at FROM api_key WHERE key_hash = $1`
+
+	var key sqlxAPIKey
+	err := repo.client.Get(&key, query, keyHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrAPIKeyNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	res := toAPIKey(&key)
+	return &res, nil
+}
+
+func (repo *apiKeyRepository) FindAllByUserID(userID app.UserID) ([]app.APIKey, error) {
+	const query = `SELECT id, user_id, name, key_hash, prefix, scopes, created_at, expires_at FROM api_key WHERE user_id = $1 ORDER BY created_at`
+
+	var keys []sqlxAPIKey
+	err := repo.client.Select(&keys, query, string(userID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.APIKey, 0, len(keys))
+	for i := range keys {
+		res = append(res, toAPIKey(&keys[i]))
+	}
+	return res, nil
+}
+
+func toAPIKey(key *sqlxAPIKey) app.APIKey {
+	var scopes []app.APIKeyScope
+	for _, scope := range strings.Split(key.Scopes, apiKeyScopeSeparator) {
+		if scope != "" {
+			scopes = append(scopes, app.APIKeyScope(scope))
+		}
+	}
+	return app.APIKey{
+		ID:             app.APIKeyID(key.ID),
+		UserID:         app.UserID(key.UserID),
+		Name:           key.Name,
+		KeyHash:        key.KeyHash,
+		Prefix:         key.Prefix,
+		Scopes:         scopes,
+		CreationTime:   key.CreationTime,
+		ExpirationTime: key.ExpirationTime,
+	}
+}
+
+type sqlxAPIKey struct {
+	ID             string    `db:"id"`
+	UserID         string    `db:"user_id"`
+	Name           string    `db:"name"`
+	KeyHash        string    `db:"key_hash"`
+	Prefix         string    `db:"prefix"`
+	Scopes         string    `db:"scopes"`
+	CreationTime   time.Time `db:"created_at"`
+	ExpirationTime time.Time `db:"expires_at"`
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/app/storedevent"
 	"arch-homework/pkg/common/infrastructure/postgres"
 
 	"github.com/pkg/errors"
@@ -27,14 +28,62 @@ func (d *dbDependency) UserRepositoryRead() app.UserRepositoryRead {
 	return NewUserRepository(d.client)
 }
 
+func (d *dbDependency) TwoFactorRepositoryRead() app.TwoFactorRepositoryRead {
+	return NewTwoFactorRepository(d.client)
+}
+
+func (d *dbDependency) ExternalIdentityRepositoryRead() app.ExternalIdentityRepositoryRead {
+	return NewExternalIdentityRepository(d.client)
+}
+
+func (d *dbDependency) RoleRepositoryRead() app.RoleRepositoryRead {
+	return NewRoleRepository(d.client)
+}
+
+func (d *dbDependency) UserSuspensionRepositoryRead() app.UserSuspensionRepositoryRead {
+	return NewUserSuspensionRepository(d.client)
+}
+
+func (d *dbDependency) APIKeyRepositoryRead() app.APIKeyRepositoryRead {
+	return NewAPIKeyRepository(d.client)
+}
+
 type transactionalUnit struct {
 	transaction postgres.Transaction
 }
 
+func (t *transactionalUnit) EventStore() storedevent.EventStore {
+	return NewEventStore(t.transaction)
+}
+
 func (t *transactionalUnit) UserRepository() app.UserRepository {
 	return NewUserRepository(t.transaction)
 }
 
+func (t *transactionalUnit) TwoFactorRepository() app.TwoFactorRepository {
+	return NewTwoFactorRepository(t.transaction)
+}
+
+func (t *transactionalUnit) PasswordResetTokenRepository() app.PasswordResetTokenRepository {
+	return NewPasswordResetTokenRepository(t.transaction)
+}
+
+func (t *transactionalUnit) ExternalIdentityRepository() app.ExternalIdentityRepository {
+	return NewExternalIdentityRepository(t.transaction)
+}
+
+func (t *transactionalUnit) RoleRepository() app.RoleRepository {
+	return NewRoleRepository(t.transaction)
+}
+
+func (t *transactionalUnit) UserSuspensionRepository() app.UserSuspensionRepository {
+	return NewUserSuspensionRepository(t.transaction)
+}
+
+func (t *transactionalUnit) APIKeyRepository() app.APIKeyRepository {
+	return NewAPIKeyRepository(t.transaction)
+}
+
 func (t *transactionalUnit) Complete(err error) error {
 	if err != nil {
 		rollbackErr := t.transaction.Rollback()
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/infrastructure/postgres"
+
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func NewExternalIdentityRepository(client postgres.Client) app.ExternalIdentityRepository {
+	return &externalIdentityRepository{client: client}
+}
+
+type externalIdentityRepository struct {
+	client postgres.Client
+}
+
+func (repo *externalIdentityRepository) Store(identity *app.ExternalIdentity) error {
+	const query = `
+			INSERT INTO user_external_identity (provider, subject, user_id)
+			VALUES (:provider, :subject, :user_id)
+			ON CONFLICT (provider, subject) DO UPDATE SET
+				user_id = excluded.user_id
+		`
+
+	identityx := sqlxExternalIdentity{
+		Provider: string(identity.Provider),
+		Subject:  identity.Subject,
+		UserID:   string(identity.UserID),
+	}
+
+	_, err := repo.client.NamedExec(query, &identityx)
+	return errors.WithStack(err)
+}
+
+func (repo *externalIdentityRepository) Remove(provider app.IdentityProviderName, userID app.UserID) error {
+	const query = `DELETE FROM user_external_identity WHERE provider = $1 AND user_id = $2`
+	_, err := repo.client.Exec(query, string(provider), string(userID))
+	return errors.WithStack(err)
+}
+
+func (repo *externalIdentityRepository) FindByProviderAndSubject(provider app.IdentityProviderName, subject string) (*app.ExternalIdentity, error) {
+	const query = `SELECT provider, subject, user_id FROM user_external_identity WHERE provider = $1 AND subject = $2`
+
+	var identity sqlxExternalIdentity
+	err := repo.client.Get(&identity, query, string(provider), subject)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, app.ErrExternalIdentityNotFound
+		}
+		return nil, errors.WithStack(err)
+	}
+	res := sqlxExternalIdentityToExternalIdentity(&identity)
+	return &res, nil
+}
+
+func (repo *externalIdentityRepository) FindAllByUserID(userID app.UserID) ([]app.ExternalIdentity, error) {
+	const query = `SELECT provider, subject, user_id FROM user_external_identity WHERE user_id = $1 ORDER BY created_at`
+
+	var identities []sqlxExternalIdentity
+	err := repo.client.Select(&identities, query, string(userID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.ExternalIdentity, 0, len(identities))
+	for _, identity := range identities {
+		res = append(res, sqlxExternalIdentityToExternalIdentity(&identity))
+	}
+	return res, nil
+}
+
+func sqlxExternalIdentityToExternalIdentity(identity *sqlxExternalIdentity) app.ExternalIdentity {
+	return app.ExternalIdentity{
+		Provider: app.IdentityProviderName(identity.Provider),
+		Subject:  identity.Subject,
+		UserID:   app.UserID(identity.UserID),
+	}
+}
+
+type sqlxExternalIdentity struct {
+	Provider string `db:"provider"`
+	Subject  string `db:"subject"`
+	UserID   string `db:"user_id"`
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/infrastructure/postgres"
+
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func NewPasswordResetTokenRepository(client postgres.Client) app.PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{client: client}
+}
+
+type passwordResetTokenRepository struct {
+	client postgres.Client
+}
+
+func (repo *passwordResetTokenRepository) Store(token *app.PasswordResetToken) error {
+	const query = `
+			INSERT INTO password_reset_token (id, token_hash, user_id, expires_at)
+			VALUES (:id, :token_hash, :user_id, :expires_at)
+		`
+
+	tokenx := sqlxPasswordResetToken{
+		ID:        sql.NullString{String: string(token.ID), Valid: true},
+		TokenHash: token.TokenHash,
+		UserID:    string(token.UserID),
+		ExpiresAt: token.ExpiresAt.UTC(),
+	}
+
+	_, err := repo.client.NamedExec(query, &tokenx)
+	return errors.WithStack(err)
+}
+
+func (repo *passwordResetTokenRepository) Take(tokenHash string) (*app.PasswordResetToken, error) {
+	const query = `DELETE FROM password_reset_token WHERE token_hash = $1 RETURNING id, token_hash, user_id, expires_at`
+	return repo.get(query, tokenHash)
+}
+
+func (repo *passwordResetTokenRepository) ReplaceHash(id app.PasswordResetTokenID, tokenHash string) (*app.PasswordResetToken, error) {
+	const query = `UPDATE password_reset_token SET token_hash = $2 WHERE id = $1 RETURNING id, token_hash, user_id, expires_at`
+	return repo.get(query, string(id), tokenHash)
+}
+
+func (repo *passwordResetTokenRepository) RemoveAllByUserID(userID app.UserID) error {
+	const query = `DELETE FROM password_reset_token WHERE user_id = $1`
+	_, err := repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
+
+func (repo *passwordResetTokenRepository) get(query string, args ...interface{}) (*app.PasswordResetToken, error) {
+	var token sqlxPasswordResetToken
+	err := repo.client.Get(&token, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, app.ErrPasswordResetTokenNotFound
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &app.PasswordResetToken{
+		ID:        app.PasswordResetTokenID(token.ID.String),
+		TokenHash: token.TokenHash,
+		UserID:    app.UserID(token.UserID),
+		ExpiresAt: token.ExpiresAt,
+	}, nil
+}
+
+type sqlxPasswordResetToken struct {
+	// ID - tokens created before token issue by ID have no ID
+	ID        sql.NullString `db:"id"`
+	TokenHash string         `db:"token_hash"`
+	UserID    string         `db:"user_id"`
+	ExpiresAt time.Time      `db:"expires_at"`
+}
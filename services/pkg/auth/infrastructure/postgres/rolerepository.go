@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/infrastructure/postgres"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+func NewRoleRepository(client postgres.Client) app.RoleRepository {
+	return &roleRepository{client: client}
+}
+
+type roleRepository struct {
+	client postgres.Client
+}
+
+func (repo *roleRepository) StoreUserRoles(userID app.UserID, roles []app.Role) error {
+	const deleteQuery = `DELETE FROM user_role WHERE user_id = $1`
+	_, err := repo.client.Exec(deleteQuery, string(userID))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	const query = `INSERT INTO user_role (user_id, role) VALUES ($1, $2)`
+	for _, role := range roles {
+		_, err = repo.client.Exec(query, string(userID), string(role))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (repo *roleRepository) FindAllRoles() ([]app.Role, error) {
+	const query = `SELECT DISTINCT role FROM role_permission ORDER BY role`
+
+	var roles []string
+	err := repo.client.Select(&roles, query)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return toRoles(roles), nil
+}
+
+func (repo *roleRepository) FindRolesByUserID(userID app.UserID) ([]app.Role, error) {
+	const query = `SELECT role FROM user_role WHERE user_id = $1 ORDER BY role`
+
+	var roles []string
+	err := repo.client.Select(&roles, query, string(userID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return toRoles(roles), nil
+}
+
+func (repo *roleRepository) FindPermissionsByRoles(roles []app.Role) ([]app.Permission, error) {
+	const sqlQuery = `SELECT DISTINCT permission FROM role_permission WHERE role IN (?) ORDER BY permission`
+
+	strRoles := make([]string, 0, len(roles))
+	for _, role := range roles {
+		strRoles = append(strRoles, string(role))
+	}
+
+	query, params, err := sqlx.In(sqlQuery, strRoles)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	query = sqlx.Rebind(sqlx.DOLLAR, query)
+
+	var permissions []string
+	err = repo.client.Select(&permissions, query, params...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		res = append(res, app.Permission(permission))
+	}
+	return res, nil
+}
+
+func toRoles(roles []string) []app.Role {
+	res := make([]app.Role, 0, len(roles))
+	for _, role := range roles {
+		res = append(res, app.Role(role))
+	}
+	return res
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/infrastructure/postgres"
+
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func NewTwoFactorRepository(client postgres.Client) app.TwoFactorRepository {
+	return &twoFactorRepository{client: client}
+}
+
+type twoFactorRepository struct {
+	client postgres.Client
+}
+
+func (repo *twoFactorRepository) Store(twoFactor *app.TwoFactor) error {
+	const query = `
+			INSERT INTO user_totp (user_id, secret, enabled, last_used_step)
+			VALUES (:user_id, :secret, :enabled, :last_used_step)
+			ON CONFLICT (user_id) DO UPDATE SET
+				secret = excluded.secret,
+				enabled = excluded.enabled,
+				last_used_step = GREATEST(user_totp.last_used_step, excluded.last_used_step)
+		`
+
+	twoFactorx := sqlxTwoFactor{
+		UserID:       string(twoFactor.UserID),
+		Secret:       twoFactor.EncryptedSecret,
+		Enabled:      twoFactor.Enabled,
+		LastUsedStep: twoFactor.LastUsedStep,
+	}
+
+	_, err := repo.client.NamedExec(query, &twoFactorx)
+	return errors.WithStack(err)
+}
+
+func (repo *twoFactorRepository) Remove(userID app.UserID) error {
+	const deleteCodesQuery = `DELETE FROM totp_recovery_code WHERE user_id = $1`
+	_, err := repo.client.Exec(deleteCodesQuery, string(userID))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	const query = `DELETE FROM user_totp WHERE user_id = $1`
+	_, err = repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
+
+func (repo *twoFactorRepository) ReplaceRecoveryCodes(userID app.UserID, codeHashes []string) error {
+	const deleteQuery = `DELETE FROM totp_recovery_code WHERE user_id = $1`
+	_, err := repo.client.Exec(deleteQuery, string(userID))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	const query = `INSERT INTO totp_recovery_code (user_id, code_hash) VALUES ($1, $2)`
+	for _, codeHash := range codeHashes {
+		_, err = repo.client.Exec(query, string(userID), codeHash)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (repo *twoFactorRepository) UseRecoveryCode(userID app.UserID, codeHash string) (bool, error) {
+	const query = `UPDATE totp_recovery_code SET used = TRUE WHERE user_id = $1 AND code_hash = $2 AND used = FALSE`
+
+	result, err := repo.client.Exec(query, string(userID), codeHash)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return affected > 0, nil
+}
+
+func (repo *twoFactorRepository) UseTOTPStep(userID app.UserID, step int64) (bool, error) {
+	const query = `UPDATE user_totp SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+
+	result, err := repo.client.Exec(query, string(userID), step)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return affected > 0, nil
+}
+
+func (repo *twoFactorRepository) FindByUserID(userID app.UserID) (*app.TwoFactor, error) {
+	const query = `SELECT user_id, secret, enabled, last_used_step FROM user_totp WHERE user_id = $1`
+
+	var twoFactor sqlxTwoFactor
+	err := repo.client.Get(&twoFactor, query, string(userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, app.ErrTwoFactorNotFound
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &app.TwoFactor{
+		UserID:          app.UserID(twoFactor.UserID),
+		EncryptedSecret: twoFactor.Secret,
+		Enabled:         twoFactor.Enabled,
+		LastUsedStep:    twoFactor.LastUsedStep,
+	}, nil
+}
+
+type sqlxTwoFactor struct {
+	UserID       string `db:"user_id"`
+	Secret       string `db:"secret"`
+	Enabled      bool   `db:"enabled"`
+	LastUsedStep int64  `db:"last_used_step"`
+}
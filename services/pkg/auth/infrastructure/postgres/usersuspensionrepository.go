@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/infrastructure/postgres"
+
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func NewUserSuspensionRepository(client postgres.Client) app.UserSuspensionRepository {
+	return &userSuspensionRepository{client: client}
+}
+
+type userSuspensionRepository struct {
+	client postgres.Client
+}
+
+func (repo *userSuspensionRepository) Store(suspension *app.UserSuspension) error {
+	const query = `
+			INSERT INTO user_suspension (user_id, reason, suspended_by, created_at)
+			VALUES (:user_id, :reason, :suspended_by, :created_at)
+			ON CONFLICT (user_id) DO UPDATE SET
+				reason = excluded.reason,
+				suspended_by = excluded.suspended_by,
+				created_at = excluded.created_at
+		`
+
+	suspensionx := sqlxUserSuspension{
+		UserID:       string(suspension.UserID),
+		Reason:       suspension.Reason,
+		SuspendedBy:  string(suspension.SuspendedBy),
+		CreationTime: suspension.CreationTime,
+	}
+
+	_, err := repo.client.NamedExec(query, &suspensionx)
+	return errors.WithStack(err)
+}
+
+func (repo *userSuspensionRepository) Remove(userID app.UserID) error {
+	const query = `DELETE FROM user_suspension WHERE user_id = $1`
+	_, err := repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
+
+func (repo *userSuspensionRepository) FindByUserID(userID app.UserID) (*app.UserSuspension, error) {
+	const query = `SELECT user_id, reason, suspended_by, created_at FROM user_suspension WHERE user_id = $1`
+
+	var suspension sqlxUserSuspension
+	err := repo.client.Get(&suspension, query, string(userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrUserNotSuspended)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &app.UserSuspension{
+		UserID:       app.UserID(suspension.UserID),
+		Reason:       suspension.Reason,
+		SuspendedBy:  app.UserID(suspension.SuspendedBy),
+		CreationTime: suspension.CreationTime,
+	}, nil
+}
+
+type sqlxUserSuspension struct {
+	UserID       string    `db:"user_id"`
+	Reason       string    `db:"reason"`
+	SuspendedBy  string    `db:"suspended_by"`
+	CreationTime time.Time `db:"created_at"`
+}
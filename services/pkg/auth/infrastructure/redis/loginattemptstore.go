@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+func NewLoginAttemptStore(client *redis.Client) app.LoginAttemptStore {
+	return &loginAttemptStore{client: client}
+}
+
+// loginAttemptStore - failures are stored in sorted set with failure time as score
+type loginAttemptStore struct {
+	client *redis.Client
+}
+
+func (s *loginAttemptStore) FindAttempts(key app.LoginAttemptKey, since time.Time) (app.LoginAttempts, error) {
+	scores, err := s.client.ZRangeByScoreWithScores(context.Background(), failuresKey(key), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return app.LoginAttempts{}, errors.WithStack(err)
+	}
+	return loginAttempts(scores), nil
+}
+
+func (s *loginAttemptStore) AddFailure(key app.LoginAttemptKey, now time.Time, window time.Duration) (app.LoginAttempts, error) {
+	ctx := context.Background()
+	redisKey := failuresKey(key)
+	var scores *redis.ZSliceCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, redisKey, &redis.Z{Score: float64(now.UnixMilli()), Member: uuid.GenerateNew()})
+		pipe.ZRemRangeByScore(ctx, redisKey, "-inf", "("+strconv.FormatInt(now.Add(-window).UnixMilli(), 10))
+		pipe.Expire(ctx, redisKey, window)
+		scores = pipe.ZRangeWithScores(ctx, redisKey, 0, -1)
+		return nil
+	})
+	if err != nil {
+		return app.LoginAttempts{}, errors.WithStack(err)
+	}
+	return loginAttempts(scores.Val()), nil
+}
+
+func (s *loginAttemptStore) ResetFailures(key app.LoginAttemptKey) error {
+	_, err := s.client.Del(context.Background(), failuresKey(key)).Result()
+	return errors.WithStack(err)
+}
+
+func (s *loginAttemptStore) FindLock(key app.LoginAttemptKey) (*time.Time, error) {
+	data, err := s.client.Get(context.Background(), lockKey(key)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	until, err := strconv.ParseInt(data, 10, 64)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lockedUntil := time.Unix(until, 0)
+	return &lockedUntil, nil
+}
+
+func (s *loginAttemptStore) Lock(key app.LoginAttemptKey, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	_, err := s.client.Set(context.Background(), lockKey(key), until.Unix(), ttl).Result()
+	return errors.WithStack(err)
+}
+
+func (s *loginAttemptStore) Unlock(key app.LoginAttemptKey) error {
+	_, err := s.client.Del(context.Background(), lockKey(key)).Result()
+	return errors.WithStack(err)
+}
+
+func loginAttempts(scores []redis.Z) app.LoginAttempts {
+	attempts := app.LoginAttempts{Failures: len(scores)}
+	if len(scores) > 0 {
+		attempts.LastFailure = time.UnixMilli(int64(scores[len(scores)-1].Score))
+	}
+	return attempts
+}
+
+func failuresKey(key app.LoginAttemptKey) string {
+	return fmt.Sprintf("login_failures:%s", string(key))
+}
+
+func lockKey(key app.LoginAttemptKey) string {
+	return fmt.Sprintf("login_lock:%s", string(key))
+}
@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	challengeFieldUserID   = "user_id"
+	challengeFieldAttempts = "attempts"
+)
+
+// incrementAttemptsScript - does not recreate expired challenge without ttl
+var incrementAttemptsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+return redis.call('HINCRBY', KEYS[1], ARGV[1], 1)
+`)
+
+func NewLoginChallengeStore(client *redis.Client) app.LoginChallengeStore {
+	return &loginChallengeStore{client: client}
+}
+
+type loginChallengeStore struct {
+	client *redis.Client
+}
+
+func (s *loginChallengeStore) Store(challenge app.LoginChallenge, ttl time.Duration) error {
+	ctx := context.Background()
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, loginChallengeKey(challenge.ID),
+			challengeFieldUserID, string(challenge.UserID),
+			challengeFieldAttempts, challenge.Attempts,
+		)
+		pipe.Expire(ctx, loginChallengeKey(challenge.ID), ttl)
+		return nil
+	})
+	return errors.WithStack(err)
+}
+
+func (s *loginChallengeStore) FindByID(id app.LoginChallengeID) (*app.LoginChallenge, error) {
+	data, err := s.client.HGetAll(context.Background(), loginChallengeKey(id)).Result()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	userID, ok := data[challengeFieldUserID]
+	if !ok {
+		return nil, errors.WithStack(app.ErrLoginChallengeNotFound)
+	}
+	if err = uuid.ValidateUUID(userID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	attempts, err := strconv.Atoi(data[challengeFieldAttempts])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &app.LoginChallenge{
+		ID:       id,
+		UserID:   app.UserID(userID),
+		Attempts: attempts,
+	}, nil
+}
+
+func (s *loginChallengeStore) IncrementAttempts(id app.LoginChallengeID) (int, error) {
+	attempts, err := incrementAttemptsScript.Run(context.Background(), s.client, []string{loginChallengeKey(id)}, challengeFieldAttempts).Int()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	if attempts < 0 {
+		return 0, errors.WithStack(app.ErrLoginChallengeNotFound)
+	}
+	return attempts, nil
+}
+
+func (s *loginChallengeStore) Remove(id app.LoginChallengeID) error {
+	_, err := s.client.Del(context.Background(), loginChallengeKey(id)).Result()
+	return errors.WithStack(err)
+}
+
+func loginChallengeKey(id app.LoginChallengeID) string {
+	return fmt.Sprintf("login_challenge:%s", string(id))
+}
@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	oauthStateFieldProvider     = "provider"
+	oauthStateFieldCodeVerifier = "code_verifier"
+	oauthStateFieldNonce        = "nonce"
+	oauthStateFieldLinkUserID   = "link_user_id"
+)
+
+func NewOAuthStateStore(client *redis.Client) app.OAuthStateStore {
+	return &oauthStateStore{client: client}
+}
+
+type oauthStateStore struct {
+	client *redis.Client
+}
+
+func (s *oauthStateStore) Store(state app.OAuthState, ttl time.Duration) error {
+	ctx := context.Background()
+	values := []interface{}{
+		oauthStateFieldProvider, string(state.Provider),
+		oauthStateFieldCodeVerifier, state.CodeVerifier,
+		oauthStateFieldNonce, state.Nonce,
+	}
+	if state.LinkUserID != nil {
+		values = append(values, oauthStateFieldLinkUserID, string(*state.LinkUserID))
+	}
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, oauthStateKey(state.State), values...)
+		pipe.Expire(ctx, oauthStateKey(state.State), ttl)
+		return nil
+	})
+	return errors.WithStack(err)
+}
+
+func (s *oauthStateStore) Take(state string) (*app.OAuthState, error) {
+	ctx := context.Background()
+	var getCmd *redis.StringStringMapCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		getCmd = pipe.HGetAll(ctx, oauthStateKey(state))
+		pipe.Del(ctx, oauthStateKey(state))
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	data := getCmd.Val()
+	provider, ok := data[oauthStateFieldProvider]
+	if !ok {
+		return nil, errors.WithStack(app.ErrOAuthStateNotFound)
+	}
+	result := app.OAuthState{
+		State:        state,
+		Provider:     app.IdentityProviderName(provider),
+		CodeVerifier: data[oauthStateFieldCodeVerifier],
+		Nonce:        data[oauthStateFieldNonce],
+	}
+	if linkUserID, ok := data[oauthStateFieldLinkUserID]; ok {
+		if err = uuid.ValidateUUID(linkUserID); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		userID := app.UserID(linkUserID)
+		result.LinkUserID = &userID
+	}
+	return &result, nil
+}
+
+func oauthStateKey(state string) string {
+	return fmt.Sprintf("oauth_state:%s", state)
+}
@@ -9,9 +9,20 @@ import (
 
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+const (
+	sessionFieldUserID   = "user_id"
+	sessionFieldDevice   = "device"
+	sessionFieldIP       = "ip"
+	sessionFieldCreated  = "created_at"
+	sessionFieldLastSeen = "last_seen_at"
+)
+
 func NewSessionClient(client *redis.Client, sessionTTL time.Duration) app.SessionClient {
 	return &sessionClient{
 		client:     client,
@@ -19,46 +30,173 @@ func NewSessionClient(client *redis.Client, sessionTTL time.Duration) app.Sessio
 	}
 }
 
+// sessionClient - session is stored in hash, set of user session ids is used as index,
+// index entries of expired sessions are removed on read
 type sessionClient struct {
 	client     *redis.Client
 	sessionTTL time.Duration
 }
 
 func (s *sessionClient) Store(session app.Session) error {
-	value := string(session.UserID)
-	_, err := s.client.Set(context.Background(), sessionKey(session.ID), value, s.sessionTTL).Result()
+	ctx := context.Background()
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, sessionKey(session.ID),
+			sessionFieldUserID, string(session.UserID),
+			sessionFieldDevice, session.Device,
+			sessionFieldIP, session.IP,
+			sessionFieldCreated, session.CreationTime.Unix(),
+			sessionFieldLastSeen, session.LastSeenTime.Unix(),
+		)
+		pipe.Expire(ctx, sessionKey(session.ID), s.sessionTTL)
+		pipe.SAdd(ctx, userSessionsKey(session.UserID), string(session.ID))
+		pipe.Expire(ctx, userSessionsKey(session.UserID), s.sessionTTL)
+		return nil
+	})
 	return errors.WithStack(err)
 }
 
 func (s *sessionClient) Remove(id app.SessionID) error {
-	_, err := s.client.Del(context.Background(), sessionKey(id)).Result()
+	ctx := context.Background()
+	userID, err := s.client.HGet(ctx, sessionKey(id), sessionFieldUserID).Result()
+	if err != nil && !errors.Is(err, redis.Nil) && !isLegacySessionErr(err) {
+		return errors.WithStack(err)
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(id))
+		if userID != "" {
+			pipe.SRem(ctx, userSessionsKey(app.UserID(userID)), string(id))
+		}
+		return nil
+	})
+	return errors.WithStack(err)
+}
+
+func (s *sessionClient) RemoveAllByUserID(userID app.UserID) error {
+	ctx := context.Background()
+	ids, err := s.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	keys := make([]string, 0, len(ids)+1)
+	for _, id := range ids {
+		keys = append(keys, sessionKey(app.SessionID(id)))
+	}
+	keys = append(keys, userSessionsKey(userID))
+	_, err = s.client.Del(ctx, keys...).Result()
 	return errors.WithStack(err)
 }
 
 func (s *sessionClient) FindByID(id app.SessionID) (*app.Session, error) {
-	data, err := s.client.Get(context.Background(), sessionKey(id)).Result()
+	data, err := s.client.HGetAll(context.Background(), sessionKey(id)).Result()
 	if err != nil {
-		if errors.Is(err, redis.Nil) || data == "" {
+		if isLegacySessionErr(err) {
 			return nil, app.ErrSessionNotFound
 		}
 		return nil, errors.WithStack(err)
 	}
-	err = uuid.ValidateUUID(data)
+	return hashToSession(id, data)
+}
+
+func (s *sessionClient) FindAllByUserID(userID app.UserID) ([]app.Session, error) {
+	ctx := context.Background()
+	ids, err := s.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cmds := make([]*redis.StringStringMapCmd, 0, len(ids))
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range ids {
+			cmds = append(cmds, pipe.HGetAll(ctx, sessionKey(app.SessionID(id))))
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	session := app.Session{
-		ID:     id,
-		UserID: app.UserID(data),
+
+	sessions := make([]app.Session, 0, len(ids))
+	var expiredIDs []interface{}
+	for i, cmd := range cmds {
+		session, err2 := hashToSession(app.SessionID(ids[i]), cmd.Val())
+		if err2 == app.ErrSessionNotFound {
+			expiredIDs = append(expiredIDs, ids[i])
+			continue
+		}
+		if err2 != nil {
+			return nil, err2
+		}
+		sessions = append(sessions, *session)
 	}
-	return &session, nil
+	if len(expiredIDs) > 0 {
+		_, err = s.client.SRem(ctx, userSessionsKey(userID), expiredIDs...).Result()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreationTime.Before(sessions[j].CreationTime)
+	})
+	return sessions, nil
 }
 
 func (s *sessionClient) UpdateSessionTTL(id app.SessionID) error {
-	_, err := s.client.Expire(context.Background(), sessionKey(id), s.sessionTTL).Result()
+	ctx := context.Background()
+	userID, err := s.client.HGet(ctx, sessionKey(id), sessionFieldUserID).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) || isLegacySessionErr(err) {
+			return app.ErrSessionNotFound
+		}
+		return errors.WithStack(err)
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, sessionKey(id), sessionFieldLastSeen, time.Now().Unix())
+		pipe.Expire(ctx, sessionKey(id), s.sessionTTL)
+		pipe.Expire(ctx, userSessionsKey(app.UserID(userID)), s.sessionTTL)
+		return nil
+	})
 	return errors.WithStack(err)
 }
 
+func hashToSession(id app.SessionID, data map[string]string) (*app.Session, error) {
+	userID, ok := data[sessionFieldUserID]
+	if !ok {
+		return nil, app.ErrSessionNotFound
+	}
+	err := uuid.ValidateUUID(userID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	created, err := strconv.ParseInt(data[sessionFieldCreated], 10, 64)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lastSeen, err := strconv.ParseInt(data[sessionFieldLastSeen], 10, 64)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	session := app.Session{
+		ID:           id,
+		UserID:       app.UserID(userID),
+		Device:       data[sessionFieldDevice],
+		IP:           data[sessionFieldIP],
+		CreationTime: time.Unix(created, 0),
+		LastSeenTime: time.Unix(lastSeen, 0),
+	}
+	return &session, nil
+}
+
+// isLegacySessionErr - sessions stored as plain user id before session index was introduced
+// are treated as expired, user has to log in again
+func isLegacySessionErr(err error) bool {
+	return strings.HasPrefix(err.Error(), "WRONGTYPE")
+}
+
 func sessionKey(id app.SessionID) string {
 	return fmt.Sprintf("session:%s", string(id))
 }
+
+func userSessionsKey(userID app.UserID) string {
+	return fmt.Sprintf("user_sessions:%s", string(userID))
+}
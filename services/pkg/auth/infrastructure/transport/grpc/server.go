@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"arch-homework/api/authpb"
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/infrastructure/grpcserver"
+
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func NewServer(userService *app.UserService, passwordService *app.PasswordService) authpb.AuthServiceServer {
+	return &server{
+		userService:     userService,
+		passwordService: passwordService,
+	}
+}
+
+type server struct {
+	authpb.UnimplementedAuthServiceServer
+	userService     *app.UserService
+	passwordService *app.PasswordService
+}
+
+func (s *server) RegisterUser(_ context.Context, req *authpb.RegisterUserRequest) (*authpb.RegisterUserResponse, error) {
+	userID, err := s.userService.Add(req.Login, req.Password)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &authpb.RegisterUserResponse{UserId: string(userID)}, nil
+}
+
+func (s *server) RemoveUser(_ context.Context, req *authpb.RemoveUserRequest) (*authpb.RemoveUserResponse, error) {
+	if err := grpcserver.ValidateUUID("user id", req.UserId); err != nil {
+		return nil, err
+	}
+	if err := s.userService.Remove(app.UserID(req.UserId)); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &authpb.RemoveUserResponse{}, nil
+}
+
+func (s *server) RequestPasswordReset(_ context.Context, req *authpb.RequestPasswordResetRequest) (*authpb.RequestPasswordResetResponse, error) {
+	if err := grpcserver.ValidateUUID("user id", req.UserId); err != nil {
+		return nil, err
+	}
+	if err := s.passwordService.RequestReset(app.UserID(req.UserId), req.Email, time.Now()); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &authpb.RequestPasswordResetResponse{}, nil
+}
+
+func toStatusError(err error) error {
+	switch errors.Cause(err) {
+	case app.ErrUserNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case app.ErrLoginAlreadyExists:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case app.ErrLoginTooLong, app.ErrInvalidPassword:
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -13,21 +13,49 @@ import (
 	"bytes"
 	"encoding/json"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const PathPrefix = "/api/v1/"
 const PathPrefixInternal = "/internal/api/v1/"
 
 const (
-	loginEndpoint  = PathPrefix + "login"
-	logoutEndpoint = PathPrefix + "logout"
+	loginEndpoint            = PathPrefix + "login"
+	logoutEndpoint           = PathPrefix + "logout"
+	sessionsEndpoint         = PathPrefix + "sessions"
+	specificSessionEndpoint  = PathPrefix + "sessions/{id}"
+	loginTwoFactorEndpoint   = PathPrefix + "login/2fa"
+	twoFactorEndpoint        = PathPrefix + "2fa"
+	twoFactorEnrollEndpoint  = PathPrefix + "2fa/enroll"
+	twoFactorConfirmEndpoint = PathPrefix + "2fa/confirm"
+	twoFactorDisableEndpoint = PathPrefix + "2fa/disable"
+	passwordEndpoint         = PathPrefix + "password"
+	passwordResetEndpoint    = PathPrefix + "password/reset"
+	oidcLoginEndpoint        = PathPrefix + "oidc/{provider}/login"
+	oidcLinkEndpoint         = PathPrefix + "oidc/{provider}/link"
+	oidcCallbackEndpoint     = PathPrefix + "oidc/{provider}/callback"
+	oidcProviderEndpoint     = PathPrefix + "oidc/{provider}"
+	oidcIdentitiesEndpoint   = PathPrefix + "oidc/identities"
+	adminUserRolesEndpoint   = PathPrefix + "admin/user/{id}/roles"
+	adminSuspensionEndpoint  = PathPrefix + "admin/user/{id}/suspension"
+	apiKeysEndpoint          = PathPrefix + "apikeys"
+	specificAPIKeyEndpoint   = PathPrefix + "apikeys/{id}"
 
-	internalRegisterUserEndpoint = PathPrefixInternal + "register"
-	internalSpecificUserEndpoint = PathPrefixInternal + "user/{id}"
-	internalAuthEndpoint         = PathPrefixInternal + "auth"
+	internalRegisterUserEndpoint  = PathPrefixInternal + "register"
+	internalUserEndpoint          = PathPrefixInternal + "user"
+	internalSpecificUserEndpoint  = PathPrefixInternal + "user/{id}"
+	internalUserSessionsEndpoint  = PathPrefixInternal + "user/{id}/sessions"
+	internalUserRolesEndpoint     = PathPrefixInternal + "user/{id}/roles"
+	internalUserExportEndpoint    = PathPrefixInternal + "user/{id}/export"
+	internalPasswordResetEndpoint = PathPrefixInternal + "user/{id}/password/reset"
+	internalResetTokenEndpoint    = PathPrefixInternal + "password/reset/{id}/token"
+	internalAuthEndpoint          = PathPrefixInternal + "auth"
+	internalLoginLockEndpoint     = PathPrefixInternal + "login/lock"
 )
 
 const (
@@ -36,12 +64,48 @@ const (
 	errorCodeUsernameAlreadyExists = 2
 	errorCodeUsernameTooLong       = 3
 	errorCodeInvalidPassword       = 4
+	errorCodeLoginLocked           = 5
+	errorCodeLoginThrottled        = 6
+	errorCodeSessionNotFound       = 7
+	errorCodeTwoFactorEnabled      = 8
+	errorCodeTwoFactorNotEnabled   = 9
+	errorCodeInvalidTwoFactorCode  = 10
+	errorCodeLoginChallengeExpired = 11
+	errorCodeInvalidResetToken     = 12
+	errorCodeProviderNotFound      = 13
+	errorCodeInvalidOAuthState     = 14
+	errorCodeExternalAuthFailed    = 15
+	errorCodeIdentityLinked        = 16
+	errorCodeEmailNotVerified      = 17
+	errorCodeEmailAlreadyExists    = 18
+	errorCodeProviderAlreadyLinked = 19
+	errorCodeLastLoginMethod       = 20
+	errorCodeIdentityNotFound      = 21
+	errorCodeUserSuspended         = 22
+	errorCodeRoleNotFound          = 23
+	errorCodeReasonRequired        = 24
+	errorCodeUserNotSuspended      = 25
+	errorCodePermissionDenied      = 26
+	errorCodeAPIKeyNotFound        = 27
+	errorCodeInvalidAPIKey         = 28
+	errorCodeInvalidAPIKeyScope    = 29
+	errorCodeInvalidAPIKeyExpiry   = 30
+	errorCodeAPIKeyNameRequired    = 31
+	errorCodeAPIKeyLimitExceeded   = 32
 )
 
 const sessionCookieName = "session_id"
+const oauthStateCookieName = "oauth_state"
 const authTokenHeader = "X-Auth-Token"
+const authorizationHeader = "Authorization"
+const bearerPrefix = "Bearer "
+const realIPHeader = "X-Real-IP"
+const maxDeviceLen = 255
 
 var errUnauthorized = errors.New("not authorized")
+var errForbidden = errors.New("access forbidden")
+var errInvalidIP = errors.New("invalid ip")
+var errUnlockParamRequired = errors.New("login or ip param required")
 
 func NewEndpointLabelCollector() metrics.EndpointLabelCollector {
 	return endpointLabelCollector{}
@@ -56,30 +120,129 @@ func (e endpointLabelCollector) EndpointLabelForURI(uri string) string {
 		if r.MatchString(uri) {
 			return internalSpecificUserEndpoint
 		}
+		r, _ = regexp.Compile("^" + PathPrefixInternal + "user/[a-f0-9-]+/sessions$")
+		if r.MatchString(uri) {
+			return internalUserSessionsEndpoint
+		}
+		r, _ = regexp.Compile("^" + PathPrefixInternal + "user/[a-f0-9-]+/password/reset$")
+		if r.MatchString(uri) {
+			return internalPasswordResetEndpoint
+		}
+		r, _ = regexp.Compile("^" + PathPrefixInternal + "password/reset/[a-f0-9-]+/token$")
+		if r.MatchString(uri) {
+			return internalResetTokenEndpoint
+		}
+		r, _ = regexp.Compile("^" + PathPrefixInternal + "user/[a-f0-9-]+/roles$")
+		if r.MatchString(uri) {
+			return internalUserRolesEndpoint
+		}
+		r, _ = regexp.Compile("^" + PathPrefixInternal + "user/[a-f0-9-]+/export$")
+		if r.MatchString(uri) {
+			return internalUserExportEndpoint
+		}
+	}
+	if strings.HasPrefix(uri, PathPrefix) {
+		r, _ := regexp.Compile("^" + PathPrefix + "sessions/[a-f0-9-]+$")
+		if r.MatchString(uri) {
+			return specificSessionEndpoint
+		}
+		r, _ = regexp.Compile("^" + PathPrefix + "apikeys/[a-f0-9-]+$")
+		if r.MatchString(uri) {
+			return specificAPIKeyEndpoint
+		}
+		if uri == oidcIdentitiesEndpoint {
+			return uri
+		}
+		r, _ = regexp.Compile("^" + PathPrefix + "oidc/[^/]+/(login|link|callback)$")
+		if m := r.FindStringSubmatch(uri); m != nil {
+			return PathPrefix + "oidc/{provider}/" + m[1]
+		}
+		r, _ = regexp.Compile("^" + PathPrefix + "oidc/[^/]+$")
+		if r.MatchString(uri) {
+			return oidcProviderEndpoint
+		}
+		r, _ = regexp.Compile("^" + PathPrefix + "admin/user/[a-f0-9-]+/(roles|suspension)$")
+		if m := r.FindStringSubmatch(uri); m != nil {
+			return PathPrefix + "admin/user/{id}/" + m[1]
+		}
 	}
 	return uri
 }
 
-func NewServer(userService *app.UserService, sessionClient app.SessionClient, tokenGenerator jwtauth.TokenGenerator, logger *logrus.Logger) *Server {
+func NewServer(
+	userService *app.UserService,
+	loginService *app.LoginService,
+	twoFactorService *app.TwoFactorService,
+	passwordService *app.PasswordService,
+	externalLoginService *app.ExternalLoginService,
+	accessService *app.AccessService,
+	apiKeyService *app.APIKeyService,
+	sessionClient app.SessionClient,
+	tokenGenerator jwtauth.TokenGenerator,
+	tokenParser jwtauth.TokenParser,
+	logger *logrus.Logger,
+) *Server {
 	return &Server{
-		userService:    userService,
-		sessionClient:  sessionClient,
-		tokenGenerator: tokenGenerator,
-		logger:         logger,
+		userService:          userService,
+		loginService:         loginService,
+		twoFactorService:     twoFactorService,
+		passwordService:      passwordService,
+		externalLoginService: externalLoginService,
+		accessService:        accessService,
+		apiKeyService:        apiKeyService,
+		sessionClient:        sessionClient,
+		tokenGenerator:       tokenGenerator,
+		tokenParser:          tokenParser,
+		logger:               logger,
 	}
 }
 
 type Server struct {
-	userService    *app.UserService
-	sessionClient  app.SessionClient
-	tokenGenerator jwtauth.TokenGenerator
-	logger         *logrus.Logger
+	userService          *app.UserService
+	loginService         *app.LoginService
+	twoFactorService     *app.TwoFactorService
+	passwordService      *app.PasswordService
+	externalLoginService *app.ExternalLoginService
+	accessService        *app.AccessService
+	apiKeyService        *app.APIKeyService
+	sessionClient        app.SessionClient
+	tokenGenerator       jwtauth.TokenGenerator
+	tokenParser          jwtauth.TokenParser
+	logger               *logrus.Logger
 }
 
 func (s *Server) MakeHandler() http.Handler {
 	router := mux.NewRouter()
 	router.Methods(http.MethodPost).Path(loginEndpoint).Handler(s.makeHandlerFunc(s.loginHandler))
+	router.Methods(http.MethodPost).Path(loginTwoFactorEndpoint).Handler(s.makeHandlerFunc(s.loginTwoFactorHandler))
 	router.Methods(http.MethodPost).Path(logoutEndpoint).Handler(s.makeHandlerFunc(s.logoutHandler))
+	router.Methods(http.MethodGet).Path(twoFactorEndpoint).Handler(s.makeHandlerFunc(s.twoFactorStatusHandler))
+	router.Methods(http.MethodPost).Path(twoFactorEnrollEndpoint).Handler(s.makeHandlerFunc(s.twoFactorEnrollHandler))
+	router.Methods(http.MethodPost).Path(twoFactorConfirmEndpoint).Handler(s.makeHandlerFunc(s.twoFactorConfirmHandler))
+	router.Methods(http.MethodPost).Path(twoFactorDisableEndpoint).Handler(s.makeHandlerFunc(s.twoFactorDisableHandler))
+	router.Methods(http.MethodPost).Path(passwordEndpoint).Handler(s.makeHandlerFunc(s.changePasswordHandler))
+	router.Methods(http.MethodPost).Path(passwordResetEndpoint).Handler(s.makeHandlerFunc(s.resetPasswordHandler))
+	router.Methods(http.MethodGet).Path(oidcIdentitiesEndpoint).Handler(s.makeHandlerFunc(s.listExternalIdentitiesHandler))
+	router.Methods(http.MethodGet).Path(oidcLoginEndpoint).Handler(s.makeHandlerFunc(s.externalLoginHandler))
+	router.Methods(http.MethodGet).Path(oidcLinkEndpoint).Handler(s.makeHandlerFunc(s.linkExternalIdentityHandler))
+	router.Methods(http.MethodGet).Path(oidcCallbackEndpoint).Handler(s.makeHandlerFunc(s.externalLoginCallbackHandler))
+	router.Methods(http.MethodDelete).Path(oidcProviderEndpoint).Handler(s.makeHandlerFunc(s.unlinkExternalIdentityHandler))
+	router.Methods(http.MethodGet).Path(sessionsEndpoint).Handler(s.makeHandlerFunc(s.listSessionsHandler))
+	router.Methods(http.MethodDelete).Path(sessionsEndpoint).Handler(s.makeHandlerFunc(s.revokeOtherSessionsHandler))
+	router.Methods(http.MethodDelete).Path(specificSessionEndpoint).Handler(s.makeHandlerFunc(s.revokeSessionHandler))
+	router.Methods(http.MethodGet).Path(apiKeysEndpoint).Handler(s.makeHandlerFunc(s.listAPIKeysHandler))
+	router.Methods(http.MethodPost).Path(apiKeysEndpoint).Handler(s.makeHandlerFunc(s.createAPIKeyHandler))
+	router.Methods(http.MethodDelete).Path(specificAPIKeyEndpoint).Handler(s.makeHandlerFunc(s.revokeAPIKeyHandler))
+	router.Methods(http.MethodGet).Path(adminUserRolesEndpoint).Handler(s.makeHandlerFunc(
+		jwtauth.RequirePermission(s.extractAuthorizationData, jwtauth.PermissionManageRoles, s.getUserRolesHandler)))
+	router.Methods(http.MethodPut).Path(adminUserRolesEndpoint).Handler(s.makeHandlerFunc(
+		jwtauth.RequirePermission(s.extractAuthorizationData, jwtauth.PermissionManageRoles, s.setUserRolesHandler)))
+	router.Methods(http.MethodGet).Path(adminSuspensionEndpoint).Handler(s.makeHandlerFunc(
+		jwtauth.RequirePermission(s.extractAuthorizationData, jwtauth.PermissionSuspendUser, s.getSuspensionHandler)))
+	router.Methods(http.MethodPost).Path(adminSuspensionEndpoint).Handler(s.makeHandlerFunc(
+		jwtauth.RequirePermission(s.extractAuthorizationData, jwtauth.PermissionSuspendUser, s.suspendUserHandler)))
+	router.Methods(http.MethodDelete).Path(adminSuspensionEndpoint).Handler(s.makeHandlerFunc(
+		jwtauth.RequirePermission(s.extractAuthorizationData, jwtauth.PermissionSuspendUser, s.unsuspendUserHandler)))
 	return router
 }
 
@@ -87,7 +250,14 @@ func (s *Server) MakeInternalHandler() http.Handler {
 	router := mux.NewRouter()
 	router.Path(internalAuthEndpoint).Handler(s.makeHandlerFunc(s.authHandler))
 	router.Methods(http.MethodPost).Path(internalRegisterUserEndpoint).Handler(s.makeHandlerFunc(s.registerUserHandler))
+	router.Methods(http.MethodGet).Path(internalUserEndpoint).Handler(s.makeHandlerFunc(s.findUserByLoginHandler))
 	router.Methods(http.MethodDelete).Path(internalSpecificUserEndpoint).Handler(s.makeHandlerFunc(s.removeUserHandler))
+	router.Methods(http.MethodDelete).Path(internalUserSessionsEndpoint).Handler(s.makeHandlerFunc(s.revokeUserSessionsHandler))
+	router.Methods(http.MethodPost).Path(internalPasswordResetEndpoint).Handler(s.makeHandlerFunc(s.requestPasswordResetHandler))
+	router.Methods(http.MethodPost).Path(internalResetTokenEndpoint).Handler(s.makeHandlerFunc(s.issueResetTokenHandler))
+	router.Methods(http.MethodDelete).Path(internalLoginLockEndpoint).Handler(s.makeHandlerFunc(s.unlockLoginHandler))
+	router.Methods(http.MethodPut).Path(internalUserRolesEndpoint).Handler(s.makeHandlerFunc(s.setUserRolesInternalHandler))
+	router.Methods(http.MethodGet).Path(internalUserExportEndpoint).Handler(s.makeHandlerFunc(s.exportUserDataHandler))
 	return router
 }
 
@@ -114,7 +284,12 @@ func (s *Server) makeHandlerFunc(fn func(http.ResponseWriter, *http.Request) err
 				fields["body"] = string(bytesBody)
 			}
 		}
-		headersBytes, _ := json.Marshal(r.Header)
+		headers := r.Header.Clone()
+		if headers.Get(authorizationHeader) != "" {
+			// api key must not get to logs
+			headers.Set(authorizationHeader, "<hidden>")
+		}
+		headersBytes, _ := json.Marshal(headers)
 		fields["headers"] = string(headersBytes)
 
 		err := fn(w, r)
@@ -149,6 +324,16 @@ func (s *Server) registerUserHandler(w http.ResponseWriter, r *http.Request) err
 	return nil
 }
 
+// findUserByLoginHandler - used by user service to find credentials left by interrupted registration
+func (s *Server) findUserByLoginHandler(w http.ResponseWriter, r *http.Request) error {
+	user, err := s.userService.FindUserByLogin(app.Login(r.URL.Query().Get("login")))
+	if err != nil {
+		return err
+	}
+	writeResponse(w, createdUserInfo{UserID: string(user.UserID)})
+	return nil
+}
+
 func (s *Server) removeUserHandler(w http.ResponseWriter, r *http.Request) error {
 	id, err := getUserIDFromRequest(r)
 	if err != nil {
@@ -162,6 +347,67 @@ func (s *Server) removeUserHandler(w http.ResponseWriter, r *http.Request) error
 	return nil
 }
 
+// exportUserDataHandler - secrets (password hash, totp secret, api key hashes) aren't exported
+func (s *Server) exportUserDataHandler(w http.ResponseWriter, r *http.Request) error {
+	id, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	user, err := s.userService.FindUserByID(id)
+	if err != nil {
+		return err
+	}
+	roles, err := s.accessService.FindRoles(id)
+	if err != nil {
+		return err
+	}
+	twoFactorEnabled, err := s.twoFactorService.IsEnabled(id)
+	if err != nil {
+		return err
+	}
+	identities, err := s.externalLoginService.FindIdentities(id)
+	if err != nil {
+		return err
+	}
+	keys, err := s.apiKeyService.FindAll(id)
+	if err != nil {
+		return err
+	}
+	sessions, err := s.sessionClient.FindAllByUserID(id)
+	if err != nil {
+		return err
+	}
+
+	info := userDataInfo{
+		Login:            string(user.Login),
+		Roles:            make([]string, 0, len(roles)),
+		TwoFactorEnabled: twoFactorEnabled,
+		Identities:       make([]externalIdentityInfo, 0, len(identities)),
+		APIKeys:          make([]apiKeyInfo, 0, len(keys)),
+		Sessions:         make([]sessionInfo, 0, len(sessions)),
+	}
+	for _, role := range roles {
+		info.Roles = append(info.Roles, string(role))
+	}
+	for _, identity := range identities {
+		info.Identities = append(info.Identities, externalIdentityInfo{Provider: string(identity.Provider), Subject: identity.Subject})
+	}
+	for i := range keys {
+		info.APIKeys = append(info.APIKeys, toAPIKeyInfo(&keys[i]))
+	}
+	for _, session := range sessions {
+		info.Sessions = append(info.Sessions, sessionInfo{
+			ID:         string(session.ID),
+			Device:     session.Device,
+			IP:         session.IP,
+			CreatedAt:  session.CreationTime,
+			LastSeenAt: session.LastSeenTime,
+		})
+	}
+	writeResponse(w, info)
+	return nil
+}
+
 func (s *Server) loginHandler(w http.ResponseWriter, r *http.Request) error {
 	var info userAuthData
 	bytesBody, err := ioutil.ReadAll(r.Body)
@@ -173,15 +419,65 @@ func (s *Server) loginHandler(w http.ResponseWriter, r *http.Request) error {
 		return errors.WithStack(err)
 	}
 
-	user, err := s.userService.FindUserByLoginAndPassword(info.Login, info.Password)
+	now := time.Now()
+	user, err := s.loginService.Login(info.Login, info.Password, getClientIP(r), now)
 	if err != nil {
 		return err
 	}
+	return s.completeLogin(w, r, user.UserID, now)
+}
+
+// completeLogin - session is started, if user isn't suspended and second factor isn't required
+func (s *Server) completeLogin(w http.ResponseWriter, r *http.Request, userID app.UserID, now time.Time) error {
+	if err := s.accessService.CheckNotSuspended(userID); err != nil {
+		return err
+	}
+	twoFactorEnabled, err := s.twoFactorService.IsEnabled(userID)
+	if err != nil {
+		return err
+	}
+	if twoFactorEnabled {
+		challengeID, err2 := s.twoFactorService.CreateLoginChallenge(userID)
+		if err2 != nil {
+			return err2
+		}
+		writeResponse(w, loginChallengeInfo{ChallengeID: string(challengeID)})
+		return nil
+	}
+	return s.startSession(w, r, userID, now)
+}
+
+func (s *Server) loginTwoFactorHandler(w http.ResponseWriter, r *http.Request) error {
+	var info loginTwoFactorData
+	if err := readRequestBody(r, &info); err != nil {
+		return err
+	}
+	if err := uuid.ValidateUUID(info.ChallengeID); err != nil {
+		return errors.WithStack(app.ErrLoginChallengeNotFound)
+	}
+
+	now := time.Now()
+	userID, err := s.twoFactorService.VerifyLoginChallenge(app.LoginChallengeID(info.ChallengeID), info.Code, now)
+	if err != nil {
+		return err
+	}
+	return s.startSession(w, r, userID, now)
+}
+
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, userID app.UserID, now time.Time) error {
+	device := r.UserAgent()
+	if len(device) > maxDeviceLen {
+		device = device[:maxDeviceLen]
+	}
 	session := app.Session{
-		ID:     app.SessionID(uuid.GenerateNew()),
-		UserID: user.UserID,
+		ID:           app.SessionID(uuid.GenerateNew()),
+		UserID:       userID,
+		Device:       device,
+		IP:           getClientIP(r),
+		CreationTime: now,
+		LastSeenTime: now,
 	}
-	err = s.sessionClient.Store(session)
+	err := s.sessionClient.Store(session)
 	if err != nil {
 		return err
 	}
@@ -191,6 +487,228 @@ func (s *Server) loginHandler(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+func (s *Server) twoFactorStatusHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	enabled, err := s.twoFactorService.IsEnabled(session.UserID)
+	if err != nil {
+		return err
+	}
+	writeResponse(w, twoFactorStatusInfo{Enabled: enabled})
+	return nil
+}
+
+func (s *Server) twoFactorEnrollHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	enrollment, err := s.twoFactorService.StartEnrollment(session.UserID)
+	if err != nil {
+		return err
+	}
+	writeResponse(w, twoFactorEnrollmentInfo{Secret: enrollment.Secret, URI: enrollment.URI})
+	return nil
+}
+
+func (s *Server) twoFactorConfirmHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	var info twoFactorCodeData
+	if err = readRequestBody(r, &info); err != nil {
+		return err
+	}
+	recoveryCodes, err := s.twoFactorService.ConfirmEnrollment(session.UserID, info.Code, time.Now())
+	if err != nil {
+		return err
+	}
+	writeResponse(w, recoveryCodesInfo{RecoveryCodes: recoveryCodes})
+	return nil
+}
+
+func (s *Server) twoFactorDisableHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	var info twoFactorCodeData
+	if err = readRequestBody(r, &info); err != nil {
+		return err
+	}
+	err = s.twoFactorService.Disable(session.UserID, info.Code, time.Now())
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) changePasswordHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	var info changePasswordData
+	if err = readRequestBody(r, &info); err != nil {
+		return err
+	}
+	err = s.passwordService.ChangePassword(session.UserID, session.ID, info.CurrentPassword, info.NewPassword)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) resetPasswordHandler(w http.ResponseWriter, r *http.Request) error {
+	var info resetPasswordData
+	if err := readRequestBody(r, &info); err != nil {
+		return err
+	}
+	err := s.passwordService.Reset(info.Token, info.Password, time.Now())
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) requestPasswordResetHandler(w http.ResponseWriter, r *http.Request) error {
+	id, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	var info passwordResetRequestData
+	if err = readRequestBody(r, &info); err != nil {
+		return err
+	}
+	err = s.passwordService.RequestReset(id, info.Email, time.Now())
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) issueResetTokenHandler(w http.ResponseWriter, r *http.Request) error {
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	token, err := s.passwordService.IssueResetToken(app.PasswordResetTokenID(id), time.Now())
+	if err != nil {
+		return err
+	}
+	writeResponse(w, resetTokenInfo{Token: token})
+	return nil
+}
+
+func (s *Server) externalLoginHandler(w http.ResponseWriter, r *http.Request) error {
+	authURL, state, err := s.externalLoginService.StartLogin(getProviderFromRequest(r), nil)
+	if err != nil {
+		return err
+	}
+	setOAuthStateCookie(w, &state)
+	http.Redirect(w, r, authURL, http.StatusFound)
+	return nil
+}
+
+func (s *Server) linkExternalIdentityHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	authURL, state, err := s.externalLoginService.StartLogin(getProviderFromRequest(r), &session.UserID)
+	if err != nil {
+		return err
+	}
+	setOAuthStateCookie(w, &state)
+	http.Redirect(w, r, authURL, http.StatusFound)
+	return nil
+}
+
+func (s *Server) externalLoginCallbackHandler(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	browserState := ""
+	if c, err := r.Cookie(oauthStateCookieName); err == nil {
+		browserState = c.Value
+	}
+	// state is single use
+	setOAuthStateCookie(w, nil)
+	// user denied access or identity provider failed to authenticate user
+	if errCode := query.Get("error"); errCode != "" {
+		return errors.Wrapf(app.ErrExternalAuthFailed, "%s %s", errCode, query.Get("error_description"))
+	}
+	result, err := s.externalLoginService.CompleteLogin(getProviderFromRequest(r), query.Get("state"), browserState, query.Get("code"))
+	if err != nil {
+		return err
+	}
+	if result.Linked {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	return s.completeLogin(w, r, result.UserID, time.Now())
+}
+
+func (s *Server) listExternalIdentitiesHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	identities, err := s.externalLoginService.FindIdentities(session.UserID)
+	if err != nil {
+		return err
+	}
+	infos := make([]externalIdentityInfo, 0, len(identities))
+	for _, identity := range identities {
+		infos = append(infos, externalIdentityInfo{Provider: string(identity.Provider), Subject: identity.Subject})
+	}
+	writeResponse(w, infos)
+	return nil
+}
+
+func (s *Server) unlinkExternalIdentityHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	err = s.externalLoginService.Unlink(session.UserID, getProviderFromRequest(r))
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) unlockLoginHandler(w http.ResponseWriter, r *http.Request) error {
+	var login *app.Login
+	var ip *string
+	if value := r.URL.Query().Get("login"); value != "" {
+		l := app.Login(value)
+		login = &l
+	}
+	if value := r.URL.Query().Get("ip"); value != "" {
+		if net.ParseIP(value) == nil {
+			return errors.WithStack(errInvalidIP)
+		}
+		ip = &value
+	}
+	if login == nil && ip == nil {
+		return errors.WithStack(errUnlockParamRequired)
+	}
+
+	err := s.loginService.Unlock(login, ip)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
 func (s *Server) logoutHandler(w http.ResponseWriter, r *http.Request) error {
 	if sessionID, err := getSessionIDFromRequest(r); err == nil {
 		err = s.sessionClient.Remove(sessionID)
@@ -204,25 +722,163 @@ func (s *Server) logoutHandler(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
-func (s *Server) authHandler(w http.ResponseWriter, r *http.Request) error {
+func (s *Server) listSessionsHandler(w http.ResponseWriter, r *http.Request) error {
+	current, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	sessions, err := s.sessionClient.FindAllByUserID(current.UserID)
+	if err != nil {
+		return err
+	}
+
+	infos := make([]sessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, sessionInfo{
+			ID:         string(session.ID),
+			Device:     session.Device,
+			IP:         session.IP,
+			CreatedAt:  session.CreationTime,
+			LastSeenAt: session.LastSeenTime,
+			Current:    session.ID == current.ID,
+		})
+	}
+	writeResponse(w, infos)
+	return nil
+}
+
+func (s *Server) revokeSessionHandler(w http.ResponseWriter, r *http.Request) error {
+	current, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	session, err := s.sessionClient.FindByID(app.SessionID(id))
+	if err != nil {
+		return err
+	}
+	if session.UserID != current.UserID {
+		return errors.WithStack(app.ErrSessionNotFound)
+	}
+
+	err = s.sessionClient.Remove(session.ID)
+	if err != nil {
+		return err
+	}
+	if session.ID == current.ID {
+		setSessionCookie(w, nil)
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) revokeOtherSessionsHandler(w http.ResponseWriter, r *http.Request) error {
+	current, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	sessions, err := s.sessionClient.FindAllByUserID(current.UserID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == current.ID {
+			continue
+		}
+		if err = s.sessionClient.Remove(session.ID); err != nil {
+			return err
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) revokeUserSessionsHandler(w http.ResponseWriter, r *http.Request) error {
+	id, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	err = s.sessionClient.RemoveAllByUserID(id)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) findCurrentSession(r *http.Request) (*app.Session, error) {
 	sessionID, err := getSessionIDFromRequest(r)
 	if err != nil {
-		return errors.WithStack(errUnauthorized)
+		return nil, errors.WithStack(errUnauthorized)
 	}
 	session, err := s.sessionClient.FindByID(sessionID)
 	if err != nil {
 		if errors.Cause(err) == app.ErrSessionNotFound {
-			return errors.WithStack(errUnauthorized)
+			return nil, errors.WithStack(errUnauthorized)
 		}
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *Server) authHandler(w http.ResponseWriter, r *http.Request) error {
+	if apiKey, ok := getAPIKeyFromRequest(r); ok {
+		return s.authAPIKeyHandler(w, apiKey)
+	}
+	session, err := s.findCurrentSession(r)
+	if err != nil {
 		return err
 	}
 	user, err := s.userService.FindUserByID(session.UserID)
 	if err != nil {
 		return err
 	}
-	_ = s.sessionClient.UpdateSessionTTL(sessionID)
+	access, err := s.accessService.FindAccess(user.UserID)
+	if err != nil {
+		return err
+	}
+	_ = s.sessionClient.UpdateSessionTTL(session.ID)
+
+	roles := make([]string, 0, len(access.Roles))
+	for _, role := range access.Roles {
+		roles = append(roles, string(role))
+	}
+	permissions := make([]jwtauth.Permission, 0, len(access.Permissions))
+	for _, permission := range access.Permissions {
+		permissions = append(permissions, jwtauth.Permission(permission))
+	}
+	token, err := s.tokenGenerator.GenerateToken(string(user.UserID), string(user.Login), roles, permissions)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set(authTokenHeader, token)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// authAPIKeyHandler - token issued for api key has scopes of key instead of user roles
+func (s *Server) authAPIKeyHandler(w http.ResponseWriter, rawKey string) error {
+	key, err := s.apiKeyService.Authenticate(rawKey, time.Now())
+	if err != nil {
+		return err
+	}
+	user, err := s.userService.FindUserByID(key.UserID)
+	if err != nil {
+		return err
+	}
+	if err = s.accessService.CheckNotSuspended(user.UserID); err != nil {
+		return err
+	}
 
-	token, err := s.tokenGenerator.GenerateToken(string(user.UserID), string(user.Login))
+	scopes := make([]jwtauth.Scope, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, jwtauth.Scope(scope))
+	}
+	token, err := s.tokenGenerator.GenerateAPIKeyToken(string(user.UserID), string(user.Login), string(key.ID), scopes)
 	if err != nil {
 		return err
 	}
@@ -232,7 +888,188 @@ func (s *Server) authHandler(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+func (s *Server) listAPIKeysHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	keys, err := s.apiKeyService.FindAll(session.UserID)
+	if err != nil {
+		return err
+	}
+	infos := make([]apiKeyInfo, 0, len(keys))
+	for i := range keys {
+		infos = append(infos, toAPIKeyInfo(&keys[i]))
+	}
+	writeResponse(w, infos)
+	return nil
+}
+
+func (s *Server) createAPIKeyHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	var info apiKeyData
+	if err = readRequestBody(r, &info); err != nil {
+		return err
+	}
+	scopes := make([]app.APIKeyScope, 0, len(info.Scopes))
+	for _, scope := range info.Scopes {
+		scopes = append(scopes, app.APIKeyScope(scope))
+	}
+	key, rawKey, err := s.apiKeyService.Create(session.UserID, info.Name, scopes, info.ExpiresAt, time.Now())
+	if err != nil {
+		return err
+	}
+	writeResponse(w, createdAPIKeyInfo{apiKeyInfo: toAPIKeyInfo(key), Key: rawKey})
+	return nil
+}
+
+func (s *Server) revokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := s.findCurrentSession(r)
+	if err != nil {
+		return err
+	}
+	id, err := getIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	err = s.apiKeyService.Revoke(session.UserID, app.APIKeyID(id))
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) getUserRolesHandler(w http.ResponseWriter, r *http.Request, _ jwtauth.TokenData) error {
+	id, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	roles, err := s.accessService.FindRoles(id)
+	if err != nil {
+		return err
+	}
+	info := userRolesData{Roles: make([]string, 0, len(roles))}
+	for _, role := range roles {
+		info.Roles = append(info.Roles, string(role))
+	}
+	writeResponse(w, info)
+	return nil
+}
+
+func (s *Server) setUserRolesHandler(w http.ResponseWriter, r *http.Request, _ jwtauth.TokenData) error {
+	return s.setUserRolesInternalHandler(w, r)
+}
+
+// setUserRolesInternalHandler - allows operator to assign first administrator
+func (s *Server) setUserRolesInternalHandler(w http.ResponseWriter, r *http.Request) error {
+	id, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	var info userRolesData
+	if err = readRequestBody(r, &info); err != nil {
+		return err
+	}
+	roles := make([]app.Role, 0, len(info.Roles))
+	for _, role := range info.Roles {
+		roles = append(roles, app.Role(role))
+	}
+	err = s.accessService.SetRoles(id, roles)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) getSuspensionHandler(w http.ResponseWriter, r *http.Request, _ jwtauth.TokenData) error {
+	id, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	suspension, err := s.accessService.FindSuspension(id)
+	if err != nil {
+		return err
+	}
+	if suspension == nil {
+		return errors.WithStack(app.ErrUserNotSuspended)
+	}
+	writeResponse(w, suspensionInfo{
+		Reason:      suspension.Reason,
+		SuspendedBy: string(suspension.SuspendedBy),
+		CreatedAt:   suspension.CreationTime,
+	})
+	return nil
+}
+
+func (s *Server) suspendUserHandler(w http.ResponseWriter, r *http.Request, tokenData jwtauth.TokenData) error {
+	id, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	var info reasonData
+	if err = readRequestBody(r, &info); err != nil {
+		return err
+	}
+	err = s.accessService.Suspend(id, app.UserID(tokenData.UserID()), info.Reason, time.Now())
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) unsuspendUserHandler(w http.ResponseWriter, r *http.Request, _ jwtauth.TokenData) error {
+	id, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	err = s.accessService.Unsuspend(id)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// extractAuthorizationData - admin endpoints are called through ingress, which puts auth token to request
+func (s *Server) extractAuthorizationData(r *http.Request) (jwtauth.TokenData, error) {
+	token := r.Header.Get(authTokenHeader)
+	if token == "" {
+		return nil, errors.WithStack(errForbidden)
+	}
+	tokenData, err := s.tokenParser.ParseToken(token)
+	if err != nil {
+		return nil, errors.Wrap(errForbidden, err.Error())
+	}
+	if err = uuid.ValidateUUID(tokenData.UserID()); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err = jwtauth.CheckScope(tokenData, r, jwtauth.NoScope, jwtauth.NoScope); err != nil {
+		return nil, errors.Wrap(errForbidden, err.Error())
+	}
+	return tokenData, nil
+}
+
+func readRequestBody(r *http.Request, v interface{}) error {
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_ = r.Body.Close()
+	return errors.WithStack(json.Unmarshal(bytesBody, v))
+}
+
 func getUserIDFromRequest(r *http.Request) (app.UserID, error) {
+	id, err := getIDFromRequest(r)
+	return app.UserID(id), err
+}
+
+func getIDFromRequest(r *http.Request) (string, error) {
 	vars := mux.Vars(r)
 	id, ok := vars["id"]
 	if !ok {
@@ -241,7 +1078,46 @@ func getUserIDFromRequest(r *http.Request) (app.UserID, error) {
 	if err := uuid.ValidateUUID(id); err != nil {
 		return "", errors.WithStack(err)
 	}
-	return app.UserID(id), nil
+	return id, nil
+}
+
+func getProviderFromRequest(r *http.Request) app.IdentityProviderName {
+	return app.IdentityProviderName(mux.Vars(r)["provider"])
+}
+
+// getClientIP - ingress puts client address to X-Real-IP header
+func getClientIP(r *http.Request) string {
+	if ip := r.Header.Get(realIPHeader); net.ParseIP(ip) != nil {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+func getAPIKeyFromRequest(r *http.Request) (string, bool) {
+	header := r.Header.Get(authorizationHeader)
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, bearerPrefix)), true
+}
+
+func toAPIKeyInfo(key *app.APIKey) apiKeyInfo {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return apiKeyInfo{
+		ID:        string(key.ID),
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    scopes,
+		CreatedAt: key.CreationTime,
+		ExpiresAt: key.ExpirationTime,
+	}
 }
 
 func getSessionIDFromRequest(r *http.Request) (app.SessionID, error) {
@@ -271,6 +1147,27 @@ func setSessionCookie(w http.ResponseWriter, sessionID *app.SessionID) {
 	http.SetCookie(w, c)
 }
 
+// setOAuthStateCookie - binds external login callback to browser which started login,
+// Lax same site mode allows cookie in top-level redirect from identity provider
+func setOAuthStateCookie(w http.ResponseWriter, state *string) {
+	c := &http.Cookie{
+		Name:     oauthStateCookieName,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if state != nil {
+		c.Value = *state
+		c.MaxAge = int(app.OAuthStateTTL.Seconds())
+	} else {
+		// delete cookie
+		c.MaxAge = -1
+	}
+
+	http.SetCookie(w, c)
+}
+
 func writeResponse(w http.ResponseWriter, response interface{}) {
 	js, err := json.Marshal(response)
 	if err != nil {
@@ -297,8 +1194,98 @@ func writeErrorResponse(w http.ResponseWriter, err error) {
 	case app.ErrInvalidPassword:
 		info.Code = errorCodeInvalidPassword
 		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrLoginLocked:
+		info.Code = errorCodeLoginLocked
+		setRetryAfterHeader(w, err)
+		w.WriteHeader(http.StatusTooManyRequests)
+	case app.ErrLoginThrottled:
+		info.Code = errorCodeLoginThrottled
+		setRetryAfterHeader(w, err)
+		w.WriteHeader(http.StatusTooManyRequests)
+	case app.ErrSessionNotFound:
+		info.Code = errorCodeSessionNotFound
+		w.WriteHeader(http.StatusNotFound)
+	case app.ErrTwoFactorAlreadyEnabled:
+		info.Code = errorCodeTwoFactorEnabled
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrTwoFactorNotEnabled:
+		info.Code = errorCodeTwoFactorNotEnabled
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrInvalidTwoFactorCode:
+		info.Code = errorCodeInvalidTwoFactorCode
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrLoginChallengeNotFound:
+		info.Code = errorCodeLoginChallengeExpired
+		w.WriteHeader(http.StatusUnauthorized)
+	case app.ErrPasswordResetTokenNotFound:
+		info.Code = errorCodeInvalidResetToken
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrIdentityProviderNotFound:
+		info.Code = errorCodeProviderNotFound
+		w.WriteHeader(http.StatusNotFound)
+	case app.ErrOAuthStateNotFound:
+		info.Code = errorCodeInvalidOAuthState
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrExternalAuthFailed:
+		info.Code = errorCodeExternalAuthFailed
+		w.WriteHeader(http.StatusUnauthorized)
+	case app.ErrExternalIdentityLinked:
+		info.Code = errorCodeIdentityLinked
+		w.WriteHeader(http.StatusConflict)
+	case app.ErrExternalEmailNotVerified:
+		info.Code = errorCodeEmailNotVerified
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrExternalEmailAlreadyExists:
+		info.Code = errorCodeEmailAlreadyExists
+		w.WriteHeader(http.StatusConflict)
+	case app.ErrIdentityProviderAlreadyLinked:
+		info.Code = errorCodeProviderAlreadyLinked
+		w.WriteHeader(http.StatusConflict)
+	case app.ErrLastLoginMethod:
+		info.Code = errorCodeLastLoginMethod
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrExternalIdentityNotFound:
+		info.Code = errorCodeIdentityNotFound
+		w.WriteHeader(http.StatusNotFound)
+	case app.ErrUserSuspended:
+		info.Code = errorCodeUserSuspended
+		w.WriteHeader(http.StatusForbidden)
+	case app.ErrRoleNotFound:
+		info.Code = errorCodeRoleNotFound
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrReasonRequired:
+		info.Code = errorCodeReasonRequired
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrUserNotSuspended:
+		info.Code = errorCodeUserNotSuspended
+		w.WriteHeader(http.StatusNotFound)
+	case jwtauth.ErrPermissionDenied:
+		info.Code = errorCodePermissionDenied
+		w.WriteHeader(http.StatusForbidden)
+	case app.ErrAPIKeyNotFound:
+		info.Code = errorCodeAPIKeyNotFound
+		w.WriteHeader(http.StatusNotFound)
+	case app.ErrInvalidAPIKey:
+		info.Code = errorCodeInvalidAPIKey
+		w.WriteHeader(http.StatusUnauthorized)
+	case app.ErrInvalidAPIKeyScope:
+		info.Code = errorCodeInvalidAPIKeyScope
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrInvalidAPIKeyExpiration:
+		info.Code = errorCodeInvalidAPIKeyExpiry
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrAPIKeyNameRequired:
+		info.Code = errorCodeAPIKeyNameRequired
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrAPIKeyLimitExceeded:
+		info.Code = errorCodeAPIKeyLimitExceeded
+		w.WriteHeader(http.StatusConflict)
+	case errInvalidIP, errUnlockParamRequired:
+		w.WriteHeader(http.StatusBadRequest)
 	case errUnauthorized:
 		w.WriteHeader(http.StatusUnauthorized)
+	case errForbidden:
+		w.WriteHeader(http.StatusForbidden)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -306,11 +1293,119 @@ func writeErrorResponse(w http.ResponseWriter, err error) {
 	_, _ = w.Write(js)
 }
 
+func setRetryAfterHeader(w http.ResponseWriter, err error) {
+	if retryAfter, ok := app.RetryAfter(err); ok {
+		seconds := int64((retryAfter + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+}
+
 type userAuthData struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
 }
 
+type loginChallengeInfo struct {
+	ChallengeID string `json:"challengeId"`
+}
+
+type loginTwoFactorData struct {
+	ChallengeID string `json:"challengeId"`
+	Code        string `json:"code"`
+}
+
+type twoFactorCodeData struct {
+	Code string `json:"code"`
+}
+
+type twoFactorStatusInfo struct {
+	Enabled bool `json:"enabled"`
+}
+
+type twoFactorEnrollmentInfo struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauthUri"`
+}
+
+type recoveryCodesInfo struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type changePasswordData struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type resetPasswordData struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type passwordResetRequestData struct {
+	Email string `json:"email"`
+}
+
+type resetTokenInfo struct {
+	Token string `json:"token"`
+}
+
+type externalIdentityInfo struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+}
+
+type userRolesData struct {
+	Roles []string `json:"roles"`
+}
+
+type reasonData struct {
+	Reason string `json:"reason"`
+}
+
+type suspensionInfo struct {
+	Reason      string    `json:"reason"`
+	SuspendedBy string    `json:"suspendedBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type userDataInfo struct {
+	Login            string                 `json:"login"`
+	Roles            []string               `json:"roles"`
+	TwoFactorEnabled bool                   `json:"twoFactorEnabled"`
+	Identities       []externalIdentityInfo `json:"identities"`
+	APIKeys          []apiKeyInfo           `json:"apiKeys"`
+	Sessions         []sessionInfo          `json:"sessions"`
+}
+
+type sessionInfo struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	Current    bool      `json:"current"`
+}
+
+type apiKeyData struct {
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type apiKeyInfo struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type createdAPIKeyInfo struct {
+	apiKeyInfo
+	Key string `json:"key"`
+}
+
 type createdUserInfo struct {
 	UserID string `json:"id"`
 }
@@ -0,0 +1,69 @@
+package userservice
+
+import (
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/common/infrastructure/httpclient"
+
+	"github.com/pkg/errors"
+
+	"encoding/json"
+	"net/http"
+)
+
+const registerExternalUserURL = "/internal/api/v1/register/external"
+
+// errorCodeEmailAlreadyExists - error code of user service
+const errorCodeEmailAlreadyExists = 4
+
+func NewClient(client http.Client, serviceHost string) app.UserServiceClient {
+	return &userServiceClient{httpClient: httpclient.NewClient(client, serviceHost)}
+}
+
+type userServiceClient struct {
+	httpClient httpclient.Client
+}
+
+func (c *userServiceClient) RegisterExternalUser(login app.Login, firstName, lastName, email string) (app.UserID, error) {
+	request := registerExternalUserRequest{
+		Login:     string(login),
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     email,
+	}
+	response := registerUserResponse{}
+	err := c.httpClient.MakeJSONRequest(request, &response, http.MethodPost, registerExternalUserURL, nil)
+	if err != nil {
+		if e, ok := errors.Cause(err).(*httpclient.HTTPError); ok {
+			errInfo := errorInfo{}
+			if json.Unmarshal([]byte(e.Body), &errInfo) == nil {
+				if errInfo.Code == errorCodeEmailAlreadyExists {
+					return "", errors.WithStack(app.ErrExternalEmailAlreadyExists)
+				}
+				return "", errors.WithStack(errors.New(errInfo.Message))
+			}
+		}
+		return "", errors.WithStack(err)
+	}
+
+	if err = uuid.ValidateUUID(response.ID); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return app.UserID(response.ID), nil
+}
+
+type registerExternalUserRequest struct {
+	Login     string `json:"login"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email"`
+}
+
+type registerUserResponse struct {
+	ID string `json:"id"`
+}
+
+type errorInfo struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,46 @@
+package userservice
+
+import (
+	"arch-homework/api/userpb"
+	"arch-homework/pkg/auth/app"
+	"arch-homework/pkg/common/app/uuid"
+
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func NewGRPCClient(conn *grpc.ClientConn) app.UserServiceClient {
+	return &userServiceGRPCClient{client: userpb.NewUserServiceClient(conn)}
+}
+
+type userServiceGRPCClient struct {
+	client userpb.UserServiceClient
+}
+
+func (c *userServiceGRPCClient) RegisterExternalUser(login app.Login, firstName, lastName, email string) (app.UserID, error) {
+	// registration is not idempotent, so it is not retried
+	response, err := c.client.RegisterExternalUser(context.Background(), &userpb.RegisterExternalUserRequest{
+		Login:     string(login),
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     email,
+	})
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			if s.Code() == codes.AlreadyExists {
+				return "", errors.WithStack(app.ErrExternalEmailAlreadyExists)
+			}
+			return "", errors.WithStack(errors.New(s.Message()))
+		}
+		return "", errors.WithStack(err)
+	}
+
+	if err = uuid.ValidateUUID(response.UserId); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return app.UserID(response.UserId), nil
+}
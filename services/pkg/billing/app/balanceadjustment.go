@@ -0,0 +1,30 @@
+package app
+
+import (
+	"github.com/pkg/errors"
+
+	"time"
+)
+
+var ErrReasonRequired = errors.New("reason required")
+
+type AdjustmentDirection string
+
+const (
+	AdjustmentDirectionCredit AdjustmentDirection = "credit"
+	AdjustmentDirectionDebit  AdjustmentDirection = "debit"
+)
+
+// BalanceAdjustment - manual correction of user balance by administrator, reason and actor are kept for audit
+type BalanceAdjustment struct {
+	UserID       UserID
+	Direction    AdjustmentDirection
+	Amount       Amount
+	Reason       string
+	ActorID      UserID
+	CreationTime time.Time
+}
+
+type BalanceAdjustmentRepository interface {
+	Store(adjustment *BalanceAdjustment) error
+}
@@ -1,8 +1,12 @@
 package app
 
-func NewBillingQueryService(repoRead UserAccountEventRepository) BillingQueryService {
+import "time"
+
+func NewBillingQueryService(repoRead UserAccountEventRepository, riskRepoRead RiskRepositoryRead, feeSchedule FeeSchedule) BillingQueryService {
 	return &billingQueryService{
-		repoRead: repoRead,
+		repoRead:     repoRead,
+		riskRepoRead: riskRepoRead,
+		feeSchedule:  feeSchedule,
 	}
 }
 
@@ -11,12 +15,25 @@ type QueryAccountStatus struct {
 	BlockedAmount Amount
 }
 
+type QueryStatementItem struct {
+	LotID        *LotID
+	Operation    string
+	Amount       Amount
+	CreationTime time.Time
+}
+
 type BillingQueryService interface {
 	AccountBalance(userID UserID) (QueryAccountStatus, error)
+	AccountStatement(userID UserID) ([]QueryStatementItem, error)
+	FeePreview(startPrice Amount, buyItNowPrice *Amount) FeePreview
+	RiskHolds() ([]RiskHold, error)
+	RiskRuleHits(userID *UserID) ([]RiskRuleHit, error)
 }
 
 type billingQueryService struct {
-	repoRead UserAccountEventRepository
+	repoRead     UserAccountEventRepository
+	riskRepoRead RiskRepositoryRead
+	feeSchedule  FeeSchedule
 }
 
 func (s *billingQueryService) AccountBalance(userID UserID) (QueryAccountStatus, error) {
@@ -41,3 +58,35 @@ func (s *billingQueryService) AccountBalance(userID UserID) (QueryAccountStatus,
 		BlockedAmount: blockedAmount,
 	}, nil
 }
+
+func (s *billingQueryService) AccountStatement(userID UserID) ([]QueryStatementItem, error) {
+	accountEvents, err := s.repoRead.FindAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]QueryStatementItem, 0, len(accountEvents))
+	for _, event := range accountEvents {
+		if event.EventType == createAccountEventType {
+			continue
+		}
+		items = append(items, QueryStatementItem{
+			LotID:        event.LotID,
+			Operation:    string(event.EventType),
+			Amount:       event.Amount,
+			CreationTime: event.CreationTime,
+		})
+	}
+	return items, nil
+}
+
+func (s *billingQueryService) FeePreview(startPrice Amount, buyItNowPrice *Amount) FeePreview {
+	return s.feeSchedule.Preview(startPrice, buyItNowPrice)
+}
+
+func (s *billingQueryService) RiskHolds() ([]RiskHold, error) {
+	return s.riskRepoRead.FindHolds()
+}
+
+func (s *billingQueryService) RiskRuleHits(userID *UserID) ([]RiskRuleHit, error) {
+	return s.riskRepoRead.FindRuleHits(userID)
+}
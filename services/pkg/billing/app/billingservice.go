@@ -3,6 +3,8 @@ package app
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -11,56 +13,179 @@ const userAccountEventLockNameTpl = "lock_acc_event_%s"
 
 var ErrAlreadyProcessed = errors.New("request with this id already processed")
 
-func NewBillingService(trUnitFactory TransactionalUnitFactory) BillingService {
-	return &billingService{trUnitFactory: trUnitFactory}
+func NewBillingService(trUnitFactory TransactionalUnitFactory, feeSchedule FeeSchedule, riskPolicy RiskPolicy) BillingService {
+	return &billingService{trUnitFactory: trUnitFactory, feeSchedule: feeSchedule, riskPolicy: riskPolicy}
 }
 
 type BillingService interface {
 	CreateAccount(userID UserID) error
 	CancelLotPayment(userID UserID, lotID LotID, amount Amount) error
+	ReleaseLotPayment(userID UserID, lotID LotID, amount Amount) error
 	FinalizeLotPayment(lotOwnerID, winnerID UserID, lotID LotID, amount Amount) error
+	RefundListingFee(lotOwnerID UserID, lotID LotID) error
+	ReleaseShippingPayment(userID UserID, lotID LotID) error
 
 	TopUpAccount(requestID RequestID, userID UserID, amount Amount) error
+	AdjustBalance(requestID RequestID, adjustment BalanceAdjustment) error
 	ProcessLotPayment(requestID RequestID, userID UserID, lotID LotID, amount Amount) error
+	PayListingFee(requestID RequestID, userID UserID, lotID LotID) error
+	BlockShippingPayment(requestID RequestID, userID UserID, lotID LotID, amount Amount) error
+
+	PlaceRiskHold(userID UserID) error
+	RemoveRiskHold(userID UserID) error
 }
 
 type billingService struct {
 	trUnitFactory TransactionalUnitFactory
+	feeSchedule   FeeSchedule
+	riskPolicy    RiskPolicy
 }
 
 func (s *billingService) CancelLotPayment(userID UserID, lotID LotID, amount Amount) error {
 	return s.executeInTransactionWithLock(
 		[]string{userAccountEventLockName(userID)},
 		func(repoProvider RepositoryProvider) error {
-			return s.changeAccountState(
+			released := false
+			err := s.changeAccountState(
 				repoProvider.UserAccountEventRepository(),
 				userID,
 				func(state UserAccountState) error {
+					blockedAmount, blocked := state.BlockedPayments()[lotID]
+					if state.IsPaymentReleased(lotID, amount) && (!blocked || blockedAmount.RawValue() != amount.RawValue()) {
+						// payment already released by reconciliation job
+						released = true
+						return nil
+					}
 					return state.AddUnblockPaymentEvent(lotID, amount)
 				})
+			if err != nil || released {
+				return err
+			}
+
+			entry := newJournalEntry(unblockPaymentJournalOperation, &lotID).
+				transfer(EscrowLedgerAccount(userID), UserLedgerAccount(userID), amount)
+			return s.postJournalEntry(repoProvider.LedgerRepository(), entry)
+		})
+}
+
+func (s *billingService) ReleaseLotPayment(userID UserID, lotID LotID, amount Amount) error {
+	return s.executeInTransactionWithLock(
+		[]string{userAccountEventLockName(userID)},
+		func(repoProvider RepositoryProvider) error {
+			err := s.changeAccountState(
+				repoProvider.UserAccountEventRepository(),
+				userID,
+				func(state UserAccountState) error {
+					return state.AddReleasePaymentEvent(lotID, amount)
+				})
+			if err != nil {
+				return err
+			}
+
+			entry := newJournalEntry(releasePaymentJournalOperation, &lotID).
+				transfer(EscrowLedgerAccount(userID), UserLedgerAccount(userID), amount)
+			return s.postJournalEntry(repoProvider.LedgerRepository(), entry)
 		})
 }
 
+// FinalizeLotPayment - shipping payment blocked by winner is transferred to lot owner too, fee isn't charged for shipping
 func (s *billingService) FinalizeLotPayment(lotOwnerID, winnerID UserID, lotID LotID, amount Amount) error {
 	return s.executeInTransactionWithLock(
-		[]string{userAccountEventLockName(lotOwnerID), userAccountEventLockName(winnerID)},
+		[]string{userAccountEventLockName(lotOwnerID), userAccountEventLockName(winnerID), userAccountEventLockName(PlatformAccountID)},
 		func(repoProvider RepositoryProvider) error {
+			var shippingAmount Amount
 			err := s.changeAccountState(
 				repoProvider.UserAccountEventRepository(),
 				winnerID,
 				func(state UserAccountState) error {
-					return state.AddFinishPaymentEvent(lotID, amount)
+					err := state.AddFinishPaymentEvent(lotID, amount)
+					if err != nil {
+						return err
+					}
+					shippingAmount = state.ShippingPayment(lotID)
+					if shippingAmount.RawValue() == 0 {
+						return nil
+					}
+					return state.AddFinishShippingPaymentEvent(lotID, shippingAmount)
 				})
 			if err != nil {
 				return err
 			}
 
-			return s.changeAccountState(
+			fee := s.feeSchedule.FinalValueFee(amount)
+			err = s.changeAccountState(
 				repoProvider.UserAccountEventRepository(),
 				lotOwnerID,
 				func(state UserAccountState) error {
-					return state.AddReceivePaymentEvent(lotID, amount)
+					err := state.AddReceivePaymentEvent(lotID, amount)
+					if err != nil {
+						return err
+					}
+					if shippingAmount.RawValue() != 0 {
+						err = state.AddReceiveShippingPaymentEvent(lotID, shippingAmount)
+						if err != nil {
+							return err
+						}
+					}
+					if fee.RawValue() == 0 {
+						return nil
+					}
+					return state.AddPayFeeEvent(lotID, fee)
 				})
+			if err != nil {
+				return err
+			}
+
+			if fee.RawValue() != 0 {
+				err = s.changePlatformAccountState(
+					repoProvider.UserAccountEventRepository(),
+					func(state UserAccountState) error {
+						return state.AddCollectFeeEvent(lotID, fee)
+					})
+				if err != nil {
+					return err
+				}
+			}
+
+			entry := newJournalEntry(finalizePaymentJournalOperation, &lotID).
+				transfer(EscrowLedgerAccount(winnerID), UserLedgerAccount(lotOwnerID), amount).
+				transfer(EscrowLedgerAccount(winnerID), UserLedgerAccount(lotOwnerID), shippingAmount).
+				transfer(UserLedgerAccount(lotOwnerID), PlatformLedgerAccount(), fee)
+			return s.postJournalEntry(repoProvider.LedgerRepository(), entry)
+		})
+}
+
+func (s *billingService) RefundListingFee(lotOwnerID UserID, lotID LotID) error {
+	return s.executeInTransactionWithLock(
+		[]string{userAccountEventLockName(lotOwnerID), userAccountEventLockName(PlatformAccountID)},
+		func(repoProvider RepositoryProvider) error {
+			var fee Amount
+			err := s.changeAccountState(
+				repoProvider.UserAccountEventRepository(),
+				lotOwnerID,
+				func(state UserAccountState) error {
+					fee = state.LotFeeAmount(lotID)
+					if fee.RawValue() == 0 {
+						return nil
+					}
+					return state.AddRefundFeeEvent(lotID, fee)
+				})
+			if err != nil || fee.RawValue() == 0 {
+				return err
+			}
+
+			err = s.changePlatformAccountState(
+				repoProvider.UserAccountEventRepository(),
+				func(state UserAccountState) error {
+					return state.AddReturnFeeEvent(lotID, fee)
+				})
+			if err != nil {
+				return err
+			}
+
+			entry := newJournalEntry(refundListingFeeJournalOperation, &lotID).
+				transfer(PlatformLedgerAccount(), UserLedgerAccount(lotOwnerID), fee)
+			return s.postJournalEntry(repoProvider.LedgerRepository(), entry)
 		})
 }
 
@@ -78,7 +203,8 @@ func (s *billingService) CreateAccount(userID UserID) error {
 }
 
 func (s *billingService) TopUpAccount(requestID RequestID, userID UserID, amount Amount) error {
-	return s.executeInTransactionWithLock(
+	var hit *RiskRuleHit
+	err := s.executeInTransactionWithLock(
 		[]string{userAccountEventLockName(userID)},
 		func(provider RepositoryProvider) error {
 			err := s.checkRequestProcessed(provider.ProcessedRequestRepository(), requestID)
@@ -86,17 +212,69 @@ func (s *billingService) TopUpAccount(requestID RequestID, userID UserID, amount
 				return err
 			}
 
-			return s.changeAccountState(
+			err = s.changeAccountState(
 				provider.UserAccountEventRepository(),
 				userID,
 				func(state UserAccountState) error {
+					hit = s.riskPolicy.CheckTopUp(userID, state, amount, time.Now())
+					if hit != nil {
+						return hit.Err()
+					}
 					return state.AddTopUpAccountEvent(amount)
 				})
+			if err != nil {
+				return err
+			}
+
+			entry := newJournalEntry(topUpJournalOperation, nil).
+				transfer(ExternalLedgerAccount(), UserLedgerAccount(userID), amount)
+			return s.postJournalEntry(provider.LedgerRepository(), entry)
 		})
+	return s.handleRiskRuleHit(hit, err)
 }
 
-func (s *billingService) ProcessLotPayment(requestID RequestID, userID UserID, lotID LotID, amount Amount) error {
+// AdjustBalance - credit adjustment comes from external account and debit adjustment goes to it like top ups,
+// risk rules aren't checked for adjustments made by administrator
+func (s *billingService) AdjustBalance(requestID RequestID, adjustment BalanceAdjustment) error {
+	adjustment.Reason = strings.TrimSpace(adjustment.Reason)
+	if adjustment.Reason == "" {
+		return errors.WithStack(ErrReasonRequired)
+	}
 	return s.executeInTransactionWithLock(
+		[]string{userAccountEventLockName(adjustment.UserID)},
+		func(provider RepositoryProvider) error {
+			err := s.checkRequestProcessed(provider.ProcessedRequestRepository(), requestID)
+			if err != nil {
+				return err
+			}
+
+			entry := newJournalEntry(adjustmentJournalOperation, nil)
+			err = s.changeAccountState(
+				provider.UserAccountEventRepository(),
+				adjustment.UserID,
+				func(state UserAccountState) error {
+					if adjustment.Direction == AdjustmentDirectionDebit {
+						entry.transfer(UserLedgerAccount(adjustment.UserID), ExternalLedgerAccount(), adjustment.Amount)
+						return state.AddDebitAdjustmentEvent(adjustment.Amount)
+					}
+					entry.transfer(ExternalLedgerAccount(), UserLedgerAccount(adjustment.UserID), adjustment.Amount)
+					return state.AddCreditAdjustmentEvent(adjustment.Amount)
+				})
+			if err != nil {
+				return err
+			}
+
+			err = provider.BalanceAdjustmentRepository().Store(&adjustment)
+			if err != nil {
+				return err
+			}
+			return s.postJournalEntry(provider.LedgerRepository(), entry)
+		})
+}
+
+func (s *billingService) ProcessLotPayment(requestID RequestID, userID UserID, lotID LotID, amount Amount) error {
+	var hit *RiskRuleHit
+	err := s.executeInTransactionWithLock(
 		[]string{userAccountEventLockName(userID)},
 		func(provider RepositoryProvider) error {
 			err := s.checkRequestProcessed(provider.ProcessedRequestRepository(), requestID)
@@ -104,15 +282,166 @@ func (s *billingService) ProcessLotPayment(requestID RequestID, userID UserID, l
 				return err
 			}
 
-			return s.changeAccountState(
+			hold, err := findRiskHold(provider.RiskRepository(), userID)
+			if err != nil {
+				return err
+			}
+
+			err = s.changeAccountState(
 				provider.UserAccountEventRepository(),
 				userID,
 				func(state UserAccountState) error {
+					hit = s.riskPolicy.CheckBlockPayment(userID, state, hold, lotID, amount, time.Now())
+					if hit != nil {
+						return hit.Err()
+					}
 					return state.AddBlockPaymentEvent(lotID, amount)
 				})
+			if err != nil {
+				return err
+			}
+
+			entry := newJournalEntry(blockPaymentJournalOperation, &lotID).
+				transfer(UserLedgerAccount(userID), EscrowLedgerAccount(userID), amount)
+			return s.postJournalEntry(provider.LedgerRepository(), entry)
+		})
+	return s.handleRiskRuleHit(hit, err)
+}
+
+func (s *billingService) PayListingFee(requestID RequestID, userID UserID, lotID LotID) error {
+	return s.executeInTransactionWithLock(
+		[]string{userAccountEventLockName(userID), userAccountEventLockName(PlatformAccountID)},
+		func(provider RepositoryProvider) error {
+			err := s.checkRequestProcessed(provider.ProcessedRequestRepository(), requestID)
+			if err != nil {
+				return err
+			}
+
+			fee := s.feeSchedule.ListingFee
+			if fee.RawValue() == 0 {
+				return nil
+			}
+
+			err = s.changeAccountState(
+				provider.UserAccountEventRepository(),
+				userID,
+				func(state UserAccountState) error {
+					return state.AddPayFeeEvent(lotID, fee)
+				})
+			if err != nil {
+				return err
+			}
+
+			err = s.changePlatformAccountState(
+				provider.UserAccountEventRepository(),
+				func(state UserAccountState) error {
+					return state.AddCollectFeeEvent(lotID, fee)
+				})
+			if err != nil {
+				return err
+			}
+
+			entry := newJournalEntry(payListingFeeJournalOperation, &lotID).
+				transfer(UserLedgerAccount(userID), PlatformLedgerAccount(), fee)
+			return s.postJournalEntry(provider.LedgerRepository(), entry)
 		})
 }
 
+// BlockShippingPayment - previously blocked shipping payment for lot is replaced with amount, so winner can change shipping option
+func (s *billingService) BlockShippingPayment(requestID RequestID, userID UserID, lotID LotID, amount Amount) error {
+	return s.executeInTransactionWithLock(
+		[]string{userAccountEventLockName(userID)},
+		func(provider RepositoryProvider) error {
+			err := s.checkRequestProcessed(provider.ProcessedRequestRepository(), requestID)
+			if err != nil {
+				return err
+			}
+
+			entry := newJournalEntry(shippingPaymentJournalOperation, &lotID)
+			err = s.changeAccountState(
+				provider.UserAccountEventRepository(),
+				userID,
+				func(state UserAccountState) error {
+					err := unblockShippingPayment(state, userID, lotID, entry)
+					if err != nil || amount.RawValue() == 0 {
+						return err
+					}
+					entry.transfer(UserLedgerAccount(userID), EscrowLedgerAccount(userID), amount)
+					return state.AddBlockShippingPaymentEvent(lotID, amount)
+				})
+			if err != nil || len(entry.Lines) == 0 {
+				return err
+			}
+			return s.postJournalEntry(provider.LedgerRepository(), entry)
+		})
+}
+
+// ReleaseShippingPayment - shipping payment blocked for cancelled delivery is returned to winner
+func (s *billingService) ReleaseShippingPayment(userID UserID, lotID LotID) error {
+	return s.executeInTransactionWithLock(
+		[]string{userAccountEventLockName(userID)},
+		func(provider RepositoryProvider) error {
+			entry := newJournalEntry(shippingPaymentJournalOperation, &lotID)
+			err := s.changeAccountState(
+				provider.UserAccountEventRepository(),
+				userID,
+				func(state UserAccountState) error {
+					return unblockShippingPayment(state, userID, lotID, entry)
+				})
+			if err != nil || len(entry.Lines) == 0 {
+				return err
+			}
+			return s.postJournalEntry(provider.LedgerRepository(), entry)
+		})
+}
+
+func (s *billingService) PlaceRiskHold(userID UserID) error {
+	return s.executeInTransactionWithLock(
+		[]string{userAccountEventLockName(userID)},
+		func(provider RepositoryProvider) error {
+			hold, err := findRiskHold(provider.RiskRepository(), userID)
+			if err != nil || hold != nil {
+				return err
+			}
+			newHold := NewManualRiskHold(userID, time.Now())
+			return provider.RiskRepository().StoreHold(&newHold)
+		})
+}
+
+func (s *billingService) RemoveRiskHold(userID UserID) error {
+	return s.executeInTransactionWithLock(
+		[]string{userAccountEventLockName(userID)},
+		func(provider RepositoryProvider) error {
+			return provider.RiskRepository().RemoveHold(userID)
+		})
+}
+
+// handleRiskRuleHit records rule hit in separate transaction, because operation transaction is rolled back
+func (s *billingService) handleRiskRuleHit(hit *RiskRuleHit, err error) error {
+	if hit == nil {
+		return err
+	}
+	recordErr := s.executeInTransactionWithLock(
+		[]string{userAccountEventLockName(hit.UserID)},
+		func(provider RepositoryProvider) error {
+			riskRepo := provider.RiskRepository()
+			err := riskRepo.StoreRuleHit(hit)
+			if err != nil || !hit.IsFraudSignal() {
+				return err
+			}
+
+			hold, err := findRiskHold(riskRepo, hit.UserID)
+			if err != nil || hold != nil {
+				return err
+			}
+			return riskRepo.StoreHold(&RiskHold{UserID: hit.UserID, Reason: hit.Rule, CreationTime: hit.CreationTime})
+		})
+	if recordErr != nil {
+		return errors.Wrap(err, recordErr.Error())
+	}
+	return err
+}
+
 func (s *billingService) checkRequestProcessed(requestRepo ProcessedRequestRepository, requestID RequestID) error {
 	alreadyProcessed, err := requestRepo.SetRequestProcessed(requestID)
 	if err != nil {
@@ -152,6 +481,26 @@ func (s *billingService) changeAccountState(accountEventRepo UserAccountEventRep
 	return nil
 }
 
+func (s *billingService) postJournalEntry(ledgerRepo LedgerRepository, entry *JournalEntry) error {
+	err := entry.Validate()
+	if err != nil {
+		return err
+	}
+	return ledgerRepo.Store(entry)
+}
+
+func (s *billingService) changePlatformAccountState(accountEventRepo UserAccountEventRepository, f func(UserAccountState) error) error {
+	return s.changeAccountState(accountEventRepo, PlatformAccountID, func(state UserAccountState) error {
+		if state.Amount() == nil {
+			err := state.AddCreateAccountEvent()
+			if err != nil {
+				return err
+			}
+		}
+		return f(state)
+	})
+}
+
 func (s *billingService) executeInTransactionWithLock(lockNames []string, f func(RepositoryProvider) error) (err error) {
 	var trUnit TransactionalUnit
 	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
@@ -176,6 +525,23 @@ func (s *billingService) executeInTransactionWithLock(lockNames []string, f func
 	return err
 }
 
+func unblockShippingPayment(state UserAccountState, userID UserID, lotID LotID, entry *JournalEntry) error {
+	blockedAmount := state.ShippingPayment(lotID)
+	if blockedAmount.RawValue() == 0 {
+		return nil
+	}
+	entry.transfer(EscrowLedgerAccount(userID), UserLedgerAccount(userID), blockedAmount)
+	return state.AddUnblockShippingPaymentEvent(lotID, blockedAmount)
+}
+
+func findRiskHold(riskRepo RiskRepositoryRead, userID UserID) (*RiskHold, error) {
+	hold, err := riskRepo.FindHoldByUserID(userID)
+	if errors.Cause(err) == ErrRiskHoldNotFound {
+		return nil, nil
+	}
+	return hold, err
+}
+
 func userAccountEventLockName(userID UserID) string {
 	return fmt.Sprintf(userAccountEventLockNameTpl, string(userID))
 }
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+	"time"
+)
+
+func TestShippingPaymentReleasedAfterDeliveryCancelled(t *testing.T) {
+	db := newTestBillingDB()
+	db.addTopUp(testUserID, 1000)
+	service := NewBillingService(db, testFeeSchedule(t), NewRiskPolicy(RiskLimits{}))
+
+	assert.Nil(t, service.BlockShippingPayment(RequestID(uuid.GenerateNew()), testUserID, testLotID, AmountFromRawValue(300)))
+	assert.Equal(t, AmountFromRawValue(300), db.accountState(t, testUserID).ShippingPayment(testLotID))
+
+	assert.Nil(t, service.ReleaseShippingPayment(testUserID, testLotID))
+	state := db.accountState(t, testUserID)
+	assert.Equal(t, emptyAmount, state.ShippingPayment(testLotID))
+	assert.Equal(t, AmountFromRawValue(1000), state.Amount())
+	assert.Len(t, db.ledgerRepo.entries, 2)
+	assert.Equal(t, []JournalLine{
+		{Account: EscrowLedgerAccount(testUserID), Debit: AmountFromRawValue(300), Credit: emptyAmount},
+		{Account: UserLedgerAccount(testUserID), Debit: emptyAmount, Credit: AmountFromRawValue(300)},
+	}, db.ledgerRepo.entries[1].Lines)
+
+	// nothing is blocked for lot any more
+	assert.Nil(t, service.ReleaseShippingPayment(testUserID, testLotID))
+	assert.Len(t, db.ledgerRepo.entries, 2)
+	assert.Len(t, db.eventRepo.events[testUserID], 4)
+}
+
+func TestFinalValueFeeBookedOnLotPaymentFinalized(t *testing.T) {
+	sellerID := UserID(uuid.GenerateNew())
+	db := newTestBillingDB()
+	db.addTopUp(testUserID, 10000)
+	db.eventRepo.events[testUserID] = append(db.eventRepo.events[testUserID], blockEvent(testLotID, 5000, time.Now()))
+	db.eventRepo.events[sellerID] = []UserAccountEvent{{UserID: sellerID, EventType: createAccountEventType, CreationTime: time.Now()}}
+	feeSchedule := testFeeSchedule(t)
+	service := NewBillingService(db, feeSchedule, NewRiskPolicy(RiskLimits{}))
+
+	assert.Nil(t, service.FinalizeLotPayment(sellerID, testUserID, testLotID, AmountFromRawValue(5000)))
+
+	// seller is credited with lot price minus 10% final value fee
+	assert.Equal(t, AmountFromRawValue(4500), db.accountState(t, sellerID).Amount())
+	assert.Equal(t, AmountFromRawValue(500), db.accountState(t, PlatformAccountID).Amount())
+	platformEvents := db.eventRepo.events[PlatformAccountID]
+	assert.Len(t, platformEvents, 2)
+	assert.Equal(t, collectFeeEventType, platformEvents[1].EventType)
+	assert.Equal(t, AmountFromRawValue(500), platformEvents[1].Amount)
+	assert.Equal(t, testLotID, *platformEvents[1].LotID)
+
+	statement, err := NewBillingQueryService(db.eventRepo, nil, feeSchedule).AccountStatement(sellerID)
+	assert.Nil(t, err)
+	assert.Len(t, statement, 2)
+	assert.Equal(t, string(receivePaymentEventType), statement[0].Operation)
+	assert.Equal(t, AmountFromRawValue(5000), statement[0].Amount)
+	assert.Equal(t, string(payFeeEventType), statement[1].Operation)
+	assert.Equal(t, AmountFromRawValue(500), statement[1].Amount)
+	assert.Equal(t, testLotID, *statement[1].LotID)
+
+	assert.Len(t, db.ledgerRepo.entries, 1)
+	assert.Contains(t, db.ledgerRepo.entries[0].Lines, JournalLine{Account: PlatformLedgerAccount(), Debit: emptyAmount, Credit: AmountFromRawValue(500)})
+}
+
+func testFeeSchedule(t *testing.T) FeeSchedule {
+	schedule, err := NewFeeSchedule(emptyAmount, []FeeTier{{Threshold: emptyAmount, BasisPoints: 1000}}, emptyAmount, nil)
+	assert.Nil(t, err)
+	return schedule
+}
+
+type testBillingDB struct {
+	eventRepo   *testUserAccountEventRepo
+	ledgerRepo  *testLedgerRepo
+	requestRepo *testProcessedRequestRepo
+}
+
+func newTestBillingDB() *testBillingDB {
+	return &testBillingDB{
+		eventRepo:   &testUserAccountEventRepo{events: map[UserID][]UserAccountEvent{}},
+		ledgerRepo:  &testLedgerRepo{},
+		requestRepo: &testProcessedRequestRepo{processed: map[RequestID]bool{}},
+	}
+}
+
+func (db *testBillingDB) addTopUp(userID UserID, amount uint64) {
+	db.eventRepo.events[userID] = append(db.eventRepo.events[userID],
+		UserAccountEvent{UserID: userID, EventType: createAccountEventType, CreationTime: time.Now()},
+		UserAccountEvent{UserID: userID, EventType: topUpAccountEventType, Amount: AmountFromRawValue(amount), CreationTime: time.Now()},
+	)
+}
+
+func (db *testBillingDB) accountState(t *testing.T, userID UserID) UserAccountState {
+	state := NewEmptyUserAccountState(userID)
+	assert.Nil(t, state.LoadEvents(db.eventRepo.events[userID]))
+	return state
+}
+
+func (db *testBillingDB) NewTransactionalUnit() (TransactionalUnit, error) {
+	return db, nil
+}
+
+func (db *testBillingDB) Complete(err error) error {
+	return err
+}
+
+func (db *testBillingDB) AddLock(string) error {
+	return nil
+}
+
+func (db *testBillingDB) UserAccountEventRepository() UserAccountEventRepository {
+	return db.eventRepo
+}
+
+func (db *testBillingDB) LedgerRepository() LedgerRepository {
+	return db.ledgerRepo
+}
+
+func (db *testBillingDB) RiskRepository() RiskRepository {
+	return nil
+}
+
+func (db *testBillingDB) ProcessedEventRepository() ProcessedEventRepository {
+	return nil
+}
+
+func (db *testBillingDB) ProcessedRequestRepository() ProcessedRequestRepository {
+	return db.requestRepo
+}
+
+func (db *testBillingDB) BalanceAdjustmentRepository() BalanceAdjustmentRepository {
+	return nil
+}
+
+func (repo *testUserAccountEventRepo) Store(event *UserAccountEvent) error {
+	repo.events[event.UserID] = append(repo.events[event.UserID], *event)
+	return nil
+}
+
+func (repo *testLedgerRepo) Store(entry *JournalEntry) error {
+	repo.entries = append(repo.entries, entry)
+	return nil
+}
+
+type testProcessedRequestRepo struct {
+	processed map[RequestID]bool
+}
+
+func (repo *testProcessedRequestRepo) SetRequestProcessed(uid RequestID) (bool, error) {
+	alreadyProcessed := repo.processed[uid]
+	repo.processed[uid] = true
+	return alreadyProcessed, nil
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type BlockedPaymentDiscrepancyKind string
+
+const (
+	DiscrepancyLotNotFound     BlockedPaymentDiscrepancyKind = "lot_not_found"
+	DiscrepancyNotHighBidder   BlockedPaymentDiscrepancyKind = "not_high_bidder"
+	DiscrepancyAmountMismatch  BlockedPaymentDiscrepancyKind = "amount_mismatch"
+	DiscrepancyPaymentNotFinal BlockedPaymentDiscrepancyKind = "payment_not_finalized"
+)
+
+var BlockedPaymentDiscrepancyKinds = []BlockedPaymentDiscrepancyKind{
+	DiscrepancyLotNotFound,
+	DiscrepancyNotHighBidder,
+	DiscrepancyAmountMismatch,
+	DiscrepancyPaymentNotFinal,
+}
+
+type BlockedPaymentDiscrepancy struct {
+	UserID UserID
+	LotID  LotID
+	Amount Amount
+	Kind   BlockedPaymentDiscrepancyKind
+	Healed bool
+}
+
+type BlockedPaymentReconciliationMetrics interface {
+	SetDiscrepancyCount(kind BlockedPaymentDiscrepancyKind, count int)
+	IncHealedCount(count int)
+	SetFailedUserCount(count int)
+}
+
+type BlockedPaymentReconciliationService interface {
+	// Reconcile checks all users even if some of them fail, found discrepancies are returned along with error
+	Reconcile() ([]BlockedPaymentDiscrepancy, error)
+}
+
+func NewBlockedPaymentReconciliationService(
+	eventRepoRead UserAccountEventRepositoryRead,
+	lotClient LotClient,
+	billingService BillingService,
+	metrics BlockedPaymentReconciliationMetrics,
+	minBlockAge time.Duration,
+) BlockedPaymentReconciliationService {
+	return &blockedPaymentReconciliationService{
+		eventRepoRead:  eventRepoRead,
+		lotClient:      lotClient,
+		billingService: billingService,
+		metrics:        metrics,
+		minBlockAge:    minBlockAge,
+	}
+}
+
+type blockedPaymentReconciliationService struct {
+	eventRepoRead  UserAccountEventRepositoryRead
+	lotClient      LotClient
+	billingService BillingService
+	metrics        BlockedPaymentReconciliationMetrics
+	minBlockAge    time.Duration
+}
+
+func (s *blockedPaymentReconciliationService) Reconcile() ([]BlockedPaymentDiscrepancy, error) {
+	userIDs, err := s.eventRepoRead.FindAllUserIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var discrepancies []BlockedPaymentDiscrepancy
+	var errs []string
+	for _, userID := range userIDs {
+		userDiscrepancies, err2 := s.reconcileUser(userID)
+		// discrepancies healed before error are still reported
+		discrepancies = append(discrepancies, userDiscrepancies...)
+		if err2 != nil {
+			errs = append(errs, fmt.Sprintf("user %s: %s", userID, err2))
+		}
+	}
+
+	counts := make(map[BlockedPaymentDiscrepancyKind]int)
+	healedCount := 0
+	for _, discrepancy := range discrepancies {
+		if discrepancy.Healed {
+			healedCount++
+			continue
+		}
+		counts[discrepancy.Kind]++
+	}
+	for _, kind := range BlockedPaymentDiscrepancyKinds {
+		s.metrics.SetDiscrepancyCount(kind, counts[kind])
+	}
+	s.metrics.IncHealedCount(healedCount)
+	s.metrics.SetFailedUserCount(len(errs))
+
+	if len(errs) > 0 {
+		return discrepancies, errors.Errorf("blocked payment reconciliation failed: %s", strings.Join(errs, "; "))
+	}
+	return discrepancies, nil
+}
+
+func (s *blockedPaymentReconciliationService) reconcileUser(userID UserID) ([]BlockedPaymentDiscrepancy, error) {
+	events, err := s.eventRepoRead.FindAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	state := NewEmptyUserAccountState(userID)
+	if err = state.LoadEvents(events); err != nil {
+		return nil, err
+	}
+	blockedPayments := state.BlockedPayments()
+	if len(blockedPayments) == 0 {
+		return nil, nil
+	}
+
+	blockTimes := make(map[LotID]time.Time)
+	for _, event := range events {
+		if event.EventType == blockPaymentEventType && event.LotID != nil {
+			blockTimes[*event.LotID] = event.CreationTime
+		}
+	}
+
+	var discrepancies []BlockedPaymentDiscrepancy
+	for lotID, amount := range blockedPayments {
+		if time.Since(blockTimes[lotID]) < s.minBlockAge {
+			// bid saga may be still in progress
+			continue
+		}
+
+		kind, found, err := s.checkBlockedPayment(userID, lotID, amount)
+		if err != nil {
+			return discrepancies, err
+		}
+		if !found {
+			continue
+		}
+
+		discrepancy := BlockedPaymentDiscrepancy{
+			UserID: userID,
+			LotID:  lotID,
+			Amount: amount,
+			Kind:   kind,
+		}
+		if kind == DiscrepancyLotNotFound || kind == DiscrepancyNotHighBidder {
+			if err = s.billingService.ReleaseLotPayment(userID, lotID, amount); err != nil {
+				return discrepancies, err
+			}
+			discrepancy.Healed = true
+		}
+		discrepancies = append(discrepancies, discrepancy)
+	}
+	return discrepancies, nil
+}
+
+func (s *blockedPaymentReconciliationService) checkBlockedPayment(userID UserID, lotID LotID, amount Amount) (kind BlockedPaymentDiscrepancyKind, found bool, err error) {
+	highBid, lotFound, err := s.lotClient.GetLotHighBid(lotID)
+	if err != nil {
+		return "", false, err
+	}
+	if !lotFound {
+		return DiscrepancyLotNotFound, true, nil
+	}
+	if highBid.HighBidderID == nil || *highBid.HighBidderID != userID {
+		return DiscrepancyNotHighBidder, true, nil
+	}
+	if highBid.HighBidAmount == nil || (*highBid.HighBidAmount).RawValue() != amount.RawValue() {
+		return DiscrepancyAmountMismatch, true, nil
+	}
+	if highBid.Status == lotStatusReceived {
+		return DiscrepancyPaymentNotFinal, true, nil
+	}
+	return "", false, nil
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+	"time"
+)
+
+func TestBlockedPaymentReconciliation(t *testing.T) {
+	outbidLotID := LotID(uuid.GenerateNew())
+	missingLotID := LotID(uuid.GenerateNew())
+	mismatchLotID := LotID(uuid.GenerateNew())
+	recentLotID := LotID(uuid.GenerateNew())
+	otherUserID := UserID(uuid.GenerateNew())
+	oldTime := time.Now().Add(-time.Hour)
+
+	eventRepo := &testUserAccountEventRepo{events: map[UserID][]UserAccountEvent{
+		testUserID: {
+			{UserID: testUserID, EventType: createAccountEventType, Amount: emptyAmount},
+			{UserID: testUserID, EventType: topUpAccountEventType, Amount: AmountFromRawValue(10000)},
+			blockEvent(testLotID, 100, oldTime),
+			blockEvent(outbidLotID, 200, oldTime),
+			blockEvent(missingLotID, 300, oldTime),
+			blockEvent(mismatchLotID, 400, oldTime),
+			blockEvent(recentLotID, 500, time.Now()),
+		},
+	}}
+	lotClient := &testLotClient{highBids: map[LotID]LotHighBid{
+		testLotID:     {LotID: testLotID, HighBidderID: &testUserID, HighBidAmount: amountPtr(100)},
+		outbidLotID:   {LotID: outbidLotID, HighBidderID: &otherUserID, HighBidAmount: amountPtr(250)},
+		mismatchLotID: {LotID: mismatchLotID, HighBidderID: &testUserID, HighBidAmount: amountPtr(450)},
+		recentLotID:   {LotID: recentLotID},
+	}}
+	billingService := &testBillingService{}
+	metrics := &testReconciliationMetrics{counts: make(map[BlockedPaymentDiscrepancyKind]int)}
+
+	service := NewBlockedPaymentReconciliationService(eventRepo, lotClient, billingService, metrics, time.Minute)
+	discrepancies, err := service.Reconcile()
+	assert.Nil(t, err)
+	assert.Len(t, discrepancies, 3)
+
+	assert.ElementsMatch(t, []LotID{outbidLotID, missingLotID}, billingService.releasedLots)
+	assert.Equal(t, 2, metrics.healed)
+	assert.Equal(t, 1, metrics.counts[DiscrepancyAmountMismatch])
+	assert.Equal(t, 0, metrics.counts[DiscrepancyNotHighBidder])
+	assert.Equal(t, 0, metrics.failedUsers)
+}
+
+func TestBlockedPaymentReconciliationContinuesAfterUserFailure(t *testing.T) {
+	failingLotID := LotID(uuid.GenerateNew())
+	otherUserID := UserID(uuid.GenerateNew())
+	oldTime := time.Now().Add(-time.Hour)
+
+	otherUserBlock := blockEvent(testLotID, 200, oldTime)
+	otherUserBlock.UserID = otherUserID
+	eventRepo := &testUserAccountEventRepo{events: map[UserID][]UserAccountEvent{
+		testUserID: {
+			{UserID: testUserID, EventType: createAccountEventType, Amount: emptyAmount},
+			{UserID: testUserID, EventType: topUpAccountEventType, Amount: AmountFromRawValue(10000)},
+			blockEvent(failingLotID, 100, oldTime),
+		},
+		otherUserID: {
+			{UserID: otherUserID, EventType: createAccountEventType, Amount: emptyAmount},
+			{UserID: otherUserID, EventType: topUpAccountEventType, Amount: AmountFromRawValue(10000)},
+			otherUserBlock,
+		},
+	}}
+	lotClient := &testLotClient{
+		highBids:   map[LotID]LotHighBid{testLotID: {LotID: testLotID, HighBidderID: &testUserID, HighBidAmount: amountPtr(200)}},
+		failedLots: map[LotID]bool{failingLotID: true},
+	}
+	billingService := &testBillingService{}
+	metrics := &testReconciliationMetrics{counts: make(map[BlockedPaymentDiscrepancyKind]int)}
+
+	service := NewBlockedPaymentReconciliationService(eventRepo, lotClient, billingService, metrics, time.Minute)
+	discrepancies, err := service.Reconcile()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), string(testUserID))
+
+	// other user is still reconciled
+	assert.Len(t, discrepancies, 1)
+	assert.Equal(t, otherUserID, discrepancies[0].UserID)
+	assert.Equal(t, []LotID{testLotID}, billingService.releasedLots)
+	assert.Equal(t, 1, metrics.healed)
+	assert.Equal(t, 1, metrics.failedUsers)
+}
+
+func blockEvent(lotID LotID, amount uint64, creationTime time.Time) UserAccountEvent {
+	return UserAccountEvent{
+		UserID:       testUserID,
+		LotID:        &lotID,
+		EventType:    blockPaymentEventType,
+		Amount:       AmountFromRawValue(amount),
+		CreationTime: creationTime,
+	}
+}
+
+func amountPtr(value uint64) *Amount {
+	amount := AmountFromRawValue(value)
+	return &amount
+}
+
+type testLotClient struct {
+	highBids   map[LotID]LotHighBid
+	failedLots map[LotID]bool
+}
+
+func (c *testLotClient) GetLotHighBid(lotID LotID) (*LotHighBid, bool, error) {
+	if c.failedLots[lotID] {
+		return nil, false, errors.New("lot service unavailable")
+	}
+	highBid, ok := c.highBids[lotID]
+	if !ok {
+		return nil, false, nil
+	}
+	return &highBid, true, nil
+}
+
+type testBillingService struct {
+	BillingService
+	releasedLots []LotID
+}
+
+func (s *testBillingService) ReleaseLotPayment(_ UserID, lotID LotID, _ Amount) error {
+	s.releasedLots = append(s.releasedLots, lotID)
+	return nil
+}
+
+type testReconciliationMetrics struct {
+	counts      map[BlockedPaymentDiscrepancyKind]int
+	healed      int
+	failedUsers int
+}
+
+func (m *testReconciliationMetrics) SetDiscrepancyCount(kind BlockedPaymentDiscrepancyKind, count int) {
+	m.counts[kind] = count
+}
+
+func (m *testReconciliationMetrics) IncHealedCount(count int) {
+	m.healed += count
+}
+
+func (m *testReconciliationMetrics) SetFailedUserCount(count int) {
+	m.failedUsers = count
+}
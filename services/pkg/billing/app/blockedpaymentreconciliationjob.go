@@ -0,0 +1,53 @@
+package app
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"context"
+	"time"
+)
+
+func StartBlockedPaymentReconciliationJob(ctx context.Context, service BlockedPaymentReconciliationService, interval time.Duration, logger *logrus.Logger) {
+	job := blockedPaymentReconciliationJob{
+		service: service,
+		logger:  logger,
+	}
+	job.start(ctx, interval)
+}
+
+type blockedPaymentReconciliationJob struct {
+	service BlockedPaymentReconciliationService
+	logger  *logrus.Logger
+}
+
+func (job *blockedPaymentReconciliationJob) start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				job.reconcile()
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (job *blockedPaymentReconciliationJob) reconcile() {
+	discrepancies, err := job.service.Reconcile()
+	if err != nil {
+		job.logger.Error(err)
+	}
+	for _, discrepancy := range discrepancies {
+		job.logger.WithFields(logrus.Fields{
+			"user_id": discrepancy.UserID,
+			"lot_id":  discrepancy.LotID,
+			"amount":  discrepancy.Amount.Value(),
+			"kind":    discrepancy.Kind,
+			"healed":  discrepancy.Healed,
+		}).Warn("blocked payment discrepancy")
+	}
+}
@@ -38,6 +38,20 @@ func NewLotReceivedEvent(userID UserID, lotID LotID, lotOwnerID UserID, finalAmo
 	}
 }
 
+func NewLotCreationCancelledEvent(lotID LotID, lotOwnerID UserID) UserEvent {
+	return lotCreationCancelledEvent{
+		lotID:      lotID,
+		lotOwnerID: lotOwnerID,
+	}
+}
+
+func NewDeliveryLotCancelledEvent(userID UserID, lotID LotID) UserEvent {
+	return deliveryLotCancelledEvent{
+		userID: userID,
+		lotID:  lotID,
+	}
+}
+
 type userRegisteredEvent struct {
 	userID UserID
 	login  string
@@ -77,3 +91,21 @@ type lotReceivedEvent struct {
 func (e lotReceivedEvent) UserID() UserID {
 	return e.userID
 }
+
+type lotCreationCancelledEvent struct {
+	lotID      LotID
+	lotOwnerID UserID
+}
+
+func (e lotCreationCancelledEvent) UserID() UserID {
+	return e.lotOwnerID
+}
+
+type deliveryLotCancelledEvent struct {
+	userID UserID
+	lotID  LotID
+}
+
+func (e deliveryLotCancelledEvent) UserID() UserID {
+	return e.userID
+}
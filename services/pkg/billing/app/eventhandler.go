@@ -8,16 +8,18 @@ type IntegrationEventParser interface {
 	ParseIntegrationEvent(event integrationevent.EventData) (UserEvent, error)
 }
 
-func NewEventHandler(trUnitFactory TransactionalUnitFactory, parser IntegrationEventParser) integrationevent.EventHandler {
+func NewEventHandler(trUnitFactory TransactionalUnitFactory, parser IntegrationEventParser, feeSchedule FeeSchedule) integrationevent.EventHandler {
 	return &eventHandler{
 		trUnitFactory: trUnitFactory,
 		parser:        parser,
+		feeSchedule:   feeSchedule,
 	}
 }
 
 type eventHandler struct {
 	trUnitFactory TransactionalUnitFactory
 	parser        IntegrationEventParser
+	feeSchedule   FeeSchedule
 }
 
 func (handler *eventHandler) Handle(event integrationevent.EventData) error {
@@ -36,7 +38,8 @@ func (handler *eventHandler) Handle(event integrationevent.EventData) error {
 			return nil
 		}
 
-		service := NewBillingService(trUnit)
+		// risk rules are checked only for operations initiated by user
+		service := NewBillingService(trUnit, handler.feeSchedule, NewRiskPolicy(RiskLimits{}))
 
 		switch e := parsedEvent.(type) {
 		case userRegisteredEvent:
@@ -47,6 +50,10 @@ func (handler *eventHandler) Handle(event integrationevent.EventData) error {
 			return service.CancelLotPayment(e.userID, e.lotID, e.bidAmount)
 		case lotReceivedEvent:
 			return service.FinalizeLotPayment(e.lotOwnerID, e.userID, e.lotID, e.finalAmount)
+		case lotCreationCancelledEvent:
+			return service.RefundListingFee(e.lotOwnerID, e.lotID)
+		case deliveryLotCancelledEvent:
+			return service.ReleaseShippingPayment(e.userID, e.lotID)
 		default:
 			return nil
 		}
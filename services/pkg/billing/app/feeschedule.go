@@ -0,0 +1,94 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const basisPointsMultiplier = 10000
+
+var ErrInvalidFeeSchedule = errors.New("invalid fee schedule")
+
+// FeeTier rate is applied to the part of the final price that is above Threshold and below next tier threshold
+type FeeTier struct {
+	Threshold   Amount
+	BasisPoints uint64
+}
+
+type FeeSchedule struct {
+	ListingFee       Amount
+	FinalValueTiers  []FeeTier
+	MinFinalValueFee Amount
+	MaxFinalValueFee *Amount
+}
+
+type FeePreview struct {
+	ListingFee            Amount
+	FinalValueFee         Amount
+	BuyItNowFinalValueFee *Amount
+}
+
+func NewFeeSchedule(listingFee Amount, tiers []FeeTier, minFinalValueFee Amount, maxFinalValueFee *Amount) (FeeSchedule, error) {
+	sortedTiers := make([]FeeTier, len(tiers))
+	copy(sortedTiers, tiers)
+	sort.Slice(sortedTiers, func(i, j int) bool {
+		return sortedTiers[i].Threshold.RawValue() < sortedTiers[j].Threshold.RawValue()
+	})
+	for i, tier := range sortedTiers {
+		if tier.BasisPoints > basisPointsMultiplier {
+			return FeeSchedule{}, errors.Wrapf(ErrInvalidFeeSchedule, "fee rate of tier %d is greater than 100%%", i)
+		}
+		if i > 0 && tier.Threshold.RawValue() == sortedTiers[i-1].Threshold.RawValue() {
+			return FeeSchedule{}, errors.Wrapf(ErrInvalidFeeSchedule, "duplicated tier threshold %.2f", tier.Threshold.Value())
+		}
+	}
+	if maxFinalValueFee != nil && (*maxFinalValueFee).RawValue() < minFinalValueFee.RawValue() {
+		return FeeSchedule{}, errors.Wrap(ErrInvalidFeeSchedule, "max final value fee is less than min final value fee")
+	}
+	return FeeSchedule{
+		ListingFee:       listingFee,
+		FinalValueTiers:  sortedTiers,
+		MinFinalValueFee: minFinalValueFee,
+		MaxFinalValueFee: maxFinalValueFee,
+	}, nil
+}
+
+func (s FeeSchedule) FinalValueFee(price Amount) Amount {
+	rawPrice := price.RawValue()
+	var fee uint64
+	for i, tier := range s.FinalValueTiers {
+		from := tier.Threshold.RawValue()
+		if rawPrice <= from {
+			break
+		}
+		to := rawPrice
+		if i+1 < len(s.FinalValueTiers) && s.FinalValueTiers[i+1].Threshold.RawValue() < to {
+			to = s.FinalValueTiers[i+1].Threshold.RawValue()
+		}
+		fee += ((to-from)*tier.BasisPoints + basisPointsMultiplier/2) / basisPointsMultiplier
+	}
+
+	if fee < s.MinFinalValueFee.RawValue() {
+		fee = s.MinFinalValueFee.RawValue()
+	}
+	if s.MaxFinalValueFee != nil && fee > (*s.MaxFinalValueFee).RawValue() {
+		fee = (*s.MaxFinalValueFee).RawValue()
+	}
+	if fee > rawPrice {
+		fee = rawPrice
+	}
+	return AmountFromRawValue(fee)
+}
+
+func (s FeeSchedule) Preview(startPrice Amount, buyItNowPrice *Amount) FeePreview {
+	preview := FeePreview{
+		ListingFee:    s.ListingFee,
+		FinalValueFee: s.FinalValueFee(startPrice),
+	}
+	if buyItNowPrice != nil {
+		fee := s.FinalValueFee(*buyItNowPrice)
+		preview.BuyItNowFinalValueFee = &fee
+	}
+	return preview
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+)
+
+func TestFinalValueFeeTiers(t *testing.T) {
+	maxFee := AmountFromRawValue(5000)
+	schedule, err := NewFeeSchedule(
+		AmountFromRawValue(50),
+		[]FeeTier{
+			{Threshold: AmountFromRawValue(10000), BasisPoints: 500},
+			{Threshold: AmountFromRawValue(0), BasisPoints: 1000},
+		},
+		AmountFromRawValue(10),
+		&maxFee,
+	)
+	assert.Nil(t, err)
+
+	assert.Equal(t, AmountFromRawValue(10), schedule.FinalValueFee(AmountFromRawValue(50)))
+	assert.Equal(t, AmountFromRawValue(500), schedule.FinalValueFee(AmountFromRawValue(5000)))
+	assert.Equal(t, AmountFromRawValue(1000), schedule.FinalValueFee(AmountFromRawValue(10000)))
+	assert.Equal(t, AmountFromRawValue(1500), schedule.FinalValueFee(AmountFromRawValue(20000)))
+	assert.Equal(t, maxFee, schedule.FinalValueFee(AmountFromRawValue(1000000)))
+	assert.Equal(t, AmountFromRawValue(5), schedule.FinalValueFee(AmountFromRawValue(5)))
+}
+
+func TestFeePreview(t *testing.T) {
+	schedule, err := NewFeeSchedule(AmountFromRawValue(50), []FeeTier{{Threshold: AmountFromRawValue(0), BasisPoints: 1000}}, emptyAmount, nil)
+	assert.Nil(t, err)
+
+	buyItNowPrice := AmountFromRawValue(3000)
+	preview := schedule.Preview(AmountFromRawValue(1000), &buyItNowPrice)
+	assert.Equal(t, AmountFromRawValue(50), preview.ListingFee)
+	assert.Equal(t, AmountFromRawValue(100), preview.FinalValueFee)
+	assert.Equal(t, AmountFromRawValue(300), *preview.BuyItNowFinalValueFee)
+}
+
+func TestInvalidFeeScheduleFailed(t *testing.T) {
+	_, err := NewFeeSchedule(emptyAmount, []FeeTier{{Threshold: emptyAmount, BasisPoints: 10001}}, emptyAmount, nil)
+	assert.Equal(t, ErrInvalidFeeSchedule, errors.Cause(err))
+
+	_, err = NewFeeSchedule(emptyAmount, []FeeTier{{Threshold: emptyAmount, BasisPoints: 1}, {Threshold: emptyAmount, BasisPoints: 2}}, emptyAmount, nil)
+	assert.Equal(t, ErrInvalidFeeSchedule, errors.Cause(err))
+
+	maxFee := AmountFromRawValue(1)
+	_, err = NewFeeSchedule(emptyAmount, nil, AmountFromRawValue(2), &maxFee)
+	assert.Equal(t, ErrInvalidFeeSchedule, errors.Cause(err))
+}
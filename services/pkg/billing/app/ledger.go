@@ -0,0 +1,122 @@
+package app
+
+import "github.com/pkg/errors"
+
+var ErrUnbalancedJournalEntry = errors.New("journal entry is not balanced")
+
+type LedgerAccountType string
+type JournalEntryID int64
+type JournalOperation string
+
+const (
+	ledgerAccountTypeUser     LedgerAccountType = "user"
+	ledgerAccountTypeEscrow   LedgerAccountType = "escrow"
+	ledgerAccountTypePlatform LedgerAccountType = "platform"
+	ledgerAccountTypeExternal LedgerAccountType = "external"
+)
+
+const (
+	topUpJournalOperation            JournalOperation = "top_up"
+	blockPaymentJournalOperation     JournalOperation = "block_payment"
+	unblockPaymentJournalOperation   JournalOperation = "unblock_payment"
+	releasePaymentJournalOperation   JournalOperation = "release_payment"
+	finalizePaymentJournalOperation  JournalOperation = "finalize_payment"
+	payListingFeeJournalOperation    JournalOperation = "pay_listing_fee"
+	refundListingFeeJournalOperation JournalOperation = "refund_listing_fee"
+	adjustmentJournalOperation       JournalOperation = "adjustment"
+	shippingPaymentJournalOperation  JournalOperation = "shipping_payment"
+	// openingBalanceJournalOperation - posted by migration for accounts with events stored before journal
+	openingBalanceJournalOperation JournalOperation = "opening_balance"
+)
+
+// LedgerAccount - user account is available user funds, escrow account is blocked user funds,
+// external account is a source of top ups
+type LedgerAccount struct {
+	Type   LedgerAccountType
+	UserID UserID
+}
+
+type JournalLine struct {
+	Account LedgerAccount
+	Debit   Amount
+	Credit  Amount
+}
+
+type JournalEntry struct {
+	Operation JournalOperation
+	LotID     *LotID
+	Lines     []JournalLine
+}
+
+type LedgerAccountBalance struct {
+	Account LedgerAccount
+	Debit   Amount
+	Credit  Amount
+}
+
+type LedgerRepositoryRead interface {
+	FindAccountBalances() ([]LedgerAccountBalance, error)
+	FindUnbalancedEntryIDs() ([]JournalEntryID, error)
+}
+
+type LedgerRepository interface {
+	LedgerRepositoryRead
+	Store(entry *JournalEntry) error
+}
+
+func NewLedgerAccount(accountType LedgerAccountType, userID UserID) LedgerAccount {
+	return LedgerAccount{Type: accountType, UserID: userID}
+}
+
+func UserLedgerAccount(userID UserID) LedgerAccount {
+	return NewLedgerAccount(ledgerAccountTypeUser, userID)
+}
+
+func EscrowLedgerAccount(userID UserID) LedgerAccount {
+	return NewLedgerAccount(ledgerAccountTypeEscrow, userID)
+}
+
+func PlatformLedgerAccount() LedgerAccount {
+	return NewLedgerAccount(ledgerAccountTypePlatform, "")
+}
+
+func ExternalLedgerAccount() LedgerAccount {
+	return NewLedgerAccount(ledgerAccountTypeExternal, "")
+}
+
+func newJournalEntry(operation JournalOperation, lotID *LotID) *JournalEntry {
+	return &JournalEntry{Operation: operation, LotID: lotID}
+}
+
+func (e *JournalEntry) transfer(from, to LedgerAccount, amount Amount) *JournalEntry {
+	if amount.RawValue() == 0 {
+		return e
+	}
+	e.Lines = append(e.Lines,
+		JournalLine{Account: from, Debit: amount, Credit: AmountFromRawValue(0)},
+		JournalLine{Account: to, Debit: AmountFromRawValue(0), Credit: amount},
+	)
+	return e
+}
+
+func (e *JournalEntry) Validate() error {
+	if len(e.Lines) < 2 {
+		return errors.Wrap(ErrUnbalancedJournalEntry, "journal entry should contain at least 2 lines")
+	}
+	var debit, credit uint64
+	for _, line := range e.Lines {
+		debit += line.Debit.RawValue()
+		credit += line.Credit.RawValue()
+	}
+	if debit != credit {
+		return errors.Wrapf(ErrUnbalancedJournalEntry, "debit %d is not equal to credit %d", debit, credit)
+	}
+	return nil
+}
+
+func (b LedgerAccountBalance) Balance() int64 {
+	if b.Account.Type == ledgerAccountTypeExternal {
+		return int64(b.Debit.RawValue()) - int64(b.Credit.RawValue())
+	}
+	return int64(b.Credit.RawValue()) - int64(b.Debit.RawValue())
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+)
+
+func TestJournalEntryTransfer(t *testing.T) {
+	amount := AmountFromRawValue(1000)
+	fee := AmountFromRawValue(100)
+	ownerID := UserID(uuid.GenerateNew())
+
+	entry := newJournalEntry(finalizePaymentJournalOperation, &testLotID).
+		transfer(EscrowLedgerAccount(testUserID), UserLedgerAccount(ownerID), amount).
+		transfer(UserLedgerAccount(ownerID), PlatformLedgerAccount(), fee).
+		transfer(UserLedgerAccount(ownerID), PlatformLedgerAccount(), emptyAmount)
+
+	assert.Nil(t, entry.Validate())
+	assert.Len(t, entry.Lines, 4)
+	assert.Equal(t, JournalLine{Account: EscrowLedgerAccount(testUserID), Debit: amount, Credit: emptyAmount}, entry.Lines[0])
+	assert.Equal(t, JournalLine{Account: PlatformLedgerAccount(), Debit: emptyAmount, Credit: fee}, entry.Lines[3])
+}
+
+func TestUnbalancedJournalEntryFailed(t *testing.T) {
+	entry := newJournalEntry(topUpJournalOperation, nil)
+	assert.Equal(t, ErrUnbalancedJournalEntry, errors.Cause(entry.Validate()))
+
+	entry.Lines = []JournalLine{
+		{Account: ExternalLedgerAccount(), Debit: AmountFromRawValue(100), Credit: emptyAmount},
+		{Account: UserLedgerAccount(testUserID), Debit: emptyAmount, Credit: AmountFromRawValue(90)},
+	}
+	assert.Equal(t, ErrUnbalancedJournalEntry, errors.Cause(entry.Validate()))
+}
+
+func TestLedgerReconciliation(t *testing.T) {
+	lotID := testLotID
+	eventRepo := &testUserAccountEventRepo{events: map[UserID][]UserAccountEvent{
+		testUserID: {
+			{UserID: testUserID, EventType: createAccountEventType, Amount: emptyAmount},
+			{UserID: testUserID, EventType: topUpAccountEventType, Amount: AmountFromRawValue(1000)},
+			{UserID: testUserID, LotID: &lotID, EventType: blockPaymentEventType, Amount: AmountFromRawValue(300)},
+		},
+	}}
+	ledgerRepo := &testLedgerRepo{balances: []LedgerAccountBalance{
+		{Account: ExternalLedgerAccount(), Debit: AmountFromRawValue(1000), Credit: emptyAmount},
+		{Account: UserLedgerAccount(testUserID), Debit: AmountFromRawValue(300), Credit: AmountFromRawValue(1000)},
+		{Account: EscrowLedgerAccount(testUserID), Debit: emptyAmount, Credit: AmountFromRawValue(300)},
+	}}
+
+	report, err := NewLedgerReconciliationService(eventRepo, ledgerRepo).Reconcile()
+	assert.Nil(t, err)
+	assert.True(t, report.Consistent())
+	assert.Equal(t, 2, report.CheckedAccounts)
+
+	ledgerRepo.balances[2].Credit = AmountFromRawValue(200)
+	ledgerRepo.unbalancedEntryIDs = []JournalEntryID{7}
+	report, err = NewLedgerReconciliationService(eventRepo, ledgerRepo).Reconcile()
+	assert.Nil(t, err)
+	assert.False(t, report.Consistent())
+	assert.Equal(t, []JournalEntryID{7}, report.UnbalancedEntries)
+	assert.Equal(t, []LedgerDiscrepancy{{
+		Account:       EscrowLedgerAccount(testUserID),
+		LedgerBalance: 200,
+		StateBalance:  300,
+	}}, report.Discrepancies)
+}
+
+func TestLedgerReconciliationWithOpeningBalances(t *testing.T) {
+	lotID := testLotID
+	ownerID := UserID(uuid.GenerateNew())
+	// events stored before journal
+	eventRepo := &testUserAccountEventRepo{events: map[UserID][]UserAccountEvent{
+		testUserID: {
+			{UserID: testUserID, EventType: createAccountEventType, Amount: emptyAmount},
+			{UserID: testUserID, EventType: topUpAccountEventType, Amount: AmountFromRawValue(1000)},
+			{UserID: testUserID, LotID: &lotID, EventType: blockPaymentEventType, Amount: AmountFromRawValue(300)},
+		},
+		ownerID: {
+			{UserID: ownerID, EventType: createAccountEventType, Amount: emptyAmount},
+			{UserID: ownerID, EventType: topUpAccountEventType, Amount: AmountFromRawValue(50)},
+		},
+		PlatformAccountID: {
+			{UserID: PlatformAccountID, EventType: createAccountEventType, Amount: emptyAmount},
+		},
+	}}
+	entries := []*JournalEntry{
+		newJournalEntry(openingBalanceJournalOperation, nil).transfer(ExternalLedgerAccount(), UserLedgerAccount(testUserID), AmountFromRawValue(700)),
+		newJournalEntry(openingBalanceJournalOperation, nil).transfer(ExternalLedgerAccount(), EscrowLedgerAccount(testUserID), AmountFromRawValue(300)),
+		newJournalEntry(openingBalanceJournalOperation, nil).transfer(ExternalLedgerAccount(), UserLedgerAccount(ownerID), AmountFromRawValue(50)),
+	}
+	ledgerRepo := &testLedgerRepo{balances: testLedgerBalances(entries)}
+
+	report, err := NewLedgerReconciliationService(eventRepo, ledgerRepo).Reconcile()
+	assert.Nil(t, err)
+	assert.True(t, report.Consistent())
+
+	// payment blocked before journal is finalized after opening balances are posted
+	fee := AmountFromRawValue(30)
+	eventRepo.events[testUserID] = append(eventRepo.events[testUserID],
+		UserAccountEvent{UserID: testUserID, LotID: &lotID, EventType: finishPaymentEventType, Amount: AmountFromRawValue(300)})
+	eventRepo.events[ownerID] = append(eventRepo.events[ownerID],
+		UserAccountEvent{UserID: ownerID, LotID: &lotID, EventType: receivePaymentEventType, Amount: AmountFromRawValue(300)},
+		UserAccountEvent{UserID: ownerID, LotID: &lotID, EventType: payFeeEventType, Amount: fee})
+	eventRepo.events[PlatformAccountID] = append(eventRepo.events[PlatformAccountID],
+		UserAccountEvent{UserID: PlatformAccountID, LotID: &lotID, EventType: collectFeeEventType, Amount: fee})
+	entries = append(entries, newJournalEntry(finalizePaymentJournalOperation, &lotID).
+		transfer(EscrowLedgerAccount(testUserID), UserLedgerAccount(ownerID), AmountFromRawValue(300)).
+		transfer(UserLedgerAccount(ownerID), PlatformLedgerAccount(), fee))
+	ledgerRepo.balances = testLedgerBalances(entries)
+
+	report, err = NewLedgerReconciliationService(eventRepo, ledgerRepo).Reconcile()
+	assert.Nil(t, err)
+	assert.True(t, report.Consistent())
+	assert.Equal(t, 5, report.CheckedAccounts)
+	for _, balance := range ledgerRepo.balances {
+		assert.GreaterOrEqual(t, balance.Balance(), int64(0))
+	}
+
+	// without opening balances escrow of pre-journal block is driven negative
+	ledgerRepo.balances = testLedgerBalances(entries[3:])
+	report, err = NewLedgerReconciliationService(eventRepo, ledgerRepo).Reconcile()
+	assert.Nil(t, err)
+	assert.False(t, report.Consistent())
+	assert.Contains(t, report.Discrepancies, LedgerDiscrepancy{
+		Account:       EscrowLedgerAccount(testUserID),
+		LedgerBalance: -300,
+		StateBalance:  0,
+	})
+}
+
+type testUserAccountEventRepo struct {
+	events map[UserID][]UserAccountEvent
+}
+
+func (repo *testUserAccountEventRepo) FindAllByUserID(id UserID) ([]UserAccountEvent, error) {
+	return repo.events[id], nil
+}
+
+func (repo *testUserAccountEventRepo) FindAllUserIDs() ([]UserID, error) {
+	ids := make([]UserID, 0, len(repo.events))
+	for id := range repo.events {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+type testLedgerRepo struct {
+	entries            []*JournalEntry
+	balances           []LedgerAccountBalance
+	unbalancedEntryIDs []JournalEntryID
+}
+
+func (repo *testLedgerRepo) FindAccountBalances() ([]LedgerAccountBalance, error) {
+	return repo.balances, nil
+}
+
+func (repo *testLedgerRepo) FindUnbalancedEntryIDs() ([]JournalEntryID, error) {
+	return repo.unbalancedEntryIDs, nil
+}
+
+func testLedgerBalances(entries []*JournalEntry) []LedgerAccountBalance {
+	var accounts []LedgerAccount
+	debits := map[LedgerAccount]uint64{}
+	credits := map[LedgerAccount]uint64{}
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			if _, ok := debits[line.Account]; !ok {
+				accounts = append(accounts, line.Account)
+			}
+			debits[line.Account] += line.Debit.RawValue()
+			credits[line.Account] += line.Credit.RawValue()
+		}
+	}
+	balances := make([]LedgerAccountBalance, 0, len(accounts))
+	for _, account := range accounts {
+		balances = append(balances, LedgerAccountBalance{
+			Account: account,
+			Debit:   AmountFromRawValue(debits[account]),
+			Credit:  AmountFromRawValue(credits[account]),
+		})
+	}
+	return balances
+}
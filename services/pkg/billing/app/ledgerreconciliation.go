@@ -0,0 +1,108 @@
+package app
+
+func NewLedgerReconciliationService(eventRepoRead UserAccountEventRepositoryRead, ledgerRepoRead LedgerRepositoryRead) LedgerReconciliationService {
+	return &ledgerReconciliationService{
+		eventRepoRead:  eventRepoRead,
+		ledgerRepoRead: ledgerRepoRead,
+	}
+}
+
+type LedgerDiscrepancy struct {
+	Account       LedgerAccount
+	LedgerBalance int64
+	StateBalance  int64
+}
+
+type LedgerReconciliationReport struct {
+	CheckedAccounts   int
+	UnbalancedEntries []JournalEntryID
+	Discrepancies     []LedgerDiscrepancy
+}
+
+func (r LedgerReconciliationReport) Consistent() bool {
+	return len(r.UnbalancedEntries) == 0 && len(r.Discrepancies) == 0
+}
+
+type LedgerReconciliationService interface {
+	Reconcile() (LedgerReconciliationReport, error)
+}
+
+type ledgerReconciliationService struct {
+	eventRepoRead  UserAccountEventRepositoryRead
+	ledgerRepoRead LedgerRepositoryRead
+}
+
+func (s *ledgerReconciliationService) Reconcile() (LedgerReconciliationReport, error) {
+	var report LedgerReconciliationReport
+
+	unbalancedEntries, err := s.ledgerRepoRead.FindUnbalancedEntryIDs()
+	if err != nil {
+		return report, err
+	}
+	report.UnbalancedEntries = unbalancedEntries
+
+	accountBalances, err := s.ledgerRepoRead.FindAccountBalances()
+	if err != nil {
+		return report, err
+	}
+	ledgerBalances := make(map[LedgerAccount]int64, len(accountBalances))
+	for _, accountBalance := range accountBalances {
+		ledgerBalances[accountBalance.Account] = accountBalance.Balance()
+	}
+
+	stateBalances, err := s.stateBalances()
+	if err != nil {
+		return report, err
+	}
+
+	for account, stateBalance := range stateBalances {
+		report.CheckedAccounts++
+		if ledgerBalance := ledgerBalances[account]; ledgerBalance != stateBalance {
+			report.Discrepancies = append(report.Discrepancies, LedgerDiscrepancy{
+				Account:       account,
+				LedgerBalance: ledgerBalance,
+				StateBalance:  stateBalance,
+			})
+		}
+	}
+	for account, ledgerBalance := range ledgerBalances {
+		if _, ok := stateBalances[account]; ok || account.Type == ledgerAccountTypeExternal || ledgerBalance == 0 {
+			continue
+		}
+		report.CheckedAccounts++
+		report.Discrepancies = append(report.Discrepancies, LedgerDiscrepancy{
+			Account:       account,
+			LedgerBalance: ledgerBalance,
+			StateBalance:  0,
+		})
+	}
+	return report, nil
+}
+
+func (s *ledgerReconciliationService) stateBalances() (map[LedgerAccount]int64, error) {
+	userIDs, err := s.eventRepoRead.FindAllUserIDs()
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[LedgerAccount]int64, len(userIDs)*2)
+	for _, userID := range userIDs {
+		events, err := s.eventRepoRead.FindAllByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+		state := NewEmptyUserAccountState(userID)
+		if err = state.LoadEvents(events); err != nil {
+			return nil, err
+		}
+		if state.Amount() == nil {
+			continue
+		}
+		if userID == PlatformAccountID {
+			balances[PlatformLedgerAccount()] = int64(state.Amount().RawValue())
+			continue
+		}
+		balances[UserLedgerAccount(userID)] = int64(state.Amount().RawValue())
+		balances[EscrowLedgerAccount(userID)] = int64(state.BlockedAmount().RawValue())
+	}
+	return balances, nil
+}
@@ -0,0 +1,16 @@
+package app
+
+type LotStatus string
+
+const lotStatusReceived LotStatus = "received"
+
+type LotHighBid struct {
+	LotID         LotID
+	Status        LotStatus
+	HighBidderID  *UserID
+	HighBidAmount *Amount
+}
+
+type LotClient interface {
+	GetLotHighBid(lotID LotID) (highBid *LotHighBid, found bool, err error)
+}
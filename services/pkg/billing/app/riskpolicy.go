@@ -0,0 +1,106 @@
+package app
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const velocityWindow = time.Hour
+
+var ErrRiskRuleViolated = errors.New("operation rejected by risk rules")
+var ErrRiskHoldNotFound = errors.New("risk hold not found")
+
+type RiskRule string
+
+const (
+	maxBlockedAmountRiskRule RiskRule = "max_blocked_amount"
+	maxActiveBidsRiskRule    RiskRule = "max_active_bids"
+	topUpVelocityRiskRule    RiskRule = "top_up_velocity"
+	bidVelocityRiskRule      RiskRule = "bid_velocity"
+	userOnHoldRiskRule       RiskRule = "user_on_hold"
+	manualHoldRiskRule       RiskRule = "manual_hold"
+)
+
+// RiskLimits - zero or nil value of limit means that limit is not checked
+type RiskLimits struct {
+	MaxBlockedAmount *Amount
+	MaxActiveBids    int
+	MaxTopUpsPerHour int
+	MaxBidsPerHour   int
+}
+
+type RiskRuleHit struct {
+	UserID       UserID
+	LotID        *LotID
+	Rule         RiskRule
+	Amount       Amount
+	CreationTime time.Time
+}
+
+// RiskHold - user on hold can't make bids until hold is removed after review
+type RiskHold struct {
+	UserID       UserID
+	Reason       RiskRule
+	CreationTime time.Time
+}
+
+type RiskRepositoryRead interface {
+	FindHoldByUserID(userID UserID) (*RiskHold, error)
+	FindHolds() ([]RiskHold, error)
+	FindRuleHits(userID *UserID) ([]RiskRuleHit, error)
+}
+
+type RiskRepository interface {
+	RiskRepositoryRead
+	StoreHold(hold *RiskHold) error
+	RemoveHold(userID UserID) error
+	StoreRuleHit(hit *RiskRuleHit) error
+}
+
+func NewRiskPolicy(limits RiskLimits) RiskPolicy {
+	return RiskPolicy{limits: limits}
+}
+
+type RiskPolicy struct {
+	limits RiskLimits
+}
+
+func (p RiskPolicy) CheckBlockPayment(userID UserID, state UserAccountState, hold *RiskHold, lotID LotID, amount Amount, now time.Time) *RiskRuleHit {
+	newHit := func(rule RiskRule) *RiskRuleHit {
+		return &RiskRuleHit{UserID: userID, LotID: &lotID, Rule: rule, Amount: amount, CreationTime: now}
+	}
+	if hold != nil {
+		return newHit(userOnHoldRiskRule)
+	}
+	if p.limits.MaxBlockedAmount != nil && state.BlockedAmount().RawValue()+amount.RawValue() > (*p.limits.MaxBlockedAmount).RawValue() {
+		return newHit(maxBlockedAmountRiskRule)
+	}
+	if p.limits.MaxActiveBids != 0 && len(state.BlockedPayments()) >= p.limits.MaxActiveBids {
+		return newHit(maxActiveBidsRiskRule)
+	}
+	if p.limits.MaxBidsPerHour != 0 && state.EventCountSince(blockPaymentEventType, now.Add(-velocityWindow)) >= p.limits.MaxBidsPerHour {
+		return newHit(bidVelocityRiskRule)
+	}
+	return nil
+}
+
+func (p RiskPolicy) CheckTopUp(userID UserID, state UserAccountState, amount Amount, now time.Time) *RiskRuleHit {
+	if p.limits.MaxTopUpsPerHour != 0 && state.EventCountSince(topUpAccountEventType, now.Add(-velocityWindow)) >= p.limits.MaxTopUpsPerHour {
+		return &RiskRuleHit{UserID: userID, Rule: topUpVelocityRiskRule, Amount: amount, CreationTime: now}
+	}
+	return nil
+}
+
+// IsFraudSignal - velocity rule hits put user on hold, other limits only reject the operation
+func (h RiskRuleHit) IsFraudSignal() bool {
+	return h.Rule == topUpVelocityRiskRule || h.Rule == bidVelocityRiskRule
+}
+
+func (h RiskRuleHit) Err() error {
+	return errors.Wrapf(ErrRiskRuleViolated, "rule %s", h.Rule)
+}
+
+func NewManualRiskHold(userID UserID, now time.Time) RiskHold {
+	return RiskHold{UserID: userID, Reason: manualHoldRiskRule, CreationTime: now}
+}
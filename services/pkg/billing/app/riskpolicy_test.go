@@ -0,0 +1,114 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+	"time"
+)
+
+func TestRiskPolicyWithoutLimits(t *testing.T) {
+	now := time.Now()
+	state := loadTestRiskState(t, now, topUpEvent(100000, now), blockEvent(LotID(uuid.GenerateNew()), 90000, now))
+
+	policy := NewRiskPolicy(RiskLimits{})
+	assert.Nil(t, policy.CheckBlockPayment(testUserID, state, nil, testLotID, AmountFromRawValue(10000), now))
+	assert.Nil(t, policy.CheckTopUp(testUserID, state, AmountFromRawValue(10000), now))
+}
+
+func TestRiskPolicyMaxBlockedAmount(t *testing.T) {
+	now := time.Now()
+	state := loadTestRiskState(t, now, topUpEvent(100000, now), blockEvent(LotID(uuid.GenerateNew()), 9000, now))
+
+	policy := NewRiskPolicy(RiskLimits{MaxBlockedAmount: amountPtr(10000)})
+	assert.Nil(t, policy.CheckBlockPayment(testUserID, state, nil, testLotID, AmountFromRawValue(1000), now))
+
+	hit := policy.CheckBlockPayment(testUserID, state, nil, testLotID, AmountFromRawValue(1001), now)
+	assert.NotNil(t, hit)
+	assert.Equal(t, maxBlockedAmountRiskRule, hit.Rule)
+	assert.Equal(t, testLotID, *hit.LotID)
+	assert.False(t, hit.IsFraudSignal())
+	assert.ErrorIs(t, hit.Err(), ErrRiskRuleViolated)
+}
+
+func TestRiskPolicyMaxActiveBids(t *testing.T) {
+	now := time.Now()
+	state := loadTestRiskState(t, now,
+		topUpEvent(100000, now),
+		blockEvent(LotID(uuid.GenerateNew()), 100, now),
+		blockEvent(LotID(uuid.GenerateNew()), 100, now),
+	)
+
+	assert.Nil(t, NewRiskPolicy(RiskLimits{MaxActiveBids: 3}).CheckBlockPayment(testUserID, state, nil, testLotID, AmountFromRawValue(100), now))
+
+	hit := NewRiskPolicy(RiskLimits{MaxActiveBids: 2}).CheckBlockPayment(testUserID, state, nil, testLotID, AmountFromRawValue(100), now)
+	assert.NotNil(t, hit)
+	assert.Equal(t, maxActiveBidsRiskRule, hit.Rule)
+}
+
+func TestRiskPolicyBidVelocity(t *testing.T) {
+	now := time.Now()
+	oldLotID := LotID(uuid.GenerateNew())
+	recentLotID := LotID(uuid.GenerateNew())
+	state := loadTestRiskState(t, now,
+		topUpEvent(100000, now.Add(-2*time.Hour)),
+		blockEvent(oldLotID, 100, now.Add(-2*time.Hour)),
+		unblockEvent(oldLotID, 100, now.Add(-90*time.Minute)),
+		blockEvent(recentLotID, 100, now.Add(-30*time.Minute)),
+		unblockEvent(recentLotID, 100, now.Add(-20*time.Minute)),
+	)
+
+	// bids older than an hour are not counted, unblocked bids are counted
+	policy := NewRiskPolicy(RiskLimits{MaxBidsPerHour: 2})
+	assert.Nil(t, policy.CheckBlockPayment(testUserID, state, nil, testLotID, AmountFromRawValue(100), now))
+
+	policy = NewRiskPolicy(RiskLimits{MaxBidsPerHour: 1})
+	hit := policy.CheckBlockPayment(testUserID, state, nil, testLotID, AmountFromRawValue(100), now)
+	assert.NotNil(t, hit)
+	assert.Equal(t, bidVelocityRiskRule, hit.Rule)
+	assert.True(t, hit.IsFraudSignal())
+}
+
+func TestRiskPolicyTopUpVelocity(t *testing.T) {
+	now := time.Now()
+	state := loadTestRiskState(t, now,
+		topUpEvent(100, now.Add(-61*time.Minute)),
+		topUpEvent(100, now.Add(-59*time.Minute)),
+	)
+
+	assert.Nil(t, NewRiskPolicy(RiskLimits{MaxTopUpsPerHour: 2}).CheckTopUp(testUserID, state, AmountFromRawValue(100), now))
+
+	hit := NewRiskPolicy(RiskLimits{MaxTopUpsPerHour: 1}).CheckTopUp(testUserID, state, AmountFromRawValue(100), now)
+	assert.NotNil(t, hit)
+	assert.Equal(t, topUpVelocityRiskRule, hit.Rule)
+	assert.Nil(t, hit.LotID)
+	assert.True(t, hit.IsFraudSignal())
+}
+
+func TestRiskPolicyUserOnHold(t *testing.T) {
+	now := time.Now()
+	state := loadTestRiskState(t, now, topUpEvent(100000, now))
+	hold := NewManualRiskHold(testUserID, now)
+
+	hit := NewRiskPolicy(RiskLimits{}).CheckBlockPayment(testUserID, state, &hold, testLotID, AmountFromRawValue(100), now)
+	assert.NotNil(t, hit)
+	assert.Equal(t, userOnHoldRiskRule, hit.Rule)
+	assert.False(t, hit.IsFraudSignal())
+}
+
+func loadTestRiskState(t *testing.T, now time.Time, events ...UserAccountEvent) UserAccountState {
+	state := NewEmptyUserAccountState(testUserID)
+	createEvent := UserAccountEvent{UserID: testUserID, EventType: createAccountEventType, Amount: emptyAmount, CreationTime: now.Add(-24 * time.Hour)}
+	assert.Nil(t, state.LoadEvents(append([]UserAccountEvent{createEvent}, events...)))
+	return state
+}
+
+func topUpEvent(amount uint64, creationTime time.Time) UserAccountEvent {
+	return UserAccountEvent{UserID: testUserID, EventType: topUpAccountEventType, Amount: AmountFromRawValue(amount), CreationTime: creationTime}
+}
+
+func unblockEvent(lotID LotID, amount uint64, creationTime time.Time) UserAccountEvent {
+	return UserAccountEvent{UserID: testUserID, LotID: &lotID, EventType: unblockPaymentEventType, Amount: AmountFromRawValue(amount), CreationTime: creationTime}
+}
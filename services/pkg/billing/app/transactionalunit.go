@@ -2,8 +2,11 @@ package app
 
 type RepositoryProvider interface {
 	UserAccountEventRepository() UserAccountEventRepository
+	LedgerRepository() LedgerRepository
+	RiskRepository() RiskRepository
 	ProcessedEventRepository() ProcessedEventRepository
 	ProcessedRequestRepository() ProcessedRequestRepository
+	BalanceAdjustmentRepository() BalanceAdjustmentRepository
 }
 
 type TransactionalUnit interface {
@@ -2,8 +2,12 @@ package app
 
 import (
 	"arch-homework/pkg/common/app/uuid"
+
+	"time"
 )
 
+const PlatformAccountID = UserID("00000000-0000-0000-0000-000000000000")
+
 type UserID uuid.UUID
 type LotID uuid.UUID
 type AccountEventType string
@@ -15,6 +19,18 @@ const (
 	unblockPaymentEventType AccountEventType = "unblock_payment"
 	finishPaymentEventType  AccountEventType = "finish_payment"
 	receivePaymentEventType AccountEventType = "receive_payment"
+	payFeeEventType         AccountEventType = "pay_fee"
+	refundFeeEventType      AccountEventType = "refund_fee"
+	collectFeeEventType     AccountEventType = "collect_fee"
+	returnFeeEventType      AccountEventType = "return_fee"
+	releasePaymentEventType AccountEventType = "release_payment"
+	creditAdjustmentType    AccountEventType = "credit_adjustment"
+	debitAdjustmentType     AccountEventType = "debit_adjustment"
+
+	blockShippingPaymentEventType   AccountEventType = "block_shipping_payment"
+	unblockShippingPaymentEventType AccountEventType = "unblock_shipping_payment"
+	finishShippingPaymentEventType  AccountEventType = "finish_shipping_payment"
+	receiveShippingPaymentEventType AccountEventType = "receive_shipping_payment"
 )
 
 type UserAccountEvent struct {
@@ -22,10 +38,13 @@ type UserAccountEvent struct {
 	LotID     *LotID
 	EventType AccountEventType
 	Amount    Amount
+
+	CreationTime time.Time
 }
 
 type UserAccountEventRepositoryRead interface {
 	FindAllByUserID(id UserID) ([]UserAccountEvent, error)
+	FindAllUserIDs() ([]UserID, error)
 }
 
 type UserAccountEventRepository interface {
@@ -1,6 +1,10 @@
 package app
 
-import "github.com/pkg/errors"
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 var ErrUserAccountNotFound = errors.New("user account not found")
 var ErrAccountAlreadyCreated = errors.New("user account already created")
@@ -10,17 +14,32 @@ var ErrLotPaymentAlreadyBlocked = errors.New("payment for lot already blocked")
 var ErrLotIDNotSpecified = errors.New("lot id absent in event")
 var ErrUnblockPayment = errors.New("can't find matched blocked payment")
 var ErrFinishPayment = errors.New("can't find blocked payment to finish it")
+var ErrPayFee = errors.New("not enough funds to pay fee")
+var ErrRefundFee = errors.New("can't find paid fee to refund it")
+var ErrReturnFee = errors.New("can't find collected fee to return it")
+var ErrDebitAdjustment = errors.New("not enough available funds for debit adjustment")
+var ErrShippingPaymentAlreadyBlocked = errors.New("shipping payment for lot already blocked")
+var ErrUnblockShippingPayment = errors.New("can't find matched blocked shipping payment")
 
 func NewEmptyUserAccountState(userID UserID) UserAccountState {
 	return &userAccountState{
 		userID:              userID,
 		lotBlockedAmountMap: make(map[LotID]Amount),
+		lotFeeAmountMap:     make(map[LotID]Amount),
+		lotReleasedMap:      make(map[LotID]Amount),
+		lotShippingMap:      make(map[LotID]Amount),
+		eventTimesMap:       make(map[AccountEventType][]time.Time),
 	}
 }
 
 type UserAccountState interface {
 	Amount() Amount
 	BlockedAmount() Amount
+	LotFeeAmount(lotID LotID) Amount
+	BlockedPayments() map[LotID]Amount
+	ShippingPayment(lotID LotID) Amount
+	IsPaymentReleased(lotID LotID, amount Amount) bool
+	EventCountSince(eventType AccountEventType, since time.Time) int
 	AddedEvents() []UserAccountEvent
 
 	LoadEvents(events []UserAccountEvent) error
@@ -31,6 +50,17 @@ type UserAccountState interface {
 	AddUnblockPaymentEvent(lotID LotID, amount Amount) error
 	AddFinishPaymentEvent(lotID LotID, amount Amount) error
 	AddReceivePaymentEvent(lotID LotID, amount Amount) error
+	AddPayFeeEvent(lotID LotID, amount Amount) error
+	AddRefundFeeEvent(lotID LotID, amount Amount) error
+	AddCollectFeeEvent(lotID LotID, amount Amount) error
+	AddReturnFeeEvent(lotID LotID, amount Amount) error
+	AddReleasePaymentEvent(lotID LotID, amount Amount) error
+	AddCreditAdjustmentEvent(amount Amount) error
+	AddDebitAdjustmentEvent(amount Amount) error
+	AddBlockShippingPaymentEvent(lotID LotID, amount Amount) error
+	AddUnblockShippingPaymentEvent(lotID LotID, amount Amount) error
+	AddFinishShippingPaymentEvent(lotID LotID, amount Amount) error
+	AddReceiveShippingPaymentEvent(lotID LotID, amount Amount) error
 }
 
 type userAccountState struct {
@@ -38,7 +68,13 @@ type userAccountState struct {
 	totalAmount         Amount
 	blockedAmount       Amount
 	lotBlockedAmountMap map[LotID]Amount
-	addedEvents         []UserAccountEvent
+	lotFeeAmountMap     map[LotID]Amount
+	lotReleasedMap      map[LotID]Amount
+	// lotShippingMap - blocked shipping payments, they are kept apart from lot payments,
+	// because lot payment is blocked and released by bids
+	lotShippingMap map[LotID]Amount
+	eventTimesMap  map[AccountEventType][]time.Time
+	addedEvents    []UserAccountEvent
 }
 
 func (state *userAccountState) Amount() Amount {
@@ -52,6 +88,44 @@ func (state *userAccountState) BlockedAmount() Amount {
 	return state.blockedAmount
 }
 
+func (state *userAccountState) LotFeeAmount(lotID LotID) Amount {
+	if amount, ok := state.lotFeeAmountMap[lotID]; ok {
+		return amount
+	}
+	return AmountFromRawValue(0)
+}
+
+func (state *userAccountState) BlockedPayments() map[LotID]Amount {
+	res := make(map[LotID]Amount, len(state.lotBlockedAmountMap))
+	for lotID, amount := range state.lotBlockedAmountMap {
+		res[lotID] = amount
+	}
+	return res
+}
+
+func (state *userAccountState) ShippingPayment(lotID LotID) Amount {
+	if amount, ok := state.lotShippingMap[lotID]; ok {
+		return amount
+	}
+	return AmountFromRawValue(0)
+}
+
+func (state *userAccountState) IsPaymentReleased(lotID LotID, amount Amount) bool {
+	releasedAmount, ok := state.lotReleasedMap[lotID]
+	return ok && releasedAmount.RawValue() == amount.RawValue()
+}
+
+// EventCountSince counts only loaded events, because creation time of added events is not known yet
+func (state *userAccountState) EventCountSince(eventType AccountEventType, since time.Time) int {
+	count := 0
+	for _, creationTime := range state.eventTimesMap[eventType] {
+		if !creationTime.Before(since) {
+			count++
+		}
+	}
+	return count
+}
+
 func (state *userAccountState) AddedEvents() []UserAccountEvent {
 	return state.addedEvents
 }
@@ -62,6 +136,7 @@ func (state *userAccountState) LoadEvents(events []UserAccountEvent) error {
 		if err != nil {
 			return err
 		}
+		state.eventTimesMap[event.EventType] = append(state.eventTimesMap[event.EventType], event.CreationTime)
 	}
 	return nil
 }
@@ -124,6 +199,114 @@ func (state *userAccountState) AddReceivePaymentEvent(lotID LotID, amount Amount
 	return state.addEvent(event)
 }
 
+func (state *userAccountState) AddPayFeeEvent(lotID LotID, amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: payFeeEventType,
+		LotID:     &lotID,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
+func (state *userAccountState) AddRefundFeeEvent(lotID LotID, amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: refundFeeEventType,
+		LotID:     &lotID,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
+func (state *userAccountState) AddCollectFeeEvent(lotID LotID, amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: collectFeeEventType,
+		LotID:     &lotID,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
+func (state *userAccountState) AddReturnFeeEvent(lotID LotID, amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: returnFeeEventType,
+		LotID:     &lotID,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
+func (state *userAccountState) AddReleasePaymentEvent(lotID LotID, amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: releasePaymentEventType,
+		LotID:     &lotID,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
+func (state *userAccountState) AddCreditAdjustmentEvent(amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: creditAdjustmentType,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
+func (state *userAccountState) AddDebitAdjustmentEvent(amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: debitAdjustmentType,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
+func (state *userAccountState) AddBlockShippingPaymentEvent(lotID LotID, amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: blockShippingPaymentEventType,
+		LotID:     &lotID,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
+func (state *userAccountState) AddUnblockShippingPaymentEvent(lotID LotID, amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: unblockShippingPaymentEventType,
+		LotID:     &lotID,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
+func (state *userAccountState) AddFinishShippingPaymentEvent(lotID LotID, amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: finishShippingPaymentEventType,
+		LotID:     &lotID,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
+func (state *userAccountState) AddReceiveShippingPaymentEvent(lotID LotID, amount Amount) error {
+	event := UserAccountEvent{
+		UserID:    state.userID,
+		EventType: receiveShippingPaymentEventType,
+		LotID:     &lotID,
+		Amount:    amount,
+	}
+	return state.addEvent(event)
+}
+
 func (state *userAccountState) addEvent(event UserAccountEvent) error {
 	err := state.applyEvent(event)
 	if err != nil {
@@ -161,6 +344,55 @@ func (state *userAccountState) applyEvent(event UserAccountEvent) error {
 			return errors.WithStack(ErrLotIDNotSpecified)
 		}
 		return state.applyReceivePaymentEvent(*event.LotID, amount)
+	case payFeeEventType:
+		if event.LotID == nil {
+			return errors.WithStack(ErrLotIDNotSpecified)
+		}
+		return state.applyPayFeeEvent(*event.LotID, amount)
+	case refundFeeEventType:
+		if event.LotID == nil {
+			return errors.WithStack(ErrLotIDNotSpecified)
+		}
+		return state.applyRefundFeeEvent(*event.LotID, amount)
+	case collectFeeEventType:
+		if event.LotID == nil {
+			return errors.WithStack(ErrLotIDNotSpecified)
+		}
+		return state.applyCollectFeeEvent(*event.LotID, amount)
+	case returnFeeEventType:
+		if event.LotID == nil {
+			return errors.WithStack(ErrLotIDNotSpecified)
+		}
+		return state.applyReturnFeeEvent(*event.LotID, amount)
+	case releasePaymentEventType:
+		if event.LotID == nil {
+			return errors.WithStack(ErrLotIDNotSpecified)
+		}
+		return state.applyReleasePaymentEvent(*event.LotID, amount)
+	case blockShippingPaymentEventType:
+		if event.LotID == nil {
+			return errors.WithStack(ErrLotIDNotSpecified)
+		}
+		return state.applyBlockShippingPaymentEvent(*event.LotID, amount)
+	case unblockShippingPaymentEventType:
+		if event.LotID == nil {
+			return errors.WithStack(ErrLotIDNotSpecified)
+		}
+		return state.applyUnblockShippingPaymentEvent(*event.LotID, amount)
+	case finishShippingPaymentEventType:
+		if event.LotID == nil {
+			return errors.WithStack(ErrLotIDNotSpecified)
+		}
+		return state.applyFinishShippingPaymentEvent(*event.LotID, amount)
+	case receiveShippingPaymentEventType:
+		if event.LotID == nil {
+			return errors.WithStack(ErrLotIDNotSpecified)
+		}
+		return state.applyReceivePaymentEvent(*event.LotID, amount)
+	case creditAdjustmentType:
+		return state.applyTopUpAccountEvent(amount)
+	case debitAdjustmentType:
+		return state.applyDebitAdjustmentEvent(amount)
 	default:
 		return errors.WithStack(errors.Errorf("unknown event type - '%s'", event.EventType))
 	}
@@ -215,6 +447,15 @@ func (state *userAccountState) applyUnblockPaymentEvent(lotID LotID, amount Amou
 	return nil
 }
 
+func (state *userAccountState) applyReleasePaymentEvent(lotID LotID, amount Amount) error {
+	err := state.applyUnblockPaymentEvent(lotID, amount)
+	if err != nil {
+		return err
+	}
+	state.lotReleasedMap[lotID] = amount
+	return nil
+}
+
 func (state *userAccountState) applyFinishPaymentEvent(lotID LotID, amount Amount) error {
 	if state.totalAmount == nil || state.blockedAmount == nil {
 		return errors.WithStack(ErrUserAccountNotFound)
@@ -236,3 +477,116 @@ func (state *userAccountState) applyReceivePaymentEvent(_ LotID, amount Amount)
 	state.totalAmount = AmountFromRawValue(state.totalAmount.RawValue() + amount.RawValue())
 	return nil
 }
+
+func (state *userAccountState) applyPayFeeEvent(lotID LotID, amount Amount) error {
+	if state.totalAmount == nil || state.blockedAmount == nil {
+		return errors.WithStack(ErrUserAccountNotFound)
+	}
+	if amount.RawValue() == 0 {
+		return errors.WithStack(ErrEmptyPayment)
+	}
+	if amount.RawValue() > state.Amount().RawValue() {
+		return errors.WithStack(ErrPayFee)
+	}
+	state.totalAmount = AmountFromRawValue(state.totalAmount.RawValue() - amount.RawValue())
+	state.lotFeeAmountMap[lotID] = AmountFromRawValue(state.LotFeeAmount(lotID).RawValue() + amount.RawValue())
+	return nil
+}
+
+func (state *userAccountState) applyRefundFeeEvent(lotID LotID, amount Amount) error {
+	if state.totalAmount == nil || state.blockedAmount == nil {
+		return errors.WithStack(ErrUserAccountNotFound)
+	}
+	lotFeeAmount := state.LotFeeAmount(lotID)
+	if amount.RawValue() == 0 || lotFeeAmount.RawValue() < amount.RawValue() {
+		return errors.WithStack(ErrRefundFee)
+	}
+	state.totalAmount = AmountFromRawValue(state.totalAmount.RawValue() + amount.RawValue())
+	state.setLotFeeAmount(lotID, lotFeeAmount.RawValue()-amount.RawValue())
+	return nil
+}
+
+func (state *userAccountState) applyCollectFeeEvent(lotID LotID, amount Amount) error {
+	if state.totalAmount == nil || state.blockedAmount == nil {
+		return errors.WithStack(ErrUserAccountNotFound)
+	}
+	state.totalAmount = AmountFromRawValue(state.totalAmount.RawValue() + amount.RawValue())
+	state.lotFeeAmountMap[lotID] = AmountFromRawValue(state.LotFeeAmount(lotID).RawValue() + amount.RawValue())
+	return nil
+}
+
+func (state *userAccountState) applyReturnFeeEvent(lotID LotID, amount Amount) error {
+	if state.totalAmount == nil || state.blockedAmount == nil {
+		return errors.WithStack(ErrUserAccountNotFound)
+	}
+	lotFeeAmount := state.LotFeeAmount(lotID)
+	if amount.RawValue() == 0 || lotFeeAmount.RawValue() < amount.RawValue() || state.Amount().RawValue() < amount.RawValue() {
+		return errors.WithStack(ErrReturnFee)
+	}
+	state.totalAmount = AmountFromRawValue(state.totalAmount.RawValue() - amount.RawValue())
+	state.setLotFeeAmount(lotID, lotFeeAmount.RawValue()-amount.RawValue())
+	return nil
+}
+
+func (state *userAccountState) applyBlockShippingPaymentEvent(lotID LotID, amount Amount) error {
+	if state.totalAmount == nil || state.blockedAmount == nil {
+		return errors.WithStack(ErrUserAccountNotFound)
+	}
+	if amount.RawValue() == 0 {
+		return errors.WithStack(ErrEmptyPayment)
+	}
+	if amount.RawValue() > state.Amount().RawValue() {
+		return errors.WithStack(ErrBlockPayment)
+	}
+	if _, ok := state.lotShippingMap[lotID]; ok {
+		return errors.WithStack(ErrShippingPaymentAlreadyBlocked)
+	}
+	state.blockedAmount = AmountFromRawValue(state.blockedAmount.RawValue() + amount.RawValue())
+	state.lotShippingMap[lotID] = amount
+	return nil
+}
+
+func (state *userAccountState) applyUnblockShippingPaymentEvent(lotID LotID, amount Amount) error {
+	if state.totalAmount == nil || state.blockedAmount == nil {
+		return errors.WithStack(ErrUserAccountNotFound)
+	}
+	shippingAmount, ok := state.lotShippingMap[lotID]
+	if !ok || shippingAmount.RawValue() != amount.RawValue() || state.blockedAmount.RawValue() < amount.RawValue() {
+		return errors.WithStack(ErrUnblockShippingPayment)
+	}
+	state.blockedAmount = AmountFromRawValue(state.blockedAmount.RawValue() - amount.RawValue())
+	delete(state.lotShippingMap, lotID)
+	return nil
+}
+
+func (state *userAccountState) applyFinishShippingPaymentEvent(lotID LotID, amount Amount) error {
+	err := state.applyUnblockShippingPaymentEvent(lotID, amount)
+	if err != nil {
+		return err
+	}
+	state.totalAmount = AmountFromRawValue(state.totalAmount.RawValue() - amount.RawValue())
+	return nil
+}
+
+// applyDebitAdjustmentEvent - blocked funds can't be debited, because they are reserved for lot payments
+func (state *userAccountState) applyDebitAdjustmentEvent(amount Amount) error {
+	if state.totalAmount == nil || state.blockedAmount == nil {
+		return errors.WithStack(ErrUserAccountNotFound)
+	}
+	if amount.RawValue() == 0 {
+		return errors.WithStack(ErrEmptyPayment)
+	}
+	if amount.RawValue() > state.Amount().RawValue() {
+		return errors.WithStack(ErrDebitAdjustment)
+	}
+	state.totalAmount = AmountFromRawValue(state.totalAmount.RawValue() - amount.RawValue())
+	return nil
+}
+
+func (state *userAccountState) setLotFeeAmount(lotID LotID, rawAmount uint64) {
+	if rawAmount == 0 {
+		delete(state.lotFeeAmountMap, lotID)
+		return
+	}
+	state.lotFeeAmountMap[lotID] = AmountFromRawValue(rawAmount)
+}
@@ -217,6 +217,73 @@ func TestPaymentReceivedEvent(t *testing.T) {
 	}, addedEvents[0])
 }
 
+func TestPayAndRefundFeeEvents(t *testing.T) {
+	state := createdOnlyState(t)
+	feeAmount := AmountFromRawValue(100)
+
+	assert.Nil(t, state.AddTopUpAccountEvent(AmountFromRawValue(1000)))
+	assert.Nil(t, state.AddBlockPaymentEvent(testLotID, AmountFromRawValue(850)))
+	assert.Nil(t, state.AddPayFeeEvent(testLotID, feeAmount))
+
+	assert.Equal(t, AmountFromRawValue(50), state.Amount())
+	assert.Equal(t, feeAmount, state.LotFeeAmount(testLotID))
+
+	err := state.AddPayFeeEvent(testLotID, feeAmount)
+	assert.Equal(t, ErrPayFee, errors.Cause(err))
+
+	err = state.AddRefundFeeEvent(testLotID, AmountFromRawValue(101))
+	assert.Equal(t, ErrRefundFee, errors.Cause(err))
+
+	assert.Nil(t, state.AddRefundFeeEvent(testLotID, feeAmount))
+	assert.Equal(t, AmountFromRawValue(150), state.Amount())
+	assert.Equal(t, emptyAmount, state.LotFeeAmount(testLotID))
+
+	addedEvents := state.AddedEvents()
+	assert.Len(t, addedEvents, 4)
+	assert.Equal(t, UserAccountEvent{
+		UserID:    testUserID,
+		LotID:     &testLotID,
+		EventType: payFeeEventType,
+		Amount:    feeAmount,
+	}, addedEvents[2])
+	assert.Equal(t, refundFeeEventType, addedEvents[3].EventType)
+}
+
+func TestCollectAndReturnFeeEvents(t *testing.T) {
+	state := createdOnlyState(t)
+	feeAmount := AmountFromRawValue(100)
+
+	err := state.AddReturnFeeEvent(testLotID, feeAmount)
+	assert.Equal(t, ErrReturnFee, errors.Cause(err))
+
+	assert.Nil(t, state.AddCollectFeeEvent(testLotID, feeAmount))
+	assert.Equal(t, feeAmount, state.Amount())
+	assert.Equal(t, feeAmount, state.LotFeeAmount(testLotID))
+
+	assert.Nil(t, state.AddReturnFeeEvent(testLotID, feeAmount))
+	assert.Equal(t, emptyAmount, state.Amount())
+	assert.Equal(t, emptyAmount, state.LotFeeAmount(testLotID))
+}
+
+func TestReleasePaymentEvent(t *testing.T) {
+	state := createdOnlyState(t)
+	paymentAmount := AmountFromRawValue(1000)
+
+	assert.Nil(t, state.AddTopUpAccountEvent(AmountFromRawValue(1000)))
+	assert.Nil(t, state.AddBlockPaymentEvent(testLotID, paymentAmount))
+	assert.Equal(t, map[LotID]Amount{testLotID: paymentAmount}, state.BlockedPayments())
+	assert.False(t, state.IsPaymentReleased(testLotID, paymentAmount))
+
+	assert.Nil(t, state.AddReleasePaymentEvent(testLotID, paymentAmount))
+	assert.Equal(t, paymentAmount, state.Amount())
+	assert.Empty(t, state.BlockedPayments())
+	assert.True(t, state.IsPaymentReleased(testLotID, paymentAmount))
+	assert.False(t, state.IsPaymentReleased(testLotID, AmountFromRawValue(999)))
+
+	err := state.AddUnblockPaymentEvent(testLotID, paymentAmount)
+	assert.Equal(t, ErrUnblockPayment, errors.Cause(err))
+}
+
 func createdOnlyState(t *testing.T) UserAccountState {
 	state := NewEmptyUserAccountState(testUserID)
 	assert.Nil(t, state.LoadEvents([]UserAccountEvent{{
@@ -228,3 +295,39 @@ func createdOnlyState(t *testing.T) UserAccountState {
 	assert.Empty(t, state.AddedEvents())
 	return state
 }
+
+func TestAdjustmentEvents(t *testing.T) {
+	state := createdOnlyState(t)
+	assert.Nil(t, state.AddCreditAdjustmentEvent(AmountFromRawValue(1000)))
+	assert.Nil(t, state.AddBlockPaymentEvent(testLotID, AmountFromRawValue(600)))
+
+	// blocked funds can't be debited
+	err := state.AddDebitAdjustmentEvent(AmountFromRawValue(500))
+	assert.Equal(t, ErrDebitAdjustment, errors.Cause(err))
+
+	assert.Nil(t, state.AddDebitAdjustmentEvent(AmountFromRawValue(400)))
+	assert.Equal(t, emptyAmount, state.Amount())
+	assert.Equal(t, AmountFromRawValue(600), state.BlockedAmount())
+}
+
+func TestShippingPaymentEvents(t *testing.T) {
+	state := createdOnlyState(t)
+	assert.Nil(t, state.AddTopUpAccountEvent(AmountFromRawValue(1000)))
+	assert.Nil(t, state.AddBlockPaymentEvent(testLotID, AmountFromRawValue(600)))
+	assert.Nil(t, state.AddBlockShippingPaymentEvent(testLotID, AmountFromRawValue(300)))
+	assert.Equal(t, AmountFromRawValue(100), state.Amount())
+	assert.Equal(t, AmountFromRawValue(900), state.BlockedAmount())
+	assert.Equal(t, AmountFromRawValue(300), state.ShippingPayment(testLotID))
+	assert.Equal(t, map[LotID]Amount{testLotID: AmountFromRawValue(600)}, state.BlockedPayments())
+
+	err := state.AddBlockShippingPaymentEvent(testLotID, AmountFromRawValue(50))
+	assert.Equal(t, ErrShippingPaymentAlreadyBlocked, errors.Cause(err))
+	err = state.AddUnblockShippingPaymentEvent(testLotID, AmountFromRawValue(200))
+	assert.Equal(t, ErrUnblockShippingPayment, errors.Cause(err))
+
+	assert.Nil(t, state.AddFinishPaymentEvent(testLotID, AmountFromRawValue(600)))
+	assert.Nil(t, state.AddFinishShippingPaymentEvent(testLotID, AmountFromRawValue(300)))
+	assert.Equal(t, AmountFromRawValue(100), state.Amount())
+	assert.Equal(t, emptyAmount, state.BlockedAmount())
+	assert.Equal(t, emptyAmount, state.ShippingPayment(testLotID))
+}
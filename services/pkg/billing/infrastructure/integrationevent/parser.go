@@ -14,6 +14,8 @@ const typeUserRegistered = "user.user_registered"
 const typeLotBidOutbid = "lot.bid_outbid"
 const typeLotBidCancelled = "lot.bid_cancelled"
 const typeLotReceived = "lot.lot_received"
+const typeLotCreationCancelled = "lot.lot_creation_cancelled"
+const typeDeliveryLotCancelled = "delivery.lot_cancelled"
 
 func NewEventParser() app.IntegrationEventParser {
 	return eventParser{}
@@ -32,6 +34,10 @@ func (e eventParser) ParseIntegrationEvent(event integrationevent.EventData) (ap
 		return parseLotBidCancelledEvent(event.Body)
 	case typeLotReceived:
 		return parseLotReceivedEvent(event.Body)
+	case typeLotCreationCancelled:
+		return parseLotCreationCancelledEvent(event.Body)
+	case typeDeliveryLotCancelled:
+		return parseDeliveryLotCancelledEvent(event.Body)
 	default:
 		return nil, nil
 	}
@@ -105,6 +111,40 @@ func parseLotReceivedEvent(strBody string) (app.UserEvent, error) {
 	return app.NewLotReceivedEvent(app.UserID(body.UserID), app.LotID(body.LotID), app.UserID(body.LotOwnerID), app.AmountFromRawValue(body.FinalAmount)), nil
 }
 
+func parseLotCreationCancelledEvent(strBody string) (app.UserEvent, error) {
+	var body lotCreationCancelledEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.LotID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.LotOwnerID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return app.NewLotCreationCancelledEvent(app.LotID(body.LotID), app.UserID(body.LotOwnerID)), nil
+}
+
+func parseDeliveryLotCancelledEvent(strBody string) (app.UserEvent, error) {
+	var body deliveryLotCancelledEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.LotID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return app.NewDeliveryLotCancelledEvent(app.UserID(body.UserID), app.LotID(body.LotID)), nil
+}
+
 type userRegisteredEventBody struct {
 	UserID string `json:"user_id"`
 	Login  string `json:"login"`
@@ -128,3 +168,13 @@ type lotReceivedEventBody struct {
 	LotOwnerID  string `json:"lot_owner_id"`
 	FinalAmount uint64 `json:"final_amount"`
 }
+
+type lotCreationCancelledEventBody struct {
+	LotID      string `json:"lot_id"`
+	LotOwnerID string `json:"lot_owner_id"`
+}
+
+type deliveryLotCancelledEventBody struct {
+	UserID string `json:"user_id"`
+	LotID  string `json:"lot_id"`
+}
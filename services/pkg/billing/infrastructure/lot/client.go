@@ -0,0 +1,66 @@
+package lot
+
+import (
+	"arch-homework/pkg/billing/app"
+	"arch-homework/pkg/common/infrastructure/httpclient"
+
+	"github.com/pkg/errors"
+
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+)
+
+const lotHighBidURLTpl = "/internal/api/v1/lot/%s/highbid"
+
+// errorCodeLotNotFound - error code of lot service, 404 without it could be returned by ingress or incompatible service version
+const errorCodeLotNotFound = 3
+
+func NewClient(client http.Client, serviceHost string) app.LotClient {
+	return &lotClient{httpClient: httpclient.NewClient(client, serviceHost)}
+}
+
+type lotClient struct {
+	httpClient httpclient.Client
+}
+
+func (c *lotClient) GetLotHighBid(lotID app.LotID) (highBid *app.LotHighBid, found bool, err error) {
+	var response lotHighBidResponse
+	err = c.httpClient.MakeJSONRequest(nil, &response, http.MethodGet, fmt.Sprintf(lotHighBidURLTpl, string(lotID)), nil)
+	if err != nil {
+		if e, ok := errors.Cause(err).(*httpclient.HTTPError); ok && e.StatusCode == http.StatusNotFound {
+			var info errorInfo
+			if json.Unmarshal([]byte(e.Body), &info) == nil && info.Code == errorCodeLotNotFound {
+				return nil, false, nil
+			}
+		}
+		return nil, false, err
+	}
+
+	highBid = &app.LotHighBid{
+		LotID:  app.LotID(response.ID),
+		Status: app.LotStatus(response.Status),
+	}
+	if response.HighBidderID != "" {
+		bidderID := app.UserID(response.HighBidderID)
+		highBid.HighBidderID = &bidderID
+	}
+	if response.HighBidAmount != 0 {
+		amount := app.AmountFromRawValue(uint64(math.Round(response.HighBidAmount * 100)))
+		highBid.HighBidAmount = &amount
+	}
+	return highBid, true, nil
+}
+
+type errorInfo struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lotHighBidResponse struct {
+	ID            string  `json:"id"`
+	Status        string  `json:"status"`
+	HighBidderID  string  `json:"highBidderId"`
+	HighBidAmount float64 `json:"highBidAmount"`
+}
@@ -0,0 +1,43 @@
+package lot
+
+import (
+	"arch-homework/pkg/billing/app"
+
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientTreatsOnlyLotServiceNotFoundErrorAsMissingLot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/internal/api/v1/lot/deleted/highbid":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"code": 3, "message": "lot not found"}`)
+		case "/internal/api/v1/lot/active/highbid":
+			_, _ = io.WriteString(w, `{"id": "active", "status": "active", "highBidderId": "user", "highBidAmount": 12.5}`)
+		default:
+			// route is unknown to ingress
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(http.Client{}, server.URL)
+
+	_, found, err := client.GetLotHighBid("deleted")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	highBid, found, err := client.GetLotHighBid("active")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, app.UserID("user"), *highBid.HighBidderID)
+	assert.Equal(t, uint64(1250), (*highBid.HighBidAmount).RawValue())
+
+	_, found, err = client.GetLotHighBid("unknown")
+	assert.Error(t, err, "404 without lot service error code doesn't release blocked payment")
+	assert.False(t, found)
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"arch-homework/pkg/billing/app"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func NewBlockedPaymentReconciliationMetrics() (app.BlockedPaymentReconciliationMetrics, error) {
+	discrepancyGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "billing_blocked_payment_discrepancies",
+		Help: "Blocked payments which do not match lot high bids",
+	}, []string{"kind"})
+	if err := prometheus.Register(discrepancyGauge); err != nil {
+		return nil, err
+	}
+
+	healedCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "billing_blocked_payment_healed_count",
+		Help: "Blocked payments released by reconciliation",
+	})
+	if err := prometheus.Register(healedCounter); err != nil {
+		return nil, err
+	}
+
+	failedUserGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "billing_blocked_payment_failed_users",
+		Help: "Users whose blocked payments could not be reconciled in the last run",
+	})
+	if err := prometheus.Register(failedUserGauge); err != nil {
+		return nil, err
+	}
+
+	return &blockedPaymentReconciliationMetrics{
+		discrepancyGauge: discrepancyGauge,
+		healedCounter:    healedCounter,
+		failedUserGauge:  failedUserGauge,
+	}, nil
+}
+
+type blockedPaymentReconciliationMetrics struct {
+	discrepancyGauge *prometheus.GaugeVec
+	healedCounter    prometheus.Counter
+	failedUserGauge  prometheus.Gauge
+}
+
+func (m *blockedPaymentReconciliationMetrics) SetDiscrepancyCount(kind app.BlockedPaymentDiscrepancyKind, count int) {
+	m.discrepancyGauge.WithLabelValues(string(kind)).Set(float64(count))
+}
+
+func (m *blockedPaymentReconciliationMetrics) IncHealedCount(count int) {
+	m.healedCounter.Add(float64(count))
+}
+
+func (m *blockedPaymentReconciliationMetrics) SetFailedUserCount(count int) {
+	m.failedUserGauge.Set(float64(count))
+}
@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"arch-homework/pkg/billing/app"
+	"arch-homework/pkg/common/infrastructure/postgres"
+
+	"github.com/pkg/errors"
+)
+
+func NewBalanceAdjustmentRepository(client postgres.Client) app.BalanceAdjustmentRepository {
+	return &balanceAdjustmentRepository{client: client}
+}
+
+type balanceAdjustmentRepository struct {
+	client postgres.Client
+}
+
+func (repo *balanceAdjustmentRepository) Store(adjustment *app.BalanceAdjustment) error {
+	const query = `
+			INSERT INTO balance_adjustment (user_id, direction, amount, reason, actor_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+	_, err := repo.client.Exec(
+		query,
+		string(adjustment.UserID),
+		string(adjustment.Direction),
+		adjustment.Amount.RawValue(),
+		adjustment.Reason,
+		string(adjustment.ActorID),
+		adjustment.CreationTime,
+	)
+	return errors.WithStack(err)
+}
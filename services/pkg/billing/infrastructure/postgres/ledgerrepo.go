@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"arch-homework/pkg/billing/app"
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func NewLedgerRepository(client postgres.Client) app.LedgerRepository {
+	return &ledgerRepository{client: client}
+}
+
+type ledgerRepository struct {
+	client postgres.Client
+}
+
+func (repo *ledgerRepository) Store(entry *app.JournalEntry) error {
+	const entryQuery = `INSERT INTO journal_entry (operation, lot_id) VALUES ($1, $2) RETURNING id`
+	const lineQuery = `
+			INSERT INTO journal_entry_line (entry_id, account_type, user_id, debit, credit)
+			VALUES (:entry_id, :account_type, :user_id, :debit, :credit)
+		`
+
+	var lotID sql.NullString
+	if entry.LotID != nil {
+		lotID.String = string(*entry.LotID)
+		lotID.Valid = true
+	}
+	var entryID int64
+	err := repo.client.Get(&entryID, entryQuery, string(entry.Operation), lotID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, line := range entry.Lines {
+		sqlxLine := sqlxJournalEntryLine{
+			EntryID:     entryID,
+			AccountType: string(line.Account.Type),
+			Debit:       line.Debit.RawValue(),
+			Credit:      line.Credit.RawValue(),
+		}
+		if line.Account.UserID != "" {
+			sqlxLine.UserID.String = string(line.Account.UserID)
+			sqlxLine.UserID.Valid = true
+		}
+		_, err = repo.client.NamedExec(lineQuery, &sqlxLine)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (repo *ledgerRepository) FindAccountBalances() ([]app.LedgerAccountBalance, error) {
+	const query = `
+			SELECT account_type, user_id, SUM(debit) AS debit, SUM(credit) AS credit
+			FROM journal_entry_line
+			GROUP BY account_type, user_id
+		`
+
+	var balances []*sqlxLedgerAccountBalance
+	err := repo.client.Select(&balances, query)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.LedgerAccountBalance, 0, len(balances))
+	for _, balance := range balances {
+		res = append(res, app.LedgerAccountBalance{
+			Account: app.NewLedgerAccount(app.LedgerAccountType(balance.AccountType), app.UserID(balance.UserID.String)),
+			Debit:   app.AmountFromRawValue(balance.Debit),
+			Credit:  app.AmountFromRawValue(balance.Credit),
+		})
+	}
+	return res, nil
+}
+
+func (repo *ledgerRepository) FindUnbalancedEntryIDs() ([]app.JournalEntryID, error) {
+	const query = `
+			SELECT je.id
+			FROM journal_entry je
+			LEFT JOIN journal_entry_line jel ON jel.entry_id = je.id
+			GROUP BY je.id
+			HAVING COALESCE(SUM(jel.debit), 0) <> COALESCE(SUM(jel.credit), 0) OR COUNT(jel.id) < 2
+			ORDER BY je.id
+		`
+
+	var ids []int64
+	err := repo.client.Select(&ids, query)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.JournalEntryID, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, app.JournalEntryID(id))
+	}
+	return res, nil
+}
+
+type sqlxJournalEntryLine struct {
+	EntryID     int64          `db:"entry_id"`
+	AccountType string         `db:"account_type"`
+	UserID      sql.NullString `db:"user_id"`
+	Debit       uint64         `db:"debit"`
+	Credit      uint64         `db:"credit"`
+}
+
+type sqlxLedgerAccountBalance struct {
+	AccountType string         `db:"account_type"`
+	UserID      sql.NullString `db:"user_id"`
+	Debit       uint64         `db:"debit"`
+	Credit      uint64         `db:"credit"`
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"arch-homework/pkg/billing/app"
+	"arch-homework/pkg/common/infrastructure/postgres"
+
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func NewRiskRepository(client postgres.Client) app.RiskRepository {
+	return &riskRepository{client: client}
+}
+
+type riskRepository struct {
+	client postgres.Client
+}
+
+func (repo *riskRepository) StoreHold(hold *app.RiskHold) error {
+	const query = `
+			INSERT INTO risk_hold (user_id, reason, created_at) VALUES ($1, $2, $3)
+			ON CONFLICT (user_id) DO NOTHING
+		`
+	_, err := repo.client.Exec(query, string(hold.UserID), string(hold.Reason), hold.CreationTime)
+	return errors.WithStack(err)
+}
+
+func (repo *riskRepository) RemoveHold(userID app.UserID) error {
+	const query = `DELETE FROM risk_hold WHERE user_id = $1`
+	_, err := repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
+
+func (repo *riskRepository) StoreRuleHit(hit *app.RiskRuleHit) error {
+	const query = `
+			INSERT INTO risk_rule_hit (user_id, lot_id, rule, amount, created_at)
+			VALUES (:user_id, :lot_id, :rule, :amount, :created_at)
+		`
+	sqlxHit := sqlxRiskRuleHit{
+		UserID:       string(hit.UserID),
+		Rule:         string(hit.Rule),
+		Amount:       hit.Amount.RawValue(),
+		CreationTime: hit.CreationTime,
+	}
+	if hit.LotID != nil {
+		sqlxHit.LotID.String = string(*hit.LotID)
+		sqlxHit.LotID.Valid = true
+	}
+	_, err := repo.client.NamedExec(query, &sqlxHit)
+	return errors.WithStack(err)
+}
+
+func (repo *riskRepository) FindHoldByUserID(userID app.UserID) (*app.RiskHold, error) {
+	const query = `SELECT user_id, reason, created_at FROM risk_hold WHERE user_id = $1`
+
+	var hold sqlxRiskHold
+	err := repo.client.Get(&hold, query, string(userID))
+	if err == sql.ErrNoRows {
+		return nil, errors.WithStack(app.ErrRiskHoldNotFound)
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := sqlxRiskHoldToRiskHold(hold)
+	return &res, nil
+}
+
+func (repo *riskRepository) FindHolds() ([]app.RiskHold, error) {
+	const query = `SELECT user_id, reason, created_at FROM risk_hold ORDER BY created_at`
+
+	var holds []sqlxRiskHold
+	err := repo.client.Select(&holds, query)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.RiskHold, 0, len(holds))
+	for _, hold := range holds {
+		res = append(res, sqlxRiskHoldToRiskHold(hold))
+	}
+	return res, nil
+}
+
+func (repo *riskRepository) FindRuleHits(userID *app.UserID) ([]app.RiskRuleHit, error) {
+	const query = `
+			SELECT user_id, lot_id, rule, amount, created_at
+			FROM risk_rule_hit
+			WHERE $1::uuid IS NULL OR user_id = $1::uuid
+			ORDER BY created_at DESC
+		`
+
+	var userIDParam sql.NullString
+	if userID != nil {
+		userIDParam.String = string(*userID)
+		userIDParam.Valid = true
+	}
+	var hits []sqlxRiskRuleHit
+	err := repo.client.Select(&hits, query, userIDParam)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.RiskRuleHit, 0, len(hits))
+	for _, hit := range hits {
+		resHit := app.RiskRuleHit{
+			UserID:       app.UserID(hit.UserID),
+			Rule:         app.RiskRule(hit.Rule),
+			Amount:       app.AmountFromRawValue(hit.Amount),
+			CreationTime: hit.CreationTime,
+		}
+		if hit.LotID.Valid {
+			lotID := app.LotID(hit.LotID.String)
+			resHit.LotID = &lotID
+		}
+		res = append(res, resHit)
+	}
+	return res, nil
+}
+
+func sqlxRiskHoldToRiskHold(hold sqlxRiskHold) app.RiskHold {
+	return app.RiskHold{
+		UserID:       app.UserID(hold.UserID),
+		Reason:       app.RiskRule(hold.Reason),
+		CreationTime: hold.CreationTime,
+	}
+}
+
+type sqlxRiskHold struct {
+	UserID       string    `db:"user_id"`
+	Reason       string    `db:"reason"`
+	CreationTime time.Time `db:"created_at"`
+}
+
+type sqlxRiskRuleHit struct {
+	UserID       string         `db:"user_id"`
+	LotID        sql.NullString `db:"lot_id"`
+	Rule         string         `db:"rule"`
+	Amount       uint64         `db:"amount"`
+	CreationTime time.Time      `db:"created_at"`
+}
@@ -38,6 +38,14 @@ func (t *transactionalUnit) UserAccountEventRepository() app.UserAccountEventRep
 	return NewUserAccountEventRepository(t.transaction)
 }
 
+func (t *transactionalUnit) LedgerRepository() app.LedgerRepository {
+	return NewLedgerRepository(t.transaction)
+}
+
+func (t *transactionalUnit) RiskRepository() app.RiskRepository {
+	return NewRiskRepository(t.transaction)
+}
+
 func (t *transactionalUnit) ProcessedEventRepository() app.ProcessedEventRepository {
 	return NewProcessedEventRepository(t.transaction)
 }
@@ -46,6 +54,10 @@ func (t *transactionalUnit) ProcessedRequestRepository() app.ProcessedRequestRep
 	return NewProcessedRequestRepository(t.transaction)
 }
 
+func (t *transactionalUnit) BalanceAdjustmentRepository() app.BalanceAdjustmentRepository {
+	return NewBalanceAdjustmentRepository(t.transaction)
+}
+
 func (t *transactionalUnit) Complete(err error) error {
 	t.nestedLevel--
 
@@ -4,6 +4,7 @@ import (
 	"arch-homework/pkg/billing/app"
 	"arch-homework/pkg/common/infrastructure/postgres"
 	"database/sql"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -37,7 +38,7 @@ func (repo *userAccountEventRepository) Store(event *app.UserAccountEvent) error
 }
 
 func (repo *userAccountEventRepository) FindAllByUserID(id app.UserID) ([]app.UserAccountEvent, error) {
-	const query = `SELECT user_id, lot_id, event_type, amount FROM user_account_event WHERE user_id = $1 ORDER BY id`
+	const query = `SELECT user_id, lot_id, event_type, amount, created_at FROM user_account_event WHERE user_id = $1 ORDER BY id`
 
 	var events []*sqlxUserAccountEvent
 	err := repo.client.Select(&events, query, string(id))
@@ -51,6 +52,8 @@ func (repo *userAccountEventRepository) FindAllByUserID(id app.UserID) ([]app.Us
 			LotID:     nil,
 			EventType: app.AccountEventType(event.EventType),
 			Amount:    app.AmountFromRawValue(event.Amount),
+
+			CreationTime: event.CreatedAt,
 		}
 		if event.LotID.Valid {
 			lotID := app.LotID(event.LotID.String)
@@ -61,9 +64,25 @@ func (repo *userAccountEventRepository) FindAllByUserID(id app.UserID) ([]app.Us
 	return res, nil
 }
 
+func (repo *userAccountEventRepository) FindAllUserIDs() ([]app.UserID, error) {
+	const query = `SELECT DISTINCT user_id FROM user_account_event`
+
+	var userIDs []string
+	err := repo.client.Select(&userIDs, query)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.UserID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		res = append(res, app.UserID(userID))
+	}
+	return res, nil
+}
+
 type sqlxUserAccountEvent struct {
 	UserID    string         `db:"user_id"`
 	LotID     sql.NullString `db:"lot_id"`
 	EventType string         `db:"event_type"`
 	Amount    uint64         `db:"amount"`
+	CreatedAt time.Time      `db:"created_at"`
 }
@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"arch-homework/api/billingpb"
+	"arch-homework/pkg/billing/app"
+	"arch-homework/pkg/common/infrastructure/grpcserver"
+
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func NewServer(billingService app.BillingService, billingQueryService app.BillingQueryService) billingpb.BillingServiceServer {
+	return &server{
+		billingService:      billingService,
+		billingQueryService: billingQueryService,
+	}
+}
+
+type server struct {
+	billingpb.UnimplementedBillingServiceServer
+	billingService      app.BillingService
+	billingQueryService app.BillingQueryService
+}
+
+func (s *server) ProcessLotPayment(ctx context.Context, req *billingpb.ProcessLotPaymentRequest) (*billingpb.ProcessLotPaymentResponse, error) {
+	requestID, err := grpcserver.RequestIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateUserAndLot(req.UserId, req.LotId); err != nil {
+		return nil, err
+	}
+	if req.Amount == 0 {
+		return nil, status.Error(codes.InvalidArgument, app.ErrNegativeAmount.Error())
+	}
+
+	err = s.billingService.ProcessLotPayment(app.RequestID(requestID), app.UserID(req.UserId), app.LotID(req.LotId), app.AmountFromRawValue(req.Amount))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &billingpb.ProcessLotPaymentResponse{}, nil
+}
+
+func (s *server) PayListingFee(ctx context.Context, req *billingpb.PayListingFeeRequest) (*billingpb.PayListingFeeResponse, error) {
+	requestID, err := grpcserver.RequestIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateUserAndLot(req.UserId, req.LotId); err != nil {
+		return nil, err
+	}
+
+	err = s.billingService.PayListingFee(app.RequestID(requestID), app.UserID(req.UserId), app.LotID(req.LotId))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &billingpb.PayListingFeeResponse{}, nil
+}
+
+func (s *server) PreviewFees(_ context.Context, req *billingpb.PreviewFeesRequest) (*billingpb.PreviewFeesResponse, error) {
+	var buyItNowPrice *app.Amount
+	if req.BuyItNowPrice != nil {
+		price := app.AmountFromRawValue(*req.BuyItNowPrice)
+		buyItNowPrice = &price
+	}
+
+	preview := s.billingQueryService.FeePreview(app.AmountFromRawValue(req.StartPrice), buyItNowPrice)
+	response := &billingpb.PreviewFeesResponse{
+		ListingFee:    preview.ListingFee.RawValue(),
+		FinalValueFee: preview.FinalValueFee.RawValue(),
+	}
+	if preview.BuyItNowFinalValueFee != nil {
+		fee := (*preview.BuyItNowFinalValueFee).RawValue()
+		response.BuyItNowFinalValueFee = &fee
+	}
+	return response, nil
+}
+
+func validateUserAndLot(userID, lotID string) error {
+	if err := grpcserver.ValidateUUID("user id", userID); err != nil {
+		return err
+	}
+	return grpcserver.ValidateUUID("lot id", lotID)
+}
+
+func toStatusError(err error) error {
+	switch errors.Cause(err) {
+	case app.ErrAlreadyProcessed:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case app.ErrBlockPayment, app.ErrPayFee, app.ErrLotPaymentAlreadyBlocked:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case app.ErrRiskRuleViolated:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case app.ErrNegativeAmount, app.ErrNotRoundedAmount:
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
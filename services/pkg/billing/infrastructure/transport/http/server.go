@@ -12,15 +12,26 @@ import (
 	"bytes"
 	"encoding/json"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 const PathPrefix = "/api/v1/"
 const PathPrefixInternal = "/internal/api/v1/"
 
 const (
-	accountEndpoint = PathPrefix + "account"
-	paymentEndpoint = PathPrefixInternal + "payment"
+	accountEndpoint          = PathPrefix + "account"
+	accountStatementEndpoint = PathPrefix + "account/statement"
+	adminAdjustmentEndpoint  = PathPrefix + "admin/account/{id}/adjustment"
+	paymentEndpoint          = PathPrefixInternal + "payment"
+	shippingPaymentEndpoint  = PathPrefixInternal + "payment/shipping"
+	listingFeeEndpoint       = PathPrefixInternal + "fee/listing"
+	feePreviewEndpoint       = PathPrefixInternal + "fee/preview"
+	riskRuleHitsEndpoint     = PathPrefixInternal + "risk/hits"
+	riskHoldsEndpoint        = PathPrefixInternal + "risk/holds"
+	userDataExportEndpoint   = PathPrefixInternal + "user/{id}/export"
 )
 
 const (
@@ -30,6 +41,12 @@ const (
 	errorNotEnoughFunds           = 3
 	errorInvalidAmount            = 4
 	errorLotPaymentAlreadyBlocked = 5
+	errorNotEnoughFundsForFee     = 6
+	errorRiskRuleViolated         = 7
+	errorReasonRequired           = 8
+	errorNotEnoughFundsForDebit   = 9
+	errorAccountNotFound          = 10
+	errorPermissionDenied         = 11
 )
 
 const authTokenHeader = "X-Auth-Token"
@@ -58,12 +75,23 @@ func (s *Server) MakeHandler() http.Handler {
 	router := mux.NewRouter()
 	router.Methods(http.MethodGet).Path(accountEndpoint).Handler(s.makeHandlerFunc(s.getAccountStatusEndpoint))
 	router.Methods(http.MethodPost).Path(accountEndpoint).Handler(s.makeHandlerFunc(s.topUpAccountEndpoint))
+	router.Methods(http.MethodGet).Path(accountStatementEndpoint).Handler(s.makeHandlerFunc(s.getAccountStatementEndpoint))
+	router.Methods(http.MethodPost).Path(adminAdjustmentEndpoint).Handler(s.makeHandlerFunc(
+		jwtauth.RequirePermission(s.extractAuthorizationData, jwtauth.PermissionAdjustBalance, s.adjustBalanceEndpoint)))
 	return router
 }
 
 func (s *Server) MakeInternalHandler() http.Handler {
 	router := mux.NewRouter()
 	router.Methods(http.MethodPost).Path(paymentEndpoint).Handler(s.makeHandlerFunc(s.processPaymentEndpoint))
+	router.Methods(http.MethodPost).Path(shippingPaymentEndpoint).Handler(s.makeHandlerFunc(s.blockShippingPaymentEndpoint))
+	router.Methods(http.MethodPost).Path(listingFeeEndpoint).Handler(s.makeHandlerFunc(s.payListingFeeEndpoint))
+	router.Methods(http.MethodGet).Path(feePreviewEndpoint).Handler(s.makeHandlerFunc(s.feePreviewEndpoint))
+	router.Methods(http.MethodGet).Path(riskRuleHitsEndpoint).Handler(s.makeHandlerFunc(s.getRiskRuleHitsEndpoint))
+	router.Methods(http.MethodGet).Path(riskHoldsEndpoint).Handler(s.makeHandlerFunc(s.getRiskHoldsEndpoint))
+	router.Methods(http.MethodPost).Path(riskHoldsEndpoint).Handler(s.makeHandlerFunc(s.placeRiskHoldEndpoint))
+	router.Methods(http.MethodDelete).Path(riskHoldsEndpoint).Handler(s.makeHandlerFunc(s.removeRiskHoldEndpoint))
+	router.Methods(http.MethodGet).Path(userDataExportEndpoint).Handler(s.makeHandlerFunc(s.exportUserDataEndpoint))
 	return router
 }
 
@@ -123,6 +151,20 @@ func (s *Server) getAccountStatusEndpoint(w http.ResponseWriter, r *http.Request
 	return nil
 }
 
+func (s *Server) getAccountStatementEndpoint(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+
+	items, err := s.billingQueryService.AccountStatement(app.UserID(tokenData.UserID()))
+	if err != nil {
+		return err
+	}
+	writeResponse(w, toStatementItemInfos(items))
+	return nil
+}
+
 func (s *Server) topUpAccountEndpoint(w http.ResponseWriter, r *http.Request) error {
 	tokenData, err := s.extractAuthorizationData(r)
 	if err != nil {
@@ -156,6 +198,51 @@ func (s *Server) topUpAccountEndpoint(w http.ResponseWriter, r *http.Request) er
 	return nil
 }
 
+// adjustBalanceEndpoint - positive amount is credited to user account, negative amount is debited from it
+func (s *Server) adjustBalanceEndpoint(w http.ResponseWriter, r *http.Request, tokenData jwtauth.TokenData) error {
+	requestID, err := s.getRequestIDHeader(r)
+	if err != nil {
+		return err
+	}
+	userID := mux.Vars(r)["id"]
+	if err = uuid.ValidateUUID(userID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	var info balanceAdjustmentInfo
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &info); err != nil {
+		return err
+	}
+	direction := app.AdjustmentDirectionCredit
+	if info.Amount < 0 {
+		direction = app.AdjustmentDirectionDebit
+	}
+	amount, err := app.AmountFromFloat(math.Abs(info.Amount))
+	if err != nil {
+		return err
+	}
+
+	err = s.billingService.AdjustBalance(requestID, app.BalanceAdjustment{
+		UserID:       app.UserID(userID),
+		Direction:    direction,
+		Amount:       amount,
+		Reason:       info.Reason,
+		ActorID:      app.UserID(tokenData.UserID()),
+		CreationTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
 func (s *Server) processPaymentEndpoint(w http.ResponseWriter, r *http.Request) error {
 	requestID, err := s.getRequestIDHeader(r)
 	if err != nil {
@@ -190,6 +277,209 @@ func (s *Server) processPaymentEndpoint(w http.ResponseWriter, r *http.Request)
 	return nil
 }
 
+// blockShippingPaymentEndpoint - shipping payment blocked for lot is replaced, free shipping option has zero amount
+func (s *Server) blockShippingPaymentEndpoint(w http.ResponseWriter, r *http.Request) error {
+	requestID, err := s.getRequestIDHeader(r)
+	if err != nil {
+		return err
+	}
+
+	var info paymentInfo
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &info); err != nil {
+		return err
+	}
+	if err = uuid.ValidateUUID(info.UserID); err != nil {
+		return err
+	}
+	if err = uuid.ValidateUUID(info.LotID); err != nil {
+		return err
+	}
+	amount := app.AmountFromRawValue(0)
+	if info.Amount != 0 {
+		amount, err = app.AmountFromFloat(info.Amount)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = s.billingService.BlockShippingPayment(requestID, app.UserID(info.UserID), app.LotID(info.LotID), amount); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) payListingFeeEndpoint(w http.ResponseWriter, r *http.Request) error {
+	requestID, err := s.getRequestIDHeader(r)
+	if err != nil {
+		return err
+	}
+
+	var info listingFeeInfo
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &info); err != nil {
+		return err
+	}
+	if err = uuid.ValidateUUID(info.UserID); err != nil {
+		return err
+	}
+	if err = uuid.ValidateUUID(info.LotID); err != nil {
+		return err
+	}
+
+	if err = s.billingService.PayListingFee(requestID, app.UserID(info.UserID), app.LotID(info.LotID)); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) feePreviewEndpoint(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	startPrice, err := amountFromQueryParam(query.Get("startPrice"))
+	if err != nil {
+		return err
+	}
+	var buyItNowPrice *app.Amount
+	if strPrice := query.Get("buyItNowPrice"); strPrice != "" {
+		price, err := amountFromQueryParam(strPrice)
+		if err != nil {
+			return err
+		}
+		buyItNowPrice = &price
+	}
+
+	preview := s.billingQueryService.FeePreview(startPrice, buyItNowPrice)
+	response := feePreviewResponse{
+		ListingFee:    preview.ListingFee.Value(),
+		FinalValueFee: preview.FinalValueFee.Value(),
+	}
+	if preview.BuyItNowFinalValueFee != nil {
+		fee := (*preview.BuyItNowFinalValueFee).Value()
+		response.BuyItNowFinalValueFee = &fee
+	}
+	writeResponse(w, response)
+	return nil
+}
+
+func (s *Server) getRiskRuleHitsEndpoint(w http.ResponseWriter, r *http.Request) error {
+	var userID *app.UserID
+	if strUserID := r.URL.Query().Get("userId"); strUserID != "" {
+		if err := uuid.ValidateUUID(strUserID); err != nil {
+			return err
+		}
+		id := app.UserID(strUserID)
+		userID = &id
+	}
+
+	hits, err := s.billingQueryService.RiskRuleHits(userID)
+	if err != nil {
+		return err
+	}
+	response := make([]riskRuleHitInfo, 0, len(hits))
+	for _, hit := range hits {
+		info := riskRuleHitInfo{
+			UserID:       string(hit.UserID),
+			Rule:         string(hit.Rule),
+			Amount:       hit.Amount.Value(),
+			CreationDate: hit.CreationTime.Format(time.RFC3339),
+		}
+		if hit.LotID != nil {
+			info.LotID = string(*hit.LotID)
+		}
+		response = append(response, info)
+	}
+	writeResponse(w, response)
+	return nil
+}
+
+func (s *Server) getRiskHoldsEndpoint(w http.ResponseWriter, _ *http.Request) error {
+	holds, err := s.billingQueryService.RiskHolds()
+	if err != nil {
+		return err
+	}
+	response := make([]riskHoldInfo, 0, len(holds))
+	for _, hold := range holds {
+		response = append(response, riskHoldInfo{
+			UserID:       string(hold.UserID),
+			Reason:       string(hold.Reason),
+			CreationDate: hold.CreationTime.Format(time.RFC3339),
+		})
+	}
+	writeResponse(w, response)
+	return nil
+}
+
+func (s *Server) placeRiskHoldEndpoint(w http.ResponseWriter, r *http.Request) error {
+	var info riskHoldRequest
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &info); err != nil {
+		return err
+	}
+	if err = uuid.ValidateUUID(info.UserID); err != nil {
+		return err
+	}
+
+	if err = s.billingService.PlaceRiskHold(app.UserID(info.UserID)); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) removeRiskHoldEndpoint(w http.ResponseWriter, r *http.Request) error {
+	userID := r.URL.Query().Get("userId")
+	if err := uuid.ValidateUUID(userID); err != nil {
+		return err
+	}
+
+	if err := s.billingService.RemoveRiskHold(app.UserID(userID)); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// exportUserDataEndpoint - account data is kept after user deletion for accounting, so export is always available
+func (s *Server) exportUserDataEndpoint(w http.ResponseWriter, r *http.Request) error {
+	userID := mux.Vars(r)["id"]
+	if err := uuid.ValidateUUID(userID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	status, err := s.billingQueryService.AccountBalance(app.UserID(userID))
+	if err != nil {
+		return err
+	}
+	items, err := s.billingQueryService.AccountStatement(app.UserID(userID))
+	if err != nil {
+		return err
+	}
+	writeResponse(w, userDataInfo{
+		Account: accountStatusResponse{
+			Amount:        status.Amount.Value(),
+			BlockedAmount: status.BlockedAmount.Value(),
+		},
+		Statement: toStatementItemInfos(items),
+	})
+	return nil
+}
+
 func (s *Server) extractAuthorizationData(r *http.Request) (jwtauth.TokenData, error) {
 	token := r.Header.Get(authTokenHeader)
 	if token == "" {
@@ -202,6 +492,9 @@ func (s *Server) extractAuthorizationData(r *http.Request) (jwtauth.TokenData, e
 	if err = uuid.ValidateUUID(tokenData.UserID()); err != nil {
 		return nil, errors.WithStack(err)
 	}
+	if err = jwtauth.CheckScope(tokenData, r, jwtauth.ScopeBillingRead, jwtauth.ScopeBillingWrite); err != nil {
+		return nil, errors.Wrap(errForbidden, err.Error())
+	}
 	return tokenData, nil
 }
 
@@ -214,6 +507,14 @@ func (s *Server) getRequestIDHeader(r *http.Request) (app.RequestID, error) {
 	return app.RequestID(requestID), nil
 }
 
+func amountFromQueryParam(param string) (app.Amount, error) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return nil, errors.Wrap(app.ErrNotRoundedAmount, err.Error())
+	}
+	return app.AmountFromFloat(value)
+}
+
 func writeResponse(w http.ResponseWriter, response interface{}) {
 	js, err := json.Marshal(response)
 	if err != nil {
@@ -237,12 +538,30 @@ func writeErrorResponse(w http.ResponseWriter, err error) {
 	case app.ErrBlockPayment:
 		info.Code = errorNotEnoughFunds
 		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrPayFee:
+		info.Code = errorNotEnoughFundsForFee
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrRiskRuleViolated:
+		info.Code = errorRiskRuleViolated
+		w.WriteHeader(http.StatusBadRequest)
 	case app.ErrLotPaymentAlreadyBlocked:
 		info.Code = errorLotPaymentAlreadyBlocked
 		w.WriteHeader(http.StatusBadRequest)
 	case app.ErrNegativeAmount, app.ErrNotRoundedAmount:
 		info.Code = errorInvalidAmount
 		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrReasonRequired:
+		info.Code = errorReasonRequired
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrDebitAdjustment:
+		info.Code = errorNotEnoughFundsForDebit
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrUserAccountNotFound:
+		info.Code = errorAccountNotFound
+		w.WriteHeader(http.StatusNotFound)
+	case jwtauth.ErrPermissionDenied:
+		info.Code = errorPermissionDenied
+		w.WriteHeader(http.StatusForbidden)
 	case errForbidden:
 		w.WriteHeader(http.StatusForbidden)
 	default:
@@ -266,8 +585,70 @@ type topUpAccountInfo struct {
 	Amount float64 `json:"amount"`
 }
 
+type balanceAdjustmentInfo struct {
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
 type paymentInfo struct {
 	UserID string  `json:"userId"`
 	LotID  string  `json:"lotId"`
 	Amount float64 `json:"amount"`
 }
+
+func toStatementItemInfos(items []app.QueryStatementItem) []statementItemInfo {
+	statement := make([]statementItemInfo, 0, len(items))
+	for _, item := range items {
+		info := statementItemInfo{
+			Operation:    item.Operation,
+			Amount:       item.Amount.Value(),
+			CreationDate: item.CreationTime.Format(time.RFC3339),
+		}
+		if item.LotID != nil {
+			info.LotID = string(*item.LotID)
+		}
+		statement = append(statement, info)
+	}
+	return statement
+}
+
+type userDataInfo struct {
+	Account   accountStatusResponse `json:"account"`
+	Statement []statementItemInfo   `json:"statement"`
+}
+
+type statementItemInfo struct {
+	LotID        string  `json:"lotId,omitempty"`
+	Operation    string  `json:"operation"`
+	Amount       float64 `json:"amount"`
+	CreationDate string  `json:"creationDate"`
+}
+
+type listingFeeInfo struct {
+	UserID string `json:"userId"`
+	LotID  string `json:"lotId"`
+}
+
+type feePreviewResponse struct {
+	ListingFee            float64  `json:"listingFee"`
+	FinalValueFee         float64  `json:"finalValueFee"`
+	BuyItNowFinalValueFee *float64 `json:"buyItNowFinalValueFee,omitempty"`
+}
+
+type riskRuleHitInfo struct {
+	UserID       string  `json:"userId"`
+	LotID        string  `json:"lotId,omitempty"`
+	Rule         string  `json:"rule"`
+	Amount       float64 `json:"amount"`
+	CreationDate string  `json:"creationDate"`
+}
+
+type riskHoldInfo struct {
+	UserID       string `json:"userId"`
+	Reason       string `json:"reason"`
+	CreationDate string `json:"creationDate"`
+}
+
+type riskHoldRequest struct {
+	UserID string `json:"userId"`
+}
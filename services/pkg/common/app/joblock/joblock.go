@@ -0,0 +1,25 @@
+package joblock
+
+// LockTransaction - lock is held until transaction is completed
+type LockTransaction interface {
+	AddLock(lockName string) error
+	Complete(err error) error
+}
+
+// Run - runs job under lock, so the same job isn't run by several instances at once.
+// Lock transaction contains no changes, job stores its changes in own transactions,
+// so job errors don't roll back anything and only release the lock
+func Run(newTransaction func() (LockTransaction, error), lockName string, job func() error) (err error) {
+	var trUnit LockTransaction
+	trUnit, err = newTransaction()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	if err = trUnit.AddLock(lockName); err != nil {
+		return err
+	}
+	return job()
+}
@@ -10,6 +10,11 @@ func GenerateNew() UUID {
 	return UUID(gouuid.NewV4().String())
 }
 
+// GenerateFromName - same name gives same uuid, so request with derived id can be retried by any caller
+func GenerateFromName(name string) UUID {
+	return UUID(gouuid.NewV5(gouuid.NamespaceOID, name).String())
+}
+
 func ValidateUUID(s string) error {
 	_, err := gouuid.FromString(s)
 	return err
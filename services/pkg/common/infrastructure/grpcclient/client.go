@@ -0,0 +1,112 @@
+package grpcclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/health" // enables client side health checking
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const RequestIDMetadataKey = "x-request-id"
+
+const (
+	maxAttemptCount = 5
+	initialBackoff  = 100 * time.Millisecond
+	maxBackoff      = 2 * time.Second
+)
+
+// serviceConfig spreads calls over all resolved addresses and skips instances whose health service reports not serving
+const serviceConfig = `{"loadBalancingConfig":[{"round_robin":{}}],"healthCheckConfig":{"serviceName":""}}`
+
+type Config struct {
+	Host        string
+	CallTimeout time.Duration
+}
+
+func Dial(cfg Config) (*grpc.ClientConn, error) {
+	conn, err := grpc.Dial("dns:///"+cfg.Host,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		// timeout limits call with all its retries
+		grpc.WithChainUnaryInterceptor(
+			makeTimeoutInterceptor(cfg.CallTimeout),
+			retryInterceptor,
+		),
+	)
+	return conn, errors.WithStack(err)
+}
+
+// WithRequestID attaches request id used by server to deduplicate mutating calls
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, requestID)
+}
+
+// Idempotent marks call as safe to repeat, only such calls are retried
+func Idempotent() grpc.CallOption {
+	return idempotentCallOption{}
+}
+
+type idempotentCallOption struct {
+	grpc.EmptyCallOption
+}
+
+func makeTimeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func retryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if !isIdempotent(opts) {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	var err error
+	backoff := initialBackoff
+	for i := 0; i < maxAttemptCount; i++ {
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		code := status.Code(err)
+		if i > 0 && code == codes.AlreadyExists {
+			// request already processed by previous attempt
+			return nil
+		}
+		if !isRetryable(code) || i+1 == maxAttemptCount {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+	return err
+}
+
+func isIdempotent(opts []grpc.CallOption) bool {
+	for _, opt := range opts {
+		if _, ok := opt.(idempotentCallOption); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isRetryable(code codes.Code) bool {
+	return code == codes.Unavailable || code == codes.DeadlineExceeded || code == codes.Aborted
+}
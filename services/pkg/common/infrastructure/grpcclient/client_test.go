@@ -0,0 +1,86 @@
+package grpcclient
+
+import (
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"context"
+	"testing"
+	"time"
+)
+
+func makeInvoker(codeSequence ...codes.Code) (grpc.UnaryInvoker, *int) {
+	callCount := 0
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		code := codeSequence[callCount]
+		callCount++
+		if code == codes.OK {
+			return nil
+		}
+		return status.Error(code, code.String())
+	}, &callCount
+}
+
+func TestRetryIdempotentCall(t *testing.T) {
+	invoker, callCount := makeInvoker(codes.Unavailable, codes.DeadlineExceeded, codes.OK)
+	err := retryInterceptor(context.Background(), "method", nil, nil, nil, invoker, Idempotent())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, *callCount)
+}
+
+func TestNotIdempotentCallIsNotRetried(t *testing.T) {
+	invoker, callCount := makeInvoker(codes.Unavailable, codes.OK)
+	err := retryInterceptor(context.Background(), "method", nil, nil, nil, invoker)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, *callCount)
+}
+
+func TestBusinessErrorIsNotRetried(t *testing.T) {
+	invoker, callCount := makeInvoker(codes.FailedPrecondition, codes.OK)
+	err := retryInterceptor(context.Background(), "method", nil, nil, nil, invoker, Idempotent())
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, 1, *callCount)
+}
+
+func TestAlreadyProcessedOnRetryIsSuccess(t *testing.T) {
+	invoker, callCount := makeInvoker(codes.DeadlineExceeded, codes.AlreadyExists)
+	err := retryInterceptor(context.Background(), "method", nil, nil, nil, invoker, Idempotent())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *callCount)
+
+	invoker, callCount = makeInvoker(codes.AlreadyExists)
+	err = retryInterceptor(context.Background(), "method", nil, nil, nil, invoker, Idempotent())
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	assert.Equal(t, 1, *callCount)
+}
+
+func TestRetriesAreLimited(t *testing.T) {
+	sequence := make([]codes.Code, maxAttemptCount)
+	for i := range sequence {
+		sequence[i] = codes.Unavailable
+	}
+	invoker, callCount := makeInvoker(sequence...)
+	err := retryInterceptor(context.Background(), "method", nil, nil, nil, invoker, Idempotent())
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, maxAttemptCount, *callCount)
+}
+
+func TestCallTimeoutLimitsAllAttempts(t *testing.T) {
+	sequence := make([]codes.Code, maxAttemptCount)
+	for i := range sequence {
+		sequence[i] = codes.Unavailable
+	}
+	invoker, callCount := makeInvoker(sequence...)
+	retryingInvoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return retryInterceptor(ctx, method, req, reply, cc, invoker, opts...)
+	}
+
+	start := time.Now()
+	err := makeTimeoutInterceptor(150*time.Millisecond)(context.Background(), "method", nil, nil, nil, retryingInvoker, Idempotent())
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	// second backoff doesn't fit into timeout
+	assert.Equal(t, 2, *callCount)
+	assert.Less(t, int64(time.Since(start)), int64(300*time.Millisecond))
+}
@@ -0,0 +1,102 @@
+package grpcserver
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"context"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const requestIDMetadataKey = "x-request-id"
+
+const readinessCheckInterval = 5 * time.Second
+
+// ReadinessCheck - returns error while server can't process calls, e.g. database is unreachable
+type ReadinessCheck func() error
+
+// NewServer - health status is updated by readiness check until ctx is done,
+// so clients with health checking skip instance which can't process calls
+func NewServer(ctx context.Context, logger *logrus.Logger, readinessCheck ReadinessCheck) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(makeLoggingInterceptor(logger)))
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go watchReadiness(ctx, healthServer, readinessCheck, readinessCheckInterval, logger)
+	return server
+}
+
+func Serve(server *grpc.Server, port string) error {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return server.Serve(listener)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, error) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadataKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if err := uuid.ValidateUUID(requestID); err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "empty or invalid request id: %s", err)
+	}
+	return requestID, nil
+}
+
+func ValidateUUID(field, value string) error {
+	if err := uuid.ValidateUUID(value); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid %s: %s", field, err)
+	}
+	return nil
+}
+
+func watchReadiness(ctx context.Context, healthServer *health.Server, readinessCheck ReadinessCheck, interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		servingStatus := healthpb.HealthCheckResponse_SERVING
+		if err := readinessCheck(); err != nil {
+			logger.WithError(err).Error("grpc server is not ready")
+			servingStatus = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		healthServer.SetServingStatus("", servingStatus)
+
+		select {
+		case <-ctx.Done():
+			healthServer.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func makeLoggingInterceptor(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		fields := logrus.Fields{
+			"method": info.FullMethod,
+		}
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			fields["requestID"] = md.Get(requestIDMetadataKey)
+		}
+		if err != nil {
+			fields["err"] = err
+			logger.WithFields(fields).Error()
+		} else {
+			logger.WithFields(fields).Info("call")
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,55 @@
+package grpcserver
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"context"
+	"io/ioutil"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthStatusFollowsReadiness(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	healthServer := health.NewServer()
+	var ready int32
+	readinessCheck := func() error {
+		if atomic.LoadInt32(&ready) == 0 {
+			return errors.New("database is unreachable")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watchReadiness(ctx, healthServer, readinessCheck, 10*time.Millisecond, logger)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return servingStatus(healthServer) == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 5*time.Millisecond)
+	atomic.StoreInt32(&ready, 1)
+	assert.Eventually(t, func() bool {
+		return servingStatus(healthServer) == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(healthServer))
+}
+
+func servingStatus(healthServer *health.Server) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := healthServer.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN
+	}
+	return resp.Status
+}
@@ -17,6 +17,8 @@ type Connector interface {
 	Open(dsn DSN) error
 	WaitUntilReady() error
 	Ready() bool
+	// Ping - checks that database is still reachable after connector became ready
+	Ping() error
 	Client() TransactionalClient
 	Close() error
 }
@@ -55,6 +57,13 @@ func (c *connector) Ready() bool {
 	return c.ready
 }
 
+func (c *connector) Ping() error {
+	if !c.ready {
+		return errors.New("database connection is not ready")
+	}
+	return errors.WithStack(c.db.Ping())
+}
+
 func (c *connector) Client() TransactionalClient {
 	if !c.ready {
 		panic("db client not ready, but requested")
@@ -0,0 +1,38 @@
+package jwtauth
+
+import (
+	"github.com/pkg/errors"
+
+	"net/http"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Permission - roles are assigned to users in auth service, permissions of user roles are included in token
+type Permission string
+
+const (
+	PermissionSuspendUser   Permission = "user.suspend"
+	PermissionManageRoles   Permission = "auth.manage_roles"
+	PermissionCloseLot      Permission = "lot.close"
+	PermissionAdjustBalance Permission = "billing.adjust_balance"
+)
+
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+type AuthorizedHandlerFunc func(w http.ResponseWriter, r *http.Request, tokenData TokenData) error
+type ExtractAuthorizationDataFunc func(r *http.Request) (TokenData, error)
+
+// RequirePermission - token is extracted by service, so missing token is reported as before,
+// handler is called only if token contains permission, otherwise ErrPermissionDenied is returned
+func RequirePermission(extract ExtractAuthorizationDataFunc, permission Permission, next AuthorizedHandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		tokenData, err := extract(r)
+		if err != nil {
+			return err
+		}
+		if !tokenData.HasPermission(permission) {
+			return errors.Wrapf(ErrPermissionDenied, "permission '%s' required", permission)
+		}
+		return next(w, r, tokenData)
+	}
+}
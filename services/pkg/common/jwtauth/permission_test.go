@@ -0,0 +1,79 @@
+package jwtauth
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequirePermission(t *testing.T) {
+	keys, err := GenerateSigningKeys("key-1")
+	assert.NoError(t, err)
+	generator, err := NewTokenGenerator(keys.Current)
+	assert.NoError(t, err)
+	parser := NewLocalTokenParser(keys)
+	extract := func(r *http.Request) (TokenData, error) {
+		return parser.ParseToken(r.Header.Get("X-Auth-Token"))
+	}
+
+	called := 0
+	handler := RequirePermission(extract, PermissionCloseLot, func(w http.ResponseWriter, r *http.Request, tokenData TokenData) error {
+		called++
+		assert.Equal(t, "admin-id", tokenData.UserID())
+		assert.Equal(t, []string{"moderator"}, tokenData.Roles())
+		return nil
+	})
+
+	moderatorToken, err := generator.GenerateToken("admin-id", "admin", []string{"moderator"}, []Permission{PermissionSuspendUser, PermissionCloseLot})
+	assert.NoError(t, err)
+	assert.NoError(t, handler(httptest.NewRecorder(), newRequestWithToken(moderatorToken)))
+	assert.Equal(t, 1, called)
+
+	userToken, err := generator.GenerateToken("user-id", "user", nil, nil)
+	assert.NoError(t, err)
+	err = handler(httptest.NewRecorder(), newRequestWithToken(userToken))
+	assert.Equal(t, ErrPermissionDenied, errors.Cause(err))
+
+	err = handler(httptest.NewRecorder(), newRequestWithToken("invalid"))
+	assert.Equal(t, ErrInvalidToken, errors.Cause(err))
+	assert.Equal(t, 1, called)
+}
+
+func newRequestWithToken(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/admin", nil)
+	r.Header.Set("X-Auth-Token", token)
+	return r
+}
+
+func TestCheckScope(t *testing.T) {
+	keys, err := GenerateSigningKeys("key-1")
+	assert.NoError(t, err)
+	generator, err := NewTokenGenerator(keys.Current)
+	assert.NoError(t, err)
+	parser := NewLocalTokenParser(keys)
+
+	apiKeyToken, err := generator.GenerateAPIKeyToken("user-id", "user", "key-id", []Scope{ScopeLotRead})
+	assert.NoError(t, err)
+	apiKeyData, err := parser.ParseToken(apiKeyToken)
+	assert.NoError(t, err)
+	sessionToken, err := generator.GenerateToken("user-id", "user", nil, nil)
+	assert.NoError(t, err)
+	sessionData, err := parser.ParseToken(sessionToken)
+	assert.NoError(t, err)
+
+	getRequest := httptest.NewRequest(http.MethodGet, "/api/v1/lots", nil)
+	postRequest := httptest.NewRequest(http.MethodPost, "/api/v1/lot", nil)
+
+	assert.NoError(t, CheckScope(apiKeyData, getRequest, ScopeLotRead, ScopeLotWrite))
+	err = CheckScope(apiKeyData, postRequest, ScopeLotRead, ScopeLotWrite)
+	assert.Equal(t, ErrScopeRequired, errors.Cause(err))
+	err = CheckScope(apiKeyData, getRequest, NoScope, NoScope)
+	assert.Equal(t, ErrScopeRequired, errors.Cause(err))
+	assert.False(t, apiKeyData.HasPermission(PermissionCloseLot))
+
+	assert.NoError(t, CheckScope(sessionData, postRequest, ScopeLotRead, ScopeLotWrite))
+	assert.NoError(t, CheckScope(sessionData, getRequest, NoScope, NoScope))
+}
@@ -0,0 +1,37 @@
+package jwtauth
+
+import (
+	"github.com/pkg/errors"
+
+	"net/http"
+)
+
+var ErrScopeRequired = errors.New("api key scope required")
+
+// Scope - api key grants access to operations of service listed in its scopes
+type Scope string
+
+const (
+	// NoScope - service isn't available for api keys
+	NoScope           Scope = ""
+	ScopeLotRead      Scope = "lot:read"
+	ScopeLotWrite     Scope = "lot:write"
+	ScopeBillingRead  Scope = "billing:read"
+	ScopeBillingWrite Scope = "billing:write"
+)
+
+// CheckScope - GET requests require readScope, other requests require writeScope,
+// tokens issued for session pass any check
+func CheckScope(tokenData TokenData, r *http.Request, readScope, writeScope Scope) error {
+	scope := writeScope
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		scope = readScope
+	}
+	if !tokenData.HasScope(scope) {
+		if scope == NoScope {
+			return errors.Wrap(ErrScopeRequired, "service isn't available for api keys")
+		}
+		return errors.Wrapf(ErrScopeRequired, "scope '%s' required", scope)
+	}
+	return nil
+}
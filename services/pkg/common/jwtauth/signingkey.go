@@ -0,0 +1,105 @@
+package jwtauth
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"sort"
+)
+
+const minRSAKeyBits = 2048
+
+// SigningKey - private key of auth service, RSA keys are used with RS256 and Ed25519 keys with EdDSA
+type SigningKey struct {
+	ID         string
+	PrivateKey crypto.Signer
+}
+
+// SigningKeys - tokens are signed with current key, public parts of all keys are published,
+// so tokens signed with previous key are valid during rotation
+type SigningKeys struct {
+	Current SigningKey
+	All     []SigningKey
+}
+
+// ParseSigningKeys - pemKeys maps key id to PKCS#8 or PKCS#1 PEM encoded private key
+func ParseSigningKeys(pemKeys map[string]string, currentKeyID string) (*SigningKeys, error) {
+	keys := &SigningKeys{}
+	found := false
+	for id, pemKey := range pemKeys {
+		privateKey, err := parsePrivateKey([]byte(pemKey))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid signing key '%s'", id)
+		}
+		key := SigningKey{ID: id, PrivateKey: privateKey}
+		keys.All = append(keys.All, key)
+		if id == currentKeyID {
+			keys.Current = key
+			found = true
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("current signing key '%s' not found", currentKeyID)
+	}
+	sort.Slice(keys.All, func(i, j int) bool { return keys.All[i].ID < keys.All[j].ID })
+	return keys, nil
+}
+
+// GenerateSigningKeys - generates Ed25519 key, which lives only in memory
+func GenerateSigningKeys(id string) (*SigningKeys, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	key := SigningKey{ID: id, PrivateKey: privateKey}
+	return &SigningKeys{Current: key, All: []SigningKey{key}}, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("pem block not found")
+	}
+	var privateKey interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, errors.Errorf("unsupported pem block '%s'", block.Type)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, errors.Errorf("rsa key must have at least %d bits", minRSAKeyBits)
+		}
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, errors.New("only rsa and ed25519 keys are supported")
+	}
+}
+
+func signingMethod(key crypto.Signer) (jwt.SigningMethod, error) {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, errors.New("unsupported signing key")
+	}
+}
@@ -5,13 +5,21 @@ import "github.com/golang-jwt/jwt/v4"
 type TokenData interface {
 	UserID() string
 	UserLogin() string
+	Roles() []string
+	HasPermission(permission Permission) bool
+	// HasScope - tokens issued for session are not restricted by scopes, unlike tokens issued for api key
+	HasScope(scope Scope) bool
 }
 
 type tokenClaims struct {
 	jwt.RegisteredClaims
 
-	ID    string `json:"uid"`
-	Login string `json:"login"`
+	ID          string       `json:"uid"`
+	Login       string       `json:"login"`
+	RoleNames   []string     `json:"roles,omitempty"`
+	Permissions []Permission `json:"permissions,omitempty"`
+	APIKeyID    string       `json:"apiKey,omitempty"`
+	Scopes      []Scope      `json:"scopes,omitempty"`
 }
 
 func (t *tokenClaims) UserID() string {
@@ -21,3 +29,28 @@ func (t *tokenClaims) UserID() string {
 func (t *tokenClaims) UserLogin() string {
 	return t.Login
 }
+
+func (t *tokenClaims) Roles() []string {
+	return t.RoleNames
+}
+
+func (t *tokenClaims) HasPermission(permission Permission) bool {
+	for _, p := range t.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *tokenClaims) HasScope(scope Scope) bool {
+	if t.APIKeyID == "" {
+		return true
+	}
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -10,30 +10,53 @@ import (
 const tokenExpirationTime = time.Minute
 
 type TokenGenerator interface {
-	GenerateToken(userID, userLogin string) (string, error)
+	GenerateToken(userID, userLogin string, roles []string, permissions []Permission) (string, error)
+	// GenerateAPIKeyToken - token issued for api key has no roles, access is limited by scopes of key
+	GenerateAPIKeyToken(userID, userLogin, apiKeyID string, scopes []Scope) (string, error)
 }
 
-func NewTokenGenerator(key string) TokenGenerator {
-	return &tokenGenerator{key: []byte(key)}
+// NewTokenGenerator - only auth service holds signing key, other services verify tokens with published public keys
+func NewTokenGenerator(key SigningKey) (TokenGenerator, error) {
+	method, err := signingMethod(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &tokenGenerator{key: key, method: method}, nil
 }
 
 type tokenGenerator struct {
-	key []byte
+	key    SigningKey
+	method jwt.SigningMethod
+}
+
+func (t *tokenGenerator) GenerateToken(userID, userLogin string, roles []string, permissions []Permission) (string, error) {
+	return t.signClaims(tokenClaims{
+		ID:          userID,
+		Login:       userLogin,
+		RoleNames:   roles,
+		Permissions: permissions,
+	})
+}
+
+func (t *tokenGenerator) GenerateAPIKeyToken(userID, userLogin, apiKeyID string, scopes []Scope) (string, error) {
+	return t.signClaims(tokenClaims{
+		ID:       userID,
+		Login:    userLogin,
+		APIKeyID: apiKeyID,
+		Scopes:   scopes,
+	})
 }
 
-func (t *tokenGenerator) GenerateToken(userID, userLogin string) (string, error) {
+func (t *tokenGenerator) signClaims(claims tokenClaims) (string, error) {
 	issuedAt := jwt.NumericDate{Time: time.Now()}
 	expiresAt := jwt.NumericDate{Time: time.Now().Add(tokenExpirationTime)}
-	claims := tokenClaims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			IssuedAt:  &issuedAt,
-			ExpiresAt: &expiresAt,
-		},
-		ID:    userID,
-		Login: userLogin,
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		IssuedAt:  &issuedAt,
+		ExpiresAt: &expiresAt,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := token.SignedString(t.key)
+	token := jwt.NewWithClaims(t.method, claims)
+	token.Header["kid"] = t.key.ID
+	tokenStr, err := token.SignedString(t.key.PrivateKey)
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
@@ -3,37 +3,141 @@ package jwtauth
 import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
+
+	"crypto"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	keySetTTL = 5 * time.Minute
+	// keySetMinRefreshInterval - limits refetching of key set, when token with unknown key id is received
+	keySetMinRefreshInterval = 5 * time.Second
 )
 
 var ErrInvalidToken = errors.New("invalid token")
 
+var validMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
 type TokenParser interface {
 	ParseToken(token string) (TokenData, error)
 }
 
-func NewTokenParser(key string) TokenParser {
-	return &tokenParser{key: []byte(key)}
+// NewTokenParser - public keys are fetched from key set of auth service and cached,
+// key set is refetched when it expires or token is signed with unknown key after rotation
+func NewTokenParser(keySetURL string, httpClient *http.Client) TokenParser {
+	return &tokenParser{
+		keySetURL:          keySetURL,
+		httpClient:         httpClient,
+		minRefreshInterval: keySetMinRefreshInterval,
+	}
 }
 
 type tokenParser struct {
-	key []byte
+	keySetURL          string
+	httpClient         *http.Client
+	minRefreshInterval time.Duration
+
+	mutex       sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// NewLocalTokenParser - auth service verifies its own tokens with public parts of signing keys
+func NewLocalTokenParser(keys *SigningKeys) TokenParser {
+	publicKeys := make(map[string]crypto.PublicKey, len(keys.All))
+	for _, key := range keys.All {
+		publicKeys[key.ID] = key.PrivateKey.Public()
+	}
+	return &localTokenParser{keys: publicKeys}
+}
+
+type localTokenParser struct {
+	keys map[string]crypto.PublicKey
+}
+
+func (t *localTokenParser) ParseToken(token string) (TokenData, error) {
+	return parseToken(token, func(kid string) (crypto.PublicKey, error) {
+		key, ok := t.keys[kid]
+		if !ok {
+			return nil, errors.Errorf("signing key '%s' not found", kid)
+		}
+		return key, nil
+	})
 }
 
 func (t *tokenParser) ParseToken(token string) (TokenData, error) {
+	return parseToken(token, t.publicKey)
+}
+
+func parseToken(token string, publicKey func(kid string) (crypto.PublicKey, error)) (TokenData, error) {
 	claims := tokenClaims{}
 
-	jwtToken, err := jwt.ParseWithClaims(
+	parser := jwt.Parser{ValidMethods: validMethods}
+	jwtToken, err := parser.ParseWithClaims(
 		token, &claims, func(token *jwt.Token) (i interface{}, err error) {
-			return t.key, nil
+			kid, _ := token.Header["kid"].(string)
+			return publicKey(kid)
 		},
 	)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, errors.Wrap(ErrInvalidToken, err.Error())
 	}
 
-	if !jwtToken.Valid || jwtToken.Method.Alg() != "HS256" {
+	if !jwtToken.Valid {
 		return nil, errors.WithStack(ErrInvalidToken)
 	}
 
 	return &claims, nil
 }
+
+func (t *tokenParser) publicKey(kid string) (crypto.PublicKey, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	expired := t.keys == nil || now.Sub(t.fetchedAt) >= keySetTTL
+	key, ok := t.keys[kid]
+	if (expired || !ok) && now.Sub(t.attemptedAt) >= t.minRefreshInterval {
+		t.attemptedAt = now
+		// previously fetched keys are used while auth service is unavailable
+		if err := t.refresh(now); err != nil && (t.keys == nil || !ok) {
+			return nil, err
+		}
+		key, ok = t.keys[kid]
+	}
+	if !ok {
+		return nil, errors.Errorf("signing key '%s' not found", kid)
+	}
+	return key, nil
+}
+
+func (t *tokenParser) refresh(now time.Time) error {
+	resp, err := t.httpClient.Get(t.keySetURL)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("key set request failed with status %d", resp.StatusCode)
+	}
+	var set jsonWebKeySet
+	if err = json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return errors.WithStack(err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			return errors.Wrapf(err, "invalid key '%s'", jwk.Kid)
+		}
+		keys[jwk.Kid] = key
+	}
+	t.keys = keys
+	t.fetchedAt = now
+	return nil
+}
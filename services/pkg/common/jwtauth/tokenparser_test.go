@@ -0,0 +1,141 @@
+package jwtauth
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestTokenSignedWithRSAAndEd25519Keys(t *testing.T) {
+	rsaKeys, err := ParseSigningKeys(map[string]string{"rsa": generateRSAPEM(t)}, "rsa")
+	assert.NoError(t, err)
+	ed25519Keys, err := GenerateSigningKeys("ed25519")
+	assert.NoError(t, err)
+
+	for _, keys := range []*SigningKeys{rsaKeys, ed25519Keys} {
+		server := newKeySetServer(t, keys)
+		parser := NewTokenParser(server.URL, server.Client())
+
+		token := generateToken(t, keys)
+		data, err := parser.ParseToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "user-id", data.UserID())
+		assert.Equal(t, "user", data.UserLogin())
+		server.Close()
+	}
+}
+
+func TestTokenParserRejectsForgedTokens(t *testing.T) {
+	keys, err := GenerateSigningKeys("key-1")
+	assert.NoError(t, err)
+	server := newKeySetServer(t, keys)
+	defer server.Close()
+	parser := NewTokenParser(server.URL, server.Client())
+
+	// shared secret tokens can't be used anymore
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims{ID: "user-id"})
+	hmacToken.Header["kid"] = "key-1"
+	token, err := hmacToken.SignedString([]byte("secret"))
+	assert.NoError(t, err)
+	_, err = parser.ParseToken(token)
+	assert.Equal(t, ErrInvalidToken, errors.Cause(err))
+
+	anotherKeys, err := GenerateSigningKeys("key-1")
+	assert.NoError(t, err)
+	_, err = parser.ParseToken(generateToken(t, anotherKeys))
+	assert.Equal(t, ErrInvalidToken, errors.Cause(err))
+}
+
+func TestTokenParserRefetchesKeySetAfterRotation(t *testing.T) {
+	keys, err := GenerateSigningKeys("key-1")
+	assert.NoError(t, err)
+	server := newKeySetServer(t, keys)
+	defer server.Close()
+	p := NewTokenParser(server.URL, server.Client())
+
+	_, err = p.ParseToken(generateToken(t, keys))
+	assert.NoError(t, err)
+	oldToken := generateToken(t, keys)
+
+	newKeys, err := GenerateSigningKeys("key-2")
+	assert.NoError(t, err)
+	server.setKeys(&SigningKeys{Current: newKeys.Current, All: append(newKeys.All, keys.All...)})
+
+	// key set was fetched recently, so it isn't refetched
+	_, err = p.ParseToken(generateToken(t, newKeys))
+	assert.Equal(t, ErrInvalidToken, errors.Cause(err))
+	assert.Equal(t, 1, server.requests)
+
+	p.(*tokenParser).minRefreshInterval = 0
+	_, err = p.ParseToken(generateToken(t, newKeys))
+	assert.NoError(t, err)
+	_, err = p.ParseToken(oldToken)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, server.requests)
+}
+
+func TestParseSigningKeysValidatesKeys(t *testing.T) {
+	_, err := ParseSigningKeys(map[string]string{"key-1": generateRSAPEM(t)}, "key-2")
+	assert.Error(t, err)
+
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+	weakPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(weakKey)})
+	_, err = ParseSigningKeys(map[string]string{"key-1": string(weakPEM)}, "key-1")
+	assert.Error(t, err)
+}
+
+func generateToken(t *testing.T, keys *SigningKeys) string {
+	generator, err := NewTokenGenerator(keys.Current)
+	assert.NoError(t, err)
+	token, err := generator.GenerateToken("user-id", "user", nil, nil)
+	assert.NoError(t, err)
+	return token
+}
+
+func generateRSAPEM(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	data, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: data}))
+}
+
+type keySetServer struct {
+	*httptest.Server
+	t *testing.T
+
+	mutex    sync.Mutex
+	handler  http.Handler
+	requests int
+}
+
+func newKeySetServer(t *testing.T, keys *SigningKeys) *keySetServer {
+	server := &keySetServer{t: t}
+	server.setKeys(keys)
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mutex.Lock()
+		server.requests++
+		handler := server.handler
+		server.mutex.Unlock()
+		handler.ServeHTTP(w, r)
+	}))
+	return server
+}
+
+func (s *keySetServer) setKeys(keys *SigningKeys) {
+	handler, err := NewKeySetHandler(keys)
+	assert.NoError(s.t, err)
+	s.mutex.Lock()
+	s.handler = handler
+	s.mutex.Unlock()
+}
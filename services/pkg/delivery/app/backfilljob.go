@@ -0,0 +1,43 @@
+package app
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"context"
+	"time"
+)
+
+// StartBackfillJob - backfill is run once on start and retried with interval until it succeeds
+func StartBackfillJob(ctx context.Context, deliveryService *DeliveryService, retryInterval time.Duration, logger *logrus.Logger) {
+	job := backfillJob{
+		deliveryService: deliveryService,
+		logger:          logger,
+	}
+	job.start(ctx, retryInterval)
+}
+
+type backfillJob struct {
+	deliveryService *DeliveryService
+	logger          *logrus.Logger
+}
+
+func (job *backfillJob) start(ctx context.Context, retryInterval time.Duration) {
+	go func() {
+		for !job.backfill() {
+			select {
+			case <-time.After(retryInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (job *backfillJob) backfill() bool {
+	err := job.deliveryService.BackfillDeliveries()
+	if err != nil {
+		job.logger.Error(err)
+		return false
+	}
+	return true
+}
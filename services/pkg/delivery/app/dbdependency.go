@@ -4,16 +4,32 @@ import "arch-homework/pkg/common/app/storedevent"
 
 type RepositoryProvider interface {
 	DeliveryInfoRepository() DeliveryInfoRepository
+	DeliveryAddressRepository() DeliveryAddressRepository
+	UserProfileRepository() UserProfileRepository
+	TrackingEventRepository() TrackingEventRepository
+	ShippingSelectionRepository() ShippingSelectionRepository
+	ShippingPaymentRepository() ShippingPaymentRepository
+	DeliveryDeadlineRepository() DeliveryDeadlineRepository
+	DeliveryEnrichmentRepository() DeliveryEnrichmentRepository
 	ProcessedRequestRepository() ProcessedRequestRepository
+	ProcessedEventRepository() ProcessedEventRepository
 	EventStore() storedevent.EventStore
 }
 
 type ReadRepositoryProvider interface {
 	DeliveryInfoRepositoryRead() DeliveryInfoRepositoryRead
+	DeliveryAddressRepositoryRead() DeliveryAddressRepositoryRead
+	UserProfileRepositoryRead() UserProfileRepositoryRead
+	TrackingEventRepositoryRead() TrackingEventRepositoryRead
+	ShippingSelectionRepositoryRead() ShippingSelectionRepositoryRead
+	ShippingPaymentRepositoryRead() ShippingPaymentRepositoryRead
+	DeliveryDeadlineRepositoryRead() DeliveryDeadlineRepositoryRead
+	DeliveryEnrichmentRepositoryRead() DeliveryEnrichmentRepositoryRead
 }
 
 type TransactionalUnit interface {
 	RepositoryProvider
+	AddLock(lockName string) error
 	Complete(err error) error
 }
 
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrDeadlineNotFound = errors.New("delivery deadline not found")
+
+type DeadlineType string
+
+const (
+	DeadlineShipBy    DeadlineType = "ship_by"
+	DeadlineConfirmBy DeadlineType = "confirm_by"
+)
+
+// DeliveryDeadline - deadlines of won lot are counted from FinishedAt
+type DeliveryDeadline struct {
+	LotID               LotID
+	SenderID            UserID
+	ReceiverID          UserID
+	FinishedAt          time.Time
+	ShipReminderSent    bool
+	ConfirmReminderSent bool
+	// Closed - lot is received or cancelled, deadlines aren't checked anymore
+	Closed bool
+}
+
+// DeadlinePolicy - zero period disables deadline,
+// reminder is sent to sender or receiver ReminderLead before deadline
+type DeadlinePolicy struct {
+	ShipByPeriod    time.Duration
+	ConfirmByPeriod time.Duration
+	ReminderLead    time.Duration
+}
+
+func (p DeadlinePolicy) ShipBy(deadline *DeliveryDeadline) *time.Time {
+	return deadlineAfter(deadline.FinishedAt, p.ShipByPeriod)
+}
+
+func (p DeadlinePolicy) ConfirmBy(deadline *DeliveryDeadline) *time.Time {
+	return deadlineAfter(deadline.FinishedAt, p.ConfirmByPeriod)
+}
+
+type DeliveryDeadlineRepositoryRead interface {
+	FindByLotID(lotID LotID) (*DeliveryDeadline, error)
+	FindAllOpen() ([]DeliveryDeadline, error)
+}
+
+type DeliveryDeadlineRepository interface {
+	DeliveryDeadlineRepositoryRead
+	Store(deadline *DeliveryDeadline) error
+}
+
+func deadlineAfter(t time.Time, period time.Duration) *time.Time {
+	if period <= 0 {
+		return nil
+	}
+	deadline := t.Add(period)
+	return &deadline
+}
+
+func reminderDue(deadline time.Time, lead time.Duration, now time.Time) bool {
+	return !now.Before(deadline.Add(-lead))
+}
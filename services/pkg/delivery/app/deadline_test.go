@@ -0,0 +1,127 @@
+package app_test
+
+import (
+	"arch-homework/pkg/delivery/app"
+
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testDeadlinePolicy = app.DeadlinePolicy{
+	ShipByPeriod:    time.Hour * 72,
+	ConfirmByPeriod: time.Hour * 24 * 14,
+	ReminderLead:    time.Hour * 24,
+}
+
+func TestUnsentLotCancelledAfterShipByDeadline(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	billing := newTestBillingClient()
+	service := newTestLotDeliveryService(db, lotInfo, billing, testDeadlinePolicy)
+	handleTestLotWonEvent(t, db, lotInfo, time.Now().Add(-time.Hour*24))
+
+	assert.NoError(t, service.ProcessDeliveryDeadlines())
+	assert.Empty(t, db.events)
+
+	db.addDeadline(lotInfo, time.Now().Add(-time.Hour*50))
+	assert.NoError(t, service.ProcessDeliveryDeadlines())
+	assert.NoError(t, service.ProcessDeliveryDeadlines())
+	assert.Equal(t, []string{"delivery.deadline_reminder"}, db.eventTypes())
+	assert.True(t, db.deadlines[lotInfo.ID].ShipReminderSent)
+
+	_, err := service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "post")
+	assert.NoError(t, err)
+	deadline := db.deadlines[lotInfo.ID]
+	deadline.FinishedAt = time.Now().Add(-time.Hour * 73)
+	db.deadlines[lotInfo.ID] = deadline
+	assert.NoError(t, service.ProcessDeliveryDeadlines())
+	assert.Equal(t, []string{"delivery.deadline_reminder", "delivery.lot_cancelled"}, db.eventTypes())
+	assert.Equal(t, app.LotStatusCancelled, db.deliveries[lotInfo.ID].LotStatus)
+	assert.True(t, db.deadlines[lotInfo.ID].Closed)
+
+	err = service.SetLotSent(newRequestID(), lotInfo.OwnerID, lotInfo.ID, testTrackingID)
+	assert.Equal(t, app.ErrInvalidLotStatus, errorsCause(err))
+}
+
+func TestUnsentLotCancelledAfterPendingShippingPayment(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	billing := newTestBillingClient()
+	service := newTestLotDeliveryService(db, lotInfo, billing, testDeadlinePolicy)
+	handleTestLotWonEvent(t, db, lotInfo, time.Now().Add(-time.Hour*73))
+
+	billing.responseLost = true
+	_, err := service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "post")
+	assert.Error(t, err)
+	assert.Error(t, service.ProcessDeliveryDeadlines())
+	assert.Equal(t, app.LotStatusFinished, db.deliveries[lotInfo.ID].LotStatus)
+
+	// payment is stored before cancellation, so billing releases it after delivery.lot_cancelled event
+	billing.responseLost = false
+	assert.NoError(t, service.ProcessDeliveryDeadlines())
+	assert.Equal(t, app.LotStatusCancelled, db.deliveries[lotInfo.ID].LotStatus)
+	assert.Equal(t, "post", db.shipping[lotInfo.ID].OptionName)
+	assert.Empty(t, db.payments)
+}
+
+func TestBackfilledLotCancelledAfterShipByDeadline(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	billing := newTestBillingClient()
+	service := newTestLotDeliveryService(db, lotInfo, billing, testDeadlinePolicy)
+	// lot won after deadlines were stored from lot.lot_won events, but before deliveries were stored
+	db.addDeadline(lotInfo, time.Now().Add(-time.Hour*73))
+
+	assert.NoError(t, service.BackfillDeliveries())
+	assert.NoError(t, service.ProcessDeliveryDeadlines())
+	assert.Equal(t, []string{"delivery.lot_cancelled"}, db.eventTypes())
+	info := db.deliveries[lotInfo.ID]
+	assert.Equal(t, app.LotStatusCancelled, info.LotStatus)
+	assert.Equal(t, lotInfo.OwnerID, info.SenderID)
+	assert.Equal(t, "winner", info.ReceiverLogin)
+	assert.True(t, db.deadlines[lotInfo.ID].Closed)
+
+	assert.NoError(t, service.ProcessDeliveryDeadlines())
+	assert.Equal(t, []string{"delivery.lot_cancelled"}, db.eventTypes())
+}
+
+func TestDeliveredLotReceivedAfterConfirmByDeadline(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	service := newTestLotDeliveryService(db, lotInfo, nil, testDeadlinePolicy)
+	lotID := db.addSentDelivery(testTrackingID)
+	lotInfo.ID = lotID
+	db.addDeadline(lotInfo, time.Now().Add(-time.Hour*24*13-time.Hour))
+
+	assert.NoError(t, service.ProcessDeliveryDeadlines())
+	assert.Equal(t, []string{"delivery.deadline_reminder"}, db.eventTypes())
+	assert.True(t, db.deadlines[lotID].ConfirmReminderSent)
+
+	deadline := db.deadlines[lotID]
+	deadline.FinishedAt = time.Now().Add(-time.Hour * 24 * 15)
+	db.deadlines[lotID] = deadline
+	assert.NoError(t, service.ProcessDeliveryDeadlines())
+	assert.Equal(t, app.LotStatusSent, db.deliveries[lotID].LotStatus, "lot isn't delivered by carrier")
+
+	db.tracking[lotID] = []app.TrackingEvent{{Status: app.TrackingStatusDelivered, Time: time.Now().Add(-time.Hour)}}
+	assert.NoError(t, service.ProcessDeliveryDeadlines())
+	assert.Equal(t, app.LotStatusReceived, db.deliveries[lotID].LotStatus)
+	assert.Equal(t, []string{"delivery.deadline_reminder", "delivery.lot_received"}, db.eventTypes())
+	assert.True(t, db.deadlines[lotID].Closed)
+
+	info, err := service.LotDeliveryInfo(lotID)
+	assert.NoError(t, err)
+	assert.Equal(t, deadline.FinishedAt.Add(testDeadlinePolicy.ShipByPeriod), *info.ShipBy)
+	assert.Equal(t, deadline.FinishedAt.Add(testDeadlinePolicy.ConfirmByPeriod), *info.ConfirmBy)
+}
+
+func (db *testDB) addDeadline(lotInfo *app.LotInfo, finishedAt time.Time) {
+	db.deadlines[lotInfo.ID] = app.DeliveryDeadline{
+		LotID:      lotInfo.ID,
+		SenderID:   lotInfo.OwnerID,
+		ReceiverID: lotInfo.ReceiverID,
+		FinishedAt: finishedAt,
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"context"
+	"time"
+)
+
+func StartDeadlineJob(ctx context.Context, deliveryService *DeliveryService, interval time.Duration, logger *logrus.Logger) {
+	job := deadlineJob{
+		deliveryService: deliveryService,
+		logger:          logger,
+	}
+	job.start(ctx, interval)
+}
+
+type deadlineJob struct {
+	deliveryService *DeliveryService
+	logger          *logrus.Logger
+}
+
+func (job *deadlineJob) start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				job.process()
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (job *deadlineJob) process() {
+	err := job.deliveryService.ProcessDeliveryDeadlines()
+	if err != nil {
+		job.logger.Error(err)
+	}
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"errors"
+	"strings"
+)
+
+var ErrAddressNotFound = errors.New("address not found")
+
+type AddressID uuid.UUID
+
+// Address - structured address from user address book, legacy free text address is kept in Street
+type Address struct {
+	RecipientName string
+	Phone         string
+	Country       string
+	PostalCode    string
+	City          string
+	Street        string
+}
+
+// String - address in single line for shipping label
+func (a Address) String() string {
+	parts := make([]string, 0, 5)
+	for _, part := range []string{a.RecipientName, a.Street, a.City, a.PostalCode, a.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DeliveryAddress - address chosen by lot winner, used when lot is sent
+type DeliveryAddress struct {
+	LotID      LotID
+	ReceiverID UserID
+	AddressID  AddressID
+	Address    Address
+}
+
+type DeliveryAddressRepositoryRead interface {
+	FindByLotID(lotID LotID) (*DeliveryAddress, error)
+}
+
+type DeliveryAddressRepository interface {
+	DeliveryAddressRepositoryRead
+	Store(address *DeliveryAddress) error
+	RemoveAllByReceiverID(receiverID UserID) error
+}
@@ -4,6 +4,7 @@ import (
 	"arch-homework/pkg/common/app/uuid"
 
 	"errors"
+	"time"
 )
 
 var ErrLotNotFound = errors.New("lot not found")
@@ -11,14 +12,18 @@ var ErrLotNotFound = errors.New("lot not found")
 type LotID uuid.UUID
 type LotStatus string
 type TrackingID string
-type Address string
 
 const (
 	LotStatusFinished LotStatus = "finished"
 	LotStatusSent     LotStatus = "sent"
 	LotStatusReceived LotStatus = "received"
+	// LotStatusCancelled - lot was not sent until ship-by deadline, payment is returned to receiver
+	LotStatusCancelled LotStatus = "cancelled"
 )
 
+// DeletedUserLogin - replaces login of deleted user in deliveries
+const DeletedUserLogin = "deleted"
+
 type DeliveryInfo struct {
 	LotID             LotID
 	LotStatus         LotStatus
@@ -32,13 +37,24 @@ type DeliveryInfo struct {
 	SenderLogin       string
 	SenderFirstName   string
 	SenderLastName    string
+	// Shipping - nil if lot has no shipping options
+	Shipping *ShippingQuote
+	// ShipBy, ConfirmBy - deadlines aren't stored with delivery, nil if deadline is disabled or unknown
+	ShipBy    *time.Time
+	ConfirmBy *time.Time
 }
 
 type DeliveryInfoRepositoryRead interface {
 	FindByLotID(id LotID) (*DeliveryInfo, error)
+	FindAllByUserID(userID UserID) ([]DeliveryInfo, error)
+	FindAllByStatus(status LotStatus) ([]DeliveryInfo, error)
 }
 
 type DeliveryInfoRepository interface {
 	DeliveryInfoRepositoryRead
 	Store(info *DeliveryInfo) error
+	// UpdateUserNames - updates login and names of user in deliveries where user is receiver or sender
+	UpdateUserNames(userID UserID, info UserInfo) error
+	// AnonymizeUser - removes personal data of user from deliveries where user is receiver or sender
+	AnonymizeUser(userID UserID) error
 }
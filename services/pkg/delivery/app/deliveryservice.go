@@ -1,8 +1,13 @@
 package app
 
 import (
+	"arch-homework/pkg/common/app/joblock"
 	"arch-homework/pkg/common/app/storedevent"
 
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/pkg/errors"
 )
 
@@ -10,37 +15,211 @@ var ErrAlreadyProcessed = errors.New("request with this id already processed")
 var ErrInvalidLotStatus = errors.New("lot status is invalid for this operation")
 var ErrStatusChangeForbidden = errors.New("delivery status change forbidden for this user")
 
-func NewDeliveryService(dbDependency DBDependency, eventSender storedevent.Sender, lotSvcClient LotServiceClient, userSvcClient UserServiceClient) *DeliveryService {
+const trackingUpdateLockName = "delivery-tracking-update"
+const deadlineProcessingLockName = "delivery-deadline-processing"
+const enrichmentLockName = "delivery-enrichment"
+
+func NewDeliveryService(
+	dbDependency DBDependency,
+	eventSender storedevent.Sender,
+	lotSvcClient LotServiceClient,
+	userSvcClient UserServiceClient,
+	addressBookClient AddressBookClient,
+	billingClient BillingClient,
+	carrierClient CarrierClient,
+	autoConfirmPeriod time.Duration,
+	deadlinePolicy DeadlinePolicy,
+	labelRenderer ShippingLabelRenderer,
+) *DeliveryService {
 	return &DeliveryService{
-		readRepo:      dbDependency.DeliveryInfoRepositoryRead(),
-		trUnitFactory: dbDependency,
-		eventSender:   eventSender,
-		lotSvcClient:  lotSvcClient,
-		userSvcClient: userSvcClient,
+		readRepo:          dbDependency.DeliveryInfoRepositoryRead(),
+		addressReadRepo:   dbDependency.DeliveryAddressRepositoryRead(),
+		profileReadRepo:   dbDependency.UserProfileRepositoryRead(),
+		trackingReadRepo:  dbDependency.TrackingEventRepositoryRead(),
+		deadlineReadRepo:  dbDependency.DeliveryDeadlineRepositoryRead(),
+		paymentReadRepo:   dbDependency.ShippingPaymentRepositoryRead(),
+		enrichmentRepo:    dbDependency.DeliveryEnrichmentRepositoryRead(),
+		trUnitFactory:     dbDependency,
+		eventSender:       eventSender,
+		lotSvcClient:      lotSvcClient,
+		userSvcClient:     userSvcClient,
+		addressBookClient: addressBookClient,
+		billingClient:     billingClient,
+		carrierClient:     carrierClient,
+		autoConfirmPeriod: autoConfirmPeriod,
+		deadlinePolicy:    deadlinePolicy,
+		labelRenderer:     labelRenderer,
 	}
 }
 
 type DeliveryService struct {
-	readRepo      DeliveryInfoRepositoryRead
-	trUnitFactory TransactionalUnitFactory
-	eventSender   storedevent.Sender
-	lotSvcClient  LotServiceClient
-	userSvcClient UserServiceClient
+	readRepo          DeliveryInfoRepositoryRead
+	addressReadRepo   DeliveryAddressRepositoryRead
+	profileReadRepo   UserProfileRepositoryRead
+	trackingReadRepo  TrackingEventRepositoryRead
+	deadlineReadRepo  DeliveryDeadlineRepositoryRead
+	paymentReadRepo   ShippingPaymentRepositoryRead
+	enrichmentRepo    DeliveryEnrichmentRepositoryRead
+	trUnitFactory     TransactionalUnitFactory
+	eventSender       storedevent.Sender
+	lotSvcClient      LotServiceClient
+	userSvcClient     UserServiceClient
+	addressBookClient AddressBookClient
+	billingClient     BillingClient
+	carrierClient     CarrierClient
+	// autoConfirmPeriod - lot delivered by carrier is marked as received after period, zero disables auto confirmation
+	autoConfirmPeriod time.Duration
+	deadlinePolicy    DeadlinePolicy
+	labelRenderer     ShippingLabelRenderer
 }
 
+// LotDeliveryInfo - delivery is stored when lot is won, names and address are filled asynchronously by enrichment
 func (s *DeliveryService) LotDeliveryInfo(lotID LotID) (*DeliveryInfo, error) {
 	info, err := s.readRepo.FindByLotID(lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, err := s.deadlineReadRepo.FindByLotID(lotID)
 	if err == nil {
-		return info, nil
+		info.ShipBy = s.deadlinePolicy.ShipBy(deadline)
+		info.ConfirmBy = s.deadlinePolicy.ConfirmBy(deadline)
+	} else if errors.Cause(err) != ErrDeadlineNotFound {
+		return nil, err
 	}
-	if errors.Cause(err) != ErrLotNotFound {
+	return info, nil
+}
+
+func (s *DeliveryService) LotTrackingHistory(lotID LotID) ([]TrackingEvent, error) {
+	return s.trackingReadRepo.FindAllByLotID(lotID)
+}
+
+// UserDeliveries - deliveries where user is receiver or sender
+func (s *DeliveryService) UserDeliveries(userID UserID) ([]DeliveryInfo, error) {
+	return s.readRepo.FindAllByUserID(userID)
+}
+
+// SelectDeliveryAddress - lot winner can choose address from address book until lot is sent,
+// otherwise default address is used
+func (s *DeliveryService) SelectDeliveryAddress(userID UserID, lotID LotID, addressID AddressID) error {
+	status, err := lotDeliveryStatus(s.readRepo, lotID)
+	if err != nil {
+		return err
+	}
+	if status != LotStatusFinished {
+		return errors.WithStack(ErrInvalidLotStatus)
+	}
+	lotInfo, err := s.lotSvcClient.FindFinishedLotInfo(lotID)
+	if err != nil {
+		return err
+	}
+	if lotInfo.ReceiverID != userID {
+		return errors.WithStack(ErrStatusChangeForbidden)
+	}
+	address, err := s.addressBookClient.GetAddress(userID, addressID)
+	if err != nil {
+		return err
+	}
+
+	return s.executeInLotTransaction(lotID, func(provider RepositoryProvider) error {
+		err := provider.DeliveryAddressRepository().Store(&DeliveryAddress{
+			LotID:      lotID,
+			ReceiverID: userID,
+			AddressID:  addressID,
+			Address:    address,
+		})
+		if err != nil {
+			return err
+		}
+		return updateFinishedDelivery(provider.DeliveryInfoRepository(), lotID, func(info *DeliveryInfo) {
+			info.ReceiverAddress = address
+		})
+	})
+}
+
+// ShippingQuotes - prices of lot shipping options for receiver address, options unavailable for the address are skipped
+func (s *DeliveryService) ShippingQuotes(userID UserID, lotID LotID) ([]ShippingQuote, error) {
+	lotInfo, err := s.lotSvcClient.FindFinishedLotInfo(lotID)
+	if err != nil {
+		return nil, err
+	}
+	if lotInfo.ReceiverID != userID && lotInfo.OwnerID != userID {
+		return nil, errors.WithStack(ErrStatusChangeForbidden)
+	}
+	address, err := s.lotReceiverAddress(lotInfo)
+	if err != nil {
 		return nil, err
 	}
-	return s.deliveryInfoFromServices(lotID)
+	return quoteShippingOptions(lotInfo, address), nil
 }
 
+// SelectShippingOption - lot winner can choose shipping option until lot is sent,
+// shipping price is blocked on winner account instead of price of previously chosen option,
+// shipping payment interrupted earlier is completed first
+func (s *DeliveryService) SelectShippingOption(userID UserID, lotID LotID, optionName string) (ShippingQuote, error) {
+	status, err := lotDeliveryStatus(s.readRepo, lotID)
+	if err != nil {
+		return ShippingQuote{}, err
+	}
+	if status != LotStatusFinished {
+		return ShippingQuote{}, errors.WithStack(ErrInvalidLotStatus)
+	}
+	lotInfo, err := s.lotSvcClient.FindFinishedLotInfo(lotID)
+	if err != nil {
+		return ShippingQuote{}, err
+	}
+	if lotInfo.ReceiverID != userID {
+		return ShippingQuote{}, errors.WithStack(ErrStatusChangeForbidden)
+	}
+	address, err := s.lotReceiverAddress(lotInfo)
+	if err != nil {
+		return ShippingQuote{}, err
+	}
+	quote, err := quoteShippingOption(lotInfo, address, optionName)
+	if err != nil {
+		return ShippingQuote{}, errors.WithStack(err)
+	}
+	err = s.completeShippingPayment(lotID)
+	if err != nil {
+		return ShippingQuote{}, err
+	}
+
+	var payment *ShippingPayment
+	err = s.executeInLotTransaction(lotID, func(provider RepositoryProvider) error {
+		// lot could be sent after check
+		status, err := lotDeliveryStatus(provider.DeliveryInfoRepository(), lotID)
+		if err != nil {
+			return err
+		}
+		if status != LotStatusFinished {
+			return errors.WithStack(ErrInvalidLotStatus)
+		}
+		payment, err = startShippingPayment(provider, lotID, userID, quote)
+		return err
+	})
+	if err != nil {
+		return ShippingQuote{}, err
+	}
+	err = s.payShipping(payment)
+	if err != nil {
+		return ShippingQuote{}, err
+	}
+	return quote, nil
+}
+
+// SetLotSent - shipping option should be selected by winner if lot has shipping options,
+// its price is checked again for final delivery address
 func (s *DeliveryService) SetLotSent(requestID RequestID, userID UserID, lotID LotID, trackingID TrackingID) error {
-	deliveryInfo, err := s.deliveryInfoFromServices(lotID)
+	err := s.carrierClient.ValidateTrackingNumber(trackingID)
+	if err != nil {
+		return err
+	}
+
+	lotInfo, err := s.lotSvcClient.FindFinishedLotInfo(lotID)
+	if err != nil {
+		return err
+	}
+	deliveryInfo, err := s.deliveryInfoFromLot(lotInfo)
 	if err != nil {
 		return err
 	}
@@ -48,8 +227,12 @@ func (s *DeliveryService) SetLotSent(requestID RequestID, userID UserID, lotID L
 	if deliveryInfo.SenderID != userID {
 		return errors.WithStack(ErrStatusChangeForbidden)
 	}
+	err = s.completeShippingPayment(lotID)
+	if err != nil {
+		return err
+	}
 
-	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+	err = s.executeInLotTransaction(lotID, func(provider RepositoryProvider) error {
 		eventRepo := provider.ProcessedRequestRepository()
 		alreadyProcessed, err := eventRepo.SetRequestProcessed(requestID)
 		if err != nil {
@@ -59,6 +242,25 @@ func (s *DeliveryService) SetLotSent(requestID RequestID, userID UserID, lotID L
 			return ErrAlreadyProcessed
 		}
 
+		// lot could be cancelled by ship-by deadline
+		status, err := lotDeliveryStatus(provider.DeliveryInfoRepository(), lotID)
+		if err != nil {
+			return err
+		}
+		if status != LotStatusFinished && status != LotStatusSent {
+			return errors.WithStack(ErrInvalidLotStatus)
+		}
+		// shipping option could be selected after completion
+		err = checkNoShippingPayment(provider, lotID)
+		if err != nil {
+			return err
+		}
+
+		deliveryInfo.Shipping, err = s.checkedShipping(provider.ShippingSelectionRepository(), lotInfo, deliveryInfo.ReceiverAddress)
+		if err != nil {
+			return err
+		}
+
 		deliveryInfoRepo := provider.DeliveryInfoRepository()
 		deliveryInfo.LotStatus = LotStatusSent
 		deliveryInfo.TrackingID = &trackingID
@@ -83,7 +285,7 @@ func (s *DeliveryService) SetLotSent(requestID RequestID, userID UserID, lotID L
 }
 
 func (s *DeliveryService) SetLotReceived(requestID RequestID, userID UserID, lotID LotID) error {
-	err := s.executeInTransaction(func(provider RepositoryProvider) error {
+	err := s.executeInLotTransaction(lotID, func(provider RepositoryProvider) error {
 		eventRepo := provider.ProcessedRequestRepository()
 		alreadyProcessed, err := eventRepo.SetRequestProcessed(requestID)
 		if err != nil {
@@ -93,8 +295,7 @@ func (s *DeliveryService) SetLotReceived(requestID RequestID, userID UserID, lot
 			return ErrAlreadyProcessed
 		}
 
-		deliveryInfoRepo := provider.DeliveryInfoRepository()
-		info, err := deliveryInfoRepo.FindByLotID(lotID)
+		info, err := provider.DeliveryInfoRepository().FindByLotID(lotID)
 		if err != nil {
 			return err
 		}
@@ -104,16 +305,212 @@ func (s *DeliveryService) SetLotReceived(requestID RequestID, userID UserID, lot
 		if info.ReceiverID != userID {
 			return errors.WithStack(ErrStatusChangeForbidden)
 		}
+		return s.storeLotReceived(provider, info)
+	})
+	if err != nil {
+		return err
+	}
+	s.eventSender.SendStoredEvents()
+	return nil
+}
+
+// UpdateTrackingStatuses - stores carrier events of sent lots,
+// lots delivered earlier than auto confirm period ago are marked as received
+func (s *DeliveryService) UpdateTrackingStatuses() error {
+	return s.executeWithLock(trackingUpdateLockName, s.updateTrackingStatuses)
+}
+
+func (s *DeliveryService) updateTrackingStatuses() error {
+	infos, err := s.readRepo.FindAllByStatus(LotStatusSent)
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for i := range infos {
+		if err2 := s.updateTrackingStatus(&infos[i]); err2 != nil {
+			errs = append(errs, fmt.Sprintf("lot %s: %s", infos[i].LotID, err2))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("tracking status update failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (s *DeliveryService) updateTrackingStatus(info *DeliveryInfo) error {
+	if info.TrackingID == nil {
+		return nil
+	}
+	events, err := s.carrierClient.GetTrackingEvents(*info.TrackingID)
+	if err != nil {
+		return err
+	}
+
+	err = s.executeInLotTransaction(info.LotID, func(provider RepositoryProvider) error {
+		trackingRepo := provider.TrackingEventRepository()
+		for _, event := range events {
+			if err := trackingRepo.Add(info.LotID, event); err != nil {
+				return err
+			}
+		}
+
+		deliveredTime := findDeliveredTime(events)
+		if s.autoConfirmPeriod <= 0 || deliveredTime == nil || time.Since(*deliveredTime) < s.autoConfirmPeriod {
+			return nil
+		}
+		// receiver could confirm receipt after lots were selected
+		current, err := provider.DeliveryInfoRepository().FindByLotID(info.LotID)
+		if err != nil {
+			return err
+		}
+		if current.LotStatus != LotStatusSent {
+			return nil
+		}
+		return s.storeLotReceived(provider, current)
+	})
+	if err != nil {
+		return err
+	}
+	s.eventSender.SendStoredEvents()
+	return nil
+}
+
+// ProcessDeliveryDeadlines - sends reminders before deadlines of won lots,
+// lot not sent until ship-by deadline is cancelled, lot delivered by carrier but not confirmed until confirm-by deadline is marked as received
+func (s *DeliveryService) ProcessDeliveryDeadlines() error {
+	return s.executeWithLock(deadlineProcessingLockName, s.processDeliveryDeadlines)
+}
 
-		event := NewLotReceivedEvent(lotID)
+func (s *DeliveryService) processDeliveryDeadlines() error {
+	deadlines, err := s.deadlineReadRepo.FindAllOpen()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var errs []string
+	for i := range deadlines {
+		if err2 := s.processDeliveryDeadline(&deadlines[i], now); err2 != nil {
+			errs = append(errs, fmt.Sprintf("lot %s: %s", deadlines[i].LotID, err2))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("delivery deadline processing failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (s *DeliveryService) processDeliveryDeadline(deadline *DeliveryDeadline, now time.Time) error {
+	status, err := lotDeliveryStatus(s.readRepo, deadline.LotID)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case LotStatusFinished:
+		shipBy := s.deadlinePolicy.ShipBy(deadline)
+		if shipBy == nil {
+			return nil
+		}
+		if now.Before(*shipBy) {
+			if deadline.ShipReminderSent || !reminderDue(*shipBy, s.deadlinePolicy.ReminderLead, now) {
+				return nil
+			}
+			return s.sendDeadlineReminder(deadline.LotID, status, DeadlineShipBy, *shipBy)
+		}
+		return s.cancelUnsentLot(deadline.LotID)
+	case LotStatusSent:
+		confirmBy := s.deadlinePolicy.ConfirmBy(deadline)
+		if confirmBy == nil {
+			return nil
+		}
+		if now.Before(*confirmBy) {
+			if deadline.ConfirmReminderSent || !reminderDue(*confirmBy, s.deadlinePolicy.ReminderLead, now) {
+				return nil
+			}
+			return s.sendDeadlineReminder(deadline.LotID, status, DeadlineConfirmBy, *confirmBy)
+		}
+		return s.confirmDeliveredLot(deadline.LotID)
+	default:
+		return s.executeInLotTransaction(deadline.LotID, func(provider RepositoryProvider) error {
+			return closeDeliveryDeadline(provider, deadline.LotID)
+		})
+	}
+}
+
+// sendDeadlineReminder - reminder is sent once, if lot status was not changed after deadlines were selected
+func (s *DeliveryService) sendDeadlineReminder(lotID LotID, status LotStatus, deadlineType DeadlineType, deadlineTime time.Time) error {
+	err := s.executeInLotTransaction(lotID, func(provider RepositoryProvider) error {
+		currentStatus, err := lotDeliveryStatus(provider.DeliveryInfoRepository(), lotID)
+		if err != nil || currentStatus != status {
+			return err
+		}
+		deadlineRepo := provider.DeliveryDeadlineRepository()
+		deadline, err := deadlineRepo.FindByLotID(lotID)
+		if err != nil {
+			return err
+		}
+
+		userID := deadline.SenderID
+		if deadlineType == DeadlineShipBy {
+			if deadline.ShipReminderSent {
+				return nil
+			}
+			deadline.ShipReminderSent = true
+		} else {
+			if deadline.ConfirmReminderSent {
+				return nil
+			}
+			deadline.ConfirmReminderSent = true
+			userID = deadline.ReceiverID
+		}
+
+		event := NewDeadlineReminderEvent(lotID, userID, deadlineType, deadlineTime)
 		err = provider.EventStore().Add(event)
 		if err != nil {
 			return err
 		}
 		s.eventSender.EventStored(event.UID)
+		return deadlineRepo.Store(deadline)
+	})
+	if err != nil {
+		return err
+	}
+	s.eventSender.SendStoredEvents()
+	return nil
+}
 
-		info.LotStatus = LotStatusReceived
-		return deliveryInfoRepo.Store(info)
+// cancelUnsentLot - lot service closes lot and releases lot payment, billing releases shipping payment after delivery.lot_cancelled event
+func (s *DeliveryService) cancelUnsentLot(lotID LotID) error {
+	// payment blocked after delivery.lot_cancelled event wouldn't be released
+	err := s.completeShippingPayment(lotID)
+	if err != nil {
+		return err
+	}
+
+	err = s.executeInLotTransaction(lotID, func(provider RepositoryProvider) error {
+		// lot could be sent after check
+		info, err := provider.DeliveryInfoRepository().FindByLotID(lotID)
+		if err != nil || info.LotStatus != LotStatusFinished {
+			return err
+		}
+		err = checkNoShippingPayment(provider, lotID)
+		if err != nil {
+			return err
+		}
+
+		info.LotStatus = LotStatusCancelled
+		err = provider.DeliveryInfoRepository().Store(info)
+		if err != nil {
+			return err
+		}
+
+		event := NewLotCancelledEvent(lotID, info.ReceiverID, info.SenderID, LotCancelledReasonNotShipped)
+		err = provider.EventStore().Add(event)
+		if err != nil {
+			return err
+		}
+		s.eventSender.EventStored(event.UID)
+		return closeDeliveryDeadline(provider, lotID)
 	})
 	if err != nil {
 		return err
@@ -122,16 +519,198 @@ func (s *DeliveryService) SetLotReceived(requestID RequestID, userID UserID, lot
 	return nil
 }
 
-func (s *DeliveryService) deliveryInfoFromServices(lotID LotID) (*DeliveryInfo, error) {
-	lotInfo, err := s.lotSvcClient.FindFinishedLotInfo(lotID)
+// confirmDeliveredLot - lot is kept sent until carrier reports delivery
+func (s *DeliveryService) confirmDeliveredLot(lotID LotID) error {
+	events, err := s.trackingReadRepo.FindAllByLotID(lotID)
+	if err != nil {
+		return err
+	}
+	if findDeliveredTime(events) == nil {
+		return nil
+	}
+
+	err = s.executeInLotTransaction(lotID, func(provider RepositoryProvider) error {
+		// receiver could confirm receipt after check
+		current, err := provider.DeliveryInfoRepository().FindByLotID(lotID)
+		if err != nil {
+			return err
+		}
+		if current.LotStatus != LotStatusSent {
+			return nil
+		}
+		return s.storeLotReceived(provider, current)
+	})
+	if err != nil {
+		return err
+	}
+	s.eventSender.SendStoredEvents()
+	return nil
+}
+
+// EnrichDeliveries - fills user names and receiver address of deliveries created from lot.lot_won events,
+// failed enrichment is retried with growing delay
+func (s *DeliveryService) EnrichDeliveries() error {
+	return s.executeWithLock(enrichmentLockName, s.enrichDeliveries)
+}
+
+func (s *DeliveryService) enrichDeliveries() error {
+	now := time.Now()
+	enrichments, err := s.enrichmentRepo.FindAllDue(now)
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for i := range enrichments {
+		enrichment := enrichments[i]
+		err2 := s.enrichDelivery(enrichment.LotID)
+		if err2 == nil {
+			continue
+		}
+		errs = append(errs, fmt.Sprintf("lot %s: %s", enrichment.LotID, err2))
+
+		enrichment.Attempts++
+		enrichment.NextAttemptAt = now.Add(enrichmentRetryDelay(enrichment.Attempts))
+		err2 = s.executeInTransaction(func(provider RepositoryProvider) error {
+			return provider.DeliveryEnrichmentRepository().Store(&enrichment)
+		})
+		if err2 != nil {
+			errs = append(errs, fmt.Sprintf("lot %s: %s", enrichment.LotID, err2))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("delivery enrichment failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// BackfillDeliveries - stores deliveries of lots won before lot.lot_won events were handled, they are filled by enrichment.
+// Deadlines of such lots are counted from backfill time like for lot.lot_won events without finish time
+func (s *DeliveryService) BackfillDeliveries() error {
+	lots, err := s.lotSvcClient.FindAllFinishedLots()
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, lot := range lots {
+		if err2 := s.backfillDelivery(lot); err2 != nil {
+			errs = append(errs, fmt.Sprintf("lot %s: %s", lot.ID, err2))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("delivery backfill failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (s *DeliveryService) backfillDelivery(lot FinishedLot) error {
+	return s.executeInLotTransaction(lot.ID, func(provider RepositoryProvider) error {
+		_, err := provider.DeliveryInfoRepository().FindByLotID(lot.ID)
+		if err == nil {
+			return nil
+		}
+		if errors.Cause(err) != ErrLotNotFound {
+			return err
+		}
+
+		deadlineRepo := provider.DeliveryDeadlineRepository()
+		_, err = deadlineRepo.FindByLotID(lot.ID)
+		if errors.Cause(err) == ErrDeadlineNotFound {
+			err = deadlineRepo.Store(&DeliveryDeadline{
+				LotID:      lot.ID,
+				SenderID:   lot.OwnerID,
+				ReceiverID: lot.ReceiverID,
+				FinishedAt: time.Now(),
+			})
+		}
+		if err != nil {
+			return err
+		}
+		return storeWonLotDelivery(provider, lot.ID, lot.ReceiverID, lot.OwnerID)
+	})
+}
+
+// enrichDelivery - sent delivery already has names and address of the moment it was sent
+func (s *DeliveryService) enrichDelivery(lotID LotID) error {
+	info, err := s.readRepo.FindByLotID(lotID)
+	if err != nil {
+		return err
+	}
+	var senderInfo, receiverInfo UserInfo
+	var address Address
+	if info.LotStatus == LotStatusFinished {
+		senderInfo, err = s.userInfo(info.SenderID)
+		if err != nil {
+			return err
+		}
+		receiverInfo, err = s.userInfo(info.ReceiverID)
+		if err != nil {
+			return err
+		}
+		address, err = s.receiverAddress(lotID, info.ReceiverID, receiverInfo)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.executeInLotTransaction(lotID, func(provider RepositoryProvider) error {
+		err := updateFinishedDelivery(provider.DeliveryInfoRepository(), lotID, func(info *DeliveryInfo) {
+			info.SenderLogin = senderInfo.Login
+			info.SenderFirstName = senderInfo.FirstName
+			info.SenderLastName = senderInfo.LastName
+			info.ReceiverLogin = receiverInfo.Login
+			info.ReceiverFirstName = receiverInfo.FirstName
+			info.ReceiverLastName = receiverInfo.LastName
+			info.ReceiverAddress = address
+		})
+		if err != nil {
+			return err
+		}
+		return provider.DeliveryEnrichmentRepository().Remove(lotID)
+	})
+}
+
+func (s *DeliveryService) storeLotReceived(provider RepositoryProvider, info *DeliveryInfo) error {
+	event := NewLotReceivedEvent(info.LotID)
+	err := provider.EventStore().Add(event)
+	if err != nil {
+		return err
+	}
+	s.eventSender.EventStored(event.UID)
+
+	info.LotStatus = LotStatusReceived
+	err = provider.DeliveryInfoRepository().Store(info)
+	if err != nil {
+		return err
+	}
+	return closeDeliveryDeadline(provider, info.LotID)
+}
+
+// checkedShipping - selected shipping option with price unchanged for receiver address
+func (s *DeliveryService) checkedShipping(selectionRepo ShippingSelectionRepositoryRead, lotInfo *LotInfo, address Address) (*ShippingQuote, error) {
+	if len(lotInfo.ShippingOptions) == 0 {
+		return nil, nil
+	}
+	selection, err := selectionRepo.FindByLotID(lotInfo.ID)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := quoteShippingOption(lotInfo, address, selection.OptionName)
+	if err != nil || quote.Price.RawValue() != selection.Price.RawValue() {
+		return nil, errors.WithStack(ErrShippingQuoteChanged)
+	}
+	return &selection.ShippingQuote, nil
+}
+
+func (s *DeliveryService) deliveryInfoFromLot(lotInfo *LotInfo) (*DeliveryInfo, error) {
+	ownerInfo, err := s.userInfo(lotInfo.OwnerID)
 	if err != nil {
 		return nil, err
 	}
-	ownerInfo, err := s.userSvcClient.GetUserInfo(lotInfo.OwnerID)
+	receiverInfo, err := s.userInfo(lotInfo.ReceiverID)
 	if err != nil {
 		return nil, err
 	}
-	receiverInfo, err := s.userSvcClient.GetUserInfo(lotInfo.ReceiverID)
+	receiverAddress, err := s.receiverAddress(lotInfo.ID, lotInfo.ReceiverID, receiverInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +722,7 @@ func (s *DeliveryService) deliveryInfoFromServices(lotID LotID) (*DeliveryInfo,
 		ReceiverLogin:     receiverInfo.Login,
 		ReceiverFirstName: receiverInfo.FirstName,
 		ReceiverLastName:  receiverInfo.LastName,
-		ReceiverAddress:   Address(receiverInfo.Address),
+		ReceiverAddress:   receiverAddress,
 		SenderID:          lotInfo.OwnerID,
 		SenderLogin:       ownerInfo.Login,
 		SenderFirstName:   ownerInfo.FirstName,
@@ -152,6 +731,90 @@ func (s *DeliveryService) deliveryInfoFromServices(lotID LotID) (*DeliveryInfo,
 	return &deliveryInfo, nil
 }
 
+// userInfo - profile from read model or from user service if profile update was not received yet
+func (s *DeliveryService) userInfo(userID UserID) (UserInfo, error) {
+	info, err := s.profileReadRepo.FindByID(userID)
+	if err == nil {
+		return *info, nil
+	}
+	if errors.Cause(err) != ErrUserProfileNotFound {
+		return UserInfo{}, err
+	}
+	return s.userSvcClient.GetUserInfo(userID)
+}
+
+func (s *DeliveryService) lotReceiverAddress(lotInfo *LotInfo) (Address, error) {
+	receiverInfo, err := s.userInfo(lotInfo.ReceiverID)
+	if err != nil {
+		return Address{}, err
+	}
+	return s.receiverAddress(lotInfo.ID, lotInfo.ReceiverID, receiverInfo)
+}
+
+// receiverAddress - address chosen by receiver, default address from address book
+// or free text address from profile if address book is empty
+func (s *DeliveryService) receiverAddress(lotID LotID, receiverID UserID, receiverInfo UserInfo) (Address, error) {
+	selected, err := s.addressReadRepo.FindByLotID(lotID)
+	if err == nil {
+		return selected.Address, nil
+	}
+	if errors.Cause(err) != ErrAddressNotFound {
+		return Address{}, err
+	}
+	address, err := s.addressBookClient.GetDefaultAddress(receiverID)
+	if err == nil {
+		return address, nil
+	}
+	if errors.Cause(err) != ErrAddressNotFound {
+		return Address{}, err
+	}
+	return Address{
+		RecipientName: strings.TrimSpace(receiverInfo.FirstName + " " + receiverInfo.LastName),
+		Street:        receiverInfo.Address,
+	}, nil
+}
+
+// lotDeliveryStatus - lot without stored delivery is finished, it is won before lot.lot_won events were handled
+func lotDeliveryStatus(repo DeliveryInfoRepositoryRead, lotID LotID) (LotStatus, error) {
+	info, err := repo.FindByLotID(lotID)
+	if err == nil {
+		return info.LotStatus, nil
+	}
+	if errors.Cause(err) != ErrLotNotFound {
+		return "", err
+	}
+	return LotStatusFinished, nil
+}
+
+// updateFinishedDelivery - delivery of lot won before lot.lot_won events were handled is not stored until backfill
+func updateFinishedDelivery(repo DeliveryInfoRepository, lotID LotID, f func(info *DeliveryInfo)) error {
+	info, err := repo.FindByLotID(lotID)
+	if err != nil {
+		if errors.Cause(err) == ErrLotNotFound {
+			return nil
+		}
+		return err
+	}
+	if info.LotStatus != LotStatusFinished {
+		return nil
+	}
+	f(info)
+	return repo.Store(info)
+}
+
+func closeDeliveryDeadline(provider RepositoryProvider, lotID LotID) error {
+	deadlineRepo := provider.DeliveryDeadlineRepository()
+	deadline, err := deadlineRepo.FindByLotID(lotID)
+	if err != nil {
+		if errors.Cause(err) == ErrDeadlineNotFound {
+			return nil
+		}
+		return err
+	}
+	deadline.Closed = true
+	return deadlineRepo.Store(deadline)
+}
+
 func (s *DeliveryService) executeInTransaction(f func(RepositoryProvider) error) (err error) {
 	var trUnit TransactionalUnit
 	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
@@ -164,3 +827,26 @@ func (s *DeliveryService) executeInTransaction(f func(RepositoryProvider) error)
 	err = f(trUnit)
 	return err
 }
+
+func (s *DeliveryService) executeWithLock(lockName string, f func() error) error {
+	return joblock.Run(func() (joblock.LockTransaction, error) {
+		return s.trUnitFactory.NewTransactionalUnit()
+	}, lockName, f)
+}
+
+// executeInLotTransaction - lot status changes are serialized with lock
+func (s *DeliveryService) executeInLotTransaction(lotID LotID, f func(RepositoryProvider) error) (err error) {
+	var trUnit TransactionalUnit
+	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	if err = trUnit.AddLock("delivery-lot-" + string(lotID)); err != nil {
+		return err
+	}
+	err = f(trUnit)
+	return err
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"time"
+)
+
+const enrichmentRetryMinDelay = time.Second * 30
+const enrichmentRetryMaxDelay = time.Hour
+
+// DeliveryEnrichment - delivery created from lot.lot_won event waits for user names and receiver address from other services
+type DeliveryEnrichment struct {
+	LotID         LotID
+	Attempts      int
+	NextAttemptAt time.Time
+}
+
+type DeliveryEnrichmentRepositoryRead interface {
+	// FindAllDue - enrichments with next attempt time not after t
+	FindAllDue(t time.Time) ([]DeliveryEnrichment, error)
+}
+
+type DeliveryEnrichmentRepository interface {
+	DeliveryEnrichmentRepositoryRead
+	Store(enrichment *DeliveryEnrichment) error
+	Remove(lotID LotID) error
+}
+
+// enrichmentRetryDelay - delay is doubled after each failed attempt
+func enrichmentRetryDelay(attempts int) time.Duration {
+	delay := enrichmentRetryMinDelay
+	for i := 1; i < attempts && delay < enrichmentRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > enrichmentRetryMaxDelay {
+		return enrichmentRetryMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,138 @@
+package app_test
+
+import (
+	"arch-homework/pkg/common/app/integrationevent"
+	"arch-homework/pkg/delivery/app"
+	infraintegrationevent "arch-homework/pkg/delivery/infrastructure/integrationevent"
+	"arch-homework/pkg/delivery/infrastructure/transport/carrier"
+
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLotWonDeliveryEnrichedWithRetries(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	userClient := &testUserClient{failed: true, users: map[app.UserID]app.UserInfo{
+		lotInfo.ReceiverID: {Login: "winner", FirstName: "Ivan", LastName: "Ivanov"},
+	}}
+	addressBook := testAddressBookClient{addresses: map[app.UserID]app.Address{
+		lotInfo.ReceiverID: {RecipientName: "Ivan Ivanov", Country: "RU", City: "Kazan"},
+	}}
+	// lot service is not used for reads and enrichment
+	service := app.NewDeliveryService(db, testEventSender{}, nil, userClient, addressBook, nil, carrier.NewFakeClient(), 0, app.DeadlinePolicy{}, nil)
+	db.profiles[lotInfo.OwnerID] = app.UserInfo{Login: "seller"}
+
+	handleTestLotWonEvent(t, db, lotInfo, time.Now())
+	info, err := service.LotDeliveryInfo(lotInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, app.LotStatusFinished, info.LotStatus)
+	assert.Equal(t, "seller", info.SenderLogin)
+	assert.Equal(t, "", info.ReceiverLogin)
+	assert.Contains(t, db.deadlines, lotInfo.ID)
+
+	assert.Error(t, service.EnrichDeliveries())
+	enrichment := db.enrichment[lotInfo.ID]
+	assert.Equal(t, 1, enrichment.Attempts)
+	assert.True(t, enrichment.NextAttemptAt.After(time.Now()))
+
+	// retry is delayed
+	userClient.failed = false
+	assert.NoError(t, service.EnrichDeliveries())
+	assert.Equal(t, "", db.deliveries[lotInfo.ID].ReceiverLogin)
+
+	enrichment.NextAttemptAt = time.Now()
+	db.enrichment[lotInfo.ID] = enrichment
+	assert.NoError(t, service.EnrichDeliveries())
+	info, err = service.LotDeliveryInfo(lotInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "winner", info.ReceiverLogin)
+	assert.Equal(t, "Ivanov", info.ReceiverLastName)
+	assert.Equal(t, "Kazan", info.ReceiverAddress.City)
+	assert.Empty(t, db.enrichment)
+
+	deliveries, err := service.UserDeliveries(lotInfo.OwnerID)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+}
+
+func TestLotWonEventDoesNotOverwriteSentDelivery(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	lotID := db.addSentDelivery(testTrackingID)
+	lotInfo.ID = lotID
+
+	handleTestLotWonEvent(t, db, lotInfo, time.Now())
+	assert.Equal(t, app.LotStatusSent, db.deliveries[lotID].LotStatus)
+	assert.Empty(t, db.enrichment)
+}
+
+func TestDeliveryOfLotWonBeforeLotWonEventsBackfilled(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	service := newTestShippingService(db, lotInfo, nil)
+
+	_, err := service.LotDeliveryInfo(lotInfo.ID)
+	assert.Equal(t, app.ErrLotNotFound, errorsCause(err), "delivery isn't requested from other services on read")
+
+	before := time.Now()
+	assert.NoError(t, service.BackfillDeliveries())
+	info := db.deliveries[lotInfo.ID]
+	assert.Equal(t, app.LotStatusFinished, info.LotStatus)
+	assert.Equal(t, lotInfo.OwnerID, info.SenderID)
+	assert.Equal(t, "seller", info.SenderLogin)
+	assert.Contains(t, db.enrichment, lotInfo.ID)
+	assert.False(t, db.deadlines[lotInfo.ID].FinishedAt.Before(before), "deadlines are counted from backfill")
+
+	assert.NoError(t, service.EnrichDeliveries())
+	info = db.deliveries[lotInfo.ID]
+	assert.Equal(t, "Kazan", info.ReceiverAddress.City)
+	assert.Empty(t, db.enrichment)
+}
+
+func TestBackfillKeepsStoredDelivery(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	service := newTestShippingService(db, lotInfo, nil)
+	handleTestLotWonEvent(t, db, lotInfo, time.Now().Add(-time.Hour))
+	assert.NoError(t, service.EnrichDeliveries())
+	stored := db.deliveries[lotInfo.ID]
+	deadline := db.deadlines[lotInfo.ID]
+
+	assert.NoError(t, service.BackfillDeliveries())
+	assert.Equal(t, stored, db.deliveries[lotInfo.ID])
+	assert.Equal(t, deadline, db.deadlines[lotInfo.ID])
+	assert.Empty(t, db.enrichment)
+}
+
+func handleTestLotWonEvent(t *testing.T, db *testDB, lotInfo *app.LotInfo, finishedAt time.Time) {
+	body, _ := json.Marshal(map[string]string{
+		"lot_id":       string(lotInfo.ID),
+		"user_id":      string(lotInfo.ReceiverID),
+		"lot_owner_id": string(lotInfo.OwnerID),
+		"finished_at":  finishedAt.UTC().Format(time.RFC3339),
+	})
+	handler := app.NewEventHandler(db, infraintegrationevent.NewEventParser())
+	err := handler.Handle(integrationevent.EventData{
+		UID:  integrationevent.EventUID(newLotID()),
+		Type: "lot.lot_won",
+		Body: string(body),
+	})
+	assert.NoError(t, err)
+}
+
+type testUserClient struct {
+	users  map[app.UserID]app.UserInfo
+	failed bool
+}
+
+func (c *testUserClient) GetUserInfo(userID app.UserID) (app.UserInfo, error) {
+	if c.failed {
+		return app.UserInfo{}, errors.New("user service unavailable")
+	}
+	return c.users[userID], nil
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"context"
+	"time"
+)
+
+func StartEnrichmentJob(ctx context.Context, deliveryService *DeliveryService, interval time.Duration, logger *logrus.Logger) {
+	job := enrichmentJob{
+		deliveryService: deliveryService,
+		logger:          logger,
+	}
+	job.start(ctx, interval)
+}
+
+type enrichmentJob struct {
+	deliveryService *DeliveryService
+	logger          *logrus.Logger
+}
+
+func (job *enrichmentJob) start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				job.enrich()
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (job *enrichmentJob) enrich() {
+	err := job.deliveryService.EnrichDeliveries()
+	if err != nil {
+		job.logger.Error(err)
+	}
+}
@@ -4,10 +4,16 @@ import (
 	"arch-homework/pkg/common/app/integrationevent"
 	"arch-homework/pkg/common/app/uuid"
 	"encoding/json"
+	"time"
 )
 
 const typeLotSent = "delivery.lot_sent"
 const typeLotReceived = "delivery.lot_received"
+const typeLotCancelled = "delivery.lot_cancelled"
+const typeDeadlineReminder = "delivery.deadline_reminder"
+
+// LotCancelledReasonNotShipped - lot was not sent until ship-by deadline
+const LotCancelledReasonNotShipped = "not_shipped"
 
 func NewLotSentEvent(lotID LotID) integrationevent.EventData {
 	body, _ := json.Marshal(lotDeliveryEventBody{
@@ -33,6 +39,36 @@ func NewLotReceivedEvent(lotID LotID) integrationevent.EventData {
 	}
 }
 
+func NewLotCancelledEvent(lotID LotID, receiverID, senderID UserID, reason string) integrationevent.EventData {
+	body, _ := json.Marshal(lotCancelledEventBody{
+		LotID:      string(lotID),
+		UserID:     string(receiverID),
+		LotOwnerID: string(senderID),
+		Reason:     reason,
+	})
+
+	return integrationevent.EventData{
+		UID:  newUID(),
+		Type: typeLotCancelled,
+		Body: string(body),
+	}
+}
+
+func NewDeadlineReminderEvent(lotID LotID, userID UserID, deadlineType DeadlineType, deadline time.Time) integrationevent.EventData {
+	body, _ := json.Marshal(deadlineReminderEventBody{
+		LotID:        string(lotID),
+		UserID:       string(userID),
+		DeadlineType: string(deadlineType),
+		Deadline:     deadline.UTC().Format(time.RFC3339),
+	})
+
+	return integrationevent.EventData{
+		UID:  newUID(),
+		Type: typeDeadlineReminder,
+		Body: string(body),
+	}
+}
+
 func newUID() integrationevent.EventUID {
 	return integrationevent.EventUID(uuid.GenerateNew())
 }
@@ -40,3 +76,17 @@ func newUID() integrationevent.EventUID {
 type lotDeliveryEventBody struct {
 	LotID string `json:"lot_id"`
 }
+
+type lotCancelledEventBody struct {
+	LotID      string `json:"lot_id"`
+	UserID     string `json:"user_id"`
+	LotOwnerID string `json:"lot_owner_id"`
+	Reason     string `json:"reason"`
+}
+
+type deadlineReminderEventBody struct {
+	LotID        string `json:"lot_id"`
+	UserID       string `json:"user_id"`
+	DeadlineType string `json:"deadline_type"`
+	Deadline     string `json:"deadline"`
+}
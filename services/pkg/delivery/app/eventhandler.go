@@ -0,0 +1,145 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/integrationevent"
+
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type IntegrationEventParser interface {
+	ParseIntegrationEvent(event integrationevent.EventData) (HandledEvent, error)
+}
+
+type ProcessedEventRepository interface {
+	SetEventProcessed(uid integrationevent.EventUID) (alreadyProcessed bool, err error)
+}
+
+func NewEventHandler(trUnitFactory TransactionalUnitFactory, parser IntegrationEventParser) integrationevent.EventHandler {
+	return &eventHandler{
+		trUnitFactory: trUnitFactory,
+		parser:        parser,
+	}
+}
+
+type eventHandler struct {
+	trUnitFactory TransactionalUnitFactory
+	parser        IntegrationEventParser
+}
+
+func (handler *eventHandler) Handle(event integrationevent.EventData) error {
+	parsedEvent, err := handler.parser.ParseIntegrationEvent(event)
+	if err != nil || parsedEvent == nil {
+		return err
+	}
+
+	return handler.executeInTransaction(func(trUnit TransactionalUnit) error {
+		alreadyProcessed, err := trUnit.ProcessedEventRepository().SetEventProcessed(event.UID)
+		if err != nil {
+			return err
+		}
+		if alreadyProcessed {
+			return nil
+		}
+
+		switch e := parsedEvent.(type) {
+		case userDeletedEvent:
+			err = trUnit.DeliveryAddressRepository().RemoveAllByReceiverID(e.userID)
+			if err != nil {
+				return err
+			}
+			err = trUnit.UserProfileRepository().Remove(e.userID)
+			if err != nil {
+				return err
+			}
+			return trUnit.DeliveryInfoRepository().AnonymizeUser(e.userID)
+		case userProfileUpdatedEvent:
+			err = trUnit.UserProfileRepository().Store(e.userID, e.info)
+			if err != nil {
+				return err
+			}
+			return trUnit.DeliveryInfoRepository().UpdateUserNames(e.userID, e.info)
+		case lotWonEvent:
+			return handleLotWonEvent(trUnit, e)
+		default:
+			return nil
+		}
+	})
+}
+
+// handleLotWonEvent - delivery isn't stored if lot was sent before event was handled
+func handleLotWonEvent(provider RepositoryProvider, e lotWonEvent) error {
+	finishedAt := e.finishedAt
+	if finishedAt.IsZero() {
+		finishedAt = time.Now()
+	}
+	err := provider.DeliveryDeadlineRepository().Store(&DeliveryDeadline{
+		LotID:      e.lotID,
+		SenderID:   e.senderID,
+		ReceiverID: e.receiverID,
+		FinishedAt: finishedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = provider.DeliveryInfoRepository().FindByLotID(e.lotID)
+	if err == nil {
+		// lot was sent before event was handled
+		return nil
+	}
+	if errors.Cause(err) != ErrLotNotFound {
+		return err
+	}
+	return storeWonLotDelivery(provider, e.lotID, e.receiverID, e.senderID)
+}
+
+// storeWonLotDelivery - delivery is stored with names from profile read model,
+// names missing in read model and receiver address are filled by enrichment
+func storeWonLotDelivery(provider RepositoryProvider, lotID LotID, receiverID, senderID UserID) error {
+	info := DeliveryInfo{
+		LotID:      lotID,
+		LotStatus:  LotStatusFinished,
+		ReceiverID: receiverID,
+		SenderID:   senderID,
+	}
+	profileRepo := provider.UserProfileRepository()
+	senderInfo, err := profileRepo.FindByID(senderID)
+	if err == nil {
+		info.SenderLogin = senderInfo.Login
+		info.SenderFirstName = senderInfo.FirstName
+		info.SenderLastName = senderInfo.LastName
+	} else if errors.Cause(err) != ErrUserProfileNotFound {
+		return err
+	}
+	receiverInfo, err := profileRepo.FindByID(receiverID)
+	if err == nil {
+		info.ReceiverLogin = receiverInfo.Login
+		info.ReceiverFirstName = receiverInfo.FirstName
+		info.ReceiverLastName = receiverInfo.LastName
+	} else if errors.Cause(err) != ErrUserProfileNotFound {
+		return err
+	}
+	err = provider.DeliveryInfoRepository().Store(&info)
+	if err != nil {
+		return err
+	}
+	return provider.DeliveryEnrichmentRepository().Store(&DeliveryEnrichment{
+		LotID:         lotID,
+		NextAttemptAt: time.Now(),
+	})
+}
+
+func (handler *eventHandler) executeInTransaction(f func(TransactionalUnit) error) (err error) {
+	var trUnit TransactionalUnit
+	trUnit, err = handler.trUnitFactory.NewTransactionalUnit()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	err = f(trUnit)
+	return err
+}
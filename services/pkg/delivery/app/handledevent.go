@@ -0,0 +1,45 @@
+package app
+
+import "time"
+
+type HandledEvent interface {
+}
+
+func NewUserDeletedEvent(userID UserID) HandledEvent {
+	return userDeletedEvent{
+		userID: userID,
+	}
+}
+
+func NewUserProfileUpdatedEvent(userID UserID, info UserInfo) HandledEvent {
+	return userProfileUpdatedEvent{
+		userID: userID,
+		info:   info,
+	}
+}
+
+// NewLotWonEvent - finishedAt is zero for events published before lot finish time was added
+func NewLotWonEvent(lotID LotID, receiverID, senderID UserID, finishedAt time.Time) HandledEvent {
+	return lotWonEvent{
+		lotID:      lotID,
+		receiverID: receiverID,
+		senderID:   senderID,
+		finishedAt: finishedAt,
+	}
+}
+
+type userDeletedEvent struct {
+	userID UserID
+}
+
+type userProfileUpdatedEvent struct {
+	userID UserID
+	info   UserInfo
+}
+
+type lotWonEvent struct {
+	lotID      LotID
+	receiverID UserID
+	senderID   UserID
+	finishedAt time.Time
+}
@@ -4,7 +4,17 @@ import "arch-homework/pkg/common/app/uuid"
 
 type UserID uuid.UUID
 
+// LotInfo - Weight is in grams, zero if not specified by seller
 type LotInfo struct {
+	ID              LotID
+	OwnerID         UserID
+	ReceiverID      UserID
+	Weight          int
+	ShippingOptions []ShippingOption
+}
+
+// FinishedLot - won lot which isn't sent yet
+type FinishedLot struct {
 	ID         LotID
 	OwnerID    UserID
 	ReceiverID UserID
@@ -12,4 +22,7 @@ type LotInfo struct {
 
 type LotServiceClient interface {
 	FindFinishedLotInfo(id LotID) (*LotInfo, error)
+	FindAllFinishedLots() ([]FinishedLot, error)
+	// FindLotDescription - description of lot in any status
+	FindLotDescription(id LotID) (string, error)
 }
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrShippingOptionNotFound = errors.New("shipping option not found")
+var ErrShippingUnavailable = errors.New("shipping option is unavailable for delivery address")
+var ErrShippingNotSelected = errors.New("shipping option not selected")
+var ErrShippingQuoteChanged = errors.New("shipping price changed, shipping option should be selected again")
+var ErrShippingPaymentFailed = errors.New("shipping payment failed")
+var ErrShippingPaymentPending = errors.New("shipping payment is in progress")
+var ErrShippingPaymentNotFound = errors.New("shipping payment not found")
+
+// ShippingRateZoneAny - zone of rate used for destination countries without own rates
+const ShippingRateZoneAny = "*"
+
+type ShippingOptionType string
+
+const (
+	ShippingOptionFlat  ShippingOptionType = "flat"
+	ShippingOptionFree  ShippingOptionType = "free"
+	ShippingOptionTable ShippingOptionType = "table"
+)
+
+// ShippingRate - price of parcel up to MaxWeight grams sent to zone (destination country code)
+type ShippingRate struct {
+	Zone      string
+	MaxWeight int
+	Price     Amount
+}
+
+// ShippingOption - shipping option defined by seller on lot
+type ShippingOption struct {
+	Name  string
+	Type  ShippingOptionType
+	Price Amount
+	Rates []ShippingRate
+}
+
+// Quote - rates of destination country are preferred over rates of any zone,
+// the lightest rate suitable for lot weight is chosen
+func (o ShippingOption) Quote(country string, weight int) (Amount, error) {
+	switch o.Type {
+	case ShippingOptionFree:
+		return AmountFromRawValue(0), nil
+	case ShippingOptionFlat:
+		return o.Price, nil
+	case ShippingOptionTable:
+		country = strings.ToUpper(strings.TrimSpace(country))
+		for _, zone := range []string{country, ShippingRateZoneAny} {
+			if zone == "" {
+				continue
+			}
+			var found *ShippingRate
+			for i, rate := range o.Rates {
+				if rate.Zone != zone || rate.MaxWeight < weight {
+					continue
+				}
+				if found == nil || rate.MaxWeight < found.MaxWeight {
+					found = &o.Rates[i]
+				}
+			}
+			if found != nil {
+				return found.Price, nil
+			}
+		}
+		return nil, ErrShippingUnavailable
+	default:
+		return nil, ErrShippingUnavailable
+	}
+}
+
+// ShippingQuote - price of shipping option for receiver address
+type ShippingQuote struct {
+	OptionName string
+	OptionType ShippingOptionType
+	Price      Amount
+}
+
+// ShippingSelection - shipping option chosen by lot winner, price is blocked on winner account.
+// Revision is incremented by each paid selection, it makes billing request id of next selection unique
+type ShippingSelection struct {
+	LotID      LotID
+	ReceiverID UserID
+	Revision   int
+	ShippingQuote
+}
+
+type ShippingSelectionRepositoryRead interface {
+	// FindByLotID - returns ErrShippingNotSelected if winner didn't choose shipping option
+	FindByLotID(lotID LotID) (*ShippingSelection, error)
+}
+
+type ShippingSelectionRepository interface {
+	ShippingSelectionRepositoryRead
+	Store(selection *ShippingSelection) error
+}
+
+// ShippingPayment - shipping option which price is being blocked by billing, it is stored before billing request
+// and removed after result is stored, so payment interrupted by failed request or commit is completed with the same request id
+type ShippingPayment struct {
+	LotID      LotID
+	ReceiverID UserID
+	Revision   int
+	RequestID  RequestID
+	ShippingQuote
+}
+
+type ShippingPaymentRepositoryRead interface {
+	// FindByLotID - returns ErrShippingPaymentNotFound if no payment is in progress
+	FindByLotID(lotID LotID) (*ShippingPayment, error)
+}
+
+type ShippingPaymentRepository interface {
+	ShippingPaymentRepositoryRead
+	Store(payment *ShippingPayment) error
+	Remove(lotID LotID) error
+}
+
+// BillingClient - previously blocked shipping payment for lot is replaced, payment is released by billing after delivery.lot_cancelled event.
+// Request with already processed id is succeeded
+type BillingClient interface {
+	BlockShippingPayment(requestID RequestID, userID UserID, lotID LotID, amount Amount) (succeeded bool, err error)
+}
+
+func quoteShippingOptions(lotInfo *LotInfo, address Address) []ShippingQuote {
+	quotes := make([]ShippingQuote, 0, len(lotInfo.ShippingOptions))
+	for _, option := range lotInfo.ShippingOptions {
+		price, err := option.Quote(address.Country, lotInfo.Weight)
+		if err != nil {
+			continue
+		}
+		quotes = append(quotes, ShippingQuote{OptionName: option.Name, OptionType: option.Type, Price: price})
+	}
+	return quotes
+}
+
+func quoteShippingOption(lotInfo *LotInfo, address Address, optionName string) (ShippingQuote, error) {
+	for _, option := range lotInfo.ShippingOptions {
+		if option.Name != optionName {
+			continue
+		}
+		price, err := option.Quote(address.Country, lotInfo.Weight)
+		if err != nil {
+			return ShippingQuote{}, err
+		}
+		return ShippingQuote{OptionName: option.Name, OptionType: option.Type, Price: price}, nil
+	}
+	return ShippingQuote{}, ErrShippingOptionNotFound
+}
@@ -0,0 +1,234 @@
+package app_test
+
+import (
+	"arch-homework/pkg/delivery/app"
+	"arch-homework/pkg/delivery/infrastructure/transport/carrier"
+
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShippingOptionQuote(t *testing.T) {
+	table := app.ShippingOption{
+		Name: "post",
+		Type: app.ShippingOptionTable,
+		Rates: []app.ShippingRate{
+			{Zone: "RU", MaxWeight: 2000, Price: app.AmountFromRawValue(500)},
+			{Zone: "RU", MaxWeight: 500, Price: app.AmountFromRawValue(300)},
+			{Zone: app.ShippingRateZoneAny, MaxWeight: 2000, Price: app.AmountFromRawValue(1000)},
+		},
+	}
+
+	for _, testCase := range []struct {
+		country string
+		weight  int
+		price   uint64
+	}{
+		{"RU", 400, 300},
+		{"ru", 500, 300},
+		{"RU", 800, 500},
+		{"DE", 800, 1000},
+		{"", 800, 1000},
+	} {
+		price, err := table.Quote(testCase.country, testCase.weight)
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.price, price.RawValue(), testCase.country)
+	}
+	_, err := table.Quote("RU", 3000)
+	assert.Equal(t, app.ErrShippingUnavailable, err)
+
+	price, err := app.ShippingOption{Type: app.ShippingOptionFlat, Price: app.AmountFromRawValue(700)}.Quote("DE", 3000)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(700), price.RawValue())
+	price, err = app.ShippingOption{Type: app.ShippingOptionFree}.Quote("DE", 3000)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), price.RawValue())
+}
+
+func TestSelectShippingOptionBlocksPayment(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	billing := newTestBillingClient()
+	service := newTestShippingService(db, lotInfo, billing)
+
+	quotes, err := service.ShippingQuotes(lotInfo.ReceiverID, lotInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []app.ShippingQuote{
+		{OptionName: "courier", OptionType: app.ShippingOptionFlat, Price: app.AmountFromRawValue(1500)},
+		{OptionName: "post", OptionType: app.ShippingOptionTable, Price: app.AmountFromRawValue(300)},
+	}, quotes)
+
+	_, err = service.SelectShippingOption(lotInfo.OwnerID, lotInfo.ID, "post")
+	assert.Equal(t, app.ErrStatusChangeForbidden, errorsCause(err))
+	_, err = service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "pickup")
+	assert.Equal(t, app.ErrShippingOptionNotFound, errorsCause(err))
+
+	quote, err := service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "post")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(300), quote.Price.RawValue())
+	assert.Equal(t, uint64(300), billing.blocked[lotInfo.ID].RawValue())
+
+	// new option price replaces blocked payment
+	_, err = service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "courier")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1500), billing.blocked[lotInfo.ID].RawValue())
+	assert.Equal(t, "courier", db.shipping[lotInfo.ID].OptionName)
+
+	billing.failed = true
+	_, err = service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "post")
+	assert.Equal(t, app.ErrShippingPaymentFailed, errorsCause(err))
+	assert.Equal(t, "courier", db.shipping[lotInfo.ID].OptionName)
+	assert.Empty(t, db.payments)
+}
+
+func TestInterruptedShippingPaymentCompleted(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	billing := newTestBillingClient()
+	service := newTestShippingService(db, lotInfo, billing)
+
+	billing.responseLost = true
+	_, err := service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "post")
+	assert.Error(t, err)
+	assert.Equal(t, uint64(300), billing.blocked[lotInfo.ID].RawValue())
+	assert.Contains(t, db.payments, lotInfo.ID)
+	assert.NotContains(t, db.shipping, lotInfo.ID)
+
+	err = service.SetLotSent(newRequestID(), lotInfo.OwnerID, lotInfo.ID, testTrackingID)
+	assert.Error(t, err)
+	assert.Empty(t, db.events)
+
+	// payment is repeated with the same request id and isn't blocked twice
+	billing.responseLost = false
+	err = service.SetLotSent(newRequestID(), lotInfo.OwnerID, lotInfo.ID, testTrackingID)
+	assert.NoError(t, err)
+	assert.Len(t, billing.processed, 1)
+	assert.Empty(t, db.payments)
+	assert.Equal(t, 1, db.shipping[lotInfo.ID].Revision)
+	assert.Equal(t, app.LotStatusSent, db.deliveries[lotInfo.ID].LotStatus)
+}
+
+func TestLotSentWithShippingOptionsRequiresSelection(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	billing := newTestBillingClient()
+	service := newTestShippingService(db, lotInfo, billing)
+
+	err := service.SetLotSent(newRequestID(), lotInfo.OwnerID, lotInfo.ID, testTrackingID)
+	assert.Equal(t, app.ErrShippingNotSelected, errorsCause(err))
+
+	_, err = service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "post")
+	assert.NoError(t, err)
+
+	// winner chose address in another zone after shipping option
+	db.addresses[lotInfo.ID] = app.DeliveryAddress{LotID: lotInfo.ID, ReceiverID: lotInfo.ReceiverID, Address: app.Address{Country: "DE"}}
+	err = service.SetLotSent(newRequestID(), lotInfo.OwnerID, lotInfo.ID, testTrackingID)
+	assert.Equal(t, app.ErrShippingQuoteChanged, errorsCause(err))
+
+	_, err = service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "post")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(900), billing.blocked[lotInfo.ID].RawValue())
+	err = service.SetLotSent(newRequestID(), lotInfo.OwnerID, lotInfo.ID, testTrackingID)
+	assert.NoError(t, err)
+	assert.Equal(t, &app.ShippingQuote{OptionName: "post", OptionType: app.ShippingOptionTable, Price: app.AmountFromRawValue(900)}, db.deliveries[lotInfo.ID].Shipping)
+
+	_, err = service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "courier")
+	assert.Equal(t, app.ErrInvalidLotStatus, errorsCause(err))
+}
+
+func newTestShippingService(db *testDB, lotInfo *app.LotInfo, billing *testBillingClient) *app.DeliveryService {
+	return newTestLotDeliveryService(db, lotInfo, billing, app.DeadlinePolicy{})
+}
+
+func newTestLotDeliveryService(db *testDB, lotInfo *app.LotInfo, billing *testBillingClient, deadlinePolicy app.DeadlinePolicy) *app.DeliveryService {
+	db.profiles[lotInfo.OwnerID] = app.UserInfo{Login: "seller"}
+	db.profiles[lotInfo.ReceiverID] = app.UserInfo{Login: "winner"}
+	addressBook := testAddressBookClient{addresses: map[app.UserID]app.Address{
+		lotInfo.ReceiverID: {RecipientName: "Ivan Ivanov", Country: "RU", City: "Kazan"},
+	}}
+	return app.NewDeliveryService(db, testEventSender{}, testLotClient{lotInfo: lotInfo}, nil, addressBook, billing, carrier.NewFakeClient(), 0, deadlinePolicy, nil)
+}
+
+func newTestLotInfo() *app.LotInfo {
+	return &app.LotInfo{
+		ID:         newLotID(),
+		OwnerID:    newUserID(),
+		ReceiverID: newUserID(),
+		Weight:     400,
+		ShippingOptions: []app.ShippingOption{
+			{Name: "courier", Type: app.ShippingOptionFlat, Price: app.AmountFromRawValue(1500)},
+			{Name: "post", Type: app.ShippingOptionTable, Rates: []app.ShippingRate{
+				{Zone: "RU", MaxWeight: 1000, Price: app.AmountFromRawValue(300)},
+				{Zone: app.ShippingRateZoneAny, MaxWeight: 1000, Price: app.AmountFromRawValue(900)},
+			}},
+		},
+	}
+}
+
+const testLotDescription = "Film camera"
+
+type testLotClient struct {
+	lotInfo *app.LotInfo
+}
+
+func (c testLotClient) FindFinishedLotInfo(id app.LotID) (*app.LotInfo, error) {
+	if id != c.lotInfo.ID {
+		return nil, app.ErrLotNotFound
+	}
+	return c.lotInfo, nil
+}
+
+func (c testLotClient) FindAllFinishedLots() ([]app.FinishedLot, error) {
+	return []app.FinishedLot{{ID: c.lotInfo.ID, OwnerID: c.lotInfo.OwnerID, ReceiverID: c.lotInfo.ReceiverID}}, nil
+}
+
+func (c testLotClient) FindLotDescription(id app.LotID) (string, error) {
+	if id != c.lotInfo.ID {
+		return "", app.ErrLotNotFound
+	}
+	return testLotDescription, nil
+}
+
+type testAddressBookClient struct {
+	addresses map[app.UserID]app.Address
+}
+
+func (c testAddressBookClient) GetAddress(app.UserID, app.AddressID) (app.Address, error) {
+	return app.Address{}, app.ErrAddressNotFound
+}
+
+func (c testAddressBookClient) GetDefaultAddress(userID app.UserID) (app.Address, error) {
+	address, ok := c.addresses[userID]
+	if !ok {
+		return app.Address{}, app.ErrAddressNotFound
+	}
+	return address, nil
+}
+
+type testBillingClient struct {
+	blocked   map[app.LotID]app.Amount
+	processed map[app.RequestID]bool
+	failed    bool
+	// responseLost - payment is blocked, but client gets error
+	responseLost bool
+}
+
+func newTestBillingClient() *testBillingClient {
+	return &testBillingClient{blocked: map[app.LotID]app.Amount{}, processed: map[app.RequestID]bool{}}
+}
+
+func (c *testBillingClient) BlockShippingPayment(requestID app.RequestID, _ app.UserID, lotID app.LotID, amount app.Amount) (bool, error) {
+	if c.failed {
+		return false, nil
+	}
+	if !c.processed[requestID] {
+		c.processed[requestID] = true
+		c.blocked[lotID] = amount
+	}
+	if c.responseLost {
+		return false, errors.New("billing response lost")
+	}
+	return true, nil
+}
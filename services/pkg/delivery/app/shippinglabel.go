@@ -0,0 +1,92 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ShippingLabel - data of printable shipping label and packing slip of won lot
+type ShippingLabel struct {
+	LotID       LotID
+	Description string
+	// TrackingID - nil until lot is sent
+	TrackingID *TrackingID
+	SenderName string
+	// SenderAddress - nil if sender has no default address in address book
+	SenderAddress   *Address
+	ReceiverName    string
+	ReceiverAddress Address
+	Shipping        *ShippingQuote
+	CreatedAt       time.Time
+}
+
+// ShippingLabelRenderer - renders shipping label and packing slip as pages of one PDF document
+type ShippingLabelRenderer interface {
+	RenderPDF(label ShippingLabel) ([]byte, error)
+}
+
+func userFullName(login, firstName, lastName string) string {
+	name := strings.TrimSpace(firstName + " " + lastName)
+	if name == "" {
+		return login
+	}
+	return name
+}
+
+// ShippingLabelPDF - label is available to lot owner until lot is received,
+// receiver address of unsent lot can be changed by winner, so it is resolved again for each label
+func (s *DeliveryService) ShippingLabelPDF(userID UserID, lotID LotID) ([]byte, error) {
+	info, err := s.readRepo.FindByLotID(lotID)
+	if err != nil {
+		return nil, err
+	}
+	if info.SenderID != userID {
+		return nil, errors.WithStack(ErrStatusChangeForbidden)
+	}
+	if info.LotStatus != LotStatusFinished && info.LotStatus != LotStatusSent {
+		return nil, errors.WithStack(ErrInvalidLotStatus)
+	}
+
+	if info.LotStatus == LotStatusFinished {
+		senderInfo, err := s.userInfo(info.SenderID)
+		if err != nil {
+			return nil, err
+		}
+		receiverInfo, err := s.userInfo(info.ReceiverID)
+		if err != nil {
+			return nil, err
+		}
+		info.ReceiverAddress, err = s.receiverAddress(lotID, info.ReceiverID, receiverInfo)
+		if err != nil {
+			return nil, err
+		}
+		info.SenderLogin, info.SenderFirstName, info.SenderLastName = senderInfo.Login, senderInfo.FirstName, senderInfo.LastName
+		info.ReceiverLogin, info.ReceiverFirstName, info.ReceiverLastName = receiverInfo.Login, receiverInfo.FirstName, receiverInfo.LastName
+	}
+
+	var senderAddress *Address
+	address, err := s.addressBookClient.GetDefaultAddress(info.SenderID)
+	if err == nil {
+		senderAddress = &address
+	} else if errors.Cause(err) != ErrAddressNotFound {
+		return nil, err
+	}
+	description, err := s.lotSvcClient.FindLotDescription(lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.labelRenderer.RenderPDF(ShippingLabel{
+		LotID:           lotID,
+		Description:     description,
+		TrackingID:      info.TrackingID,
+		SenderName:      userFullName(info.SenderLogin, info.SenderFirstName, info.SenderLastName),
+		SenderAddress:   senderAddress,
+		ReceiverName:    userFullName(info.ReceiverLogin, info.ReceiverFirstName, info.ReceiverLastName),
+		ReceiverAddress: info.ReceiverAddress,
+		Shipping:        info.Shipping,
+		CreatedAt:       time.Now(),
+	})
+}
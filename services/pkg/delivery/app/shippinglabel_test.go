@@ -0,0 +1,66 @@
+package app_test
+
+import (
+	"arch-homework/pkg/delivery/app"
+	"arch-homework/pkg/delivery/infrastructure/transport/carrier"
+
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShippingLabelAvailableToSenderUntilLotReceived(t *testing.T) {
+	db := newTestDB()
+	lotInfo := newTestLotInfo()
+	renderer := &testLabelRenderer{}
+	service := newTestLabelService(db, lotInfo, renderer)
+	handleTestLotWonEvent(t, db, lotInfo, time.Now())
+
+	_, err := service.ShippingLabelPDF(lotInfo.ReceiverID, lotInfo.ID)
+	assert.Equal(t, app.ErrStatusChangeForbidden, errorsCause(err))
+
+	pdf, err := service.ShippingLabelPDF(lotInfo.OwnerID, lotInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("pdf"), pdf)
+	assert.Equal(t, lotInfo.ID, renderer.label.LotID)
+	assert.Equal(t, testLotDescription, renderer.label.Description)
+	assert.Equal(t, "seller", renderer.label.SenderName)
+	assert.Equal(t, &app.Address{RecipientName: "Petr Petrov", City: "Moscow"}, renderer.label.SenderAddress)
+	assert.Equal(t, "Ivan Ivanov", renderer.label.ReceiverName)
+	assert.Equal(t, "Kazan", renderer.label.ReceiverAddress.City, "receiver address is resolved before enrichment")
+	assert.Nil(t, renderer.label.TrackingID)
+	assert.Nil(t, renderer.label.Shipping)
+
+	_, err = service.SelectShippingOption(lotInfo.ReceiverID, lotInfo.ID, "courier")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetLotSent(newRequestID(), lotInfo.OwnerID, lotInfo.ID, testTrackingID))
+	_, err = service.ShippingLabelPDF(lotInfo.OwnerID, lotInfo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, testTrackingID, *renderer.label.TrackingID)
+	assert.Equal(t, "courier", renderer.label.Shipping.OptionName)
+
+	assert.NoError(t, service.SetLotReceived(newRequestID(), lotInfo.ReceiverID, lotInfo.ID))
+	_, err = service.ShippingLabelPDF(lotInfo.OwnerID, lotInfo.ID)
+	assert.Equal(t, app.ErrInvalidLotStatus, errorsCause(err))
+}
+
+func newTestLabelService(db *testDB, lotInfo *app.LotInfo, renderer app.ShippingLabelRenderer) *app.DeliveryService {
+	db.profiles[lotInfo.OwnerID] = app.UserInfo{Login: "seller"}
+	db.profiles[lotInfo.ReceiverID] = app.UserInfo{Login: "winner", FirstName: "Ivan", LastName: "Ivanov"}
+	addressBook := testAddressBookClient{addresses: map[app.UserID]app.Address{
+		lotInfo.OwnerID:    {RecipientName: "Petr Petrov", City: "Moscow"},
+		lotInfo.ReceiverID: {RecipientName: "Ivan Ivanov", Country: "RU", City: "Kazan"},
+	}}
+	billing := newTestBillingClient()
+	return app.NewDeliveryService(db, testEventSender{}, testLotClient{lotInfo: lotInfo}, nil, addressBook, billing, carrier.NewFakeClient(), 0, app.DeadlinePolicy{}, renderer)
+}
+
+type testLabelRenderer struct {
+	label app.ShippingLabel
+}
+
+func (r *testLabelRenderer) RenderPDF(label app.ShippingLabel) ([]byte, error) {
+	r.label = label
+	return []byte("pdf"), nil
+}
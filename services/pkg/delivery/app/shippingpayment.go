@@ -0,0 +1,122 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// payShipping - billing is requested outside of lot transaction, payment stays stored if request or commit fails
+// and is completed later with the same request id
+func (s *DeliveryService) payShipping(payment *ShippingPayment) error {
+	succeeded, err := s.billingClient.BlockShippingPayment(payment.RequestID, payment.ReceiverID, payment.LotID, payment.Price)
+	if err != nil {
+		return err
+	}
+	err = s.executeInLotTransaction(payment.LotID, func(provider RepositoryProvider) error {
+		return finishShippingPayment(provider, payment, succeeded)
+	})
+	if err != nil || succeeded {
+		return err
+	}
+	return errors.WithStack(ErrShippingPaymentFailed)
+}
+
+// completeShippingPayment - payment interrupted by failed billing request or commit is repeated,
+// billing answers already processed request as succeeded
+func (s *DeliveryService) completeShippingPayment(lotID LotID) error {
+	payment, err := s.paymentReadRepo.FindByLotID(lotID)
+	if err != nil {
+		if errors.Cause(err) == ErrShippingPaymentNotFound {
+			return nil
+		}
+		return err
+	}
+	err = s.payShipping(payment)
+	if errors.Cause(err) == ErrShippingPaymentFailed {
+		// declined payment is removed, previously selected shipping option is kept
+		return nil
+	}
+	return err
+}
+
+// startShippingPayment - request id is derived from lot, option and selection revision,
+// so repeated request of the same payment is recognized by billing
+func startShippingPayment(provider RepositoryProvider, lotID LotID, receiverID UserID, quote ShippingQuote) (*ShippingPayment, error) {
+	err := checkNoShippingPayment(provider, lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := 1
+	selection, err := provider.ShippingSelectionRepository().FindByLotID(lotID)
+	if err == nil {
+		revision = selection.Revision + 1
+	} else if errors.Cause(err) != ErrShippingNotSelected {
+		return nil, err
+	}
+
+	payment := ShippingPayment{
+		LotID:         lotID,
+		ReceiverID:    receiverID,
+		Revision:      revision,
+		RequestID:     shippingPaymentRequestID(lotID, quote.OptionName, revision),
+		ShippingQuote: quote,
+	}
+	err = provider.ShippingPaymentRepository().Store(&payment)
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// finishShippingPayment - payment could be already finished by concurrent completion
+func finishShippingPayment(provider RepositoryProvider, payment *ShippingPayment, succeeded bool) error {
+	paymentRepo := provider.ShippingPaymentRepository()
+	stored, err := paymentRepo.FindByLotID(payment.LotID)
+	if err != nil {
+		if errors.Cause(err) == ErrShippingPaymentNotFound {
+			return nil
+		}
+		return err
+	}
+	if stored.RequestID != payment.RequestID {
+		return nil
+	}
+
+	err = paymentRepo.Remove(payment.LotID)
+	if err != nil || !succeeded {
+		return err
+	}
+	err = provider.ShippingSelectionRepository().Store(&ShippingSelection{
+		LotID:         payment.LotID,
+		ReceiverID:    payment.ReceiverID,
+		Revision:      payment.Revision,
+		ShippingQuote: payment.ShippingQuote,
+	})
+	if err != nil {
+		return err
+	}
+	quote := payment.ShippingQuote
+	return updateFinishedDelivery(provider.DeliveryInfoRepository(), payment.LotID, func(info *DeliveryInfo) {
+		info.Shipping = &quote
+	})
+}
+
+// checkNoShippingPayment - lot isn't sent or cancelled while shipping payment is in progress
+func checkNoShippingPayment(provider RepositoryProvider, lotID LotID) error {
+	_, err := provider.ShippingPaymentRepository().FindByLotID(lotID)
+	if err == nil {
+		return errors.WithStack(ErrShippingPaymentPending)
+	}
+	if errors.Cause(err) != ErrShippingPaymentNotFound {
+		return err
+	}
+	return nil
+}
+
+func shippingPaymentRequestID(lotID LotID, optionName string, revision int) RequestID {
+	return RequestID(uuid.GenerateFromName(fmt.Sprintf("delivery-shipping-payment-%s-%s-%d", lotID, optionName, revision)))
+}
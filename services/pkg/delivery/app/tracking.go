@@ -0,0 +1,49 @@
+package app
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidTrackingNumber = errors.New("invalid tracking number")
+
+type TrackingStatus string
+
+const (
+	TrackingStatusInTransit      TrackingStatus = "in_transit"
+	TrackingStatusOutForDelivery TrackingStatus = "out_for_delivery"
+	TrackingStatusDelivered      TrackingStatus = "delivered"
+)
+
+type TrackingEvent struct {
+	Status   TrackingStatus
+	Location string
+	Time     time.Time
+}
+
+type CarrierClient interface {
+	ValidateTrackingNumber(trackingID TrackingID) error
+	// GetTrackingEvents - parcel events known to carrier ordered by time, events with unknown statuses are skipped
+	GetTrackingEvents(trackingID TrackingID) ([]TrackingEvent, error)
+}
+
+type TrackingEventRepositoryRead interface {
+	// FindAllByLotID - tracking history of lot ordered by event time
+	FindAllByLotID(lotID LotID) ([]TrackingEvent, error)
+}
+
+type TrackingEventRepository interface {
+	TrackingEventRepositoryRead
+	// Add - already stored event with same status and time is ignored
+	Add(lotID LotID, event TrackingEvent) error
+}
+
+func findDeliveredTime(events []TrackingEvent) *time.Time {
+	for _, event := range events {
+		if event.Status == TrackingStatusDelivered {
+			t := event.Time
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,512 @@
+package app_test
+
+import (
+	"arch-homework/pkg/common/app/integrationevent"
+	"arch-homework/pkg/common/app/storedevent"
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/delivery/app"
+	"arch-homework/pkg/delivery/infrastructure/transport/carrier"
+
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTrackingID = app.TrackingID("RA123456789RU")
+
+func TestLotSentWithInvalidTrackingNumberRejected(t *testing.T) {
+	db := newTestDB()
+	service := newTestDeliveryService(db, carrier.NewFakeClient(), 0)
+
+	for _, trackingID := range []app.TrackingID{"", "RA1", "ra123456789ru", "RA 123456789 RU"} {
+		err := service.SetLotSent(newRequestID(), newUserID(), newLotID(), trackingID)
+		assert.Equal(t, app.ErrInvalidTrackingNumber, errorsCause(err), string(trackingID))
+	}
+}
+
+func TestTrackingEventsRecorded(t *testing.T) {
+	db := newTestDB()
+	fakeCarrier := carrier.NewFakeClient()
+	service := newTestDeliveryService(db, fakeCarrier, 0)
+	lotID := db.addSentDelivery(testTrackingID)
+
+	sentTime := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	fakeCarrier.AddEvent(testTrackingID, app.TrackingEvent{Status: app.TrackingStatusInTransit, Location: "Moscow", Time: sentTime})
+	assert.NoError(t, service.UpdateTrackingStatuses())
+
+	fakeCarrier.AddEvent(testTrackingID, app.TrackingEvent{Status: app.TrackingStatusOutForDelivery, Location: "Kazan", Time: sentTime.Add(time.Minute)})
+	fakeCarrier.AddEvent(testTrackingID, app.TrackingEvent{Status: app.TrackingStatusDelivered, Location: "Kazan", Time: sentTime.Add(time.Minute * 2)})
+	assert.NoError(t, service.UpdateTrackingStatuses())
+
+	history, err := service.LotTrackingHistory(lotID)
+	assert.NoError(t, err)
+	assert.Equal(t, []app.TrackingEvent{
+		{Status: app.TrackingStatusInTransit, Location: "Moscow", Time: sentTime},
+		{Status: app.TrackingStatusOutForDelivery, Location: "Kazan", Time: sentTime.Add(time.Minute)},
+		{Status: app.TrackingStatusDelivered, Location: "Kazan", Time: sentTime.Add(time.Minute * 2)},
+	}, history)
+	assert.Equal(t, app.LotStatusSent, db.deliveries[lotID].LotStatus, "auto confirmation is disabled")
+	assert.Empty(t, db.events)
+}
+
+func TestDeliveredLotReceivedAfterAutoConfirmPeriod(t *testing.T) {
+	db := newTestDB()
+	fakeCarrier := carrier.NewFakeClient()
+	service := newTestDeliveryService(db, fakeCarrier, time.Hour*24)
+	lotID := db.addSentDelivery(testTrackingID)
+
+	fakeCarrier.AddEvent(testTrackingID, app.TrackingEvent{Status: app.TrackingStatusDelivered, Time: time.Now().Add(-time.Hour)})
+	assert.NoError(t, service.UpdateTrackingStatuses())
+	assert.Equal(t, app.LotStatusSent, db.deliveries[lotID].LotStatus)
+
+	const trackingID2 = app.TrackingID("RB987654321RU")
+	lotID2 := db.addSentDelivery(trackingID2)
+	fakeCarrier.AddEvent(trackingID2, app.TrackingEvent{Status: app.TrackingStatusInTransit, Time: time.Now().Add(-time.Hour * 50)})
+	fakeCarrier.AddEvent(trackingID2, app.TrackingEvent{Status: app.TrackingStatusDelivered, Time: time.Now().Add(-time.Hour * 25)})
+	assert.NoError(t, service.UpdateTrackingStatuses())
+	assert.Equal(t, app.LotStatusSent, db.deliveries[lotID].LotStatus)
+	assert.Equal(t, app.LotStatusReceived, db.deliveries[lotID2].LotStatus)
+	assert.Equal(t, []string{"delivery.lot_received"}, db.eventTypes())
+
+	// received lot isn't tracked anymore
+	assert.NoError(t, service.UpdateTrackingStatuses())
+	assert.Len(t, db.events, 1)
+}
+
+func TestManuallyReceivedLotNotTracked(t *testing.T) {
+	db := newTestDB()
+	fakeCarrier := carrier.NewFakeClient()
+	service := newTestDeliveryService(db, fakeCarrier, time.Hour)
+	lotID := db.addSentDelivery(testTrackingID)
+
+	err := service.SetLotReceived(newRequestID(), db.deliveries[lotID].ReceiverID, lotID)
+	assert.NoError(t, err)
+	assert.Equal(t, app.LotStatusReceived, db.deliveries[lotID].LotStatus)
+
+	fakeCarrier.AddEvent(testTrackingID, app.TrackingEvent{Status: app.TrackingStatusDelivered, Time: time.Now().Add(-time.Hour * 2)})
+	assert.NoError(t, service.UpdateTrackingStatuses())
+	assert.Empty(t, db.tracking[lotID])
+	assert.Equal(t, []string{"delivery.lot_received"}, db.eventTypes())
+}
+
+func newTestDeliveryService(db *testDB, carrierClient app.CarrierClient, autoConfirmPeriod time.Duration) *app.DeliveryService {
+	return app.NewDeliveryService(db, testEventSender{}, nil, nil, nil, nil, carrierClient, autoConfirmPeriod, app.DeadlinePolicy{}, nil)
+}
+
+func newRequestID() app.RequestID {
+	return app.RequestID(uuid.GenerateNew())
+}
+
+func newUserID() app.UserID {
+	return app.UserID(uuid.GenerateNew())
+}
+
+func newLotID() app.LotID {
+	return app.LotID(uuid.GenerateNew())
+}
+
+func errorsCause(err error) error {
+	type causer interface {
+		Cause() error
+	}
+	for err != nil {
+		c, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = c.Cause()
+	}
+	return err
+}
+
+type testEventSender struct {
+}
+
+func (s testEventSender) EventStored(integrationevent.EventUID) {
+}
+
+func (s testEventSender) SendStoredEvents() {
+}
+
+// testDB - changes are applied immediately
+type testDB struct {
+	deliveries map[app.LotID]app.DeliveryInfo
+	tracking   map[app.LotID][]app.TrackingEvent
+	shipping   map[app.LotID]app.ShippingSelection
+	payments   map[app.LotID]app.ShippingPayment
+	addresses  map[app.LotID]app.DeliveryAddress
+	profiles   map[app.UserID]app.UserInfo
+	deadlines  map[app.LotID]app.DeliveryDeadline
+	enrichment map[app.LotID]app.DeliveryEnrichment
+	requests   map[app.RequestID]bool
+	processed  map[integrationevent.EventUID]bool
+	events     []integrationevent.EventData
+}
+
+func newTestDB() *testDB {
+	return &testDB{
+		deliveries: map[app.LotID]app.DeliveryInfo{},
+		tracking:   map[app.LotID][]app.TrackingEvent{},
+		shipping:   map[app.LotID]app.ShippingSelection{},
+		payments:   map[app.LotID]app.ShippingPayment{},
+		addresses:  map[app.LotID]app.DeliveryAddress{},
+		profiles:   map[app.UserID]app.UserInfo{},
+		deadlines:  map[app.LotID]app.DeliveryDeadline{},
+		enrichment: map[app.LotID]app.DeliveryEnrichment{},
+		requests:   map[app.RequestID]bool{},
+		processed:  map[integrationevent.EventUID]bool{},
+	}
+}
+
+func (db *testDB) addSentDelivery(trackingID app.TrackingID) app.LotID {
+	lotID := newLotID()
+	db.deliveries[lotID] = app.DeliveryInfo{
+		LotID:      lotID,
+		LotStatus:  app.LotStatusSent,
+		TrackingID: &trackingID,
+		ReceiverID: newUserID(),
+		SenderID:   newUserID(),
+	}
+	return lotID
+}
+
+func (db *testDB) eventTypes() []string {
+	res := make([]string, 0, len(db.events))
+	for _, event := range db.events {
+		res = append(res, event.Type)
+	}
+	return res
+}
+
+func (db *testDB) NewTransactionalUnit() (app.TransactionalUnit, error) {
+	return db, nil
+}
+
+func (db *testDB) DeliveryInfoRepositoryRead() app.DeliveryInfoRepositoryRead {
+	return db.DeliveryInfoRepository()
+}
+
+func (db *testDB) DeliveryInfoRepository() app.DeliveryInfoRepository {
+	return testDeliveryInfoRepo{db: db}
+}
+
+func (db *testDB) DeliveryAddressRepositoryRead() app.DeliveryAddressRepositoryRead {
+	return db.DeliveryAddressRepository()
+}
+
+func (db *testDB) DeliveryAddressRepository() app.DeliveryAddressRepository {
+	return testDeliveryAddressRepo{db: db}
+}
+
+func (db *testDB) UserProfileRepositoryRead() app.UserProfileRepositoryRead {
+	return db.UserProfileRepository()
+}
+
+func (db *testDB) UserProfileRepository() app.UserProfileRepository {
+	return testUserProfileRepo{db: db}
+}
+
+func (db *testDB) ShippingSelectionRepositoryRead() app.ShippingSelectionRepositoryRead {
+	return db.ShippingSelectionRepository()
+}
+
+func (db *testDB) ShippingSelectionRepository() app.ShippingSelectionRepository {
+	return testShippingSelectionRepo{db: db}
+}
+
+func (db *testDB) ShippingPaymentRepositoryRead() app.ShippingPaymentRepositoryRead {
+	return db.ShippingPaymentRepository()
+}
+
+func (db *testDB) ShippingPaymentRepository() app.ShippingPaymentRepository {
+	return testShippingPaymentRepo{db: db}
+}
+
+func (db *testDB) DeliveryDeadlineRepositoryRead() app.DeliveryDeadlineRepositoryRead {
+	return db.DeliveryDeadlineRepository()
+}
+
+func (db *testDB) DeliveryDeadlineRepository() app.DeliveryDeadlineRepository {
+	return testDeliveryDeadlineRepo{db: db}
+}
+
+func (db *testDB) DeliveryEnrichmentRepositoryRead() app.DeliveryEnrichmentRepositoryRead {
+	return db.DeliveryEnrichmentRepository()
+}
+
+func (db *testDB) DeliveryEnrichmentRepository() app.DeliveryEnrichmentRepository {
+	return testDeliveryEnrichmentRepo{db: db}
+}
+
+func (db *testDB) TrackingEventRepositoryRead() app.TrackingEventRepositoryRead {
+	return db.TrackingEventRepository()
+}
+
+func (db *testDB) TrackingEventRepository() app.TrackingEventRepository {
+	return testTrackingEventRepo{db: db}
+}
+
+func (db *testDB) ProcessedRequestRepository() app.ProcessedRequestRepository {
+	return db
+}
+
+func (db *testDB) ProcessedEventRepository() app.ProcessedEventRepository {
+	return db
+}
+
+func (db *testDB) EventStore() storedevent.EventStore {
+	return db
+}
+
+func (db *testDB) AddLock(string) error {
+	return nil
+}
+
+func (db *testDB) Complete(err error) error {
+	return err
+}
+
+func (db *testDB) SetRequestProcessed(uid app.RequestID) (bool, error) {
+	alreadyProcessed := db.requests[uid]
+	db.requests[uid] = true
+	return alreadyProcessed, nil
+}
+
+func (db *testDB) SetEventProcessed(uid integrationevent.EventUID) (bool, error) {
+	alreadyProcessed := db.processed[uid]
+	db.processed[uid] = true
+	return alreadyProcessed, nil
+}
+
+func (db *testDB) Add(event integrationevent.EventData) error {
+	db.events = append(db.events, event)
+	return nil
+}
+
+func (db *testDB) ConfirmDelivery(storedevent.EventID) error {
+	return nil
+}
+
+func (db *testDB) FindByUIDs([]integrationevent.EventUID) ([]storedevent.Event, error) {
+	return nil, nil
+}
+
+func (db *testDB) FindAllUnconfirmedBefore(time.Time) ([]storedevent.Event, error) {
+	return nil, nil
+}
+
+type testDeliveryInfoRepo struct {
+	db *testDB
+}
+
+func (r testDeliveryInfoRepo) FindByLotID(id app.LotID) (*app.DeliveryInfo, error) {
+	info, ok := r.db.deliveries[id]
+	if !ok {
+		return nil, app.ErrLotNotFound
+	}
+	return &info, nil
+}
+
+func (r testDeliveryInfoRepo) FindAllByUserID(userID app.UserID) ([]app.DeliveryInfo, error) {
+	var res []app.DeliveryInfo
+	for _, info := range r.db.deliveries {
+		if info.ReceiverID == userID || info.SenderID == userID {
+			res = append(res, info)
+		}
+	}
+	return res, nil
+}
+
+func (r testDeliveryInfoRepo) FindAllByStatus(status app.LotStatus) ([]app.DeliveryInfo, error) {
+	var res []app.DeliveryInfo
+	for _, info := range r.db.deliveries {
+		if info.LotStatus == status {
+			res = append(res, info)
+		}
+	}
+	return res, nil
+}
+
+func (r testDeliveryInfoRepo) Store(info *app.DeliveryInfo) error {
+	r.db.deliveries[info.LotID] = *info
+	return nil
+}
+
+func (r testDeliveryInfoRepo) UpdateUserNames(userID app.UserID, user app.UserInfo) error {
+	for lotID, info := range r.db.deliveries {
+		if info.ReceiverID == userID {
+			info.ReceiverLogin, info.ReceiverFirstName, info.ReceiverLastName = user.Login, user.FirstName, user.LastName
+		}
+		if info.SenderID == userID {
+			info.SenderLogin, info.SenderFirstName, info.SenderLastName = user.Login, user.FirstName, user.LastName
+		}
+		r.db.deliveries[lotID] = info
+	}
+	return nil
+}
+
+func (r testDeliveryInfoRepo) AnonymizeUser(userID app.UserID) error {
+	for lotID, info := range r.db.deliveries {
+		if info.ReceiverID == userID {
+			info.ReceiverLogin, info.ReceiverFirstName, info.ReceiverLastName = app.DeletedUserLogin, "", ""
+			info.ReceiverAddress = app.Address{}
+		}
+		if info.SenderID == userID {
+			info.SenderLogin, info.SenderFirstName, info.SenderLastName = app.DeletedUserLogin, "", ""
+		}
+		r.db.deliveries[lotID] = info
+	}
+	return nil
+}
+
+type testTrackingEventRepo struct {
+	db *testDB
+}
+
+func (r testTrackingEventRepo) FindAllByLotID(lotID app.LotID) ([]app.TrackingEvent, error) {
+	return r.db.tracking[lotID], nil
+}
+
+func (r testTrackingEventRepo) Add(lotID app.LotID, event app.TrackingEvent) error {
+	for _, stored := range r.db.tracking[lotID] {
+		if stored.Status == event.Status && stored.Time.Equal(event.Time) {
+			return nil
+		}
+	}
+	r.db.tracking[lotID] = append(r.db.tracking[lotID], event)
+	return nil
+}
+
+type testDeliveryAddressRepo struct {
+	db *testDB
+}
+
+func (r testDeliveryAddressRepo) FindByLotID(lotID app.LotID) (*app.DeliveryAddress, error) {
+	address, ok := r.db.addresses[lotID]
+	if !ok {
+		return nil, app.ErrAddressNotFound
+	}
+	return &address, nil
+}
+
+func (r testDeliveryAddressRepo) Store(address *app.DeliveryAddress) error {
+	r.db.addresses[address.LotID] = *address
+	return nil
+}
+
+func (r testDeliveryAddressRepo) RemoveAllByReceiverID(app.UserID) error {
+	return nil
+}
+
+type testUserProfileRepo struct {
+	db *testDB
+}
+
+func (r testUserProfileRepo) FindByID(userID app.UserID) (*app.UserInfo, error) {
+	info, ok := r.db.profiles[userID]
+	if !ok {
+		return nil, app.ErrUserProfileNotFound
+	}
+	return &info, nil
+}
+
+func (r testUserProfileRepo) Store(userID app.UserID, info app.UserInfo) error {
+	r.db.profiles[userID] = info
+	return nil
+}
+
+func (r testUserProfileRepo) Remove(userID app.UserID) error {
+	delete(r.db.profiles, userID)
+	return nil
+}
+
+type testShippingSelectionRepo struct {
+	db *testDB
+}
+
+func (r testShippingSelectionRepo) FindByLotID(lotID app.LotID) (*app.ShippingSelection, error) {
+	selection, ok := r.db.shipping[lotID]
+	if !ok {
+		return nil, app.ErrShippingNotSelected
+	}
+	return &selection, nil
+}
+
+func (r testShippingSelectionRepo) Store(selection *app.ShippingSelection) error {
+	r.db.shipping[selection.LotID] = *selection
+	return nil
+}
+
+type testShippingPaymentRepo struct {
+	db *testDB
+}
+
+func (r testShippingPaymentRepo) FindByLotID(lotID app.LotID) (*app.ShippingPayment, error) {
+	payment, ok := r.db.payments[lotID]
+	if !ok {
+		return nil, app.ErrShippingPaymentNotFound
+	}
+	return &payment, nil
+}
+
+func (r testShippingPaymentRepo) Store(payment *app.ShippingPayment) error {
+	r.db.payments[payment.LotID] = *payment
+	return nil
+}
+
+func (r testShippingPaymentRepo) Remove(lotID app.LotID) error {
+	delete(r.db.payments, lotID)
+	return nil
+}
+
+type testDeliveryDeadlineRepo struct {
+	db *testDB
+}
+
+func (r testDeliveryDeadlineRepo) FindByLotID(lotID app.LotID) (*app.DeliveryDeadline, error) {
+	deadline, ok := r.db.deadlines[lotID]
+	if !ok {
+		return nil, app.ErrDeadlineNotFound
+	}
+	return &deadline, nil
+}
+
+func (r testDeliveryDeadlineRepo) FindAllOpen() ([]app.DeliveryDeadline, error) {
+	var res []app.DeliveryDeadline
+	for _, deadline := range r.db.deadlines {
+		if !deadline.Closed {
+			res = append(res, deadline)
+		}
+	}
+	return res, nil
+}
+
+func (r testDeliveryDeadlineRepo) Store(deadline *app.DeliveryDeadline) error {
+	r.db.deadlines[deadline.LotID] = *deadline
+	return nil
+}
+
+type testDeliveryEnrichmentRepo struct {
+	db *testDB
+}
+
+func (r testDeliveryEnrichmentRepo) FindAllDue(t time.Time) ([]app.DeliveryEnrichment, error) {
+	var res []app.DeliveryEnrichment
+	for _, enrichment := range r.db.enrichment {
+		if !enrichment.NextAttemptAt.After(t) {
+			res = append(res, enrichment)
+		}
+	}
+	return res, nil
+}
+
+func (r testDeliveryEnrichmentRepo) Store(enrichment *app.DeliveryEnrichment) error {
+	r.db.enrichment[enrichment.LotID] = *enrichment
+	return nil
+}
+
+func (r testDeliveryEnrichmentRepo) Remove(lotID app.LotID) error {
+	delete(r.db.enrichment, lotID)
+	return nil
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"context"
+	"time"
+)
+
+func StartTrackingUpdateJob(ctx context.Context, deliveryService *DeliveryService, interval time.Duration, logger *logrus.Logger) {
+	job := trackingUpdateJob{
+		deliveryService: deliveryService,
+		logger:          logger,
+	}
+	job.start(ctx, interval)
+}
+
+type trackingUpdateJob struct {
+	deliveryService *DeliveryService
+	logger          *logrus.Logger
+}
+
+func (job *trackingUpdateJob) start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				job.update()
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (job *trackingUpdateJob) update() {
+	err := job.deliveryService.UpdateTrackingStatuses()
+	if err != nil {
+		job.logger.Error(err)
+	}
+}
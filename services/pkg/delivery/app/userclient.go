@@ -10,3 +10,9 @@ type UserInfo struct {
 type UserServiceClient interface {
 	GetUserInfo(userID UserID) (UserInfo, error)
 }
+
+// AddressBookClient - returns ErrAddressNotFound if user has no such address
+type AddressBookClient interface {
+	GetAddress(userID UserID, addressID AddressID) (Address, error)
+	GetDefaultAddress(userID UserID) (Address, error)
+}
@@ -0,0 +1,16 @@
+package app
+
+import "errors"
+
+var ErrUserProfileNotFound = errors.New("user profile not found")
+
+// UserProfileRepositoryRead - read model of user profiles, updated by user.profile_updated events
+type UserProfileRepositoryRead interface {
+	FindByID(userID UserID) (*UserInfo, error)
+}
+
+type UserProfileRepository interface {
+	UserProfileRepositoryRead
+	Store(userID UserID, info UserInfo) error
+	Remove(userID UserID) error
+}
@@ -0,0 +1,78 @@
+package app_test
+
+import (
+	"arch-homework/pkg/common/app/integrationevent"
+	"arch-homework/pkg/delivery/app"
+	infraintegrationevent "arch-homework/pkg/delivery/infrastructure/integrationevent"
+
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserProfileEventsUpdateReadModel(t *testing.T) {
+	db := newTestDB()
+	lotID := db.addSentDelivery(testTrackingID)
+	delivery := db.deliveries[lotID]
+	receiverID, senderID := delivery.ReceiverID, delivery.SenderID
+
+	// profile is published on registration
+	handleTestProfileUpdatedEvent(t, db, receiverID, app.UserInfo{Login: "winner", FirstName: "Ivan", Address: "Kazan"})
+	assert.Equal(t, app.UserInfo{Login: "winner", FirstName: "Ivan", Address: "Kazan"}, db.profiles[receiverID])
+	assert.Equal(t, "winner", db.deliveries[lotID].ReceiverLogin)
+
+	handleTestProfileUpdatedEvent(t, db, receiverID, app.UserInfo{Login: "winner", FirstName: "Ivan", LastName: "Ivanov", Address: "Moscow"})
+	handleTestProfileUpdatedEvent(t, db, senderID, app.UserInfo{Login: "seller", FirstName: "Petr"})
+	assert.Equal(t, app.UserInfo{Login: "winner", FirstName: "Ivan", LastName: "Ivanov", Address: "Moscow"}, db.profiles[receiverID])
+	delivery = db.deliveries[lotID]
+	assert.Equal(t, "Ivanov", delivery.ReceiverLastName)
+	assert.Equal(t, "seller", delivery.SenderLogin)
+	assert.Equal(t, "Petr", delivery.SenderFirstName)
+
+	handleTestUserEvent(t, db, "user.user_deleted", map[string]string{"user_id": string(receiverID)})
+	assert.NotContains(t, db.profiles, receiverID)
+	assert.Contains(t, db.profiles, senderID)
+	delivery = db.deliveries[lotID]
+	assert.Equal(t, app.DeletedUserLogin, delivery.ReceiverLogin)
+	assert.Equal(t, "", delivery.ReceiverFirstName)
+	assert.Equal(t, "seller", delivery.SenderLogin)
+}
+
+func TestDuplicateUserProfileEventIgnored(t *testing.T) {
+	db := newTestDB()
+	userID := newUserID()
+	handler := app.NewEventHandler(db, infraintegrationevent.NewEventParser())
+	body, _ := json.Marshal(map[string]string{"user_id": string(userID), "login": "old"})
+	event := integrationevent.EventData{
+		UID:  integrationevent.EventUID(newLotID()),
+		Type: "user.profile_updated",
+		Body: string(body),
+	}
+	assert.NoError(t, handler.Handle(event))
+
+	handleTestProfileUpdatedEvent(t, db, userID, app.UserInfo{Login: "new"})
+	assert.NoError(t, handler.Handle(event))
+	assert.Equal(t, "new", db.profiles[userID].Login)
+}
+
+func handleTestProfileUpdatedEvent(t *testing.T, db *testDB, userID app.UserID, info app.UserInfo) {
+	handleTestUserEvent(t, db, "user.profile_updated", map[string]string{
+		"user_id":    string(userID),
+		"login":      info.Login,
+		"first_name": info.FirstName,
+		"last_name":  info.LastName,
+		"address":    info.Address,
+	})
+}
+
+func handleTestUserEvent(t *testing.T, db *testDB, eventType string, body map[string]string) {
+	strBody, _ := json.Marshal(body)
+	handler := app.NewEventHandler(db, infraintegrationevent.NewEventParser())
+	err := handler.Handle(integrationevent.EventData{
+		UID:  integrationevent.EventUID(newLotID()),
+		Type: eventType,
+		Body: string(strBody),
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,111 @@
+package integrationevent
+
+import (
+	"arch-homework/pkg/common/app/integrationevent"
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/delivery/app"
+
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const typeUserDeleted = "user.user_deleted"
+const typeUserProfileUpdated = "user.profile_updated"
+const typeLotWon = "lot.lot_won"
+
+func NewEventParser() app.IntegrationEventParser {
+	return eventParser{}
+}
+
+type eventParser struct {
+}
+
+func (e eventParser) ParseIntegrationEvent(event integrationevent.EventData) (app.HandledEvent, error) {
+	switch event.Type {
+	case typeUserDeleted:
+		return parseUserDeletedEvent(event.Body)
+	case typeUserProfileUpdated:
+		return parseUserProfileUpdatedEvent(event.Body)
+	case typeLotWon:
+		return parseLotWonEvent(event.Body)
+	default:
+		return nil, nil
+	}
+}
+
+func parseUserDeletedEvent(strBody string) (app.HandledEvent, error) {
+	var body userDeletedEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return app.NewUserDeletedEvent(app.UserID(body.UserID)), nil
+}
+
+func parseUserProfileUpdatedEvent(strBody string) (app.HandledEvent, error) {
+	var body userProfileUpdatedEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if body.Login == "" {
+		return nil, errors.New("login required")
+	}
+	return app.NewUserProfileUpdatedEvent(app.UserID(body.UserID), app.UserInfo{
+		Login:     body.Login,
+		FirstName: body.FirstName,
+		LastName:  body.LastName,
+		Address:   body.Address,
+	}), nil
+}
+
+func parseLotWonEvent(strBody string) (app.HandledEvent, error) {
+	var body lotWonEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, id := range []string{body.LotID, body.UserID, body.LotOwnerID} {
+		err = uuid.ValidateUUID(id)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	var finishedAt time.Time
+	if body.FinishedAt != "" {
+		finishedAt, err = time.Parse(time.RFC3339, body.FinishedAt)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return app.NewLotWonEvent(app.LotID(body.LotID), app.UserID(body.UserID), app.UserID(body.LotOwnerID), finishedAt), nil
+}
+
+type userDeletedEventBody struct {
+	UserID string `json:"user_id"`
+}
+
+type userProfileUpdatedEventBody struct {
+	UserID    string `json:"user_id"`
+	Login     string `json:"login"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Address   string `json:"address"`
+}
+
+type lotWonEventBody struct {
+	LotID      string `json:"lot_id"`
+	UserID     string `json:"user_id"`
+	LotOwnerID string `json:"lot_owner_id"`
+	FinishedAt string `json:"finished_at"`
+}
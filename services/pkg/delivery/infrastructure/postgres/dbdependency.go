@@ -20,6 +20,34 @@ func (d *dbDependency) DeliveryInfoRepositoryRead() app.DeliveryInfoRepositoryRe
 	return NewDeliveryInfoRepository(d.client)
 }
 
+func (d *dbDependency) DeliveryAddressRepositoryRead() app.DeliveryAddressRepositoryRead {
+	return NewDeliveryAddressRepository(d.client)
+}
+
+func (d *dbDependency) UserProfileRepositoryRead() app.UserProfileRepositoryRead {
+	return NewUserProfileRepository(d.client)
+}
+
+func (d *dbDependency) TrackingEventRepositoryRead() app.TrackingEventRepositoryRead {
+	return NewTrackingEventRepository(d.client)
+}
+
+func (d *dbDependency) ShippingSelectionRepositoryRead() app.ShippingSelectionRepositoryRead {
+	return NewShippingSelectionRepository(d.client)
+}
+
+func (d *dbDependency) ShippingPaymentRepositoryRead() app.ShippingPaymentRepositoryRead {
+	return NewShippingPaymentRepository(d.client)
+}
+
+func (d *dbDependency) DeliveryDeadlineRepositoryRead() app.DeliveryDeadlineRepositoryRead {
+	return NewDeliveryDeadlineRepository(d.client)
+}
+
+func (d *dbDependency) DeliveryEnrichmentRepositoryRead() app.DeliveryEnrichmentRepositoryRead {
+	return NewDeliveryEnrichmentRepository(d.client)
+}
+
 func (d *dbDependency) NewTransactionalUnit() (app.TransactionalUnit, error) {
 	transaction, err := d.client.BeginTransaction()
 	if err != nil {
@@ -36,6 +64,34 @@ func (t *transactionalUnit) DeliveryInfoRepository() app.DeliveryInfoRepository
 	return NewDeliveryInfoRepository(t.transaction)
 }
 
+func (t *transactionalUnit) DeliveryAddressRepository() app.DeliveryAddressRepository {
+	return NewDeliveryAddressRepository(t.transaction)
+}
+
+func (t *transactionalUnit) UserProfileRepository() app.UserProfileRepository {
+	return NewUserProfileRepository(t.transaction)
+}
+
+func (t *transactionalUnit) TrackingEventRepository() app.TrackingEventRepository {
+	return NewTrackingEventRepository(t.transaction)
+}
+
+func (t *transactionalUnit) ShippingSelectionRepository() app.ShippingSelectionRepository {
+	return NewShippingSelectionRepository(t.transaction)
+}
+
+func (t *transactionalUnit) ShippingPaymentRepository() app.ShippingPaymentRepository {
+	return NewShippingPaymentRepository(t.transaction)
+}
+
+func (t *transactionalUnit) DeliveryDeadlineRepository() app.DeliveryDeadlineRepository {
+	return NewDeliveryDeadlineRepository(t.transaction)
+}
+
+func (t *transactionalUnit) DeliveryEnrichmentRepository() app.DeliveryEnrichmentRepository {
+	return NewDeliveryEnrichmentRepository(t.transaction)
+}
+
 func (t *transactionalUnit) EventStore() storedevent.EventStore {
 	return NewEventStore(t.transaction)
 }
@@ -44,6 +100,16 @@ func (t *transactionalUnit) ProcessedRequestRepository() app.ProcessedRequestRep
 	return NewProcessedRequestRepository(t.transaction)
 }
 
+func (t *transactionalUnit) ProcessedEventRepository() app.ProcessedEventRepository {
+	return NewProcessedEventRepository(t.transaction)
+}
+
+func (t *transactionalUnit) AddLock(lockName string) error {
+	const lockQuery = "SELECT pg_advisory_xact_lock(hashtext($1))"
+	_, err := t.transaction.Exec(lockQuery, lockName)
+	return errors.WithStack(err)
+}
+
 func (t *transactionalUnit) Complete(err error) error {
 	if err != nil {
 		rollbackErr := t.transaction.Rollback()
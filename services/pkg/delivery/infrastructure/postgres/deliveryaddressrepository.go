@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/delivery/app"
+
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func NewDeliveryAddressRepository(client postgres.Client) app.DeliveryAddressRepository {
+	return &deliveryAddressRepository{client: client}
+}
+
+type deliveryAddressRepository struct {
+	client postgres.Client
+}
+
+func (repo *deliveryAddressRepository) FindByLotID(lotID app.LotID) (*app.DeliveryAddress, error) {
+	const query = `
+			SELECT lot_id, receiver_id, address_id, recipient_name, phone, country, postal_code, city, street
+			FROM delivery_address WHERE lot_id = $1
+		`
+
+	var address sqlxDeliveryAddress
+	err := repo.client.Get(&address, query, string(lotID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrAddressNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &app.DeliveryAddress{
+		LotID:      app.LotID(address.LotID),
+		ReceiverID: app.UserID(address.ReceiverID),
+		AddressID:  app.AddressID(address.AddressID),
+		Address: app.Address{
+			RecipientName: address.RecipientName,
+			Phone:         address.Phone,
+			Country:       address.Country,
+			PostalCode:    address.PostalCode,
+			City:          address.City,
+			Street:        address.Street,
+		},
+	}, nil
+}
+
+func (repo *deliveryAddressRepository) Store(address *app.DeliveryAddress) error {
+	const query = `
+			INSERT INTO delivery_address (lot_id, receiver_id, address_id, recipient_name, phone, country, postal_code, city, street)
+			VALUES (:lot_id, :receiver_id, :address_id, :recipient_name, :phone, :country, :postal_code, :city, :street)
+			ON CONFLICT (lot_id) DO UPDATE SET
+				address_id = excluded.address_id,
+				recipient_name = excluded.recipient_name,
+				phone = excluded.phone,
+				country = excluded.country,
+				postal_code = excluded.postal_code,
+				city = excluded.city,
+				street = excluded.street;
+		`
+
+	addressx := sqlxDeliveryAddress{
+		LotID:         string(address.LotID),
+		ReceiverID:    string(address.ReceiverID),
+		AddressID:     string(address.AddressID),
+		RecipientName: address.Address.RecipientName,
+		Phone:         address.Address.Phone,
+		Country:       address.Address.Country,
+		PostalCode:    address.Address.PostalCode,
+		City:          address.Address.City,
+		Street:        address.Address.Street,
+	}
+
+	_, err := repo.client.NamedExec(query, &addressx)
+	return errors.WithStack(err)
+}
+
+func (repo *deliveryAddressRepository) RemoveAllByReceiverID(receiverID app.UserID) error {
+	const query = `DELETE FROM delivery_address WHERE receiver_id = $1`
+	_, err := repo.client.Exec(query, string(receiverID))
+	return errors.WithStack(err)
+}
+
+type sqlxDeliveryAddress struct {
+	LotID         string `db:"lot_id"`
+	ReceiverID    string `db:"receiver_id"`
+	AddressID     string `db:"address_id"`
+	RecipientName string `db:"recipient_name"`
+	Phone         string `db:"phone"`
+	Country       string `db:"country"`
+	PostalCode    string `db:"postal_code"`
+	City          string `db:"city"`
+	Street        string `db:"street"`
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/delivery/app"
+
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func NewDeliveryDeadlineRepository(client postgres.Client) app.DeliveryDeadlineRepository {
+	return &deliveryDeadlineRepository{client: client}
+}
+
+type deliveryDeadlineRepository struct {
+	client postgres.Client
+}
+
+func (repo *deliveryDeadlineRepository) FindByLotID(lotID app.LotID) (*app.DeliveryDeadline, error) {
+	const query = `
+			SELECT lot_id, sender_id, receiver_id, finished_at, ship_reminder_sent, confirm_reminder_sent, closed
+			FROM delivery_deadline WHERE lot_id = $1
+		`
+
+	var deadline sqlxDeliveryDeadline
+	err := repo.client.Get(&deadline, query, string(lotID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrDeadlineNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	res := sqlxDeliveryDeadlineToDeliveryDeadline(deadline)
+	return &res, nil
+}
+
+func (repo *deliveryDeadlineRepository) FindAllOpen() ([]app.DeliveryDeadline, error) {
+	const query = `
+			SELECT lot_id, sender_id, receiver_id, finished_at, ship_reminder_sent, confirm_reminder_sent, closed
+			FROM delivery_deadline WHERE closed = FALSE ORDER BY finished_at
+		`
+
+	var deadlines []sqlxDeliveryDeadline
+	err := repo.client.Select(&deadlines, query)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.DeliveryDeadline, 0, len(deadlines))
+	for _, deadline := range deadlines {
+		res = append(res, sqlxDeliveryDeadlineToDeliveryDeadline(deadline))
+	}
+	return res, nil
+}
+
+func (repo *deliveryDeadlineRepository) Store(deadline *app.DeliveryDeadline) error {
+	const query = `
+			INSERT INTO delivery_deadline (lot_id, sender_id, receiver_id, finished_at, ship_reminder_sent, confirm_reminder_sent, closed)
+			VALUES (:lot_id, :sender_id, :receiver_id, :finished_at, :ship_reminder_sent, :confirm_reminder_sent, :closed)
+			ON CONFLICT (lot_id) DO UPDATE SET
+				ship_reminder_sent = excluded.ship_reminder_sent,
+				confirm_reminder_sent = excluded.confirm_reminder_sent,
+				closed = excluded.closed;
+		`
+
+	deadlinex := sqlxDeliveryDeadline{
+		LotID:               string(deadline.LotID),
+		SenderID:            string(deadline.SenderID),
+		ReceiverID:          string(deadline.ReceiverID),
+		FinishedAt:          deadline.FinishedAt,
+		ShipReminderSent:    deadline.ShipReminderSent,
+		ConfirmReminderSent: deadline.ConfirmReminderSent,
+		Closed:              deadline.Closed,
+	}
+	_, err := repo.client.NamedExec(query, &deadlinex)
+	return errors.WithStack(err)
+}
+
+func sqlxDeliveryDeadlineToDeliveryDeadline(deadline sqlxDeliveryDeadline) app.DeliveryDeadline {
+	return app.DeliveryDeadline{
+		LotID:               app.LotID(deadline.LotID),
+		SenderID:            app.UserID(deadline.SenderID),
+		ReceiverID:          app.UserID(deadline.ReceiverID),
+		FinishedAt:          deadline.FinishedAt,
+		ShipReminderSent:    deadline.ShipReminderSent,
+		ConfirmReminderSent: deadline.ConfirmReminderSent,
+		Closed:              deadline.Closed,
+	}
+}
+
+type sqlxDeliveryDeadline struct {
+	LotID               string    `db:"lot_id"`
+	SenderID            string    `db:"sender_id"`
+	ReceiverID          string    `db:"receiver_id"`
+	FinishedAt          time.Time `db:"finished_at"`
+	ShipReminderSent    bool      `db:"ship_reminder_sent"`
+	ConfirmReminderSent bool      `db:"confirm_reminder_sent"`
+	Closed              bool      `db:"closed"`
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/delivery/app"
+
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func NewDeliveryEnrichmentRepository(client postgres.Client) app.DeliveryEnrichmentRepository {
+	return &deliveryEnrichmentRepository{client: client}
+}
+
+type deliveryEnrichmentRepository struct {
+	client postgres.Client
+}
+
+func (repo *deliveryEnrichmentRepository) FindAllDue(t time.Time) ([]app.DeliveryEnrichment, error) {
+	const query = `SELECT lot_id, attempts, next_attempt_at FROM delivery_enrichment WHERE next_attempt_at <= $1 ORDER BY next_attempt_at`
+
+	var enrichments []sqlxDeliveryEnrichment
+	err := repo.client.Select(&enrichments, query, t)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.DeliveryEnrichment, 0, len(enrichments))
+	for _, enrichment := range enrichments {
+		res = append(res, app.DeliveryEnrichment{
+			LotID:         app.LotID(enrichment.LotID),
+			Attempts:      enrichment.Attempts,
+			NextAttemptAt: enrichment.NextAttemptAt,
+		})
+	}
+	return res, nil
+}
+
+func (repo *deliveryEnrichmentRepository) Store(enrichment *app.DeliveryEnrichment) error {
+	const query = `
+			INSERT INTO delivery_enrichment (lot_id, attempts, next_attempt_at)
+			VALUES (:lot_id, :attempts, :next_attempt_at)
+			ON CONFLICT (lot_id) DO UPDATE SET
+				attempts = excluded.attempts,
+				next_attempt_at = excluded.next_attempt_at;
+		`
+
+	enrichmentx := sqlxDeliveryEnrichment{
+		LotID:         string(enrichment.LotID),
+		Attempts:      enrichment.Attempts,
+		NextAttemptAt: enrichment.NextAttemptAt,
+	}
+	_, err := repo.client.NamedExec(query, &enrichmentx)
+	return errors.WithStack(err)
+}
+
+func (repo *deliveryEnrichmentRepository) Remove(lotID app.LotID) error {
+	const query = `DELETE FROM delivery_enrichment WHERE lot_id = $1`
+
+	_, err := repo.client.Exec(query, string(lotID))
+	return errors.WithStack(err)
+}
+
+type sqlxDeliveryEnrichment struct {
+	LotID         string    `db:"lot_id"`
+	Attempts      int       `db:"attempts"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+}
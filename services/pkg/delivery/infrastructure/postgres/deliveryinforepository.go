@@ -20,7 +20,8 @@ type deliveryInfoRepository struct {
 func (repo *deliveryInfoRepository) FindByLotID(id app.LotID) (*app.DeliveryInfo, error) {
 	const query = `
 			SELECT lot_id, status, tracking_id, receiver_id, receiver_login, receiver_first_name, receiver_last_name,
-				receiver_address, sender_id, sender_login, sender_first_name, sender_last_name
+				receiver_name, receiver_phone, receiver_country, receiver_postal_code, receiver_city, receiver_address,
+				sender_id, sender_login, sender_first_name, sender_last_name, shipping_option, shipping_type, shipping_price
 			FROM delivery WHERE lot_id = $1
 		`
 
@@ -36,20 +37,109 @@ func (repo *deliveryInfoRepository) FindByLotID(id app.LotID) (*app.DeliveryInfo
 	return &res, nil
 }
 
+func (repo *deliveryInfoRepository) FindAllByUserID(userID app.UserID) ([]app.DeliveryInfo, error) {
+	const query = `
+			SELECT lot_id, status, tracking_id, receiver_id, receiver_login, receiver_first_name, receiver_last_name,
+				receiver_name, receiver_phone, receiver_country, receiver_postal_code, receiver_city, receiver_address,
+				sender_id, sender_login, sender_first_name, sender_last_name, shipping_option, shipping_type, shipping_price
+			FROM delivery WHERE receiver_id = $1 OR sender_id = $1
+		`
+
+	var infos []sqlxDeliveryInfo
+	err := repo.client.Select(&infos, query, string(userID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.DeliveryInfo, 0, len(infos))
+	for _, info := range infos {
+		res = append(res, sqlxDeliveryInfoToDeliveryInfo(info))
+	}
+	return res, nil
+}
+
+func (repo *deliveryInfoRepository) FindAllByStatus(status app.LotStatus) ([]app.DeliveryInfo, error) {
+	const query = `
+			SELECT lot_id, status, tracking_id, receiver_id, receiver_login, receiver_first_name, receiver_last_name,
+				receiver_name, receiver_phone, receiver_country, receiver_postal_code, receiver_city, receiver_address,
+				sender_id, sender_login, sender_first_name, sender_last_name, shipping_option, shipping_type, shipping_price
+			FROM delivery WHERE status = $1
+		`
+
+	var infos []sqlxDeliveryInfo
+	err := repo.client.Select(&infos, query, string(status))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.DeliveryInfo, 0, len(infos))
+	for _, info := range infos {
+		res = append(res, sqlxDeliveryInfoToDeliveryInfo(info))
+	}
+	return res, nil
+}
+
+func (repo *deliveryInfoRepository) UpdateUserNames(userID app.UserID, info app.UserInfo) error {
+	const receiverQuery = `
+			UPDATE delivery SET receiver_login = $2, receiver_first_name = $3, receiver_last_name = $4
+			WHERE receiver_id = $1
+		`
+	const senderQuery = `
+			UPDATE delivery SET sender_login = $2, sender_first_name = $3, sender_last_name = $4
+			WHERE sender_id = $1
+		`
+
+	_, err := repo.client.Exec(receiverQuery, string(userID), info.Login, info.FirstName, info.LastName)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = repo.client.Exec(senderQuery, string(userID), info.Login, info.FirstName, info.LastName)
+	return errors.WithStack(err)
+}
+
+func (repo *deliveryInfoRepository) AnonymizeUser(userID app.UserID) error {
+	const receiverQuery = `
+			UPDATE delivery SET receiver_login = $2, receiver_first_name = '', receiver_last_name = '',
+				receiver_name = '', receiver_phone = '', receiver_country = '', receiver_postal_code = '', receiver_city = '', receiver_address = ''
+			WHERE receiver_id = $1
+		`
+	const senderQuery = `
+			UPDATE delivery SET sender_login = $2, sender_first_name = '', sender_last_name = ''
+			WHERE sender_id = $1
+		`
+
+	_, err := repo.client.Exec(receiverQuery, string(userID), app.DeletedUserLogin)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = repo.client.Exec(senderQuery, string(userID), app.DeletedUserLogin)
+	return errors.WithStack(err)
+}
+
 func (repo *deliveryInfoRepository) Store(info *app.DeliveryInfo) error {
 	const query = `
-			INSERT INTO delivery (lot_id, status, tracking_id, receiver_id, receiver_login, receiver_first_name, receiver_last_name, receiver_address, sender_id, sender_login, sender_first_name, sender_last_name)
-			VALUES (:lot_id, :status, :tracking_id, :receiver_id, :receiver_login, :receiver_first_name, :receiver_last_name, :receiver_address, :sender_id, :sender_login, :sender_first_name, :sender_last_name)
+			INSERT INTO delivery (lot_id, status, tracking_id, receiver_id, receiver_login, receiver_first_name, receiver_last_name,
+				receiver_name, receiver_phone, receiver_country, receiver_postal_code, receiver_city, receiver_address,
+				sender_id, sender_login, sender_first_name, sender_last_name, shipping_option, shipping_type, shipping_price)
+			VALUES (:lot_id, :status, :tracking_id, :receiver_id, :receiver_login, :receiver_first_name, :receiver_last_name,
+				:receiver_name, :receiver_phone, :receiver_country, :receiver_postal_code, :receiver_city, :receiver_address,
+				:sender_id, :sender_login, :sender_first_name, :sender_last_name, :shipping_option, :shipping_type, :shipping_price)
 			ON CONFLICT (lot_id) DO UPDATE SET
 				status = excluded.status,
 				tracking_id = excluded.tracking_id,
 				receiver_login = excluded.receiver_login,
 				receiver_first_name = excluded.receiver_first_name,
 				receiver_last_name = excluded.receiver_last_name,
+				receiver_name = excluded.receiver_name,
+				receiver_phone = excluded.receiver_phone,
+				receiver_country = excluded.receiver_country,
+				receiver_postal_code = excluded.receiver_postal_code,
+				receiver_city = excluded.receiver_city,
 				receiver_address = excluded.receiver_address,
 				sender_login = excluded.sender_login,
 				sender_first_name = excluded.sender_first_name,
-				sender_last_name = excluded.sender_last_name;
+				sender_last_name = excluded.sender_last_name,
+				shipping_option = excluded.shipping_option,
+				shipping_type = excluded.shipping_type,
+				shipping_price = excluded.shipping_price;
 		`
 
 	if info.TrackingID == nil {
@@ -57,18 +147,28 @@ func (repo *deliveryInfoRepository) Store(info *app.DeliveryInfo) error {
 	}
 
 	infox := sqlxDeliveryInfo{
-		LotID:             string(info.LotID),
-		Status:            string(info.LotStatus),
-		TrackingID:        string(*info.TrackingID),
-		ReceiverID:        string(info.ReceiverID),
-		ReceiverLogin:     info.ReceiverLogin,
-		ReceiverFirstName: info.ReceiverFirstName,
-		ReceiverLastName:  info.ReceiverLastName,
-		ReceiverAddress:   string(info.ReceiverAddress),
-		SenderID:          string(info.SenderID),
-		SenderLogin:       info.SenderLogin,
-		SenderFirstName:   info.SenderFirstName,
-		SenderLastName:    info.SenderLastName,
+		LotID:              string(info.LotID),
+		Status:             string(info.LotStatus),
+		TrackingID:         string(*info.TrackingID),
+		ReceiverID:         string(info.ReceiverID),
+		ReceiverLogin:      info.ReceiverLogin,
+		ReceiverFirstName:  info.ReceiverFirstName,
+		ReceiverLastName:   info.ReceiverLastName,
+		ReceiverName:       info.ReceiverAddress.RecipientName,
+		ReceiverPhone:      info.ReceiverAddress.Phone,
+		ReceiverCountry:    info.ReceiverAddress.Country,
+		ReceiverPostalCode: info.ReceiverAddress.PostalCode,
+		ReceiverCity:       info.ReceiverAddress.City,
+		ReceiverAddress:    info.ReceiverAddress.Street,
+		SenderID:           string(info.SenderID),
+		SenderLogin:        info.SenderLogin,
+		SenderFirstName:    info.SenderFirstName,
+		SenderLastName:     info.SenderLastName,
+	}
+	if info.Shipping != nil {
+		infox.ShippingOption = sql.NullString{String: info.Shipping.OptionName, Valid: true}
+		infox.ShippingType = sql.NullString{String: string(info.Shipping.OptionType), Valid: true}
+		infox.ShippingPrice = sql.NullInt64{Int64: int64(info.Shipping.Price.RawValue()), Valid: true}
 	}
 
 	_, err := repo.client.NamedExec(query, &infox)
@@ -77,7 +177,7 @@ func (repo *deliveryInfoRepository) Store(info *app.DeliveryInfo) error {
 
 func sqlxDeliveryInfoToDeliveryInfo(info sqlxDeliveryInfo) app.DeliveryInfo {
 	trackingID := app.TrackingID(info.TrackingID)
-	return app.DeliveryInfo{
+	res := app.DeliveryInfo{
 		LotID:             app.LotID(info.LotID),
 		LotStatus:         app.LotStatus(info.Status),
 		TrackingID:        &trackingID,
@@ -85,25 +185,48 @@ func sqlxDeliveryInfoToDeliveryInfo(info sqlxDeliveryInfo) app.DeliveryInfo {
 		ReceiverLogin:     info.ReceiverLogin,
 		ReceiverFirstName: info.ReceiverFirstName,
 		ReceiverLastName:  info.ReceiverLastName,
-		ReceiverAddress:   app.Address(info.ReceiverAddress),
-		SenderID:          app.UserID(info.SenderID),
-		SenderLogin:       info.SenderLogin,
-		SenderFirstName:   info.SenderFirstName,
-		SenderLastName:    info.SenderLastName,
+		ReceiverAddress: app.Address{
+			RecipientName: info.ReceiverName,
+			Phone:         info.ReceiverPhone,
+			Country:       info.ReceiverCountry,
+			PostalCode:    info.ReceiverPostalCode,
+			City:          info.ReceiverCity,
+			Street:        info.ReceiverAddress,
+		},
+		SenderID:        app.UserID(info.SenderID),
+		SenderLogin:     info.SenderLogin,
+		SenderFirstName: info.SenderFirstName,
+		SenderLastName:  info.SenderLastName,
+	}
+	if info.ShippingOption.Valid {
+		res.Shipping = &app.ShippingQuote{
+			OptionName: info.ShippingOption.String,
+			OptionType: app.ShippingOptionType(info.ShippingType.String),
+			Price:      app.AmountFromRawValue(uint64(info.ShippingPrice.Int64)),
+		}
 	}
+	return res
 }
 
 type sqlxDeliveryInfo struct {
-	LotID             string `db:"lot_id"`
-	Status            string `db:"status"`
-	TrackingID        string `db:"tracking_id"`
-	ReceiverID        string `db:"receiver_id"`
-	ReceiverLogin     string `db:"receiver_login"`
-	ReceiverFirstName string `db:"receiver_first_name"`
-	ReceiverLastName  string `db:"receiver_last_name"`
-	ReceiverAddress   string `db:"receiver_address"`
-	SenderID          string `db:"sender_id"`
-	SenderLogin       string `db:"sender_login"`
-	SenderFirstName   string `db:"sender_first_name"`
-	SenderLastName    string `db:"sender_last_name"`
+	LotID              string         `db:"lot_id"`
+	Status             string         `db:"status"`
+	TrackingID         string         `db:"tracking_id"`
+	ReceiverID         string         `db:"receiver_id"`
+	ReceiverLogin      string         `db:"receiver_login"`
+	ReceiverFirstName  string         `db:"receiver_first_name"`
+	ReceiverLastName   string         `db:"receiver_last_name"`
+	ReceiverName       string         `db:"receiver_name"`
+	ReceiverPhone      string         `db:"receiver_phone"`
+	ReceiverCountry    string         `db:"receiver_country"`
+	ReceiverPostalCode string         `db:"receiver_postal_code"`
+	ReceiverCity       string         `db:"receiver_city"`
+	ReceiverAddress    string         `db:"receiver_address"`
+	SenderID           string         `db:"sender_id"`
+	SenderLogin        string         `db:"sender_login"`
+	SenderFirstName    string         `db:"sender_first_name"`
+	SenderLastName     string         `db:"sender_last_name"`
+	ShippingOption     sql.NullString `db:"shipping_option"`
+	ShippingType       sql.NullString `db:"shipping_type"`
+	ShippingPrice      sql.NullInt64  `db:"shipping_price"`
 }
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"arch-homework/pkg/common/app/integrationevent"
 	"arch-homework/pkg/common/infrastructure/postgres"
 	"arch-homework/pkg/delivery/app"
 
@@ -13,15 +14,27 @@ func NewProcessedRequestRepository(client postgres.Client) app.ProcessedRequestR
 	return &processedRequestRepository{client: client}
 }
 
+func NewProcessedEventRepository(client postgres.Client) app.ProcessedEventRepository {
+	return &processedRequestRepository{client: client}
+}
+
 type processedRequestRepository struct {
 	client postgres.Client
 }
 
 func (repo *processedRequestRepository) SetRequestProcessed(uid app.RequestID) (alreadyProcessed bool, err error) {
+	return repo.setRequestProcessed(string(uid))
+}
+
+func (repo *processedRequestRepository) SetEventProcessed(uid integrationevent.EventUID) (alreadyProcessed bool, err error) {
+	return repo.setRequestProcessed(string(uid))
+}
+
+func (repo *processedRequestRepository) setRequestProcessed(uid string) (bool, error) {
 	const query = `INSERT INTO processed_request (uid) VALUES ($1) ON CONFLICT DO NOTHING RETURNING uid`
 
 	var resUID string
-	err = repo.client.Get(&resUID, query, string(uid))
+	err := repo.client.Get(&resUID, query, uid)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return true, nil
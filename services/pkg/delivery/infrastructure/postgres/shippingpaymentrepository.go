@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/delivery/app"
+
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func NewShippingPaymentRepository(client postgres.Client) app.ShippingPaymentRepository {
+	return &shippingPaymentRepository{client: client}
+}
+
+type shippingPaymentRepository struct {
+	client postgres.Client
+}
+
+func (repo *shippingPaymentRepository) FindByLotID(lotID app.LotID) (*app.ShippingPayment, error) {
+	const query = `SELECT lot_id, receiver_id, revision, request_id, option_name, option_type, price FROM delivery_shipping_payment WHERE lot_id = $1`
+
+	var payment sqlxShippingPayment
+	err := repo.client.Get(&payment, query, string(lotID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrShippingPaymentNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &app.ShippingPayment{
+		LotID:      app.LotID(payment.LotID),
+		ReceiverID: app.UserID(payment.ReceiverID),
+		Revision:   payment.Revision,
+		RequestID:  app.RequestID(payment.RequestID),
+		ShippingQuote: app.ShippingQuote{
+			OptionName: payment.OptionName,
+			OptionType: app.ShippingOptionType(payment.OptionType),
+			Price:      app.AmountFromRawValue(payment.Price),
+		},
+	}, nil
+}
+
+func (repo *shippingPaymentRepository) Store(payment *app.ShippingPayment) error {
+	const query = `
+			INSERT INTO delivery_shipping_payment (lot_id, receiver_id, revision, request_id, option_name, option_type, price)
+			VALUES (:lot_id, :receiver_id, :revision, :request_id, :option_name, :option_type, :price)
+		`
+
+	paymentx := sqlxShippingPayment{
+		LotID:      string(payment.LotID),
+		ReceiverID: string(payment.ReceiverID),
+		Revision:   payment.Revision,
+		RequestID:  string(payment.RequestID),
+		OptionName: payment.OptionName,
+		OptionType: string(payment.OptionType),
+		Price:      payment.Price.RawValue(),
+	}
+	_, err := repo.client.NamedExec(query, &paymentx)
+	return errors.WithStack(err)
+}
+
+func (repo *shippingPaymentRepository) Remove(lotID app.LotID) error {
+	const query = `DELETE FROM delivery_shipping_payment WHERE lot_id = $1`
+	_, err := repo.client.Exec(query, string(lotID))
+	return errors.WithStack(err)
+}
+
+type sqlxShippingPayment struct {
+	LotID      string `db:"lot_id"`
+	ReceiverID string `db:"receiver_id"`
+	Revision   int    `db:"revision"`
+	RequestID  string `db:"request_id"`
+	OptionName string `db:"option_name"`
+	OptionType string `db:"option_type"`
+	Price      uint64 `db:"price"`
+}
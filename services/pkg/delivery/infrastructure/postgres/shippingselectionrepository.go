@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/delivery/app"
+
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func NewShippingSelectionRepository(client postgres.Client) app.ShippingSelectionRepository {
+	return &shippingSelectionRepository{client: client}
+}
+
+type shippingSelectionRepository struct {
+	client postgres.Client
+}
+
+func (repo *shippingSelectionRepository) FindByLotID(lotID app.LotID) (*app.ShippingSelection, error) {
+	const query = `SELECT lot_id, receiver_id, revision, option_name, option_type, price FROM delivery_shipping WHERE lot_id = $1`
+
+	var selection sqlxShippingSelection
+	err := repo.client.Get(&selection, query, string(lotID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrShippingNotSelected)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &app.ShippingSelection{
+		LotID:      app.LotID(selection.LotID),
+		ReceiverID: app.UserID(selection.ReceiverID),
+		Revision:   selection.Revision,
+		ShippingQuote: app.ShippingQuote{
+			OptionName: selection.OptionName,
+			OptionType: app.ShippingOptionType(selection.OptionType),
+			Price:      app.AmountFromRawValue(selection.Price),
+		},
+	}, nil
+}
+
+func (repo *shippingSelectionRepository) Store(selection *app.ShippingSelection) error {
+	const query = `
+			INSERT INTO delivery_shipping (lot_id, receiver_id, revision, option_name, option_type, price)
+			VALUES (:lot_id, :receiver_id, :revision, :option_name, :option_type, :price)
+			ON CONFLICT (lot_id) DO UPDATE SET
+				revision = excluded.revision,
+				option_name = excluded.option_name,
+				option_type = excluded.option_type,
+				price = excluded.price;
+		`
+
+	selectionx := sqlxShippingSelection{
+		LotID:      string(selection.LotID),
+		ReceiverID: string(selection.ReceiverID),
+		Revision:   selection.Revision,
+		OptionName: selection.OptionName,
+		OptionType: string(selection.OptionType),
+		Price:      selection.Price.RawValue(),
+	}
+	_, err := repo.client.NamedExec(query, &selectionx)
+	return errors.WithStack(err)
+}
+
+type sqlxShippingSelection struct {
+	LotID      string `db:"lot_id"`
+	ReceiverID string `db:"receiver_id"`
+	Revision   int    `db:"revision"`
+	OptionName string `db:"option_name"`
+	OptionType string `db:"option_type"`
+	Price      uint64 `db:"price"`
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/delivery/app"
+
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func NewTrackingEventRepository(client postgres.Client) app.TrackingEventRepository {
+	return &trackingEventRepository{client: client}
+}
+
+type trackingEventRepository struct {
+	client postgres.Client
+}
+
+func (repo *trackingEventRepository) FindAllByLotID(lotID app.LotID) ([]app.TrackingEvent, error) {
+	const query = `SELECT lot_id, status, location, event_time FROM tracking_event WHERE lot_id = $1 ORDER BY event_time`
+
+	var events []sqlxTrackingEvent
+	err := repo.client.Select(&events, query, string(lotID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.TrackingEvent, 0, len(events))
+	for _, event := range events {
+		res = append(res, app.TrackingEvent{
+			Status:   app.TrackingStatus(event.Status),
+			Location: event.Location,
+			Time:     event.EventTime,
+		})
+	}
+	return res, nil
+}
+
+func (repo *trackingEventRepository) Add(lotID app.LotID, event app.TrackingEvent) error {
+	const query = `
+			INSERT INTO tracking_event (lot_id, status, location, event_time)
+			VALUES (:lot_id, :status, :location, :event_time)
+			ON CONFLICT (lot_id, status, event_time) DO NOTHING
+		`
+
+	eventx := sqlxTrackingEvent{
+		LotID:     string(lotID),
+		Status:    string(event.Status),
+		Location:  event.Location,
+		EventTime: event.Time,
+	}
+	_, err := repo.client.NamedExec(query, &eventx)
+	return errors.WithStack(err)
+}
+
+type sqlxTrackingEvent struct {
+	LotID     string    `db:"lot_id"`
+	Status    string    `db:"status"`
+	Location  string    `db:"location"`
+	EventTime time.Time `db:"event_time"`
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/delivery/app"
+
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func NewUserProfileRepository(client postgres.Client) app.UserProfileRepository {
+	return &userProfileRepository{client: client}
+}
+
+type userProfileRepository struct {
+	client postgres.Client
+}
+
+func (repo *userProfileRepository) FindByID(userID app.UserID) (*app.UserInfo, error) {
+	const query = `SELECT user_id, login, first_name, last_name, address FROM user_profile WHERE user_id = $1`
+
+	var profile sqlxUserProfile
+	err := repo.client.Get(&profile, query, string(userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrUserProfileNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return &app.UserInfo{
+		Login:     profile.Login,
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		Address:   profile.Address,
+	}, nil
+}
+
+func (repo *userProfileRepository) Store(userID app.UserID, info app.UserInfo) error {
+	const query = `
+			INSERT INTO user_profile (user_id, login, first_name, last_name, address)
+			VALUES (:user_id, :login, :first_name, :last_name, :address)
+			ON CONFLICT (user_id) DO UPDATE SET
+				login = excluded.login,
+				first_name = excluded.first_name,
+				last_name = excluded.last_name,
+				address = excluded.address;
+		`
+
+	profilex := sqlxUserProfile{
+		UserID:    string(userID),
+		Login:     info.Login,
+		FirstName: info.FirstName,
+		LastName:  info.LastName,
+		Address:   info.Address,
+	}
+
+	_, err := repo.client.NamedExec(query, &profilex)
+	return errors.WithStack(err)
+}
+
+func (repo *userProfileRepository) Remove(userID app.UserID) error {
+	const query = `DELETE FROM user_profile WHERE user_id = $1`
+	_, err := repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
+
+type sqlxUserProfile struct {
+	UserID    string `db:"user_id"`
+	Login     string `db:"login"`
+	FirstName string `db:"first_name"`
+	LastName  string `db:"last_name"`
+	Address   string `db:"address"`
+}
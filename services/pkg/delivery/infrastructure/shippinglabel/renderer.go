@@ -0,0 +1,257 @@
+package shippinglabel
+
+import (
+	"arch-homework/pkg/delivery/app"
+
+	"bytes"
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/pkg/errors"
+)
+
+const (
+	regularFontFile = "DejaVuSansCondensed.ttf"
+	boldFontFile    = "DejaVuSansCondensed-Bold.ttf"
+
+	unicodeFontFamily = "DejaVu"
+	coreFontFamily    = "Helvetica"
+
+	pageMargin   = 15.0
+	contentWidth = 210 - 2*pageMargin
+	lineHeight   = 6.0
+
+	barcodeHeight      = 18.0
+	barcodeModuleWidth = 0.33
+	qrSize             = 40.0
+)
+
+// NewRenderer - fontDir should contain DejaVu Sans Condensed fonts for non-latin names and addresses,
+// core PDF font with latin-1 charset is used if fontDir is empty
+func NewRenderer(fontDir string) (app.ShippingLabelRenderer, error) {
+	if fontDir == "" {
+		return &renderer{}, nil
+	}
+	regularFont, err := ioutil.ReadFile(filepath.Join(fontDir, regularFontFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read label font")
+	}
+	boldFont, err := ioutil.ReadFile(filepath.Join(fontDir, boldFontFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read label font")
+	}
+	return &renderer{regularFont: regularFont, boldFont: boldFont}, nil
+}
+
+type renderer struct {
+	regularFont []byte
+	boldFont    []byte
+}
+
+// RenderPDF - first page is shipping label to attach to parcel, second page is packing slip to put inside
+func (r *renderer) RenderPDF(label app.ShippingLabel) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetTitle("Lot "+string(label.LotID), true)
+
+	d := r.newDocument(pdf)
+	d.shippingLabelPage(label)
+	d.packingSlipPage(label)
+
+	buf := bytes.Buffer{}
+	if err := pdf.Output(&buf); err != nil {
+		return nil, errors.Wrap(err, "failed to render shipping label")
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *renderer) newDocument(pdf *gofpdf.Fpdf) *document {
+	if r.regularFont == nil {
+		return &document{
+			pdf:        pdf,
+			fontFamily: coreFontFamily,
+			translate:  pdf.UnicodeTranslatorFromDescriptor(""),
+		}
+	}
+	pdf.AddUTF8FontFromBytes(unicodeFontFamily, "", r.regularFont)
+	pdf.AddUTF8FontFromBytes(unicodeFontFamily, "B", r.boldFont)
+	return &document{
+		pdf:        pdf,
+		fontFamily: unicodeFontFamily,
+		translate:  func(s string) string { return s },
+	}
+}
+
+type document struct {
+	pdf        *gofpdf.Fpdf
+	fontFamily string
+	// translate - converts utf-8 text to charset of font
+	translate func(string) string
+}
+
+func (d *document) shippingLabelPage(label app.ShippingLabel) {
+	d.pdf.AddPage()
+	d.title("Shipping label", label)
+
+	d.heading("From")
+	d.addressBlock(label.SenderName, label.SenderAddress, 11)
+	d.pdf.Ln(lineHeight)
+
+	d.heading("To")
+	d.addressBlock(label.ReceiverName, &label.ReceiverAddress, 14)
+	d.pdf.Ln(lineHeight)
+
+	if label.Shipping != nil {
+		d.field("Shipping", label.Shipping.OptionName)
+		d.pdf.Ln(lineHeight)
+	}
+
+	qrTop := d.pdf.GetY()
+	if label.TrackingID != nil {
+		d.heading("Tracking number")
+		d.barcode(string(*label.TrackingID))
+	} else {
+		d.field("Tracking number", "not assigned yet")
+	}
+	d.pdf.Ln(lineHeight)
+	d.heading("Lot")
+	d.barcode(string(label.LotID))
+
+	d.qrCode(qrContent(label), pageMargin+contentWidth-qrSize, qrTop)
+}
+
+func (d *document) packingSlipPage(label app.ShippingLabel) {
+	d.pdf.AddPage()
+	d.title("Packing slip", label)
+
+	d.field("Lot", string(label.LotID))
+	if label.TrackingID != nil {
+		d.field("Tracking number", string(*label.TrackingID))
+	}
+	if label.Shipping != nil {
+		d.field("Shipping", fmt.Sprintf("%s, %.2f", label.Shipping.OptionName, label.Shipping.Price.Value()))
+	}
+	d.pdf.Ln(lineHeight)
+
+	d.heading("Sender")
+	d.addressBlock(label.SenderName, label.SenderAddress, 11)
+	d.pdf.Ln(lineHeight)
+	d.heading("Receiver")
+	d.addressBlock(label.ReceiverName, &label.ReceiverAddress, 11)
+	d.pdf.Ln(lineHeight)
+
+	d.heading("Item")
+	d.pdf.SetFont(d.fontFamily, "", 11)
+	d.pdf.MultiCell(contentWidth, lineHeight, d.translate(label.Description), "1", "L", false)
+}
+
+func (d *document) title(title string, label app.ShippingLabel) {
+	d.pdf.SetFont(d.fontFamily, "B", 18)
+	d.pdf.CellFormat(contentWidth/2, 10, title, "", 0, "L", false, 0, "")
+	d.pdf.SetFont(d.fontFamily, "", 10)
+	d.pdf.CellFormat(contentWidth/2, 10, label.CreatedAt.Format("2006-01-02 15:04 MST"), "", 1, "R", false, 0, "")
+	d.pdf.Line(pageMargin, d.pdf.GetY(), pageMargin+contentWidth, d.pdf.GetY())
+	d.pdf.Ln(lineHeight)
+}
+
+func (d *document) heading(text string) {
+	d.pdf.SetFont(d.fontFamily, "B", 10)
+	d.pdf.CellFormat(contentWidth, lineHeight, strings.ToUpper(text), "", 1, "L", false, 0, "")
+}
+
+func (d *document) field(name, value string) {
+	d.pdf.SetFont(d.fontFamily, "B", 11)
+	d.pdf.CellFormat(40, lineHeight, name+":", "", 0, "L", false, 0, "")
+	d.pdf.SetFont(d.fontFamily, "", 11)
+	d.pdf.MultiCell(contentWidth-40, lineHeight, d.translate(value), "", "L", false)
+}
+
+// addressBlock - recipient name of address is preferred to user name
+func (d *document) addressBlock(userName string, address *app.Address, fontSize float64) {
+	lines := []string{userName}
+	if address != nil {
+		if address.RecipientName != "" {
+			lines[0] = address.RecipientName
+		}
+		lines = append(lines,
+			address.Street,
+			strings.TrimSpace(address.PostalCode+" "+address.City),
+			address.Country,
+		)
+		if address.Phone != "" {
+			lines = append(lines, "Tel. "+address.Phone)
+		}
+	}
+
+	d.pdf.SetFont(d.fontFamily, "", fontSize)
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		d.pdf.MultiCell(contentWidth, fontSize*0.5, d.translate(line), "", "L", false)
+	}
+}
+
+// barcode - Code 128 is drawn with vector bars, text is printed below
+func (d *document) barcode(content string) {
+	code, err := code128.Encode(content)
+	if err != nil {
+		d.pdf.SetError(errors.Wrapf(err, "failed to encode barcode of %s", content))
+		return
+	}
+	bounds := code.Bounds()
+	moduleWidth := barcodeModuleWidth
+	if maxWidth := contentWidth - qrSize - lineHeight; float64(bounds.Dx())*moduleWidth > maxWidth {
+		moduleWidth = maxWidth / float64(bounds.Dx())
+	}
+
+	x, y := pageMargin, d.pdf.GetY()
+	d.pdf.SetFillColor(0, 0, 0)
+	for i := bounds.Min.X; i < bounds.Max.X; i++ {
+		if isDark(code.At(i, bounds.Min.Y)) {
+			d.pdf.Rect(x+float64(i-bounds.Min.X)*moduleWidth, y, moduleWidth, barcodeHeight, "F")
+		}
+	}
+	d.pdf.SetY(y + barcodeHeight)
+	d.pdf.SetFont("Courier", "", 10)
+	d.pdf.CellFormat(float64(bounds.Dx())*moduleWidth, lineHeight, content, "", 1, "C", false, 0, "")
+}
+
+func (d *document) qrCode(content string, x, y float64) {
+	code, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		d.pdf.SetError(errors.Wrap(err, "failed to encode qr code"))
+		return
+	}
+	bounds := code.Bounds()
+	moduleSize := qrSize / float64(bounds.Dx())
+	d.pdf.SetFillColor(0, 0, 0)
+	for i := bounds.Min.X; i < bounds.Max.X; i++ {
+		for j := bounds.Min.Y; j < bounds.Max.Y; j++ {
+			if isDark(code.At(i, j)) {
+				d.pdf.Rect(x+float64(i-bounds.Min.X)*moduleSize, y+float64(j-bounds.Min.Y)*moduleSize, moduleSize, moduleSize, "F")
+			}
+		}
+	}
+}
+
+// qrContent - lot id and tracking number separated by new line, tracking number is omitted until lot is sent
+func qrContent(label app.ShippingLabel) string {
+	content := "lot:" + string(label.LotID)
+	if label.TrackingID != nil {
+		content += "\ntracking:" + string(*label.TrackingID)
+	}
+	return content
+}
+
+func isDark(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r+g+b < 3*0x8000
+}
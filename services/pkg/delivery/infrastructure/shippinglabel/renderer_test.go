@@ -0,0 +1,36 @@
+package shippinglabel
+
+import (
+	"arch-homework/pkg/delivery/app"
+
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRendererWithCoreFontRendersLabelAndPackingSlip(t *testing.T) {
+	renderer, err := NewRenderer("")
+	assert.NoError(t, err)
+	trackingID := app.TrackingID("RA123456789RU")
+
+	pdf, err := renderer.RenderPDF(app.ShippingLabel{
+		LotID:           "0b9c1b3e-6a4f-4d8a-9f0e-2a7c5d1e3f4b",
+		Description:     "Film camera, lens and case",
+		TrackingID:      &trackingID,
+		SenderName:      "seller",
+		ReceiverName:    "Иван Иванов",
+		ReceiverAddress: app.Address{RecipientName: "Иван Иванов", Country: "RU", City: "Казань", Street: "ул. Баумана, 1"},
+		Shipping:        &app.ShippingQuote{OptionName: "post", OptionType: app.ShippingOptionFlat, Price: app.AmountFromRawValue(900)},
+		CreatedAt:       time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err, "characters missing in core font don't fail rendering")
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+	assert.Contains(t, string(pdf), "/Count 2")
+}
+
+func TestNewRendererFailsWithoutFonts(t *testing.T) {
+	_, err := NewRenderer(t.TempDir())
+	assert.Error(t, err)
+}
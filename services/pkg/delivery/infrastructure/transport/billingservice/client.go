@@ -0,0 +1,59 @@
+package billingservice
+
+import (
+	"arch-homework/pkg/common/infrastructure/httpclient"
+	"arch-homework/pkg/delivery/app"
+
+	"github.com/pkg/errors"
+
+	"net/http"
+	"time"
+)
+
+const shippingPaymentURL = "/internal/api/v1/payment/shipping"
+const maxAttemptCount = 10
+
+func NewClient(client http.Client, serviceHost string) app.BillingClient {
+	return &billingClient{httpClient: httpclient.NewClient(client, serviceHost)}
+}
+
+type billingClient struct {
+	httpClient httpclient.Client
+}
+
+// BlockShippingPayment - request id is derived from shipping selection, so conflict means payment was already blocked
+func (c *billingClient) BlockShippingPayment(requestID app.RequestID, userID app.UserID, lotID app.LotID, amount app.Amount) (succeeded bool, err error) {
+	request := shippingPaymentRequest{
+		UserID: string(userID),
+		LotID:  string(lotID),
+		Amount: amount.Value(),
+	}
+	strRequestID := string(requestID)
+
+	for i := 0; i < maxAttemptCount; i++ {
+		err = c.httpClient.MakeJSONRequest(request, nil, http.MethodPost, shippingPaymentURL, &strRequestID)
+		if err == nil {
+			return true, nil
+		}
+		if e, ok := errors.Cause(err).(*httpclient.HTTPError); ok {
+			if e.StatusCode == http.StatusBadRequest {
+				return false, nil
+			}
+			if e.StatusCode == http.StatusConflict {
+				// request already processed
+				return true, nil
+			}
+		}
+
+		// wait before next attempt
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	return false, err
+}
+
+type shippingPaymentRequest struct {
+	UserID string  `json:"userID"`
+	LotID  string  `json:"lotID"`
+	Amount float64 `json:"amount"`
+}
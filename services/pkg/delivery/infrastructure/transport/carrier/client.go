@@ -0,0 +1,90 @@
+package carrier
+
+import (
+	"arch-homework/pkg/common/infrastructure/httpclient"
+	"arch-homework/pkg/delivery/app"
+
+	"github.com/pkg/errors"
+
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const trackingURLTpl = "/api/v1/tracking/%s"
+
+// trackingNumberPattern - common format of carrier tracking numbers, e.g. RA123456789RU or 1Z999AA10123456784
+var trackingNumberPattern = regexp.MustCompile("^[A-Z0-9]{8,35}$")
+
+var carrierStatuses = map[string]app.TrackingStatus{
+	"in_transit":       app.TrackingStatusInTransit,
+	"out_for_delivery": app.TrackingStatusOutForDelivery,
+	"delivered":        app.TrackingStatusDelivered,
+}
+
+// NewClient - client of carrier tracking API
+func NewClient(client http.Client, carrierHost string) app.CarrierClient {
+	return &carrierClient{httpClient: httpclient.NewClient(client, carrierHost)}
+}
+
+type carrierClient struct {
+	httpClient httpclient.Client
+}
+
+func (c *carrierClient) ValidateTrackingNumber(trackingID app.TrackingID) error {
+	return validateTrackingNumber(trackingID)
+}
+
+func (c *carrierClient) GetTrackingEvents(trackingID app.TrackingID) ([]app.TrackingEvent, error) {
+	requestURL := fmt.Sprintf(trackingURLTpl, url.PathEscape(string(trackingID)))
+	response := trackingResponse{}
+	err := c.httpClient.MakeJSONRequest(nil, &response, http.MethodGet, requestURL, nil)
+	if err != nil {
+		if httpErr, ok := errors.Cause(err).(*httpclient.HTTPError); ok && httpErr.StatusCode == http.StatusNotFound {
+			// parcel isn't registered by carrier yet
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	events := make([]app.TrackingEvent, 0, len(response.Events))
+	for _, event := range response.Events {
+		status, ok := carrierStatuses[event.Status]
+		if !ok {
+			continue
+		}
+		eventTime, err := time.Parse(time.RFC3339, event.Time)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		events = append(events, app.TrackingEvent{
+			Status:   status,
+			Location: event.Location,
+			Time:     eventTime.UTC(),
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events, nil
+}
+
+func validateTrackingNumber(trackingID app.TrackingID) error {
+	if !trackingNumberPattern.MatchString(string(trackingID)) {
+		return errors.Wrapf(app.ErrInvalidTrackingNumber, "tracking number %q", string(trackingID))
+	}
+	return nil
+}
+
+type trackingResponse struct {
+	Events []trackingEventInfo `json:"events"`
+}
+
+type trackingEventInfo struct {
+	Status   string `json:"status"`
+	Location string `json:"location"`
+	Time     string `json:"time"`
+}
@@ -0,0 +1,48 @@
+package carrier
+
+import (
+	"arch-homework/pkg/delivery/app"
+
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientParsesTrackingEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/tracking/RA123456789RU" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, `{"events": [
+			{"status": "delivered", "location": "Kazan", "time": "2021-05-03T10:00:00+03:00"},
+			{"status": "accepted", "location": "Moscow", "time": "2021-05-01T09:00:00Z"},
+			{"status": "in_transit", "location": "Moscow", "time": "2021-05-01T12:00:00Z"}
+		]}`)
+	}))
+	defer server.Close()
+	client := NewClient(http.Client{}, server.URL)
+
+	events, err := client.GetTrackingEvents("RA123456789RU")
+	assert.NoError(t, err)
+	assert.Equal(t, []app.TrackingEvent{
+		{Status: app.TrackingStatusInTransit, Location: "Moscow", Time: time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{Status: app.TrackingStatusDelivered, Location: "Kazan", Time: time.Date(2021, 5, 3, 7, 0, 0, 0, time.UTC)},
+	}, events)
+
+	events, err = client.GetTrackingEvents("RB987654321RU")
+	assert.NoError(t, err, "parcel unknown to carrier has no events")
+	assert.Empty(t, events)
+}
+
+func TestValidateTrackingNumber(t *testing.T) {
+	assert.NoError(t, validateTrackingNumber("RA123456789RU"))
+	assert.NoError(t, validateTrackingNumber("1Z999AA10123456784"))
+	assert.Error(t, validateTrackingNumber("1234567"))
+	assert.Error(t, validateTrackingNumber("ra123456789ru"))
+	assert.Error(t, validateTrackingNumber("RA123456789RU/../"))
+}
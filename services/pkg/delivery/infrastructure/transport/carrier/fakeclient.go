@@ -0,0 +1,44 @@
+package carrier
+
+import (
+	"arch-homework/pkg/delivery/app"
+
+	"sort"
+	"sync"
+)
+
+// NewFakeClient - in-memory carrier for tests and environments without carrier integration,
+// parcel events are added manually
+func NewFakeClient() *FakeClient {
+	return &FakeClient{events: map[app.TrackingID][]app.TrackingEvent{}}
+}
+
+type FakeClient struct {
+	mutex  sync.Mutex
+	events map[app.TrackingID][]app.TrackingEvent
+}
+
+func (c *FakeClient) AddEvent(trackingID app.TrackingID, event app.TrackingEvent) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	events := append(c.events[trackingID], event)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	c.events[trackingID] = events
+}
+
+func (c *FakeClient) ValidateTrackingNumber(trackingID app.TrackingID) error {
+	return validateTrackingNumber(trackingID)
+}
+
+func (c *FakeClient) GetTrackingEvents(trackingID app.TrackingID) ([]app.TrackingEvent, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	events := c.events[trackingID]
+	res := make([]app.TrackingEvent, len(events))
+	copy(res, events)
+	return res, nil
+}
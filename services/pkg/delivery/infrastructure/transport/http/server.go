@@ -12,19 +12,26 @@ import (
 
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 )
 
 const PathPrefix = "/api/v1/"
+const PathPrefixInternal = "/internal/api/v1/"
 
 const (
-	lotSentEndpoint          = PathPrefix + "lot/sent"
-	lotReceivedEndpoint      = PathPrefix + "lot/received"
-	specificDeliveryEndpoint = PathPrefix + "lot/{id}/delivery"
+	lotSentEndpoint            = PathPrefix + "lot/sent"
+	lotReceivedEndpoint        = PathPrefix + "lot/received"
+	specificDeliveryEndpoint   = PathPrefix + "lot/{id}/delivery"
+	deliveryAddressEndpoint    = PathPrefix + "lot/{id}/delivery/address"
+	deliveryShippingEndpoint   = PathPrefix + "lot/{id}/delivery/shipping"
+	deliveryLabelEndpoint      = PathPrefix + "lot/{id}/delivery/label"
+	internalUserExportEndpoint = PathPrefixInternal + "user/{id}/export"
 )
 
 const (
@@ -33,6 +40,12 @@ const (
 	errorCodeAlreadyProcessed = 2
 	errorCodeUserNotFound     = 3
 	errorCodeInvalidLotStatus = 4
+	errorCodeAddressNotFound  = 5
+	errorCodeInvalidTracking  = 6
+	errorCodeShippingNotFound = 7
+	errorCodeShippingInvalid  = 8
+	errorCodeShippingPayment  = 9
+	errorCodeShippingPending  = 10
 )
 
 const authTokenHeader = "X-Auth-Token"
@@ -50,11 +63,29 @@ type endpointLabelCollector struct {
 
 func (e endpointLabelCollector) EndpointLabelForURI(uri string) string {
 	if strings.HasPrefix(uri, PathPrefix) {
-		r, _ := regexp.Compile("^" + PathPrefix + "lot/[a-f0-9-]+/delivery")
+		r, _ := regexp.Compile("^" + PathPrefix + "lot/[a-f0-9-]+/delivery/address$")
+		if r.MatchString(uri) {
+			return deliveryAddressEndpoint
+		}
+		r, _ = regexp.Compile("^" + PathPrefix + "lot/[a-f0-9-]+/delivery/shipping$")
+		if r.MatchString(uri) {
+			return deliveryShippingEndpoint
+		}
+		r, _ = regexp.Compile("^" + PathPrefix + "lot/[a-f0-9-]+/delivery/label$")
+		if r.MatchString(uri) {
+			return deliveryLabelEndpoint
+		}
+		r, _ = regexp.Compile("^" + PathPrefix + "lot/[a-f0-9-]+/delivery")
 		if r.MatchString(uri) {
 			return specificDeliveryEndpoint
 		}
 	}
+	if strings.HasPrefix(uri, PathPrefixInternal) {
+		r, _ := regexp.Compile("^" + PathPrefixInternal + "user/[a-f0-9-]+/export$")
+		if r.MatchString(uri) {
+			return internalUserExportEndpoint
+		}
+	}
 	return uri
 }
 
@@ -82,12 +113,17 @@ func (s *Server) MakeHandler() http.Handler {
 	router.Methods(http.MethodPost).Path(lotSentEndpoint).Handler(s.makeHandlerFunc(s.lotSentHandler))
 	router.Methods(http.MethodPost).Path(lotReceivedEndpoint).Handler(s.makeHandlerFunc(s.lotReceivedHandler))
 	router.Methods(http.MethodGet).Path(specificDeliveryEndpoint).Handler(s.makeHandlerFunc(s.getLotDeliveryHandler))
+	router.Methods(http.MethodPut).Path(deliveryAddressEndpoint).Handler(s.makeHandlerFunc(s.selectDeliveryAddressHandler))
+	router.Methods(http.MethodGet).Path(deliveryShippingEndpoint).Handler(s.makeHandlerFunc(s.getShippingQuotesHandler))
+	router.Methods(http.MethodPut).Path(deliveryShippingEndpoint).Handler(s.makeHandlerFunc(s.selectShippingOptionHandler))
+	router.Methods(http.MethodGet).Path(deliveryLabelEndpoint).Handler(s.makeHandlerFunc(s.getShippingLabelHandler))
 
 	return router
 }
 
 func (s *Server) MakeInternalHandler() http.Handler {
 	router := mux.NewRouter()
+	router.Methods(http.MethodGet).Path(internalUserExportEndpoint).Handler(s.makeHandlerFunc(s.exportUserDataHandler))
 	return router
 }
 
@@ -207,7 +243,140 @@ func (s *Server) getLotDeliveryHandler(w http.ResponseWriter, r *http.Request) e
 	if err != nil {
 		return err
 	}
-	writeResponse(w, toDeliveryInfo(*profile))
+	history, err := s.deliveryService.LotTrackingHistory(lotID)
+	if err != nil {
+		return err
+	}
+	info := toDeliveryInfo(*profile)
+	info.TrackingHistory = toTrackingEventInfos(history)
+	writeResponse(w, info)
+	return nil
+}
+
+func (s *Server) selectDeliveryAddressHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+
+	lotID, err := getLotIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	var addressData deliveryAddressData
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &addressData); err != nil {
+		return errors.WithStack(err)
+	}
+	if err = uuid.ValidateUUID(addressData.AddressID); err != nil {
+		return errors.Wrap(app.ErrAddressNotFound, err.Error())
+	}
+
+	err = s.deliveryService.SelectDeliveryAddress(app.UserID(tokenData.UserID()), lotID, app.AddressID(addressData.AddressID))
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, http.StatusText(http.StatusOK))
+	return nil
+}
+
+func (s *Server) getShippingQuotesHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+
+	lotID, err := getLotIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	quotes, err := s.deliveryService.ShippingQuotes(app.UserID(tokenData.UserID()), lotID)
+	if err != nil {
+		return err
+	}
+	infos := make([]shippingInfo, 0, len(quotes))
+	for _, quote := range quotes {
+		infos = append(infos, toShippingInfo(quote))
+	}
+	writeResponse(w, infos)
+	return nil
+}
+
+func (s *Server) getShippingLabelHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+
+	lotID, err := getLotIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	label, err := s.deliveryService.ShippingLabelPDF(app.UserID(tokenData.UserID()), lotID)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="lot-%s-label.pdf"`, lotID))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(label)
+	return nil
+}
+
+func (s *Server) selectShippingOptionHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+
+	lotID, err := getLotIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	var optionData shippingOptionData
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &optionData); err != nil {
+		return errors.WithStack(err)
+	}
+
+	quote, err := s.deliveryService.SelectShippingOption(app.UserID(tokenData.UserID()), lotID, optionData.Option)
+	if err != nil {
+		return err
+	}
+	writeResponse(w, toShippingInfo(quote))
+	return nil
+}
+
+func (s *Server) exportUserDataHandler(w http.ResponseWriter, r *http.Request) error {
+	userID := mux.Vars(r)["id"]
+	if err := uuid.ValidateUUID(userID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	deliveries, err := s.deliveryService.UserDeliveries(app.UserID(userID))
+	if err != nil {
+		return err
+	}
+	infos := make([]deliveryInfo, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		infos = append(infos, toDeliveryInfo(delivery))
+	}
+	writeResponse(w, infos)
 	return nil
 }
 
@@ -223,6 +392,9 @@ func (s *Server) extractAuthorizationData(r *http.Request) (jwtauth.TokenData, e
 	if err = uuid.ValidateUUID(tokenData.UserID()); err != nil {
 		return nil, errors.WithStack(err)
 	}
+	if err = jwtauth.CheckScope(tokenData, r, jwtauth.NoScope, jwtauth.NoScope); err != nil {
+		return nil, errors.Wrap(errForbidden, err.Error())
+	}
 	return tokenData, nil
 }
 
@@ -267,12 +439,30 @@ func writeErrorResponse(w http.ResponseWriter, err error) {
 	case app.ErrInvalidLotStatus:
 		info.Code = errorCodeInvalidLotStatus
 		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrInvalidTrackingNumber:
+		info.Code = errorCodeInvalidTracking
+		w.WriteHeader(http.StatusBadRequest)
 	case app.ErrAlreadyProcessed:
 		info.Code = errorCodeAlreadyProcessed
 		w.WriteHeader(http.StatusConflict)
 	case app.ErrLotNotFound:
 		info.Code = errorCodeUserNotFound
 		w.WriteHeader(http.StatusNotFound)
+	case app.ErrAddressNotFound:
+		info.Code = errorCodeAddressNotFound
+		w.WriteHeader(http.StatusNotFound)
+	case app.ErrShippingOptionNotFound:
+		info.Code = errorCodeShippingNotFound
+		w.WriteHeader(http.StatusNotFound)
+	case app.ErrShippingUnavailable, app.ErrShippingNotSelected, app.ErrShippingQuoteChanged:
+		info.Code = errorCodeShippingInvalid
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrShippingPaymentFailed:
+		info.Code = errorCodeShippingPayment
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrShippingPaymentPending:
+		info.Code = errorCodeShippingPending
+		w.WriteHeader(http.StatusConflict)
 	case errForbidden, app.ErrStatusChangeForbidden:
 		w.WriteHeader(http.StatusForbidden)
 	default:
@@ -287,7 +477,7 @@ func toDeliveryInfo(info app.DeliveryInfo) deliveryInfo {
 	if info.TrackingID != nil {
 		trackingID = string(*info.TrackingID)
 	}
-	return deliveryInfo{
+	res := deliveryInfo{
 		LotID:      string(info.LotID),
 		Status:     string(info.LotStatus),
 		TrackingID: trackingID,
@@ -300,9 +490,48 @@ func toDeliveryInfo(info app.DeliveryInfo) deliveryInfo {
 			Login:     info.ReceiverLogin,
 			FirstName: info.ReceiverFirstName,
 			LastName:  info.ReceiverLastName,
-			Address:   string(info.ReceiverAddress),
+			Address:   info.ReceiverAddress.String(),
+			ShippingAddress: shippingAddressInfo{
+				RecipientName: info.ReceiverAddress.RecipientName,
+				Phone:         info.ReceiverAddress.Phone,
+				Country:       info.ReceiverAddress.Country,
+				PostalCode:    info.ReceiverAddress.PostalCode,
+				City:          info.ReceiverAddress.City,
+				Street:        info.ReceiverAddress.Street,
+			},
 		},
 	}
+	if info.Shipping != nil {
+		shipping := toShippingInfo(*info.Shipping)
+		res.Shipping = &shipping
+	}
+	if info.ShipBy != nil {
+		res.ShipBy = info.ShipBy.Format(time.RFC3339)
+	}
+	if info.ConfirmBy != nil {
+		res.ConfirmBy = info.ConfirmBy.Format(time.RFC3339)
+	}
+	return res
+}
+
+func toShippingInfo(quote app.ShippingQuote) shippingInfo {
+	return shippingInfo{
+		Option: quote.OptionName,
+		Type:   string(quote.OptionType),
+		Price:  quote.Price.Value(),
+	}
+}
+
+func toTrackingEventInfos(events []app.TrackingEvent) []trackingEventInfo {
+	res := make([]trackingEventInfo, 0, len(events))
+	for _, event := range events {
+		res = append(res, trackingEventInfo{
+			Status:   string(event.Status),
+			Location: event.Location,
+			Time:     event.Time.Format(time.RFC3339),
+		})
+	}
+	return res
 }
 
 type errorInfo struct {
@@ -319,6 +548,20 @@ type lotReceivedData struct {
 	LotID string `json:"id"`
 }
 
+type deliveryAddressData struct {
+	AddressID string `json:"addressId"`
+}
+
+type shippingOptionData struct {
+	Option string `json:"option"`
+}
+
+type shippingInfo struct {
+	Option string  `json:"option"`
+	Type   string  `json:"type"`
+	Price  float64 `json:"price"`
+}
+
 type senderInfo struct {
 	Login     string `json:"login"`
 	FirstName string `json:"firstName"`
@@ -326,16 +569,36 @@ type senderInfo struct {
 }
 
 type receiverInfo struct {
-	Login     string `json:"login"`
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
-	Address   string `json:"address"`
+	Login           string              `json:"login"`
+	FirstName       string              `json:"firstName"`
+	LastName        string              `json:"lastName"`
+	Address         string              `json:"address"`
+	ShippingAddress shippingAddressInfo `json:"shippingAddress"`
+}
+
+type shippingAddressInfo struct {
+	RecipientName string `json:"recipientName"`
+	Phone         string `json:"phone"`
+	Country       string `json:"country"`
+	PostalCode    string `json:"postalCode"`
+	City          string `json:"city"`
+	Street        string `json:"street"`
+}
+
+type trackingEventInfo struct {
+	Status   string `json:"status"`
+	Location string `json:"location"`
+	Time     string `json:"time"`
 }
 
 type deliveryInfo struct {
-	LotID      string       `json:"id"`
-	Status     string       `json:"status"`
-	Sender     senderInfo   `json:"sender"`
-	Receiver   receiverInfo `json:"receiver"`
-	TrackingID string       `json:"trackingId,omitempty"`
+	LotID           string              `json:"id"`
+	Status          string              `json:"status"`
+	Sender          senderInfo          `json:"sender"`
+	Receiver        receiverInfo        `json:"receiver"`
+	TrackingID      string              `json:"trackingId,omitempty"`
+	Shipping        *shippingInfo       `json:"shipping,omitempty"`
+	ShipBy          string              `json:"shipBy,omitempty"`
+	ConfirmBy       string              `json:"confirmBy,omitempty"`
+	TrackingHistory []trackingEventInfo `json:"trackingHistory,omitempty"`
 }
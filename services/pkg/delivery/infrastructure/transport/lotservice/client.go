@@ -8,10 +8,12 @@ import (
 	"github.com/pkg/errors"
 
 	"fmt"
+	"math"
 	"net/http"
 )
 
 const lotInfoURLTpl = "/internal/api/v1/lot/%s"
+const finishedLotsURL = "/internal/api/v1/lots/finished"
 
 func NewClient(client http.Client, serviceHost string) app.LotServiceClient {
 	return &lotServiceClient{httpClient: httpclient.NewClient(client, serviceHost)}
@@ -46,14 +48,93 @@ func (c *lotServiceClient) FindFinishedLotInfo(id app.LotID) (*app.LotInfo, erro
 		ID:         app.LotID(response.ID),
 		OwnerID:    app.UserID(response.OwnerID),
 		ReceiverID: app.UserID(response.LastBidderID),
+		Weight:     response.Weight,
+	}
+	for _, option := range response.ShippingOptions {
+		shippingOption := app.ShippingOption{
+			Name:  option.Name,
+			Type:  app.ShippingOptionType(option.Type),
+			Price: amountFromFloat(option.Price),
+		}
+		for _, rate := range option.Rates {
+			shippingOption.Rates = append(shippingOption.Rates, app.ShippingRate{
+				Zone:      rate.Zone,
+				MaxWeight: rate.MaxWeight,
+				Price:     amountFromFloat(rate.Price),
+			})
+		}
+		info.ShippingOptions = append(info.ShippingOptions, shippingOption)
 	}
 
 	return &info, nil
 }
 
+func (c *lotServiceClient) FindAllFinishedLots() ([]app.FinishedLot, error) {
+	var response []finishedLotResponse
+	err := c.httpClient.MakeJSONRequest(nil, &response, http.MethodGet, finishedLotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]app.FinishedLot, 0, len(response))
+	for _, lot := range response {
+		if err = uuid.ValidateUUID(lot.ID); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err = uuid.ValidateUUID(lot.OwnerID); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err = uuid.ValidateUUID(lot.WinnerID); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		res = append(res, app.FinishedLot{
+			ID:         app.LotID(lot.ID),
+			OwnerID:    app.UserID(lot.OwnerID),
+			ReceiverID: app.UserID(lot.WinnerID),
+		})
+	}
+	return res, nil
+}
+
+func (c *lotServiceClient) FindLotDescription(id app.LotID) (string, error) {
+	requestURL := fmt.Sprintf(lotInfoURLTpl, string(id))
+	response := lotInfoResponse{}
+	err := c.httpClient.MakeJSONRequest(nil, &response, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return "", err
+	}
+	return response.Description, nil
+}
+
 type lotInfoResponse struct {
 	ID           string `json:"id"`
 	Status       string `json:"status"`
 	OwnerID      string `json:"ownerId"`
 	LastBidderID string `json:"lastBidderID"`
+	Description  string `json:"description"`
+
+	Weight          int                      `json:"weight"`
+	ShippingOptions []shippingOptionResponse `json:"shippingOptions"`
+}
+
+type finishedLotResponse struct {
+	ID       string `json:"id"`
+	OwnerID  string `json:"ownerId"`
+	WinnerID string `json:"winnerId"`
+}
+
+type shippingOptionResponse struct {
+	Name  string                 `json:"name"`
+	Type  string                 `json:"type"`
+	Price float64                `json:"price"`
+	Rates []shippingRateResponse `json:"rates"`
+}
+
+type shippingRateResponse struct {
+	Zone      string  `json:"zone"`
+	MaxWeight int     `json:"maxWeight"`
+	Price     float64 `json:"price"`
+}
+
+func amountFromFloat(value float64) app.Amount {
+	return app.AmountFromRawValue(uint64(math.Round(value * 100)))
 }
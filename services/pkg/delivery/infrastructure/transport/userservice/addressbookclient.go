@@ -0,0 +1,61 @@
+package userservice
+
+import (
+	"arch-homework/pkg/common/infrastructure/httpclient"
+	"arch-homework/pkg/delivery/app"
+
+	"github.com/pkg/errors"
+
+	"fmt"
+	"net/http"
+)
+
+const userAddressURLTpl = "/internal/api/v1/user/%s/addresses/%s"
+const userDefaultAddressURLTpl = "/internal/api/v1/user/%s/addresses/default"
+
+func NewAddressBookClient(client http.Client, serviceHost string) app.AddressBookClient {
+	return &addressBookClient{
+		httpClient: httpclient.NewClient(client, serviceHost),
+	}
+}
+
+type addressBookClient struct {
+	httpClient httpclient.Client
+}
+
+func (c *addressBookClient) GetAddress(userID app.UserID, addressID app.AddressID) (app.Address, error) {
+	return c.getAddress(fmt.Sprintf(userAddressURLTpl, string(userID), string(addressID)))
+}
+
+func (c *addressBookClient) GetDefaultAddress(userID app.UserID) (app.Address, error) {
+	return c.getAddress(fmt.Sprintf(userDefaultAddressURLTpl, string(userID)))
+}
+
+func (c *addressBookClient) getAddress(requestURL string) (app.Address, error) {
+	var response addressResponse
+	err := c.httpClient.MakeJSONRequest(nil, &response, http.MethodGet, requestURL, nil)
+	if err != nil {
+		if e, ok := errors.Cause(err).(*httpclient.HTTPError); ok && e.StatusCode == http.StatusNotFound {
+			return app.Address{}, errors.Wrap(app.ErrAddressNotFound, e.Body)
+		}
+		return app.Address{}, err
+	}
+
+	return app.Address{
+		RecipientName: response.RecipientName,
+		Phone:         response.Phone,
+		Country:       response.Country,
+		PostalCode:    response.PostalCode,
+		City:          response.City,
+		Street:        response.Street,
+	}, nil
+}
+
+type addressResponse struct {
+	RecipientName string `json:"recipientName"`
+	Phone         string `json:"phone"`
+	Country       string `json:"country"`
+	PostalCode    string `json:"postalCode"`
+	City          string `json:"city"`
+	Street        string `json:"street"`
+}
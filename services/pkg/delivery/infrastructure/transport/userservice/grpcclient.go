@@ -0,0 +1,36 @@
+package userservice
+
+import (
+	"arch-homework/api/userpb"
+	"arch-homework/pkg/common/infrastructure/grpcclient"
+	"arch-homework/pkg/delivery/app"
+
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+func NewGRPCClient(conn *grpc.ClientConn) app.UserServiceClient {
+	return &userGRPCClient{client: userpb.NewUserServiceClient(conn)}
+}
+
+type userGRPCClient struct {
+	client userpb.UserServiceClient
+}
+
+func (c *userGRPCClient) GetUserInfo(userID app.UserID) (app.UserInfo, error) {
+	profile, err := c.client.GetUserProfile(context.Background(), &userpb.GetUserProfileRequest{UserId: string(userID)}, grpcclient.Idempotent())
+	if err != nil {
+		return app.UserInfo{}, errors.WithStack(err)
+	}
+
+	info := app.UserInfo{
+		Login:     profile.Login,
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		Address:   profile.Address,
+	}
+
+	return info, nil
+}
@@ -1,5 +1,13 @@
 package app
 
+type FeePreview struct {
+	ListingFee            Amount
+	FinalValueFee         Amount
+	BuyItNowFinalValueFee *Amount
+}
+
 type BillingClient interface {
 	ProcessOrderPayment(userID UserID, lotID LotID, price Amount) (succeeded bool, err error)
+	PayListingFee(userID UserID, lotID LotID) (succeeded bool, err error)
+	PreviewFees(startPrice Amount, buyItNowPrice *Amount) (FeePreview, error)
 }
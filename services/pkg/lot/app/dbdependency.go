@@ -5,14 +5,20 @@ import "arch-homework/pkg/common/app/storedevent"
 type RepositoryProvider interface {
 	LotRepository() LotRepository
 	BidRepository() BidRepository
+	ShippingOptionRepository() ShippingOptionRepository
 	ProcessedRequestRepository() ProcessedRequestRepository
 	ProcessedEventRepository() ProcessedEventRepository
+	UnverifiedUserRepository() UnverifiedUserRepository
+	UserLoginRepository() UserLoginRepository
+	DeletedUserRepository() DeletedUserRepository
 	EventStore() storedevent.EventStore
 }
 
 type ReadRepositoryProvider interface {
 	LotRepositoryRead() LotRepositoryRead
 	ProcessedRequestRepositoryRead() ProcessedRequestRepositoryRead
+	UnverifiedUserRepositoryRead() UnverifiedUserRepositoryRead
+	DeletedUserRepositoryRead() DeletedUserRepositoryRead
 }
 
 type TransactionalUnit interface {
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"github.com/pkg/errors"
+
+	"fmt"
+)
+
+const userLockNameTpl = "lock_user_%s"
+
+var ErrUserDeleted = errors.New("user is deleted")
+
+// DeletedUserRepositoryRead - users whose account deletion was started by user service,
+// new lots and bids of these users are rejected
+type DeletedUserRepositoryRead interface {
+	IsDeleted(userID UserID) (bool, error)
+}
+
+type DeletedUserRepository interface {
+	DeletedUserRepositoryRead
+	Add(userID UserID) error
+	Remove(userID UserID) error
+}
+
+type UserDeletionService interface {
+	// StartUserDeletion - marks user as deleted and returns user activity counted after mark,
+	// mark is removed if user has activity, so user can complete lots and deletion is refused
+	StartUserDeletion(userID UserID) (*UserActivityQueryData, error)
+}
+
+func NewUserDeletionService(trUnitFactory TransactionalUnitFactory, lotQueryService LotQueryService) UserDeletionService {
+	return &userDeletionService{
+		trUnitFactory:   trUnitFactory,
+		lotQueryService: lotQueryService,
+	}
+}
+
+type userDeletionService struct {
+	trUnitFactory   TransactionalUnitFactory
+	lotQueryService LotQueryService
+}
+
+func (s *userDeletionService) StartUserDeletion(userID UserID) (*UserActivityQueryData, error) {
+	err := s.executeInTransactionWithLock(userLockName(userID), func(provider RepositoryProvider) error {
+		return provider.DeletedUserRepository().Add(userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// lots and bids are stored under the same user lock, so all of them stored before mark are already committed
+	activity, err := s.lotQueryService.GetUserActivity(userID)
+	if err == nil && activity.ActiveLotCount == 0 && activity.ActiveBidCount == 0 {
+		return activity, nil
+	}
+	err2 := s.executeInTransactionWithLock(userLockName(userID), func(provider RepositoryProvider) error {
+		return provider.DeletedUserRepository().Remove(userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return activity, err2
+}
+
+func (s *userDeletionService) executeInTransactionWithLock(lockName string, f func(RepositoryProvider) error) (err error) {
+	var trUnit TransactionalUnit
+	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	err = trUnit.AddLock(lockName)
+	if err != nil {
+		return err
+	}
+	err = f(trUnit)
+	return err
+}
+
+// checkUserNotDeleted - check without user lock only rejects request early, it should be repeated under user lock
+func checkUserNotDeleted(repo DeletedUserRepositoryRead, userID UserID) error {
+	deleted, err := repo.IsDeleted(userID)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		return errors.WithStack(ErrUserDeleted)
+	}
+	return nil
+}
+
+func userLockName(userID UserID) string {
+	return fmt.Sprintf(userLockNameTpl, string(userID))
+}
@@ -0,0 +1,59 @@
+package app_test
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/lot/app"
+
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserDeletionStarted(t *testing.T) {
+	db := newTestDB()
+	userID := app.UserID(uuid.GenerateNew())
+	service := app.NewUserDeletionService(db, &testLotQueryService{activity: map[app.UserID]app.UserActivityQueryData{}})
+
+	activity, err := service.StartUserDeletion(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, app.UserActivityQueryData{}, *activity)
+	assert.True(t, db.deleted[userID])
+	assert.Equal(t, []string{"lock_user_" + string(userID)}, db.locks)
+
+	// user service repeats request after partial failure
+	activity, err = service.StartUserDeletion(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, app.UserActivityQueryData{}, *activity)
+	assert.True(t, db.deleted[userID])
+}
+
+func TestUserDeletionWithActivityRefused(t *testing.T) {
+	db := newTestDB()
+	sellerID := app.UserID(uuid.GenerateNew())
+	bidderID := app.UserID(uuid.GenerateNew())
+	service := app.NewUserDeletionService(db, &testLotQueryService{activity: map[app.UserID]app.UserActivityQueryData{
+		sellerID: {ActiveLotCount: 1},
+		bidderID: {ActiveBidCount: 2},
+	}})
+
+	activity, err := service.StartUserDeletion(sellerID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, activity.ActiveLotCount)
+	assert.False(t, db.deleted[sellerID])
+
+	activity, err = service.StartUserDeletion(bidderID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, activity.ActiveBidCount)
+	assert.False(t, db.deleted[bidderID])
+}
+
+// testLotQueryService - only user activity query is implemented
+type testLotQueryService struct {
+	app.LotQueryService
+	activity map[app.UserID]app.UserActivityQueryData
+}
+
+func (s *testLotQueryService) GetUserActivity(userID app.UserID) (*app.UserActivityQueryData, error) {
+	activity := s.activity[userID]
+	return &activity, nil
+}
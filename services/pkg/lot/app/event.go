@@ -4,6 +4,7 @@ import (
 	"arch-homework/pkg/common/app/integrationevent"
 	"arch-homework/pkg/common/app/uuid"
 	"encoding/json"
+	"time"
 )
 
 const typeLotWon = "lot.lot_won"
@@ -12,12 +13,14 @@ const typeLotSent = "lot.lot_sent"
 const typeLotReceived = "lot.lot_received"
 const typeBidOutbid = "lot.bid_outbid"
 const typeBidCancelled = "lot.bid_cancelled"
+const typeLotCreationCancelled = "lot.lot_creation_cancelled"
 
-func NewLotWonEvent(lotID LotID, userID, lotOwnerID UserID) integrationevent.EventData {
+func NewLotWonEvent(lotID LotID, userID, lotOwnerID UserID, finishedAt time.Time) integrationevent.EventData {
 	body, _ := json.Marshal(lotWonEventBody{
 		LotID:      string(lotID),
 		UserID:     string(userID),
 		LotOwnerID: string(lotOwnerID),
+		FinishedAt: finishedAt.UTC().Format(time.RFC3339),
 	})
 
 	return integrationevent.EventData{
@@ -27,10 +30,12 @@ func NewLotWonEvent(lotID LotID, userID, lotOwnerID UserID) integrationevent.Eve
 	}
 }
 
-func NewLotClosedEvent(lotID LotID, lotOwnerID UserID) integrationevent.EventData {
+// NewLotClosedEvent - reason is set, when lot is closed by moderator
+func NewLotClosedEvent(lotID LotID, lotOwnerID UserID, reason string) integrationevent.EventData {
 	body, _ := json.Marshal(lotClosedEventBody{
 		LotID:      string(lotID),
 		LotOwnerID: string(lotOwnerID),
+		Reason:     reason,
 	})
 
 	return integrationevent.EventData{
@@ -97,6 +102,19 @@ func NewBidCancelledEvent(lotID LotID, userID UserID, bidAmount Amount) integrat
 	}
 }
 
+func NewLotCreationCancelledEvent(lotID LotID, lotOwnerID UserID) integrationevent.EventData {
+	body, _ := json.Marshal(lotCreationCancelledEventBody{
+		LotID:      string(lotID),
+		LotOwnerID: string(lotOwnerID),
+	})
+
+	return integrationevent.EventData{
+		UID:  newUID(),
+		Type: typeLotCreationCancelled,
+		Body: string(body),
+	}
+}
+
 func newUID() integrationevent.EventUID {
 	return integrationevent.EventUID(uuid.GenerateNew())
 }
@@ -105,11 +123,13 @@ type lotWonEventBody struct {
 	LotID      string `json:"lot_id"`
 	UserID     string `json:"user_id"`
 	LotOwnerID string `json:"lot_owner_id"`
+	FinishedAt string `json:"finished_at"`
 }
 
 type lotClosedEventBody struct {
 	LotID      string `json:"lot_id"`
 	LotOwnerID string `json:"lot_owner_id"`
+	Reason     string `json:"reason,omitempty"`
 }
 
 type lotSentEventBody struct {
@@ -130,3 +150,8 @@ type bidEventBody struct {
 	LotID     string `json:"lot_id"`
 	BidAmount uint64 `json:"bid_amount"`
 }
+
+type lotCreationCancelledEventBody struct {
+	LotID      string `json:"lot_id"`
+	LotOwnerID string `json:"lot_owner_id"`
+}
@@ -61,6 +61,14 @@ func (handler *eventHandler) Handle(event integrationevent.EventData) error {
 			return service.SetLotSent(e.lotID)
 		case deliveryLotReceivedEvent:
 			return service.SetLotReceived(e.lotID)
+		case deliveryLotCancelledEvent:
+			return service.CancelUnsentLot(e.lotID, e.reason)
+		case userEmailVerificationRequestedEvent:
+			return trUnit.UnverifiedUserRepository().Add(e.userID)
+		case userEmailVerifiedEvent:
+			return trUnit.UnverifiedUserRepository().Remove(e.userID)
+		case userProfileUpdatedEvent:
+			return trUnit.UserLoginRepository().Store(e.userID, e.login)
 		default:
 			handled = false
 			return nil
@@ -0,0 +1,215 @@
+package app_test
+
+import (
+	"arch-homework/pkg/common/app/integrationevent"
+	"arch-homework/pkg/common/app/storedevent"
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/lot/app"
+	infraintegrationevent "arch-homework/pkg/lot/infrastructure/integrationevent"
+
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserEventsUpdateLoginReadModel(t *testing.T) {
+	db := newTestDB()
+	userID := app.UserID(uuid.GenerateNew())
+
+	// profile is published on registration
+	handleTestUserEvent(t, db, "user.profile_updated", map[string]string{"user_id": string(userID), "login": "user", "first_name": "Ivan"})
+	assert.Equal(t, "user", db.logins[userID])
+
+	handleTestUserEvent(t, db, "user.profile_updated", map[string]string{"user_id": string(userID), "login": "user", "last_name": "Ivanov"})
+	assert.Equal(t, "user", db.logins[userID])
+
+	// anonymized profile is published before user.user_deleted
+	handleTestUserEvent(t, db, "user.profile_updated", map[string]string{"user_id": string(userID), "login": "deleted-" + string(userID)})
+	handleTestUserEvent(t, db, "user.user_deleted", map[string]string{"user_id": string(userID)})
+	assert.Equal(t, "deleted-"+string(userID), db.logins[userID])
+	assert.Len(t, db.logins, 1)
+}
+
+func TestDuplicateUserProfileEventIgnored(t *testing.T) {
+	db := newTestDB()
+	userID := app.UserID(uuid.GenerateNew())
+	handler := app.NewEventHandler(db, infraintegrationevent.NewEventParser(), testEventSender{}, nil)
+	body, _ := json.Marshal(map[string]string{"user_id": string(userID), "login": "old"})
+	event := integrationevent.EventData{
+		UID:  integrationevent.EventUID(uuid.GenerateNew()),
+		Type: "user.profile_updated",
+		Body: string(body),
+	}
+	assert.NoError(t, handler.Handle(event))
+
+	handleTestUserEvent(t, db, "user.profile_updated", map[string]string{"user_id": string(userID), "login": "new"})
+	assert.NoError(t, handler.Handle(event))
+	assert.Equal(t, "new", db.logins[userID])
+}
+
+func TestUserProfileEventWithoutLoginRejected(t *testing.T) {
+	db := newTestDB()
+	body, _ := json.Marshal(map[string]string{"user_id": string(uuid.GenerateNew())})
+	handler := app.NewEventHandler(db, infraintegrationevent.NewEventParser(), testEventSender{}, nil)
+	err := handler.Handle(integrationevent.EventData{
+		UID:  integrationevent.EventUID(uuid.GenerateNew()),
+		Type: "user.profile_updated",
+		Body: string(body),
+	})
+	assert.Error(t, err)
+	assert.Empty(t, db.logins)
+}
+
+func handleTestUserEvent(t *testing.T, db *testDB, eventType string, body map[string]string) {
+	strBody, _ := json.Marshal(body)
+	handler := app.NewEventHandler(db, infraintegrationevent.NewEventParser(), testEventSender{}, nil)
+	err := handler.Handle(integrationevent.EventData{
+		UID:  integrationevent.EventUID(uuid.GenerateNew()),
+		Type: eventType,
+		Body: string(strBody),
+	})
+	assert.NoError(t, err)
+}
+
+// testDB - changes are applied immediately, only repositories used by user events, user deletion and lot creation are implemented
+type testDB struct {
+	logins     map[app.UserID]string
+	unverified map[app.UserID]bool
+	deleted    map[app.UserID]bool
+	processed  map[integrationevent.EventUID]bool
+	requests   map[app.RequestID]bool
+	lots       map[app.LotID]app.Lot
+	locks      []string
+}
+
+func newTestDB() *testDB {
+	return &testDB{
+		logins:     map[app.UserID]string{},
+		unverified: map[app.UserID]bool{},
+		deleted:    map[app.UserID]bool{},
+		processed:  map[integrationevent.EventUID]bool{},
+		requests:   map[app.RequestID]bool{},
+		lots:       map[app.LotID]app.Lot{},
+	}
+}
+
+func (db *testDB) NewTransactionalUnit() (app.TransactionalUnit, error) {
+	return db, nil
+}
+
+func (db *testDB) LotRepositoryRead() app.LotRepositoryRead {
+	return nil
+}
+
+func (db *testDB) LotRepository() app.LotRepository {
+	return testLotRepo{db: db}
+}
+
+func (db *testDB) BidRepository() app.BidRepository {
+	return nil
+}
+
+func (db *testDB) ShippingOptionRepository() app.ShippingOptionRepository {
+	return testShippingOptionRepo{}
+}
+
+func (db *testDB) ProcessedRequestRepositoryRead() app.ProcessedRequestRepositoryRead {
+	return testRequestRepo{db: db}
+}
+
+func (db *testDB) ProcessedRequestRepository() app.ProcessedRequestRepository {
+	return testRequestRepo{db: db}
+}
+
+func (db *testDB) ProcessedEventRepository() app.ProcessedEventRepository {
+	return db
+}
+
+func (db *testDB) UnverifiedUserRepositoryRead() app.UnverifiedUserRepositoryRead {
+	return db
+}
+
+func (db *testDB) UnverifiedUserRepository() app.UnverifiedUserRepository {
+	return db
+}
+
+func (db *testDB) UserLoginRepository() app.UserLoginRepository {
+	return testUserLoginRepo{db: db}
+}
+
+func (db *testDB) DeletedUserRepositoryRead() app.DeletedUserRepositoryRead {
+	return testDeletedUserRepo{db: db}
+}
+
+func (db *testDB) DeletedUserRepository() app.DeletedUserRepository {
+	return testDeletedUserRepo{db: db}
+}
+
+func (db *testDB) EventStore() storedevent.EventStore {
+	return nil
+}
+
+func (db *testDB) AddLock(lockName string) error {
+	db.locks = append(db.locks, lockName)
+	return nil
+}
+
+func (db *testDB) Complete(err error) error {
+	return err
+}
+
+func (db *testDB) SetEventProcessed(uid integrationevent.EventUID) (bool, error) {
+	if db.processed[uid] {
+		return true, nil
+	}
+	db.processed[uid] = true
+	return false, nil
+}
+
+func (db *testDB) IsUnverified(userID app.UserID) (bool, error) {
+	return db.unverified[userID], nil
+}
+
+func (db *testDB) Add(userID app.UserID) error {
+	db.unverified[userID] = true
+	return nil
+}
+
+func (db *testDB) Remove(userID app.UserID) error {
+	delete(db.unverified, userID)
+	return nil
+}
+
+type testUserLoginRepo struct {
+	db *testDB
+}
+
+func (r testUserLoginRepo) Store(userID app.UserID, login string) error {
+	r.db.logins[userID] = login
+	return nil
+}
+
+type testDeletedUserRepo struct {
+	db *testDB
+}
+
+func (r testDeletedUserRepo) IsDeleted(userID app.UserID) (bool, error) {
+	return r.db.deleted[userID], nil
+}
+
+func (r testDeletedUserRepo) Add(userID app.UserID) error {
+	r.db.deleted[userID] = true
+	return nil
+}
+
+func (r testDeletedUserRepo) Remove(userID app.UserID) error {
+	delete(r.db.deleted, userID)
+	return nil
+}
+
+type testEventSender struct{}
+
+func (s testEventSender) EventStored(integrationevent.EventUID) {}
+
+func (s testEventSender) SendStoredEvents() {}
@@ -15,6 +15,32 @@ func NewDeliveryLotReceivedEvent(lotID LotID) HandledEvent {
 	}
 }
 
+func NewDeliveryLotCancelledEvent(lotID LotID, reason string) HandledEvent {
+	return deliveryLotCancelledEvent{
+		lotID:  lotID,
+		reason: reason,
+	}
+}
+
+func NewUserEmailVerificationRequestedEvent(userID UserID) HandledEvent {
+	return userEmailVerificationRequestedEvent{
+		userID: userID,
+	}
+}
+
+func NewUserEmailVerifiedEvent(userID UserID) HandledEvent {
+	return userEmailVerifiedEvent{
+		userID: userID,
+	}
+}
+
+func NewUserProfileUpdatedEvent(userID UserID, login string) HandledEvent {
+	return userProfileUpdatedEvent{
+		userID: userID,
+		login:  login,
+	}
+}
+
 type deliveryLotSentEvent struct {
 	lotID LotID
 }
@@ -22,3 +48,21 @@ type deliveryLotSentEvent struct {
 type deliveryLotReceivedEvent struct {
 	lotID LotID
 }
+
+type deliveryLotCancelledEvent struct {
+	lotID  LotID
+	reason string
+}
+
+type userEmailVerificationRequestedEvent struct {
+	userID UserID
+}
+
+type userEmailVerifiedEvent struct {
+	userID UserID
+}
+
+type userProfileUpdatedEvent struct {
+	userID UserID
+	login  string
+}
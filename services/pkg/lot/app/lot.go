@@ -25,9 +25,11 @@ type Lot struct {
 	Description   string
 	StartPrice    Amount
 	BuyItNowPrice *Amount
-	Status        LotStatus
-	EndTime       time.Time
-	CreationTime  time.Time
+	// Weight - weight in grams, zero if not specified by seller
+	Weight       int
+	Status       LotStatus
+	EndTime      time.Time
+	CreationTime time.Time
 }
 
 type LotSpecification struct {
@@ -2,11 +2,13 @@ package app
 
 import "time"
 
+// LotQueryData - ShippingOptions are loaded only for single lot
 type LotQueryData struct {
 	Lot
-	OwnerLogin    string
-	LastBidAmount *Amount
-	LastBidderID  *UserID
+	OwnerLogin      string
+	LastBidAmount   *Amount
+	LastBidderID    *UserID
+	ShippingOptions []ShippingOption
 }
 
 type BidQueryData struct {
@@ -19,8 +21,46 @@ type LotWithBidsQueryData struct {
 	Bids []BidQueryData
 }
 
+type LotHighBidQueryData struct {
+	LotID         LotID
+	Status        LotStatus
+	HighBidderID  *UserID
+	HighBidAmount *Amount
+}
+
+// UserActivityQueryData - lots of user and lots with user high bid, which aren't received yet
+type UserActivityQueryData struct {
+	ActiveLotCount int
+	ActiveBidCount int
+}
+
+// SellerStatsQueryData - SoldLotCount is count of lots with winner, CompletedSaleCount is count of lots received by winner
+type SellerStatsQueryData struct {
+	ActiveLotCount     int
+	SoldLotCount       int
+	CompletedSaleCount int
+}
+
+// FinishedLotQueryData - won lot which isn't sent yet, WinnerID is user with highest bid
+type FinishedLotQueryData struct {
+	LotID    LotID
+	OwnerID  UserID
+	WinnerID UserID
+}
+
+// UserDataQueryData - personal data of user for export
+type UserDataQueryData struct {
+	Lots []Lot
+	Bids []Bid
+}
+
 type LotQueryService interface {
 	Get(lotID LotID) (*LotQueryData, error)
+	GetHighBid(lotID LotID) (*LotHighBidQueryData, error)
 	FindAvailable(userID UserID, createdAfter *time.Time, searchString *string, withParticipationOnly bool, wonOnly bool) ([]LotQueryData, error)
 	FindByOwnerID(ownerID UserID) ([]LotWithBidsQueryData, error)
+	GetUserActivity(userID UserID) (*UserActivityQueryData, error)
+	GetSellerStats(ownerID UserID) (*SellerStatsQueryData, error)
+	FindUserData(userID UserID) (*UserDataQueryData, error)
+	FindAllFinished() ([]FinishedLotQueryData, error)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -17,11 +18,14 @@ const lotLockNameTpl = "lock_lot_%s"
 
 var ErrBidOnOwnLot = errors.New("can't add bids for own lots")
 var ErrPaymentFailed = errors.New("order payment failed")
+var ErrPaymentRejectedByRiskRules = errors.New("payment rejected by billing risk rules")
+var ErrListingFeePaymentFailed = errors.New("listing fee payment failed")
 var ErrInvalidEndTime = errors.New("invalid end time")
 var ErrInvalidBuyItNowPrice = errors.New("invalid buy it now price")
 var ErrLotClosed = errors.New("lot closed")
 var ErrInvalidBidAmount = errors.New("invalid bid amount")
 var ErrAlreadyProcessed = errors.New("request with this id already processed")
+var ErrReasonRequired = errors.New("reason required")
 
 func NewLotService(
 	readRepoProvider ReadRepositoryProvider,
@@ -38,10 +42,13 @@ func NewLotService(
 }
 
 type LotService interface {
-	CreateLot(requestID RequestID, userID UserID, description string, startPrice float64, endTime time.Time, buyItNowPrice *float64) (LotID, error)
+	CreateLot(requestID RequestID, userID UserID, description string, startPrice float64, endTime time.Time, buyItNowPrice *float64, shipping LotShippingSpec) (LotID, error)
+	PreviewFees(startPrice float64, buyItNowPrice *float64) (FeePreview, error)
 	CreateBid(requestID RequestID, userID UserID, lotID LotID, amount float64) error
 	SetLotSent(lotID LotID) error
 	SetLotReceived(lotID LotID) error
+	CloseLot(lotID LotID, reason string) error
+	CancelUnsentLot(lotID LotID, reason string) error
 	ProcessCompletedLots() error
 }
 
@@ -52,29 +59,43 @@ type lotService struct {
 	billingClient    BillingClient
 }
 
-func (s *lotService) CreateLot(requestID RequestID, userID UserID, description string, startPrice float64, endTime time.Time, buyItNowPrice *float64) (LotID, error) {
-	startPriceAmount, err := AmountFromFloat(startPrice)
+func (s *lotService) CreateLot(requestID RequestID, userID UserID, description string, startPrice float64, endTime time.Time, buyItNowPrice *float64, shipping LotShippingSpec) (LotID, error) {
+	startPriceAmount, buyItNowAmount, err := parseLotPrices(startPrice, buyItNowPrice)
 	if err != nil {
 		return "", err
 	}
-	var buyItNowAmount *Amount
-	if buyItNowPrice != nil {
-		amount, err := AmountFromFloat(*buyItNowPrice)
-		if err != nil {
-			return "", err
-		}
-		if amount.RawValue() < startPriceAmount.RawValue() {
-			return "", errors.WithStack(ErrInvalidBuyItNowPrice)
-		}
-		buyItNowAmount = &amount
+	shippingOptions, err := parseLotShipping(shipping)
+	if err != nil {
+		return "", err
 	}
 	if !endTime.After(time.Now()) {
 		return "", errors.WithStack(ErrInvalidEndTime)
 	}
+	if err = s.checkRequestID(requestID); err != nil {
+		return "", errors.WithStack(err)
+	}
+	unverified, err := s.readRepoProvider.UnverifiedUserRepositoryRead().IsUnverified(userID)
+	if err != nil {
+		return "", err
+	}
+	if unverified {
+		return "", errors.WithStack(ErrEmailNotVerified)
+	}
+	if err = checkUserNotDeleted(s.readRepoProvider.DeletedUserRepositoryRead(), userID); err != nil {
+		return "", err
+	}
 
 	lotID := LotID(uuid.GenerateNew())
 
-	err = s.executeInTransactionWithLock("", func(provider RepositoryProvider) error {
+	feePaymentSucceeded, err := s.billingClient.PayListingFee(userID, lotID)
+	if err != nil {
+		return "", err
+	}
+	if !feePaymentSucceeded {
+		return "", errors.WithStack(ErrListingFeePaymentFailed)
+	}
+
+	err = s.executeInTransactionWithLock(userLockName(userID), func(provider RepositoryProvider) error {
 		eventRepo := provider.ProcessedRequestRepository()
 		alreadyProcessed, err2 := eventRepo.SetRequestProcessed(requestID)
 		if err2 != nil {
@@ -83,6 +104,9 @@ func (s *lotService) CreateLot(requestID RequestID, userID UserID, description s
 		if alreadyProcessed {
 			return errors.WithStack(ErrAlreadyProcessed)
 		}
+		if err2 = checkUserNotDeleted(provider.DeletedUserRepository(), userID); err2 != nil {
+			return err2
+		}
 
 		lot := Lot{
 			ID:            lotID,
@@ -90,15 +114,35 @@ func (s *lotService) CreateLot(requestID RequestID, userID UserID, description s
 			Description:   description,
 			StartPrice:    startPriceAmount,
 			BuyItNowPrice: buyItNowAmount,
+			Weight:        shipping.Weight,
 			Status:        LotStatusActive,
 			EndTime:       endTime,
 			CreationTime:  time.Now(),
 		}
 
-		return provider.LotRepository().Store(&lot)
+		err2 = provider.LotRepository().Store(&lot)
+		if err2 != nil {
+			return err2
+		}
+		return provider.ShippingOptionRepository().StoreAll(lotID, shippingOptions)
 	})
+	if err != nil {
+		err2 := s.sendLotCreationCancelledEvent(lotID, userID)
+		if err2 != nil {
+			err = errors.Wrap(err, err2.Error())
+		}
+		return "", err
+	}
 
-	return lotID, err
+	return lotID, nil
+}
+
+func (s *lotService) PreviewFees(startPrice float64, buyItNowPrice *float64) (FeePreview, error) {
+	startPriceAmount, buyItNowAmount, err := parseLotPrices(startPrice, buyItNowPrice)
+	if err != nil {
+		return FeePreview{}, err
+	}
+	return s.billingClient.PreviewFees(startPriceAmount, buyItNowAmount)
 }
 
 func (s *lotService) CreateBid(requestID RequestID, userID UserID, lotID LotID, amount float64) error {
@@ -109,6 +153,9 @@ func (s *lotService) CreateBid(requestID RequestID, userID UserID, lotID LotID,
 	if err = s.checkRequestID(requestID); err != nil {
 		return errors.WithStack(err)
 	}
+	if err = checkUserNotDeleted(s.readRepoProvider.DeletedUserRepositoryRead(), userID); err != nil {
+		return err
+	}
 
 	paymentSucceeded, err := s.billingClient.ProcessOrderPayment(userID, lotID, bidAmount)
 	if err != nil {
@@ -118,7 +165,7 @@ func (s *lotService) CreateBid(requestID RequestID, userID UserID, lotID LotID,
 		return errors.WithStack(ErrPaymentFailed)
 	}
 
-	err = s.executeInTransactionWithLock(lotLockName(lotID), func(provider RepositoryProvider) error {
+	err = s.executeInTransactionWithLocks([]string{lotLockName(lotID), userLockName(userID)}, func(provider RepositoryProvider) error {
 		eventRepo := provider.ProcessedRequestRepository()
 		alreadyProcessed, err := eventRepo.SetRequestProcessed(requestID)
 		if err != nil {
@@ -127,6 +174,9 @@ func (s *lotService) CreateBid(requestID RequestID, userID UserID, lotID LotID,
 		if alreadyProcessed {
 			return errors.WithStack(ErrAlreadyProcessed)
 		}
+		if err = checkUserNotDeleted(provider.DeletedUserRepository(), userID); err != nil {
+			return err
+		}
 
 		err = s.handleLotNewBid(provider, lotID, userID, bidAmount)
 		if err != nil {
@@ -230,6 +280,84 @@ func (s *lotService) SetLotReceived(lotID LotID) error {
 	})
 }
 
+// CloseLot - active lot is closed by moderator without winner, payment blocked for high bid is released
+func (s *lotService) CloseLot(lotID LotID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.WithStack(ErrReasonRequired)
+	}
+	err := s.executeInTransactionWithLock(lotLockName(lotID), func(provider RepositoryProvider) error {
+		lotRepo := provider.LotRepository()
+		lot, err := lotRepo.FindByID(lotID)
+		if err != nil {
+			return err
+		}
+		if lot.Status != LotStatusActive {
+			return errors.WithStack(ErrLotClosed)
+		}
+
+		lastBid, err := provider.BidRepository().TryFindLastByLotID(lotID)
+		if err != nil {
+			return err
+		}
+		events := []integrationevent.EventData{NewLotClosedEvent(lotID, lot.OwnerID, reason)}
+		if lastBid != nil {
+			events = append(events, NewBidCancelledEvent(lotID, lastBid.UserID, lastBid.Amount))
+		}
+		for _, event := range events {
+			err = provider.EventStore().Add(event)
+			if err != nil {
+				return err
+			}
+			s.eventSender.EventStored(event.UID)
+		}
+
+		lot.Status = LotStatusClosed
+		return lotRepo.Store(lot)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.eventSender.SendStoredEvents()
+	return nil
+}
+
+// CancelUnsentLot - finished lot not sent by owner until delivery deadline is closed, payment blocked for winning bid is released
+func (s *lotService) CancelUnsentLot(lotID LotID, reason string) error {
+	return s.executeInTransactionWithLock(lotLockName(lotID), func(provider RepositoryProvider) error {
+		lotRepo := provider.LotRepository()
+		lot, err := lotRepo.FindByID(lotID)
+		if err != nil {
+			return err
+		}
+		if lot.Status != LotStatusFinished {
+			return nil
+		}
+
+		lastBid, err := provider.BidRepository().TryFindLastByLotID(lotID)
+		if err != nil {
+			return err
+		}
+		if lastBid == nil {
+			return errors.New("can't cancel delivery of lot without bids")
+		}
+		for _, event := range []integrationevent.EventData{
+			NewLotClosedEvent(lotID, lot.OwnerID, reason),
+			NewBidCancelledEvent(lotID, lastBid.UserID, lastBid.Amount),
+		} {
+			err = provider.EventStore().Add(event)
+			if err != nil {
+				return err
+			}
+			s.eventSender.EventStored(event.UID)
+		}
+
+		lot.Status = LotStatusClosed
+		return lotRepo.Store(lot)
+	})
+}
+
 func (s *lotService) ProcessCompletedLots() error {
 	lots, err := s.readRepoProvider.LotRepositoryRead().FindActiveCompletedLots()
 	if err != nil || len(lots) == 0 {
@@ -259,10 +387,10 @@ func (s *lotService) ProcessCompletedLots() error {
 			var event integrationevent.EventData
 			if lastBid != nil {
 				lot.Status = LotStatusFinished
-				event = NewLotWonEvent(lotID, lastBid.UserID, lot.OwnerID)
+				event = NewLotWonEvent(lotID, lastBid.UserID, lot.OwnerID, time.Now())
 			} else {
 				lot.Status = LotStatusClosed
-				event = NewLotClosedEvent(lotID, lot.OwnerID)
+				event = NewLotClosedEvent(lotID, lot.OwnerID, "")
 			}
 			err = provider.EventStore().Add(event)
 			if err != nil {
@@ -307,7 +435,7 @@ func (s *lotService) handleLotNewBid(provider RepositoryProvider, lotID LotID, u
 		lot.Status = LotStatusFinished
 		lotChanged = true
 
-		event := NewLotWonEvent(lotID, userID, lot.OwnerID)
+		event := NewLotWonEvent(lotID, userID, lot.OwnerID, curTime)
 		err = provider.EventStore().Add(event)
 		if err != nil {
 			return err
@@ -345,6 +473,28 @@ func (s *lotService) sendLotBidCancelledEvent(lotID LotID, userID UserID, bidAmo
 	return err
 }
 
+func (s *lotService) sendLotCreationCancelledEvent(lotID LotID, lotOwnerID UserID) error {
+	var err error
+	for i := 0; i < maxCancelAttempts; i++ {
+		err = s.executeInTransactionWithLock(lotLockName(lotID), func(provider RepositoryProvider) error {
+			event := NewLotCreationCancelledEvent(lotID, lotOwnerID)
+
+			err := provider.EventStore().Add(event)
+			if err != nil {
+				return err
+			}
+			s.eventSender.EventStored(event.UID)
+
+			return nil
+		})
+		if err == nil {
+			s.eventSender.SendStoredEvents()
+			return nil
+		}
+	}
+	return err
+}
+
 func (s *lotService) checkRequestID(requestID RequestID) error {
 	processed, err := s.readRepoProvider.ProcessedRequestRepositoryRead().IsRequestProcessed(requestID)
 	if err != nil {
@@ -356,7 +506,12 @@ func (s *lotService) checkRequestID(requestID RequestID) error {
 	return nil
 }
 
-func (s *lotService) executeInTransactionWithLock(lockName string, f func(RepositoryProvider) error) (err error) {
+func (s *lotService) executeInTransactionWithLock(lockName string, f func(RepositoryProvider) error) error {
+	return s.executeInTransactionWithLocks([]string{lockName}, f)
+}
+
+// executeInTransactionWithLocks - lot lock should be taken before user lock to avoid deadlocks
+func (s *lotService) executeInTransactionWithLocks(lockNames []string, f func(RepositoryProvider) error) (err error) {
 	var trUnit TransactionalUnit
 	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
 	if err != nil {
@@ -365,7 +520,7 @@ func (s *lotService) executeInTransactionWithLock(lockName string, f func(Reposi
 	defer func() {
 		err = trUnit.Complete(err)
 	}()
-	if lockName != "" {
+	for _, lockName := range lockNames {
 		err = trUnit.AddLock(lockName)
 		if err != nil {
 			return err
@@ -375,6 +530,25 @@ func (s *lotService) executeInTransactionWithLock(lockName string, f func(Reposi
 	return err
 }
 
+func parseLotPrices(startPrice float64, buyItNowPrice *float64) (Amount, *Amount, error) {
+	startPriceAmount, err := AmountFromFloat(startPrice)
+	if err != nil {
+		return nil, nil, err
+	}
+	var buyItNowAmount *Amount
+	if buyItNowPrice != nil {
+		amount, err := AmountFromFloat(*buyItNowPrice)
+		if err != nil {
+			return nil, nil, err
+		}
+		if amount.RawValue() < startPriceAmount.RawValue() {
+			return nil, nil, errors.WithStack(ErrInvalidBuyItNowPrice)
+		}
+		buyItNowAmount = &amount
+	}
+	return startPriceAmount, buyItNowAmount, nil
+}
+
 func lotLockName(lotID LotID) string {
 	return fmt.Sprintf(lotLockNameTpl, string(lotID))
 }
@@ -0,0 +1,79 @@
+package app_test
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/lot/app"
+
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLotOfUnverifiedUserRejectedUntilEmailVerified(t *testing.T) {
+	db := newTestDB()
+	billingClient := &testBillingClient{}
+	service := app.NewLotService(db, db, testEventSender{}, billingClient)
+	userID := app.UserID(uuid.GenerateNew())
+	handleTestUserEvent(t, db, "user.email_verification_requested", map[string]string{"user_id": string(userID)})
+
+	_, err := createTestLot(service, userID)
+	assert.Equal(t, app.ErrEmailNotVerified, errors.Cause(err))
+	assert.Empty(t, db.lots)
+	assert.Zero(t, billingClient.listingFeePayments)
+
+	handleTestUserEvent(t, db, "user.email_verified", map[string]string{"user_id": string(userID)})
+	lotID, err := createTestLot(service, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, db.lots[lotID].OwnerID)
+	assert.Equal(t, 1, billingClient.listingFeePayments)
+}
+
+func createTestLot(service app.LotService, userID app.UserID) (app.LotID, error) {
+	return service.CreateLot(app.RequestID(uuid.GenerateNew()), userID, "lot", 100, time.Now().Add(time.Hour), nil, app.LotShippingSpec{})
+}
+
+// testBillingClient - only listing fee payment is implemented
+type testBillingClient struct {
+	app.BillingClient
+	listingFeePayments int
+}
+
+func (c *testBillingClient) PayListingFee(app.UserID, app.LotID) (bool, error) {
+	c.listingFeePayments++
+	return true, nil
+}
+
+// testLotRepo - only lot storing is implemented
+type testLotRepo struct {
+	app.LotRepository
+	db *testDB
+}
+
+func (r testLotRepo) Store(lot *app.Lot) error {
+	r.db.lots[lot.ID] = *lot
+	return nil
+}
+
+type testShippingOptionRepo struct {
+	app.ShippingOptionRepository
+}
+
+func (r testShippingOptionRepo) StoreAll(app.LotID, []app.ShippingOption) error {
+	return nil
+}
+
+type testRequestRepo struct {
+	db *testDB
+}
+
+func (r testRequestRepo) IsRequestProcessed(uid app.RequestID) (bool, error) {
+	return r.db.requests[uid], nil
+}
+
+func (r testRequestRepo) SetRequestProcessed(uid app.RequestID) (bool, error) {
+	alreadyProcessed := r.db.requests[uid]
+	r.db.requests[uid] = true
+	return alreadyProcessed, nil
+}
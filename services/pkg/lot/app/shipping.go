@@ -0,0 +1,145 @@
+package app
+
+import (
+	"github.com/pkg/errors"
+
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidShippingOption = errors.New("invalid shipping option")
+
+const maxShippingOptionCount = 10
+
+// ShippingRateZoneAny - zone of rate used for destination countries without own rates
+const ShippingRateZoneAny = "*"
+
+var shippingRateZonePattern = regexp.MustCompile(`^([A-Z]{2}|\*)$`)
+
+type ShippingOptionType string
+
+const (
+	ShippingOptionFlat  ShippingOptionType = "flat"
+	ShippingOptionFree  ShippingOptionType = "free"
+	ShippingOptionTable ShippingOptionType = "table"
+)
+
+// ShippingRate - price of parcel up to MaxWeight grams sent to zone (destination country code)
+type ShippingRate struct {
+	Zone      string
+	MaxWeight int
+	Price     Amount
+}
+
+// ShippingOption - Price is set for flat option, Rates are set for table option
+type ShippingOption struct {
+	Name  string
+	Type  ShippingOptionType
+	Price Amount
+	Rates []ShippingRate
+}
+
+// ShippingOptionSpec - shipping option defined by seller, prices are not validated yet
+type ShippingOptionSpec struct {
+	Name  string
+	Type  string
+	Price float64
+	Rates []ShippingRateSpec
+}
+
+type ShippingRateSpec struct {
+	Zone      string
+	MaxWeight int
+	Price     float64
+}
+
+// LotShippingSpec - weight of lot in grams is required for table options
+type LotShippingSpec struct {
+	Weight  int
+	Options []ShippingOptionSpec
+}
+
+type ShippingOptionRepository interface {
+	FindAllByLotID(lotID LotID) ([]ShippingOption, error)
+	// StoreAll - replaces shipping options of lot
+	StoreAll(lotID LotID, options []ShippingOption) error
+}
+
+func parseLotShipping(spec LotShippingSpec) ([]ShippingOption, error) {
+	if spec.Weight < 0 {
+		return nil, errors.Wrap(ErrInvalidShippingOption, "weight can't be negative")
+	}
+	if len(spec.Options) > maxShippingOptionCount {
+		return nil, errors.Wrapf(ErrInvalidShippingOption, "lot can't have more than %d shipping options", maxShippingOptionCount)
+	}
+	options := make([]ShippingOption, 0, len(spec.Options))
+	names := make(map[string]bool, len(spec.Options))
+	for _, optionSpec := range spec.Options {
+		option, err := parseShippingOption(optionSpec, spec.Weight)
+		if err != nil {
+			return nil, err
+		}
+		if names[option.Name] {
+			return nil, errors.Wrapf(ErrInvalidShippingOption, "duplicate option name '%s'", option.Name)
+		}
+		names[option.Name] = true
+		options = append(options, option)
+	}
+	return options, nil
+}
+
+func parseShippingOption(spec ShippingOptionSpec, weight int) (ShippingOption, error) {
+	option := ShippingOption{
+		Name: strings.TrimSpace(spec.Name),
+		Type: ShippingOptionType(spec.Type),
+	}
+	if option.Name == "" {
+		return ShippingOption{}, errors.Wrap(ErrInvalidShippingOption, "option name required")
+	}
+
+	switch option.Type {
+	case ShippingOptionFree:
+		return option, nil
+	case ShippingOptionFlat:
+		price, err := AmountFromFloat(spec.Price)
+		if err != nil {
+			return ShippingOption{}, err
+		}
+		option.Price = price
+		return option, nil
+	case ShippingOptionTable:
+		if weight == 0 {
+			return ShippingOption{}, errors.Wrapf(ErrInvalidShippingOption, "lot weight required for option '%s'", option.Name)
+		}
+		if len(spec.Rates) == 0 {
+			return ShippingOption{}, errors.Wrapf(ErrInvalidShippingOption, "rates required for option '%s'", option.Name)
+		}
+		rateKeys := make(map[ShippingRate]bool, len(spec.Rates))
+		for _, rateSpec := range spec.Rates {
+			rate := ShippingRate{
+				Zone:      strings.ToUpper(strings.TrimSpace(rateSpec.Zone)),
+				MaxWeight: rateSpec.MaxWeight,
+			}
+			if !shippingRateZonePattern.MatchString(rate.Zone) {
+				return ShippingOption{}, errors.Wrapf(ErrInvalidShippingOption, "invalid zone '%s', country code or '*' expected", rateSpec.Zone)
+			}
+			if rate.MaxWeight <= 0 {
+				return ShippingOption{}, errors.Wrap(ErrInvalidShippingOption, "rate max weight should be positive")
+			}
+			if rateKeys[rate] {
+				return ShippingOption{}, errors.Wrapf(ErrInvalidShippingOption, "duplicate rate for zone '%s' and weight %d", rate.Zone, rate.MaxWeight)
+			}
+			rateKeys[rate] = true
+
+			price, err := AmountFromFloat(rateSpec.Price)
+			if err != nil {
+				return ShippingOption{}, err
+			}
+			rate.Price = price
+			option.Rates = append(option.Rates, rate)
+		}
+		return option, nil
+	default:
+		return ShippingOption{}, errors.Wrapf(ErrInvalidShippingOption, "unknown option type '%s'", spec.Type)
+	}
+}
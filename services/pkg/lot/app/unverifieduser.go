@@ -0,0 +1,17 @@
+package app
+
+import "errors"
+
+var ErrEmailNotVerified = errors.New("email not verified")
+
+// UnverifiedUserRepositoryRead - users are tracked by user.email_verification_requested and user.email_verified events,
+// users registered before email verification are not tracked and treated as verified
+type UnverifiedUserRepositoryRead interface {
+	IsUnverified(userID UserID) (bool, error)
+}
+
+type UnverifiedUserRepository interface {
+	UnverifiedUserRepositoryRead
+	Add(userID UserID) error
+	Remove(userID UserID) error
+}
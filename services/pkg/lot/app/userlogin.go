@@ -0,0 +1,6 @@
+package app
+
+// UserLoginRepository - read model of user logins, updated by user.profile_updated events
+type UserLoginRepository interface {
+	Store(userID UserID, login string) error
+}
@@ -7,13 +7,22 @@ import (
 
 	"github.com/pkg/errors"
 
+	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 const processPaymentURL = "/internal/api/v1/payment"
+const listingFeeURL = "/internal/api/v1/fee/listing"
+const feePreviewURL = "/internal/api/v1/fee/preview"
 const maxAttemptCount = 10
 
+// billing service error code of payment rejected by risk rules
+const errorCodeRiskRuleViolated = 7
+
 func NewClient(client http.Client, serviceHost string) app.BillingClient {
 	return &billingClient{httpClient: httpclient.NewClient(client, serviceHost)}
 }
@@ -23,21 +32,59 @@ type billingClient struct {
 }
 
 func (c *billingClient) ProcessOrderPayment(userID app.UserID, lotID app.LotID, price app.Amount) (succeeded bool, err error) {
-	requestID := string(uuid.GenerateNew())
-
 	request := processPaymentRequest{
 		UserID: string(userID),
 		LotID:  string(lotID),
 		Amount: price.Value(),
 	}
+	return c.makeIdempotentRequest(request, processPaymentURL)
+}
+
+func (c *billingClient) PayListingFee(userID app.UserID, lotID app.LotID) (succeeded bool, err error) {
+	request := listingFeeRequest{
+		UserID: string(userID),
+		LotID:  string(lotID),
+	}
+	return c.makeIdempotentRequest(request, listingFeeURL)
+}
+
+func (c *billingClient) PreviewFees(startPrice app.Amount, buyItNowPrice *app.Amount) (app.FeePreview, error) {
+	query := url.Values{}
+	query.Set("startPrice", fmt.Sprintf("%.2f", startPrice.Value()))
+	if buyItNowPrice != nil {
+		query.Set("buyItNowPrice", fmt.Sprintf("%.2f", (*buyItNowPrice).Value()))
+	}
+
+	var response feePreviewResponse
+	err := c.httpClient.MakeJSONRequest(nil, &response, http.MethodGet, feePreviewURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return app.FeePreview{}, err
+	}
+	preview := app.FeePreview{
+		ListingFee:    amountFromFloat(response.ListingFee),
+		FinalValueFee: amountFromFloat(response.FinalValueFee),
+	}
+	if response.BuyItNowFinalValueFee != nil {
+		fee := amountFromFloat(*response.BuyItNowFinalValueFee)
+		preview.BuyItNowFinalValueFee = &fee
+	}
+	return preview, nil
+}
+
+func (c *billingClient) makeIdempotentRequest(request interface{}, reqURL string) (succeeded bool, err error) {
+	requestID := string(uuid.GenerateNew())
 
 	for i := 0; i < maxAttemptCount; i++ {
-		err = c.httpClient.MakeJSONRequest(request, nil, http.MethodPost, processPaymentURL, &requestID)
+		err = c.httpClient.MakeJSONRequest(request, nil, http.MethodPost, reqURL, &requestID)
 		if err == nil {
 			return true, nil
 		}
 		if e, ok := errors.Cause(err).(*httpclient.HTTPError); ok {
 			if e.StatusCode == http.StatusBadRequest {
+				var info errorInfo
+				if json.Unmarshal([]byte(e.Body), &info) == nil && info.Code == errorCodeRiskRuleViolated {
+					return false, errors.Wrap(app.ErrPaymentRejectedByRiskRules, info.Message)
+				}
 				return false, nil
 			}
 			if i > 0 && e.StatusCode == http.StatusConflict {
@@ -53,8 +100,28 @@ func (c *billingClient) ProcessOrderPayment(userID app.UserID, lotID app.LotID,
 	return false, err
 }
 
+type errorInfo struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
 type processPaymentRequest struct {
 	UserID string  `json:"userID"`
 	LotID  string  `json:"lotID"`
 	Amount float64 `json:"amount"`
 }
+
+type listingFeeRequest struct {
+	UserID string `json:"userID"`
+	LotID  string `json:"lotID"`
+}
+
+type feePreviewResponse struct {
+	ListingFee            float64  `json:"listingFee"`
+	FinalValueFee         float64  `json:"finalValueFee"`
+	BuyItNowFinalValueFee *float64 `json:"buyItNowFinalValueFee"`
+}
+
+func amountFromFloat(value float64) app.Amount {
+	return app.AmountFromRawValue(uint64(math.Round(value * 100)))
+}
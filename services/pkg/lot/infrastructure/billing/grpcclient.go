@@ -0,0 +1,77 @@
+package billing
+
+import (
+	"arch-homework/api/billingpb"
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/common/infrastructure/grpcclient"
+	"arch-homework/pkg/lot/app"
+
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func NewGRPCClient(conn *grpc.ClientConn) app.BillingClient {
+	return &billingGRPCClient{client: billingpb.NewBillingServiceClient(conn)}
+}
+
+type billingGRPCClient struct {
+	client billingpb.BillingServiceClient
+}
+
+func (c *billingGRPCClient) ProcessOrderPayment(userID app.UserID, lotID app.LotID, price app.Amount) (succeeded bool, err error) {
+	ctx := grpcclient.WithRequestID(context.Background(), string(uuid.GenerateNew()))
+	_, err = c.client.ProcessLotPayment(ctx, &billingpb.ProcessLotPaymentRequest{
+		UserId: string(userID),
+		LotId:  string(lotID),
+		Amount: price.RawValue(),
+	}, grpcclient.Idempotent())
+	return toCallResult(err)
+}
+
+func (c *billingGRPCClient) PayListingFee(userID app.UserID, lotID app.LotID) (succeeded bool, err error) {
+	ctx := grpcclient.WithRequestID(context.Background(), string(uuid.GenerateNew()))
+	_, err = c.client.PayListingFee(ctx, &billingpb.PayListingFeeRequest{
+		UserId: string(userID),
+		LotId:  string(lotID),
+	}, grpcclient.Idempotent())
+	return toCallResult(err)
+}
+
+func (c *billingGRPCClient) PreviewFees(startPrice app.Amount, buyItNowPrice *app.Amount) (app.FeePreview, error) {
+	request := &billingpb.PreviewFeesRequest{StartPrice: startPrice.RawValue()}
+	if buyItNowPrice != nil {
+		price := (*buyItNowPrice).RawValue()
+		request.BuyItNowPrice = &price
+	}
+
+	response, err := c.client.PreviewFees(context.Background(), request, grpcclient.Idempotent())
+	if err != nil {
+		return app.FeePreview{}, errors.WithStack(err)
+	}
+	preview := app.FeePreview{
+		ListingFee:    app.AmountFromRawValue(response.ListingFee),
+		FinalValueFee: app.AmountFromRawValue(response.FinalValueFee),
+	}
+	if response.BuyItNowFinalValueFee != nil {
+		fee := app.AmountFromRawValue(*response.BuyItNowFinalValueFee)
+		preview.BuyItNowFinalValueFee = &fee
+	}
+	return preview, nil
+}
+
+func toCallResult(err error) (succeeded bool, resErr error) {
+	switch status.Code(err) {
+	case codes.OK:
+		return true, nil
+	case codes.FailedPrecondition, codes.InvalidArgument:
+		return false, nil
+	case codes.PermissionDenied:
+		return false, errors.Wrap(app.ErrPaymentRejectedByRiskRules, status.Convert(err).Message())
+	default:
+		return false, errors.WithStack(err)
+	}
+}
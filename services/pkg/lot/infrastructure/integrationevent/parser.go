@@ -12,6 +12,10 @@ import (
 
 const typeDeliveryLotSent = "delivery.lot_sent"
 const typeDeliveryLotReceived = "delivery.lot_received"
+const typeDeliveryLotCancelled = "delivery.lot_cancelled"
+const typeUserEmailVerificationRequested = "user.email_verification_requested"
+const typeUserEmailVerified = "user.email_verified"
+const typeUserProfileUpdated = "user.profile_updated"
 
 func NewEventParser() app.IntegrationEventParser {
 	return eventParser{}
@@ -26,6 +30,14 @@ func (e eventParser) ParseIntegrationEvent(event integrationevent.EventData) (ap
 		return parseLotSentEvent(event.Body)
 	case typeDeliveryLotReceived:
 		return parseLotReceivedEvent(event.Body)
+	case typeDeliveryLotCancelled:
+		return parseLotCancelledEvent(event.Body)
+	case typeUserEmailVerificationRequested:
+		return parseUserEmailVerificationRequestedEvent(event.Body)
+	case typeUserEmailVerified:
+		return parseUserEmailVerifiedEvent(event.Body)
+	case typeUserProfileUpdated:
+		return parseUserProfileUpdatedEvent(event.Body)
 	default:
 		return nil, nil
 	}
@@ -57,6 +69,66 @@ func parseLotReceivedEvent(strBody string) (app.HandledEvent, error) {
 	return app.NewDeliveryLotReceivedEvent(app.LotID(body.LotID)), nil
 }
 
+func parseLotCancelledEvent(strBody string) (app.HandledEvent, error) {
+	var body deliveryLotCancelledEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.LotID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return app.NewDeliveryLotCancelledEvent(app.LotID(body.LotID), "delivery cancelled: "+body.Reason), nil
+}
+
+func parseUserEmailVerificationRequestedEvent(strBody string) (app.HandledEvent, error) {
+	body, err := parseUserEvent(strBody)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewUserEmailVerificationRequestedEvent(app.UserID(body.UserID)), nil
+}
+
+func parseUserEmailVerifiedEvent(strBody string) (app.HandledEvent, error) {
+	body, err := parseUserEvent(strBody)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewUserEmailVerifiedEvent(app.UserID(body.UserID)), nil
+}
+
+func parseUserProfileUpdatedEvent(strBody string) (app.HandledEvent, error) {
+	body, err := parseUserEvent(strBody)
+	if err != nil {
+		return nil, err
+	}
+	if body.Login == "" {
+		return nil, errors.New("login required")
+	}
+	return app.NewUserProfileUpdatedEvent(app.UserID(body.UserID), body.Login), nil
+}
+
+func parseUserEvent(strBody string) (userEventBody, error) {
+	var body userEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return body, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.UserID)
+	return body, errors.WithStack(err)
+}
+
+type userEventBody struct {
+	UserID string `json:"user_id"`
+	Login  string `json:"login,omitempty"`
+}
+
 type deliveryLotEventBody struct {
 	LotID string `json:"lot_id"`
 }
+
+type deliveryLotCancelledEventBody struct {
+	LotID  string `json:"lot_id"`
+	Reason string `json:"reason"`
+}
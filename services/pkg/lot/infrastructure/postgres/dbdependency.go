@@ -24,6 +24,14 @@ func (d *dbDependency) LotRepositoryRead() app.LotRepositoryRead {
 	return NewLotRepository(d.client)
 }
 
+func (d *dbDependency) UnverifiedUserRepositoryRead() app.UnverifiedUserRepositoryRead {
+	return NewUnverifiedUserRepository(d.client)
+}
+
+func (d *dbDependency) DeletedUserRepositoryRead() app.DeletedUserRepositoryRead {
+	return NewDeletedUserRepository(d.client)
+}
+
 func (d *dbDependency) NewTransactionalUnit() (app.TransactionalUnit, error) {
 	transaction, err := d.client.BeginTransaction()
 	if err != nil {
@@ -63,6 +71,22 @@ func (t *transactionalUnit) ProcessedEventRepository() app.ProcessedEventReposit
 	return NewProcessedEventRepository(t.transaction)
 }
 
+func (t *transactionalUnit) ShippingOptionRepository() app.ShippingOptionRepository {
+	return NewShippingOptionRepository(t.transaction)
+}
+
+func (t *transactionalUnit) UnverifiedUserRepository() app.UnverifiedUserRepository {
+	return NewUnverifiedUserRepository(t.transaction)
+}
+
+func (t *transactionalUnit) UserLoginRepository() app.UserLoginRepository {
+	return NewUserLoginRepository(t.transaction)
+}
+
+func (t *transactionalUnit) DeletedUserRepository() app.DeletedUserRepository {
+	return NewDeletedUserRepository(t.transaction)
+}
+
 func (t *transactionalUnit) Complete(err error) error {
 	t.nestedLevel--
 
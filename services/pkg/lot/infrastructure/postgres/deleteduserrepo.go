@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/lot/app"
+
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func NewDeletedUserRepository(client postgres.Client) app.DeletedUserRepository {
+	return &deletedUserRepository{client: client}
+}
+
+type deletedUserRepository struct {
+	client postgres.Client
+}
+
+func (repo *deletedUserRepository) IsDeleted(userID app.UserID) (bool, error) {
+	const query = `SELECT user_id FROM deleted_user WHERE user_id = $1`
+
+	var resID string
+	err := repo.client.Get(&resID, query, string(userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (repo *deletedUserRepository) Add(userID app.UserID) error {
+	const query = `INSERT INTO deleted_user (user_id) VALUES ($1) ON CONFLICT DO NOTHING`
+	_, err := repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
+
+func (repo *deletedUserRepository) Remove(userID app.UserID) error {
+	const query = `DELETE FROM deleted_user WHERE user_id = $1`
+	_, err := repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
@@ -32,12 +32,15 @@ func (s *lotQueryService) Get(lotID app.LotID) (*app.LotQueryData, error) {
 				   l.status,
 				   l.start_price,
 				   l.buy_it_now_price,
+				   l.weight,
 				   l.end_time,
 				   l.created_at,
 				   b.user_id AS last_bidder_id,
-				   b.amount  AS last_bid_amount
+				   b.amount  AS last_bid_amount,
+				   u.login   AS owner_login
 			FROM lot AS l
 					 LEFT JOIN LATERAL (SELECT user_id, amount FROM bid WHERE lot_id = l.id ORDER BY amount DESC LIMIT 1) AS b ON TRUE
+					 LEFT JOIN user_login AS u ON u.user_id = l.owner_id
 			WHERE l.id = $1
 		`
 
@@ -50,7 +53,48 @@ func (s *lotQueryService) Get(lotID app.LotID) (*app.LotQueryData, error) {
 		return nil, errors.WithStack(err)
 	}
 	res, err := s.toLotQueryData(&lot)
-	return &res, err
+	if err != nil {
+		return nil, err
+	}
+	res.ShippingOptions, err = NewShippingOptionRepository(s.client).FindAllByLotID(lotID)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *lotQueryService) GetHighBid(lotID app.LotID) (*app.LotHighBidQueryData, error) {
+	const sqlQuery = `
+			SELECT l.id,
+				   l.status,
+				   b.user_id AS high_bidder_id,
+				   b.amount  AS high_bid_amount
+			FROM lot AS l
+					 LEFT JOIN LATERAL (SELECT user_id, amount FROM bid WHERE lot_id = l.id ORDER BY amount DESC LIMIT 1) AS b ON TRUE
+			WHERE l.id = $1
+		`
+
+	var lot sqlxLotHighBidQueryData
+	err := s.client.Get(&lot, sqlQuery, string(lotID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrLotNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	res := app.LotHighBidQueryData{
+		LotID:  app.LotID(lot.ID),
+		Status: app.LotStatus(lot.Status),
+	}
+	if lot.HighBidderID.Valid {
+		bidderID := app.UserID(lot.HighBidderID.String)
+		res.HighBidderID = &bidderID
+	}
+	if lot.HighBidAmount.Valid {
+		amount := app.AmountFromRawValue(uint64(lot.HighBidAmount.Int64))
+		res.HighBidAmount = &amount
+	}
+	return &res, nil
 }
 
 func (s *lotQueryService) FindAvailable(userID app.UserID, createdAfter *time.Time, searchString *string, withParticipationOnly bool, wonOnly bool) ([]app.LotQueryData, error) {
@@ -61,12 +105,15 @@ func (s *lotQueryService) FindAvailable(userID app.UserID, createdAfter *time.Ti
 				   l.status,
 				   l.start_price,
 				   l.buy_it_now_price,
+				   l.weight,
 				   l.end_time,
 				   l.created_at,
 				   b.user_id AS last_bidder_id,
-				   b.amount  AS last_bid_amount
+				   b.amount  AS last_bid_amount,
+				   u.login   AS owner_login
 			FROM lot AS l
-					 LEFT JOIN LATERAL (SELECT user_id, amount FROM bid WHERE lot_id = l.id ORDER BY amount DESC LIMIT 1) AS b ON TRUE 
+					 LEFT JOIN LATERAL (SELECT user_id, amount FROM bid WHERE lot_id = l.id ORDER BY amount DESC LIMIT 1) AS b ON TRUE
+					 LEFT JOIN user_login AS u ON u.user_id = l.owner_id 
 		`
 
 	query := sqlQuery
@@ -126,7 +173,7 @@ func (s *lotQueryService) FindAvailable(userID app.UserID, createdAfter *time.Ti
 
 func (s *lotQueryService) FindByOwnerID(ownerID app.UserID) ([]app.LotWithBidsQueryData, error) {
 	const sqlQuery = `
-			SELECT id, owner_id, description, status, start_price, buy_it_now_price, end_time, created_at FROM lot
+			SELECT id, owner_id, description, status, start_price, buy_it_now_price, weight, end_time, created_at FROM lot
 			WHERE owner_id = $1
 		`
 
@@ -156,8 +203,119 @@ func (s *lotQueryService) FindByOwnerID(ownerID app.UserID) ([]app.LotWithBidsQu
 	return res, nil
 }
 
+func (s *lotQueryService) GetUserActivity(userID app.UserID) (*app.UserActivityQueryData, error) {
+	const sqlQuery = `
+			SELECT (SELECT COUNT(*) FROM lot WHERE owner_id = $1 AND status IN ($2, $3, $4)) AS active_lot_count,
+				   (SELECT COUNT(*)
+					FROM lot AS l
+							 INNER JOIN LATERAL (SELECT user_id FROM bid WHERE lot_id = l.id ORDER BY amount DESC LIMIT 1) AS b ON TRUE
+					WHERE b.user_id = $1 AND l.status IN ($2, $3, $4)) AS active_bid_count
+		`
+
+	var activity sqlxUserActivity
+	err := s.client.Get(&activity, sqlQuery,
+		string(userID),
+		string(app.LotStatusActive),
+		string(app.LotStatusFinished),
+		string(app.LotStatusSent),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &app.UserActivityQueryData{
+		ActiveLotCount: activity.ActiveLotCount,
+		ActiveBidCount: activity.ActiveBidCount,
+	}, nil
+}
+
+func (s *lotQueryService) GetSellerStats(ownerID app.UserID) (*app.SellerStatsQueryData, error) {
+	const sqlQuery = `
+			SELECT COUNT(*) FILTER (WHERE status = $2) AS active_lot_count,
+				   COUNT(*) FILTER (WHERE status IN ($3, $4, $5)) AS sold_lot_count,
+				   COUNT(*) FILTER (WHERE status = $5) AS completed_sale_count
+			FROM lot WHERE owner_id = $1
+		`
+
+	var stats sqlxSellerStats
+	err := s.client.Get(&stats, sqlQuery,
+		string(ownerID),
+		string(app.LotStatusActive),
+		string(app.LotStatusFinished),
+		string(app.LotStatusSent),
+		string(app.LotStatusReceived),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &app.SellerStatsQueryData{
+		ActiveLotCount:     stats.ActiveLotCount,
+		SoldLotCount:       stats.SoldLotCount,
+		CompletedSaleCount: stats.CompletedSaleCount,
+	}, nil
+}
+
+func (s *lotQueryService) FindAllFinished() ([]app.FinishedLotQueryData, error) {
+	const sqlQuery = `
+			SELECT l.id, l.owner_id, b.user_id AS winner_id
+			FROM lot AS l
+					 INNER JOIN LATERAL (SELECT user_id FROM bid WHERE lot_id = l.id ORDER BY amount DESC LIMIT 1) AS b ON TRUE
+			WHERE l.status = $1
+			ORDER BY l.end_time
+		`
+
+	var lots []sqlxFinishedLot
+	err := s.client.Select(&lots, sqlQuery, string(app.LotStatusFinished))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.FinishedLotQueryData, 0, len(lots))
+	for _, lot := range lots {
+		res = append(res, app.FinishedLotQueryData{
+			LotID:    app.LotID(lot.ID),
+			OwnerID:  app.UserID(lot.OwnerID),
+			WinnerID: app.UserID(lot.WinnerID),
+		})
+	}
+	return res, nil
+}
+
+func (s *lotQueryService) FindUserData(userID app.UserID) (*app.UserDataQueryData, error) {
+	const lotsQuery = `
+			SELECT id, owner_id, description, status, start_price, buy_it_now_price, weight, end_time, created_at FROM lot
+			WHERE owner_id = $1 ORDER BY created_at
+		`
+	const bidsQuery = `SELECT lot_id, user_id, amount, created_at FROM bid WHERE user_id = $1 ORDER BY created_at`
+
+	var lots []*sqlxLot
+	err := s.client.Select(&lots, lotsQuery, string(userID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var bids []*sqlxBid
+	err = s.client.Select(&bids, bidsQuery, string(userID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	res := app.UserDataQueryData{
+		Lots: make([]app.Lot, 0, len(lots)),
+		Bids: make([]app.Bid, 0, len(bids)),
+	}
+	for _, lot := range lots {
+		res.Lots = append(res.Lots, sqlxLotToLot(lot))
+	}
+	for _, bid := range bids {
+		res.Bids = append(res.Bids, sqlxBidToBid(bid))
+	}
+	return &res, nil
+}
+
 func (s *lotQueryService) lotBidsMap(lotIDs []string) (map[string][]app.BidQueryData, error) {
-	const sqlQuery = `SELECT lot_id, user_id, amount, created_at FROM bid WHERE lot_id IN (?)`
+	const sqlQuery = `
+			SELECT b.lot_id, b.user_id, b.amount, b.created_at, u.login AS user_login FROM bid AS b
+			LEFT JOIN user_login AS u ON u.user_id = b.user_id
+			WHERE b.lot_id IN (?)
+		`
 
 	query, params, err := sqlx.In(sqlQuery, lotIDs)
 	if err != nil {
@@ -165,7 +323,7 @@ func (s *lotQueryService) lotBidsMap(lotIDs []string) (map[string][]app.BidQuery
 	}
 	query = sqlx.Rebind(sqlx.DOLLAR, query)
 
-	var bids []*sqlxBid
+	var bids []*sqlxBidQueryData
 	err = s.client.Select(&bids, query, params...)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -173,13 +331,13 @@ func (s *lotQueryService) lotBidsMap(lotIDs []string) (map[string][]app.BidQuery
 
 	res := make(map[string][]app.BidQueryData)
 	for _, bid := range bids {
-		userLogin, err := s.userClient.GetUserLogin(app.UserID(bid.UserID))
+		userLogin, err := s.userLogin(bid.UserID, bid.UserLogin)
 		if err != nil {
 			return nil, err
 		}
 
 		res[bid.LotID] = append(res[bid.LotID], app.BidQueryData{
-			Bid:       sqlxBidToBid(bid),
+			Bid:       sqlxBidToBid(&bid.sqlxBid),
 			UserLogin: userLogin,
 		})
 	}
@@ -187,7 +345,7 @@ func (s *lotQueryService) lotBidsMap(lotIDs []string) (map[string][]app.BidQuery
 }
 
 func (s *lotQueryService) toLotQueryData(lot *sqlxLotQueryData) (app.LotQueryData, error) {
-	ownerLogin, err := s.userClient.GetUserLogin(app.UserID(lot.OwnerID))
+	ownerLogin, err := s.userLogin(lot.OwnerID, lot.OwnerLogin)
 	if err != nil {
 		return app.LotQueryData{}, err
 	}
@@ -197,6 +355,7 @@ func (s *lotQueryService) toLotQueryData(lot *sqlxLotQueryData) (app.LotQueryDat
 			OwnerID:      app.UserID(lot.OwnerID),
 			Description:  lot.Description,
 			StartPrice:   app.AmountFromRawValue(lot.StartPrice),
+			Weight:       lot.Weight,
 			Status:       app.LotStatus(lot.Status),
 			EndTime:      lot.EndTime,
 			CreationTime: lot.CreationTime,
@@ -218,6 +377,15 @@ func (s *lotQueryService) toLotQueryData(lot *sqlxLotQueryData) (app.LotQueryDat
 	return data, nil
 }
 
+// userLogin - login is taken from read model updated by user.profile_updated events,
+// user service is called only for users whose profile wasn't updated since read model was introduced
+func (s *lotQueryService) userLogin(userID string, login sql.NullString) (string, error) {
+	if login.Valid {
+		return login.String, nil
+	}
+	return s.userClient.GetUserLogin(app.UserID(userID))
+}
+
 type sqlxLotQueryData struct {
 	ID            string         `db:"id"`
 	OwnerID       string         `db:"owner_id"`
@@ -225,8 +393,39 @@ type sqlxLotQueryData struct {
 	Status        string         `db:"status"`
 	StartPrice    uint64         `db:"start_price"`
 	BuyItNowPrice sql.NullInt64  `db:"buy_it_now_price"`
+	Weight        int            `db:"weight"`
 	EndTime       time.Time      `db:"end_time"`
 	CreationTime  time.Time      `db:"created_at"`
 	LastBidderID  sql.NullString `db:"last_bidder_id"`
 	LastBidAmount sql.NullInt64  `db:"last_bid_amount"`
+	OwnerLogin    sql.NullString `db:"owner_login"`
+}
+
+type sqlxBidQueryData struct {
+	sqlxBid
+	UserLogin sql.NullString `db:"user_login"`
+}
+
+type sqlxFinishedLot struct {
+	ID       string `db:"id"`
+	OwnerID  string `db:"owner_id"`
+	WinnerID string `db:"winner_id"`
+}
+
+type sqlxSellerStats struct {
+	ActiveLotCount     int `db:"active_lot_count"`
+	SoldLotCount       int `db:"sold_lot_count"`
+	CompletedSaleCount int `db:"completed_sale_count"`
+}
+
+type sqlxUserActivity struct {
+	ActiveLotCount int `db:"active_lot_count"`
+	ActiveBidCount int `db:"active_bid_count"`
+}
+
+type sqlxLotHighBidQueryData struct {
+	ID            string         `db:"id"`
+	Status        string         `db:"status"`
+	HighBidderID  sql.NullString `db:"high_bidder_id"`
+	HighBidAmount sql.NullInt64  `db:"high_bid_amount"`
 }
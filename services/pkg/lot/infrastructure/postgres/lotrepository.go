@@ -19,7 +19,7 @@ type lotRepository struct {
 }
 
 func (repo *lotRepository) FindByID(id app.LotID) (*app.Lot, error) {
-	const query = `SELECT id, owner_id, description, status, start_price, buy_it_now_price, end_time, created_at FROM lot WHERE id = $1`
+	const query = `SELECT id, owner_id, description, status, start_price, buy_it_now_price, weight, end_time, created_at FROM lot WHERE id = $1`
 
 	var lot sqlxLot
 	err := repo.client.Get(&lot, query, string(id))
@@ -35,7 +35,7 @@ func (repo *lotRepository) FindByID(id app.LotID) (*app.Lot, error) {
 
 func (repo *lotRepository) FindActiveCompletedLots() ([]app.Lot, error) {
 	const query = `
-			SELECT id, owner_id, description, status, start_price, buy_it_now_price, end_time, created_at FROM lot
+			SELECT id, owner_id, description, status, start_price, buy_it_now_price, weight, end_time, created_at FROM lot
 			WHERE status = $1 AND end_time < $2
 		`
 
@@ -54,8 +54,8 @@ func (repo *lotRepository) FindActiveCompletedLots() ([]app.Lot, error) {
 
 func (repo *lotRepository) Store(lot *app.Lot) error {
 	const query = `
-			INSERT INTO lot (id, owner_id, description, status, start_price, buy_it_now_price, end_time, created_at)
-			VALUES (:id, :owner_id, :description, :status, :start_price, :buy_it_now_price, :end_time, :created_at)
+			INSERT INTO lot (id, owner_id, description, status, start_price, buy_it_now_price, weight, end_time, created_at)
+			VALUES (:id, :owner_id, :description, :status, :start_price, :buy_it_now_price, :weight, :end_time, :created_at)
 			ON CONFLICT (id) DO UPDATE SET
 				description = excluded.description,
 				status = excluded.status,
@@ -68,6 +68,7 @@ func (repo *lotRepository) Store(lot *app.Lot) error {
 		Description:  lot.Description,
 		Status:       string(lot.Status),
 		StartPrice:   lot.StartPrice.RawValue(),
+		Weight:       lot.Weight,
 		EndTime:      lot.EndTime,
 		CreationTime: lot.CreationTime,
 	}
@@ -93,6 +94,7 @@ func sqlxLotToLot(lot *sqlxLot) app.Lot {
 		Description:   lot.Description,
 		StartPrice:    app.AmountFromRawValue(lot.StartPrice),
 		BuyItNowPrice: buyItNowPrice,
+		Weight:        lot.Weight,
 		Status:        app.LotStatus(lot.Status),
 		EndTime:       lot.EndTime,
 		CreationTime:  lot.CreationTime,
@@ -106,6 +108,7 @@ type sqlxLot struct {
 	Status        string        `db:"status"`
 	StartPrice    uint64        `db:"start_price"`
 	BuyItNowPrice sql.NullInt64 `db:"buy_it_now_price"`
+	Weight        int           `db:"weight"`
 	EndTime       time.Time     `db:"end_time"`
 	CreationTime  time.Time     `db:"created_at"`
 }
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/lot/app"
+
+	"github.com/pkg/errors"
+)
+
+func NewShippingOptionRepository(client postgres.Client) app.ShippingOptionRepository {
+	return &shippingOptionRepository{client: client}
+}
+
+type shippingOptionRepository struct {
+	client postgres.Client
+}
+
+func (repo *shippingOptionRepository) FindAllByLotID(lotID app.LotID) ([]app.ShippingOption, error) {
+	const optionsQuery = `SELECT lot_id, name, type, price, position FROM lot_shipping_option WHERE lot_id = $1 ORDER BY position`
+	const ratesQuery = `SELECT lot_id, option_name, zone, max_weight, price FROM lot_shipping_rate WHERE lot_id = $1 ORDER BY zone, max_weight`
+
+	var options []sqlxShippingOption
+	err := repo.client.Select(&options, optionsQuery, string(lotID))
+	if err != nil || len(options) == 0 {
+		return nil, errors.WithStack(err)
+	}
+	var rates []sqlxShippingRate
+	err = repo.client.Select(&rates, ratesQuery, string(lotID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	optionRates := make(map[string][]app.ShippingRate)
+	for _, rate := range rates {
+		optionRates[rate.OptionName] = append(optionRates[rate.OptionName], app.ShippingRate{
+			Zone:      rate.Zone,
+			MaxWeight: rate.MaxWeight,
+			Price:     app.AmountFromRawValue(rate.Price),
+		})
+	}
+
+	res := make([]app.ShippingOption, 0, len(options))
+	for _, option := range options {
+		res = append(res, app.ShippingOption{
+			Name:  option.Name,
+			Type:  app.ShippingOptionType(option.Type),
+			Price: app.AmountFromRawValue(option.Price),
+			Rates: optionRates[option.Name],
+		})
+	}
+	return res, nil
+}
+
+func (repo *shippingOptionRepository) StoreAll(lotID app.LotID, options []app.ShippingOption) error {
+	const deleteOptionsQuery = `DELETE FROM lot_shipping_option WHERE lot_id = $1`
+	const deleteRatesQuery = `DELETE FROM lot_shipping_rate WHERE lot_id = $1`
+	const insertOptionQuery = `
+			INSERT INTO lot_shipping_option (lot_id, name, type, price, position)
+			VALUES (:lot_id, :name, :type, :price, :position)
+		`
+	const insertRateQuery = `
+			INSERT INTO lot_shipping_rate (lot_id, option_name, zone, max_weight, price)
+			VALUES (:lot_id, :option_name, :zone, :max_weight, :price)
+		`
+
+	_, err := repo.client.Exec(deleteRatesQuery, string(lotID))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = repo.client.Exec(deleteOptionsQuery, string(lotID))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i, option := range options {
+		optionx := sqlxShippingOption{
+			LotID:    string(lotID),
+			Name:     option.Name,
+			Type:     string(option.Type),
+			Price:    option.Price.RawValue(),
+			Position: i,
+		}
+		_, err = repo.client.NamedExec(insertOptionQuery, &optionx)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		for _, rate := range option.Rates {
+			ratex := sqlxShippingRate{
+				LotID:      string(lotID),
+				OptionName: option.Name,
+				Zone:       rate.Zone,
+				MaxWeight:  rate.MaxWeight,
+				Price:      rate.Price.RawValue(),
+			}
+			_, err = repo.client.NamedExec(insertRateQuery, &ratex)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+	return nil
+}
+
+type sqlxShippingOption struct {
+	LotID    string `db:"lot_id"`
+	Name     string `db:"name"`
+	Type     string `db:"type"`
+	Price    uint64 `db:"price"`
+	Position int    `db:"position"`
+}
+
+type sqlxShippingRate struct {
+	LotID      string `db:"lot_id"`
+	OptionName string `db:"option_name"`
+	Zone       string `db:"zone"`
+	MaxWeight  int    `db:"max_weight"`
+	Price      uint64 `db:"price"`
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/lot/app"
+
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+func NewUnverifiedUserRepository(client postgres.Client) app.UnverifiedUserRepository {
+	return &unverifiedUserRepository{client: client}
+}
+
+type unverifiedUserRepository struct {
+	client postgres.Client
+}
+
+func (repo *unverifiedUserRepository) IsUnverified(userID app.UserID) (bool, error) {
+	const query = `SELECT user_id FROM unverified_user WHERE user_id = $1`
+
+	var resID string
+	err := repo.client.Get(&resID, query, string(userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (repo *unverifiedUserRepository) Add(userID app.UserID) error {
+	const query = `INSERT INTO unverified_user (user_id) VALUES ($1) ON CONFLICT DO NOTHING`
+	_, err := repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
+
+func (repo *unverifiedUserRepository) Remove(userID app.UserID) error {
+	const query = `DELETE FROM unverified_user WHERE user_id = $1`
+	_, err := repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
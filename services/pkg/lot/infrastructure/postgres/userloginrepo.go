@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/lot/app"
+
+	"github.com/pkg/errors"
+)
+
+func NewUserLoginRepository(client postgres.Client) app.UserLoginRepository {
+	return &userLoginRepository{client: client}
+}
+
+type userLoginRepository struct {
+	client postgres.Client
+}
+
+func (repo *userLoginRepository) Store(userID app.UserID, login string) error {
+	const query = `
+			INSERT INTO user_login (user_id, login) VALUES ($1, $2)
+			ON CONFLICT (user_id) DO UPDATE SET login = excluded.login
+		`
+	_, err := repo.client.Exec(query, string(userID), login)
+	return errors.WithStack(err)
+}
@@ -8,9 +8,11 @@ import (
 
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,12 +27,19 @@ const PathPrefix = "/api/v1/"
 const PathPrefixInternal = "/internal/api/v1/"
 
 const (
-	createLotEndpoint           = PathPrefix + "lot"
-	createBidEndpoint           = PathPrefix + "lot/{id}/bid"
-	lotsEndpoint                = PathPrefix + "lots"
-	myLotsEndpoint              = PathPrefix + "lots/my"
-	specificLotEndpoint         = PathPrefix + "lot/{id}"
-	internalSpecificLotEndpoint = PathPrefixInternal + "lot/{id}"
+	createLotEndpoint            = PathPrefix + "lot"
+	createBidEndpoint            = PathPrefix + "lot/{id}/bid"
+	lotFeesEndpoint              = PathPrefix + "lot/fees"
+	lotsEndpoint                 = PathPrefix + "lots"
+	myLotsEndpoint               = PathPrefix + "lots/my"
+	specificLotEndpoint          = PathPrefix + "lot/{id}"
+	adminCloseLotEndpoint        = PathPrefix + "admin/lot/{id}/close"
+	internalSpecificLotEndpoint  = PathPrefixInternal + "lot/{id}"
+	internalLotHighBidEndpoint   = PathPrefixInternal + "lot/{id}/highbid"
+	internalFinishedLotsEndpoint = PathPrefixInternal + "lots/finished"
+	internalUserExportEndpoint   = PathPrefixInternal + "user/{id}/export"
+	internalSellerStatsEndpoint  = PathPrefixInternal + "user/{id}/seller-stats"
+	internalUserDeletionEndpoint = PathPrefixInternal + "user/{id}/deletion"
 )
 
 const (
@@ -45,8 +54,18 @@ const (
 	errorCodeInvalidBidAmount     = 8
 	errorCodeInvalidAmount        = 9
 	errorBidOnOwnLot              = 10
+	errorListingFeePaymentFailed  = 11
+	errorPaymentRejectedByRisk    = 12
+	errorReasonRequired           = 13
+	errorPermissionDenied         = 14
+	errorEmailNotVerified         = 15
+	errorInvalidShippingOption    = 16
+	errorUserDeleted              = 17
 )
 
+// ownerProfilePathTpl - public seller profile of user service as routed by ingress
+const ownerProfilePathTpl = "/user/api/v1/users/%s"
+
 const authTokenHeader = "X-Auth-Token"
 const requestIDHeader = "X-Request-ID"
 
@@ -71,29 +90,45 @@ func (e endpointLabelCollector) EndpointLabelForURI(uri string) string {
 		if r, _ := regexp.Compile("^" + PathPrefix + "lots[?]"); r.MatchString(uri) {
 			return lotsEndpoint
 		}
+		if r, _ := regexp.Compile("^" + PathPrefix + "admin/lot/[a-f0-9-]+/close$"); r.MatchString(uri) {
+			return adminCloseLotEndpoint
+		}
 	} else if strings.HasPrefix(uri, PathPrefixInternal) {
-		r, _ := regexp.Compile("^" + PathPrefixInternal + "lot/[a-f0-9-]+$")
-		if r.MatchString(uri) {
+		if r, _ := regexp.Compile("^" + PathPrefixInternal + "lot/[a-f0-9-]+$"); r.MatchString(uri) {
 			return internalSpecificLotEndpoint
 		}
+		if r, _ := regexp.Compile("^" + PathPrefixInternal + "lot/[a-f0-9-]+/highbid$"); r.MatchString(uri) {
+			return internalLotHighBidEndpoint
+		}
+		if r, _ := regexp.Compile("^" + PathPrefixInternal + "user/[a-f0-9-]+/(export|seller-stats|deletion)$"); r.MatchString(uri) {
+			return PathPrefixInternal + "user/{id}/" + r.FindStringSubmatch(uri)[1]
+		}
 	}
 	return uri
 }
 
-func NewServer(lotService app.LotService, lotQueryService app.LotQueryService, tokenParser jwtauth.TokenParser, logger *logrus.Logger) *Server {
+func NewServer(
+	lotService app.LotService,
+	lotQueryService app.LotQueryService,
+	userDeletionService app.UserDeletionService,
+	tokenParser jwtauth.TokenParser,
+	logger *logrus.Logger,
+) *Server {
 	return &Server{
-		lotService:      lotService,
-		lotQueryService: lotQueryService,
-		tokenParser:     tokenParser,
-		logger:          logger,
+		lotService:          lotService,
+		lotQueryService:     lotQueryService,
+		userDeletionService: userDeletionService,
+		tokenParser:         tokenParser,
+		logger:              logger,
 	}
 }
 
 type Server struct {
-	lotService      app.LotService
-	lotQueryService app.LotQueryService
-	tokenParser     jwtauth.TokenParser
-	logger          *logrus.Logger
+	lotService          app.LotService
+	lotQueryService     app.LotQueryService
+	userDeletionService app.UserDeletionService
+	tokenParser         jwtauth.TokenParser
+	logger              *logrus.Logger
 }
 
 func (s *Server) MakeHandler() http.Handler {
@@ -101,9 +136,12 @@ func (s *Server) MakeHandler() http.Handler {
 
 	router.Methods(http.MethodPost).Path(createLotEndpoint).Handler(s.makeHandlerFunc(s.createLotHandler))
 	router.Methods(http.MethodPost).Path(createBidEndpoint).Handler(s.makeHandlerFunc(s.createBidHandler))
+	router.Methods(http.MethodGet).Path(lotFeesEndpoint).Handler(s.makeHandlerFunc(s.lotFeesHandler))
 	router.Methods(http.MethodGet).Path(specificLotEndpoint).Handler(s.makeHandlerFunc(s.getLotHandler))
 	router.Methods(http.MethodGet).Path(lotsEndpoint).Handler(s.makeHandlerFunc(s.findLotsHandler))
 	router.Methods(http.MethodGet).Path(myLotsEndpoint).Handler(s.makeHandlerFunc(s.myLotsHandler))
+	router.Methods(http.MethodPost).Path(adminCloseLotEndpoint).Handler(s.makeHandlerFunc(
+		jwtauth.RequirePermission(s.extractAuthorizationData, jwtauth.PermissionCloseLot, s.closeLotHandler)))
 
 	return router
 }
@@ -111,6 +149,11 @@ func (s *Server) MakeHandler() http.Handler {
 func (s *Server) MakeInternalHandler() http.Handler {
 	router := mux.NewRouter()
 	router.Methods(http.MethodGet).Path(internalSpecificLotEndpoint).Handler(s.makeHandlerFunc(s.getLotInternalHandler))
+	router.Methods(http.MethodGet).Path(internalLotHighBidEndpoint).Handler(s.makeHandlerFunc(s.getLotHighBidInternalHandler))
+	router.Methods(http.MethodGet).Path(internalFinishedLotsEndpoint).Handler(s.makeHandlerFunc(s.findFinishedLotsInternalHandler))
+	router.Methods(http.MethodGet).Path(internalUserExportEndpoint).Handler(s.makeHandlerFunc(s.exportUserDataInternalHandler))
+	router.Methods(http.MethodGet).Path(internalSellerStatsEndpoint).Handler(s.makeHandlerFunc(s.getSellerStatsInternalHandler))
+	router.Methods(http.MethodPost).Path(internalUserDeletionEndpoint).Handler(s.makeHandlerFunc(s.startUserDeletionInternalHandler))
 	return router
 }
 
@@ -185,6 +228,121 @@ func (s *Server) getLotInternalHandler(w http.ResponseWriter, r *http.Request) e
 	return nil
 }
 
+func (s *Server) findFinishedLotsInternalHandler(w http.ResponseWriter, _ *http.Request) error {
+	lots, err := s.lotQueryService.FindAllFinished()
+	if err != nil {
+		return err
+	}
+	res := make([]finishedLotInfo, 0, len(lots))
+	for _, lot := range lots {
+		res = append(res, finishedLotInfo{
+			ID:       string(lot.LotID),
+			OwnerID:  string(lot.OwnerID),
+			WinnerID: string(lot.WinnerID),
+		})
+	}
+	writeResponse(w, res)
+	return nil
+}
+
+func (s *Server) getLotHighBidInternalHandler(w http.ResponseWriter, r *http.Request) error {
+	lotID, err := getIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	highBid, err := s.lotQueryService.GetHighBid(lotID)
+	if err != nil {
+		return err
+	}
+	info := lotHighBidInfo{
+		ID:     string(highBid.LotID),
+		Status: string(highBid.Status),
+	}
+	if highBid.HighBidderID != nil {
+		info.HighBidderID = string(*highBid.HighBidderID)
+	}
+	if highBid.HighBidAmount != nil {
+		info.HighBidAmount = (*highBid.HighBidAmount).Value()
+	}
+	writeResponse(w, info)
+	return nil
+}
+
+func (s *Server) startUserDeletionInternalHandler(w http.ResponseWriter, r *http.Request) error {
+	userID, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	activity, err := s.userDeletionService.StartUserDeletion(userID)
+	if err != nil {
+		return err
+	}
+	writeResponse(w, userActivityInfo{
+		ActiveLotCount: activity.ActiveLotCount,
+		ActiveBidCount: activity.ActiveBidCount,
+	})
+	return nil
+}
+
+func (s *Server) getSellerStatsInternalHandler(w http.ResponseWriter, r *http.Request) error {
+	userID, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	stats, err := s.lotQueryService.GetSellerStats(userID)
+	if err != nil {
+		return err
+	}
+	writeResponse(w, sellerStatsInfo{
+		ActiveLotCount:     stats.ActiveLotCount,
+		SoldLotCount:       stats.SoldLotCount,
+		CompletedSaleCount: stats.CompletedSaleCount,
+	})
+	return nil
+}
+
+func (s *Server) exportUserDataInternalHandler(w http.ResponseWriter, r *http.Request) error {
+	userID, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	data, err := s.lotQueryService.FindUserData(userID)
+	if err != nil {
+		return err
+	}
+	info := userDataInfo{
+		Lots: make([]userLotInfo, 0, len(data.Lots)),
+		Bids: make([]userBidInfo, 0, len(data.Bids)),
+	}
+	for _, lot := range data.Lots {
+		lotData := userLotInfo{
+			ID:           string(lot.ID),
+			Description:  lot.Description,
+			EndTime:      lot.EndTime.Format(time.RFC3339),
+			StartPrice:   lot.StartPrice.Value(),
+			Status:       string(lot.Status),
+			CreationDate: lot.CreationTime.Format(time.RFC3339),
+		}
+		if lot.BuyItNowPrice != nil {
+			lotData.BuyItNowPrice = (*lot.BuyItNowPrice).Value()
+		}
+		info.Lots = append(info.Lots, lotData)
+	}
+	for _, bid := range data.Bids {
+		info.Bids = append(info.Bids, userBidInfo{
+			LotID:        string(bid.LotID),
+			Amount:       bid.Amount.Value(),
+			CreationDate: bid.CreationTime.Format(time.RFC3339),
+		})
+	}
+	writeResponse(w, info)
+	return nil
+}
+
 func (s *Server) findLotsHandler(w http.ResponseWriter, r *http.Request) error {
 	tokenData, err := s.extractAuthorizationData(r)
 	if err != nil {
@@ -302,6 +460,7 @@ func (s *Server) createLotHandler(w http.ResponseWriter, r *http.Request) error
 		info.StartPrice,
 		endTime,
 		buyItNowPrice,
+		toLotShippingSpec(info),
 	)
 	if err != nil {
 		return err
@@ -311,6 +470,42 @@ func (s *Server) createLotHandler(w http.ResponseWriter, r *http.Request) error
 	return nil
 }
 
+func (s *Server) lotFeesHandler(w http.ResponseWriter, r *http.Request) error {
+	_, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	startPrice, err := strconv.ParseFloat(query.Get("startPrice"), 64)
+	if err != nil {
+		return errors.Wrap(app.ErrNegativeAmount, err.Error())
+	}
+	var buyItNowPrice *float64
+	if strPrice := query.Get("buyItNowPrice"); strPrice != "" {
+		price, err := strconv.ParseFloat(strPrice, 64)
+		if err != nil {
+			return errors.Wrap(app.ErrNegativeAmount, err.Error())
+		}
+		buyItNowPrice = &price
+	}
+
+	preview, err := s.lotService.PreviewFees(startPrice, buyItNowPrice)
+	if err != nil {
+		return err
+	}
+	info := lotFeesInfo{
+		ListingFee:    preview.ListingFee.Value(),
+		FinalValueFee: preview.FinalValueFee.Value(),
+	}
+	if preview.BuyItNowFinalValueFee != nil {
+		fee := (*preview.BuyItNowFinalValueFee).Value()
+		info.BuyItNowFinalValueFee = &fee
+	}
+	writeResponse(w, info)
+	return nil
+}
+
 func (s *Server) createBidHandler(w http.ResponseWriter, r *http.Request) error {
 	tokenData, err := s.extractAuthorizationData(r)
 	if err != nil {
@@ -347,6 +542,36 @@ func (s *Server) createBidHandler(w http.ResponseWriter, r *http.Request) error
 	return nil
 }
 
+func (s *Server) closeLotHandler(w http.ResponseWriter, r *http.Request, tokenData jwtauth.TokenData) error {
+	lotID, err := getIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	var info closeLotInfo
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &info); err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = s.lotService.CloseLot(lotID, info.Reason)
+	if err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"lot_id":   lotID,
+		"actor_id": tokenData.UserID(),
+		"reason":   info.Reason,
+	}).Info("lot closed by moderator")
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
 func (s *Server) extractAuthorizationData(r *http.Request) (jwtauth.TokenData, error) {
 	token := r.Header.Get(authTokenHeader)
 	if token == "" {
@@ -359,6 +584,9 @@ func (s *Server) extractAuthorizationData(r *http.Request) (jwtauth.TokenData, e
 	if err = uuid.ValidateUUID(tokenData.UserID()); err != nil {
 		return nil, errors.WithStack(err)
 	}
+	if err = jwtauth.CheckScope(tokenData, r, jwtauth.ScopeLotRead, jwtauth.ScopeLotWrite); err != nil {
+		return nil, errors.Wrap(errForbidden, err.Error())
+	}
 	return tokenData, nil
 }
 
@@ -383,6 +611,17 @@ func getIDFromRequest(r *http.Request) (app.LotID, error) {
 	return app.LotID(id), nil
 }
 
+func getUserIDFromRequest(r *http.Request) (app.UserID, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return "", errors.New("id param required")
+	}
+	if err := uuid.ValidateUUID(id); err != nil {
+		return "", err
+	}
+	return app.UserID(id), nil
+}
+
 func writeResponse(w http.ResponseWriter, response interface{}) {
 	js, err := json.Marshal(response)
 	if err != nil {
@@ -427,6 +666,27 @@ func writeErrorResponse(w http.ResponseWriter, err error) {
 	case app.ErrBidOnOwnLot:
 		info.Code = errorBidOnOwnLot
 		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrPaymentRejectedByRiskRules:
+		info.Code = errorPaymentRejectedByRisk
+		w.WriteHeader(http.StatusForbidden)
+	case app.ErrListingFeePaymentFailed:
+		info.Code = errorListingFeePaymentFailed
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrReasonRequired:
+		info.Code = errorReasonRequired
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrEmailNotVerified:
+		info.Code = errorEmailNotVerified
+		w.WriteHeader(http.StatusForbidden)
+	case app.ErrInvalidShippingOption:
+		info.Code = errorInvalidShippingOption
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrUserDeleted:
+		info.Code = errorUserDeleted
+		w.WriteHeader(http.StatusForbidden)
+	case jwtauth.ErrPermissionDenied:
+		info.Code = errorPermissionDenied
+		w.WriteHeader(http.StatusForbidden)
 	case errForbidden:
 		w.WriteHeader(http.StatusForbidden)
 	default:
@@ -438,16 +698,18 @@ func writeErrorResponse(w http.ResponseWriter, err error) {
 
 func toLotInfo(lot app.LotQueryData) lotInfo {
 	info := lotInfo{
-		ID:            string(lot.ID),
-		Description:   lot.Description,
-		EndTime:       lot.EndTime.Format(time.RFC3339),
-		StartPrice:    lot.StartPrice.Value(),
-		Status:        string(lot.Status),
-		OwnerID:       string(lot.OwnerID),
-		OwnerLogin:    lot.OwnerLogin,
-		CreationDate:  lot.CreationTime.Format(time.RFC3339),
-		LastBidAmount: 0,
-		LastBidderID:  "",
+		ID:               string(lot.ID),
+		Description:      lot.Description,
+		EndTime:          lot.EndTime.Format(time.RFC3339),
+		StartPrice:       lot.StartPrice.Value(),
+		Status:           string(lot.Status),
+		OwnerID:          string(lot.OwnerID),
+		OwnerLogin:       lot.OwnerLogin,
+		OwnerProfilePath: fmt.Sprintf(ownerProfilePathTpl, lot.OwnerID),
+		CreationDate:     lot.CreationTime.Format(time.RFC3339),
+		Weight:           lot.Weight,
+		LastBidAmount:    0,
+		LastBidderID:     "",
 	}
 	if lot.BuyItNowPrice != nil {
 		info.BuyItNowPrice = (*lot.BuyItNowPrice).Value()
@@ -458,26 +720,84 @@ func toLotInfo(lot app.LotQueryData) lotInfo {
 	if lot.LastBidderID != nil {
 		info.LastBidderID = string(*lot.LastBidderID)
 	}
+	for _, option := range lot.ShippingOptions {
+		optionInfo := shippingOptionInfo{
+			Name:  option.Name,
+			Type:  string(option.Type),
+			Price: option.Price.Value(),
+		}
+		for _, rate := range option.Rates {
+			optionInfo.Rates = append(optionInfo.Rates, shippingRateInfo{
+				Zone:      rate.Zone,
+				MaxWeight: rate.MaxWeight,
+				Price:     rate.Price.Value(),
+			})
+		}
+		info.ShippingOptions = append(info.ShippingOptions, optionInfo)
+	}
 	return info
 }
 
+func toLotShippingSpec(info createLotInfo) app.LotShippingSpec {
+	spec := app.LotShippingSpec{Weight: info.Weight}
+	for _, option := range info.ShippingOptions {
+		optionSpec := app.ShippingOptionSpec{
+			Name:  option.Name,
+			Type:  option.Type,
+			Price: option.Price,
+		}
+		for _, rate := range option.Rates {
+			optionSpec.Rates = append(optionSpec.Rates, app.ShippingRateSpec{
+				Zone:      rate.Zone,
+				MaxWeight: rate.MaxWeight,
+				Price:     rate.Price,
+			})
+		}
+		spec.Options = append(spec.Options, optionSpec)
+	}
+	return spec
+}
+
 type errorInfo struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
 type lotInfo struct {
+	ID               string               `json:"id"`
+	Description      string               `json:"description"`
+	EndTime          string               `json:"endTime"`
+	StartPrice       float64              `json:"startPrice"`
+	BuyItNowPrice    float64              `json:"buyItNowPrice,omitempty"`
+	Status           string               `json:"status"`
+	OwnerID          string               `json:"ownerId"`
+	OwnerLogin       string               `json:"ownerLogin"`
+	OwnerProfilePath string               `json:"ownerProfilePath"`
+	CreationDate     string               `json:"creationDate"`
+	LastBidAmount    float64              `json:"lastBidAmount,omitempty"`
+	LastBidderID     string               `json:"lastBidderId,omitempty"`
+	Weight           int                  `json:"weight,omitempty"`
+	ShippingOptions  []shippingOptionInfo `json:"shippingOptions,omitempty"`
+}
+
+type shippingOptionInfo struct {
+	Name  string             `json:"name"`
+	Type  string             `json:"type"`
+	Price float64            `json:"price,omitempty"`
+	Rates []shippingRateInfo `json:"rates,omitempty"`
+}
+
+type shippingRateInfo struct {
+	Zone      string  `json:"zone"`
+	MaxWeight int     `json:"maxWeight"`
+	Price     float64 `json:"price"`
+}
+
+type lotHighBidInfo struct {
 	ID            string  `json:"id"`
-	Description   string  `json:"description"`
-	EndTime       string  `json:"endTime"`
-	StartPrice    float64 `json:"startPrice"`
-	BuyItNowPrice float64 `json:"buyItNowPrice,omitempty"`
 	Status        string  `json:"status"`
-	OwnerID       string  `json:"ownerId"`
-	OwnerLogin    string  `json:"ownerLogin"`
-	CreationDate  string  `json:"creationDate"`
-	LastBidAmount float64 `json:"lastBidAmount,omitempty"`
-	LastBidderID  string  `json:"lastBidderId,omitempty"`
+	HighBidderID  string  `json:"highBidderId,omitempty"`
+	HighBidAmount float64 `json:"highBidAmount,omitempty"`
 }
 
 type bidInfo struct {
@@ -498,11 +818,55 @@ type lotExInfo struct {
 	Bids          []bidInfo `json:"bids"`
 }
 
-type createLotInfo struct {
+type userLotInfo struct {
+	ID            string  `json:"id"`
 	Description   string  `json:"description"`
 	EndTime       string  `json:"endTime"`
 	StartPrice    float64 `json:"startPrice"`
 	BuyItNowPrice float64 `json:"buyItNowPrice,omitempty"`
+	Status        string  `json:"status"`
+	CreationDate  string  `json:"creationDate"`
+}
+
+type userBidInfo struct {
+	LotID        string  `json:"lotId"`
+	Amount       float64 `json:"amount"`
+	CreationDate string  `json:"creationDate"`
+}
+
+type finishedLotInfo struct {
+	ID       string `json:"id"`
+	OwnerID  string `json:"ownerId"`
+	WinnerID string `json:"winnerId"`
+}
+
+type userActivityInfo struct {
+	ActiveLotCount int `json:"activeLotCount"`
+	ActiveBidCount int `json:"activeBidCount"`
+}
+
+type sellerStatsInfo struct {
+	ActiveLotCount     int `json:"activeLotCount"`
+	SoldLotCount       int `json:"soldLotCount"`
+	CompletedSaleCount int `json:"completedSaleCount"`
+}
+
+type userDataInfo struct {
+	Lots []userLotInfo `json:"lots"`
+	Bids []userBidInfo `json:"bids"`
+}
+
+type createLotInfo struct {
+	Description     string               `json:"description"`
+	EndTime         string               `json:"endTime"`
+	StartPrice      float64              `json:"startPrice"`
+	BuyItNowPrice   float64              `json:"buyItNowPrice,omitempty"`
+	Weight          int                  `json:"weight,omitempty"`
+	ShippingOptions []shippingOptionInfo `json:"shippingOptions,omitempty"`
+}
+
+type closeLotInfo struct {
+	Reason string `json:"reason"`
 }
 
 type createBidInfo struct {
@@ -512,3 +876,9 @@ type createBidInfo struct {
 type createLotResponse struct {
 	ID string `json:"id"`
 }
+
+type lotFeesInfo struct {
+	ListingFee            float64  `json:"listingFee"`
+	FinalValueFee         float64  `json:"finalValueFee"`
+	BuyItNowFinalValueFee *float64 `json:"buyItNowFinalValueFee,omitempty"`
+}
@@ -0,0 +1,37 @@
+package user
+
+import (
+	"arch-homework/api/userpb"
+	"arch-homework/pkg/common/infrastructure/grpcclient"
+	"arch-homework/pkg/lot/app"
+
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+func NewGRPCClient(conn *grpc.ClientConn) app.UserClient {
+	return &userGRPCClient{
+		client:       userpb.NewUserServiceClient(conn),
+		userLoginMap: make(map[app.UserID]string),
+	}
+}
+
+type userGRPCClient struct {
+	client       userpb.UserServiceClient
+	userLoginMap map[app.UserID]string
+}
+
+func (c *userGRPCClient) GetUserLogin(userID app.UserID) (string, error) {
+	if login, ok := c.userLoginMap[userID]; ok {
+		return login, nil
+	}
+
+	profile, err := c.client.GetUserProfile(context.Background(), &userpb.GetUserProfileRequest{UserId: string(userID)}, grpcclient.Idempotent())
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	c.userLoginMap[userID] = profile.Login
+	return profile.Login, nil
+}
@@ -0,0 +1,12 @@
+package app
+
+import "github.com/pkg/errors"
+
+var ErrPasswordResetTokenNotFound = errors.New("password reset token is expired or already used")
+
+type PasswordResetTokenID string
+
+// AuthClient - raw password reset token isn't passed in events, it is issued by auth service right before sending
+type AuthClient interface {
+	IssuePasswordResetToken(tokenID PasswordResetTokenID) (string, error)
+}
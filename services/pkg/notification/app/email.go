@@ -0,0 +1,8 @@
+package app
+
+type Email string
+
+// EmailSender - sends plain text email
+type EmailSender interface {
+	Send(to Email, subject, body string) error
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"arch-homework/pkg/common/app/integrationevent"
+	"time"
 )
 
 type ProcessedEventRepository interface {
@@ -49,6 +50,56 @@ func NewBidOutbidEvent(lotID LotID, userID UserID) HandledEvent {
 	}
 }
 
+func NewDeliveryDeadlineReminderEvent(lotID LotID, userID UserID, notificationType NotificationType, deadline time.Time) HandledEvent {
+	return deliveryDeadlineReminderEvent{
+		lotID:            lotID,
+		userID:           userID,
+		notificationType: notificationType,
+		deadline:         deadline,
+	}
+}
+
+func NewDeliveryLotCancelledEvent(lotID LotID, lotOwnerID, userID UserID) HandledEvent {
+	return deliveryLotCancelledEvent{
+		lotID:      lotID,
+		lotOwnerID: lotOwnerID,
+		userID:     userID,
+	}
+}
+
+func NewLoginLockedEvent(userID UserID, lockedUntil time.Time) HandledEvent {
+	return loginLockedEvent{
+		userID:      userID,
+		lockedUntil: lockedUntil,
+	}
+}
+
+// NewPasswordResetRequestedEvent - token is passed only by events published before token issue by ID
+func NewPasswordResetRequestedEvent(userID UserID, email Email, tokenID PasswordResetTokenID, token string, expiresAt time.Time) HandledEvent {
+	return passwordResetRequestedEvent{
+		userID:    userID,
+		email:     email,
+		tokenID:   tokenID,
+		token:     token,
+		expiresAt: expiresAt,
+	}
+}
+
+func NewEmailVerificationRequestedEvent(userID UserID, email Email, token string, expiresAt time.Time) HandledEvent {
+	return emailVerificationRequestedEvent{
+		userID:    userID,
+		email:     email,
+		token:     token,
+		expiresAt: expiresAt,
+	}
+}
+
+func NewUserDeletedEvent(userID UserID) HandledEvent {
+	return userDeletedEvent{
+		userID: userID,
+	}
+}
+
 type lotWonEvent struct {
 	lotID      LotID
 	lotOwnerID UserID
@@ -76,3 +127,40 @@ type bidOutbidEvent struct {
 	lotID  LotID
 	userID UserID
 }
+
+type deliveryDeadlineReminderEvent struct {
+	lotID            LotID
+	userID           UserID
+	notificationType NotificationType
+	deadline         time.Time
+}
+
+type deliveryLotCancelledEvent struct {
+	lotID      LotID
+	lotOwnerID UserID
+	userID     UserID
+}
+
+type loginLockedEvent struct {
+	userID      UserID
+	lockedUntil time.Time
+}
+
+type passwordResetRequestedEvent struct {
+	userID    UserID
+	email     Email
+	tokenID   PasswordResetTokenID
+	token     string
+	expiresAt time.Time
+}
+
+type emailVerificationRequestedEvent struct {
+	userID    UserID
+	email     Email
+	token     string
+	expiresAt time.Time
+}
+
+type userDeletedEvent struct {
+	userID UserID
+}
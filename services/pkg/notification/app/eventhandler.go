@@ -2,22 +2,43 @@ package app
 
 import (
 	"arch-homework/pkg/common/app/integrationevent"
+
+	"github.com/pkg/errors"
+
+	"fmt"
+	"net/url"
+	"time"
 )
 
 type IntegrationEventParser interface {
 	ParseIntegrationEvent(event integrationevent.EventData) (HandledEvent, error)
 }
 
-func NewEventHandler(trUnitFactory TransactionalUnitFactory, parser IntegrationEventParser) integrationevent.EventHandler {
+func NewEventHandler(
+	trUnitFactory TransactionalUnitFactory,
+	parser IntegrationEventParser,
+	emailSender EmailSender,
+	authClient AuthClient,
+	passwordResetURL string,
+	emailVerificationURL string,
+) integrationevent.EventHandler {
 	return &eventHandler{
-		trUnitFactory: trUnitFactory,
-		parser:        parser,
+		trUnitFactory:        trUnitFactory,
+		parser:               parser,
+		emailSender:          emailSender,
+		authClient:           authClient,
+		passwordResetURL:     passwordResetURL,
+		emailVerificationURL: emailVerificationURL,
 	}
 }
 
 type eventHandler struct {
-	trUnitFactory TransactionalUnitFactory
-	parser        IntegrationEventParser
+	trUnitFactory        TransactionalUnitFactory
+	parser               IntegrationEventParser
+	emailSender          EmailSender
+	authClient           AuthClient
+	passwordResetURL     string
+	emailVerificationURL string
 }
 
 func (handler *eventHandler) Handle(event integrationevent.EventData) error {
@@ -49,12 +70,66 @@ func (handler *eventHandler) Handle(event integrationevent.EventData) error {
 			return handleLotReceivedEvent(service, e)
 		case bidOutbidEvent:
 			return handleBidOutbidEvent(service, e)
+		case deliveryDeadlineReminderEvent:
+			return service.AddDeadlineReminderNotification(e.notificationType, e.lotID, e.userID, e.deadline)
+		case deliveryLotCancelledEvent:
+			return handleDeliveryLotCancelledEvent(service, e)
+		case loginLockedEvent:
+			return handleLoginLockedEvent(service, e)
+		case passwordResetRequestedEvent:
+			return handler.handlePasswordResetRequestedEvent(service, e)
+		case emailVerificationRequestedEvent:
+			return handler.handleEmailVerificationRequestedEvent(service, e)
+		case userDeletedEvent:
+			return handleUserDeletedEvent(service, e)
 		default:
 			return nil
 		}
 	})
 }
 
+// handlePasswordResetRequestedEvent - email is sent inside transaction, so event is retried if sending failed,
+// token issued on retry replaces the unsent one. Nothing is sent for token which is already used or expired
+func (handler *eventHandler) handlePasswordResetRequestedEvent(service NotificationService, e passwordResetRequestedEvent) error {
+	token := e.token
+	if token == "" {
+		var err error
+		token, err = handler.authClient.IssuePasswordResetToken(e.tokenID)
+		if errors.Cause(err) == ErrPasswordResetTokenNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	err := service.AddPasswordResetRequestedNotification(e.userID)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf(
+		"To reset your password follow the link: %s?token=%s\n\nThe link is valid until %s and can be used only once. If you didn't request password reset, ignore this email.\n",
+		handler.passwordResetURL,
+		url.QueryEscape(token),
+		e.expiresAt.UTC().Format(time.RFC1123),
+	)
+	return handler.emailSender.Send(e.email, "Password reset", body)
+}
+
+func (handler *eventHandler) handleEmailVerificationRequestedEvent(service NotificationService, e emailVerificationRequestedEvent) error {
+	err := service.AddEmailVerificationRequestedNotification(e.userID, e.email)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf(
+		"To confirm your email follow the link: %s?token=%s\n\nThe link is valid until %s.\n",
+		handler.emailVerificationURL,
+		url.QueryEscape(e.token),
+		e.expiresAt.UTC().Format(time.RFC1123),
+	)
+	return handler.emailSender.Send(e.email, "Email verification", body)
+}
+
 func (handler *eventHandler) executeInTransaction(f func(RepositoryProvider) error) (err error) {
 	var trUnit TransactionalUnit
 	trUnit, err = handler.trUnitFactory.NewTransactionalUnit()
@@ -91,3 +166,19 @@ func handleLotReceivedEvent(service NotificationService, e lotReceivedEvent) err
 func handleBidOutbidEvent(service NotificationService, e bidOutbidEvent) error {
 	return service.AddNotification(TypeBidOutbid, e.lotID, e.userID)
 }
+
+func handleDeliveryLotCancelledEvent(service NotificationService, e deliveryLotCancelledEvent) error {
+	err := service.AddNotification(TypeDeliveryCancelled, e.lotID, e.lotOwnerID)
+	if err != nil {
+		return err
+	}
+	return service.AddNotification(TypeDeliveryCancelled, e.lotID, e.userID)
+}
+
+func handleLoginLockedEvent(service NotificationService, e loginLockedEvent) error {
+	return service.AddLoginLockedNotification(e.userID, e.lockedUntil)
+}
+
+func handleUserDeletedEvent(service NotificationService, e userDeletedEvent) error {
+	return service.RemoveAllNotifications(e.userID)
+}
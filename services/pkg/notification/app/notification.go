@@ -12,12 +12,18 @@ type LotID uuid.UUID
 type NotificationType string
 
 const (
-	TypeLotFinished NotificationType = "lotFinished"
-	TypeLotClosed   NotificationType = "lotClosed"
-	TypeLotWon      NotificationType = "lotWon"
-	TypeLotSent     NotificationType = "lotSent"
-	TypeLotReceived NotificationType = "lotReceived"
-	TypeBidOutbid   NotificationType = "bidOutbid"
+	TypeLotFinished                NotificationType = "lotFinished"
+	TypeLotClosed                  NotificationType = "lotClosed"
+	TypeLotWon                     NotificationType = "lotWon"
+	TypeLotSent                    NotificationType = "lotSent"
+	TypeLotReceived                NotificationType = "lotReceived"
+	TypeBidOutbid                  NotificationType = "bidOutbid"
+	TypeShipByReminder             NotificationType = "shipByReminder"
+	TypeConfirmByReminder          NotificationType = "confirmByReminder"
+	TypeDeliveryCancelled          NotificationType = "deliveryCancelled"
+	TypeLoginLocked                NotificationType = "loginLocked"
+	TypePasswordResetRequested     NotificationType = "passwordResetRequested"
+	TypeEmailVerificationRequested NotificationType = "emailVerificationRequested"
 )
 
 type Notification struct {
@@ -31,4 +37,5 @@ type Notification struct {
 type NotificationRepository interface {
 	Store(notification *Notification) error
 	FindAllByUserID(userID UserID) ([]Notification, error)
+	RemoveAllByUserID(userID UserID) error
 }
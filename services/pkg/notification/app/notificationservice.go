@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -14,6 +15,11 @@ func NewNotificationService(repo NotificationRepository) NotificationService {
 
 type NotificationService interface {
 	AddNotification(notificationType NotificationType, lotID LotID, userID UserID) error
+	AddDeadlineReminderNotification(notificationType NotificationType, lotID LotID, userID UserID, deadline time.Time) error
+	AddLoginLockedNotification(userID UserID, lockedUntil time.Time) error
+	AddPasswordResetRequestedNotification(userID UserID) error
+	AddEmailVerificationRequestedNotification(userID UserID, email Email) error
+	RemoveAllNotifications(userID UserID) error
 }
 
 type notificationService struct {
@@ -34,6 +40,56 @@ func (n *notificationService) AddNotification(notificationType NotificationType,
 	return n.repo.Store(&notification)
 }
 
+func (n *notificationService) AddDeadlineReminderNotification(notificationType NotificationType, lotID LotID, userID UserID, deadline time.Time) error {
+	var msg string
+	switch notificationType {
+	case TypeShipByReminder:
+		msg = fmt.Sprintf("Send the lot %s until %s, otherwise the sale will be cancelled", string(lotID), deadline.UTC().Format(time.RFC1123))
+	case TypeConfirmByReminder:
+		msg = fmt.Sprintf("Confirm receipt of the lot %s until %s, delivered lot will be confirmed automatically", string(lotID), deadline.UTC().Format(time.RFC1123))
+	default:
+		return errors.New("unknown deadline reminder type")
+	}
+	notification := Notification{
+		Type:    notificationType,
+		UserID:  userID,
+		LotID:   &lotID,
+		Message: msg,
+	}
+	return n.repo.Store(&notification)
+}
+
+func (n *notificationService) AddLoginLockedNotification(userID UserID, lockedUntil time.Time) error {
+	notification := Notification{
+		Type:    TypeLoginLocked,
+		UserID:  userID,
+		Message: fmt.Sprintf("Your account has been locked until %s after too many failed login attempts. If it wasn't you, change your password", lockedUntil.UTC().Format(time.RFC1123)),
+	}
+	return n.repo.Store(&notification)
+}
+
+func (n *notificationService) AddPasswordResetRequestedNotification(userID UserID) error {
+	notification := Notification{
+		Type:    TypePasswordResetRequested,
+		UserID:  userID,
+		Message: "Password reset has been requested, the link has been sent to your email. If it wasn't you, ignore it",
+	}
+	return n.repo.Store(&notification)
+}
+
+func (n *notificationService) AddEmailVerificationRequestedNotification(userID UserID, email Email) error {
+	notification := Notification{
+		Type:    TypeEmailVerificationRequested,
+		UserID:  userID,
+		Message: fmt.Sprintf("Confirm your email %s, the link has been sent to it", string(email)),
+	}
+	return n.repo.Store(&notification)
+}
+
+func (n *notificationService) RemoveAllNotifications(userID UserID) error {
+	return n.repo.RemoveAllByUserID(userID)
+}
+
 func messageForLot(notificationType NotificationType, lotID LotID) (string, error) {
 	switch notificationType {
 	case TypeLotFinished:
@@ -48,6 +104,8 @@ func messageForLot(notificationType NotificationType, lotID LotID) (string, erro
 		return fmt.Sprintf("Lot %s has been received", string(lotID)), nil
 	case TypeBidOutbid:
 		return fmt.Sprintf("Your bid in the lot %s has been outbid", string(lotID)), nil
+	case TypeDeliveryCancelled:
+		return fmt.Sprintf("Sale of the lot %s has been cancelled, the lot wasn't sent in time", string(lotID)), nil
 	default:
 		return "", errors.New("unknown notification type")
 	}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"arch-homework/pkg/common/infrastructure/httpclient"
+	"arch-homework/pkg/notification/app"
+
+	"github.com/pkg/errors"
+
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const issueResetTokenURLTpl = "/internal/api/v1/password/reset/%s/token"
+
+// errorCodeInvalidResetToken - error code of auth service for expired or used token
+const errorCodeInvalidResetToken = 12
+
+func NewClient(client http.Client, serviceHost string) app.AuthClient {
+	return &authClient{httpClient: httpclient.NewClient(client, serviceHost)}
+}
+
+type authClient struct {
+	httpClient httpclient.Client
+}
+
+func (c *authClient) IssuePasswordResetToken(tokenID app.PasswordResetTokenID) (string, error) {
+	var response resetTokenResponse
+	err := c.httpClient.MakeJSONRequest(nil, &response, http.MethodPost, fmt.Sprintf(issueResetTokenURLTpl, string(tokenID)), nil)
+	if err != nil {
+		if e, ok := errors.Cause(err).(*httpclient.HTTPError); ok && e.StatusCode == http.StatusBadRequest {
+			var info errorInfo
+			if json.Unmarshal([]byte(e.Body), &info) == nil && info.Code == errorCodeInvalidResetToken {
+				return "", errors.WithStack(app.ErrPasswordResetTokenNotFound)
+			}
+		}
+		return "", err
+	}
+	return response.Token, nil
+}
+
+type errorInfo struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type resetTokenResponse struct {
+	Token string `json:"token"`
+}
@@ -0,0 +1,79 @@
+package email
+
+import (
+	"arch-homework/pkg/notification/app"
+
+	"github.com/pkg/errors"
+
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string
+}
+
+func NewSMTPSender(cfg SMTPConfig) (app.EmailSender, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid smtp sender address")
+	}
+	var auth smtp.Auth
+	// local smtp sink accepts emails without authentication
+	if cfg.User != "" {
+		auth = smtp.PlainAuth("", cfg.User, cfg.Password, cfg.Host)
+	}
+	return &smtpSender{
+		addr: net.JoinHostPort(cfg.Host, cfg.Port),
+		auth: auth,
+		from: from,
+	}, nil
+}
+
+// smtpSender - STARTTLS is used if server supports it, plain auth is allowed only over TLS or to localhost
+type smtpSender struct {
+	addr string
+	auth smtp.Auth
+	from *mail.Address
+}
+
+func (s *smtpSender) Send(to app.Email, subject, body string) error {
+	toAddress, err := mail.ParseAddress(string(to))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	msg := buildMessage(s.from, toAddress, subject, body, time.Now())
+	err = smtp.SendMail(s.addr, s.auth, s.from.Address, []string{toAddress.Address}, msg)
+	return errors.WithStack(err)
+}
+
+func buildMessage(from, to *mail.Address, subject, body string, date time.Time) []byte {
+	var msg bytes.Buffer
+	writeHeader(&msg, "From", from.String())
+	writeHeader(&msg, "To", to.String())
+	writeHeader(&msg, "Subject", mime.QEncoding.Encode("utf-8", subject))
+	writeHeader(&msg, "Date", date.Format(time.RFC1123Z))
+	writeHeader(&msg, "MIME-Version", "1.0")
+	writeHeader(&msg, "Content-Type", "text/plain; charset=UTF-8")
+	writeHeader(&msg, "Content-Transfer-Encoding", "8bit")
+	msg.WriteString("\r\n")
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return msg.Bytes()
+}
+
+func writeHeader(msg *bytes.Buffer, name, value string) {
+	// header values must not contain line breaks, otherwise headers can be injected
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	msg.WriteString(fmt.Sprintf("%s: %s\r\n", name, value))
+}
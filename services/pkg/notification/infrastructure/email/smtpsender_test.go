@@ -0,0 +1,106 @@
+package email
+
+import (
+	"bufio"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMTPSenderDeliversToSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	received := make(chan sinkMessage, 1)
+	go serveSMTPSink(listener, received)
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	assert.NoError(t, err)
+	sender, err := NewSMTPSender(SMTPConfig{Host: host, Port: port, From: "noreply@arch.homework"})
+	assert.NoError(t, err)
+
+	err = sender.Send("user@example.com", "Password reset", "first line\nsecond line\n")
+	assert.NoError(t, err)
+
+	msg := <-received
+	assert.Equal(t, "<noreply@arch.homework>", msg.from)
+	assert.Equal(t, []string{"<user@example.com>"}, msg.to)
+	assert.Contains(t, msg.data, "Subject: Password reset\r\n")
+	assert.Contains(t, msg.data, "To: <user@example.com>\r\n")
+	assert.True(t, strings.HasSuffix(msg.data, "\r\n\r\nfirst line\r\nsecond line\r\n"))
+}
+
+func TestSMTPSenderRejectsInvalidRecipient(t *testing.T) {
+	sender, err := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: "1", From: "noreply@arch.homework"})
+	assert.NoError(t, err)
+
+	err = sender.Send("user@example.com\r\nBcc: other@example.com", "subject", "body")
+	assert.Error(t, err)
+}
+
+type sinkMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// serveSMTPSink - minimal smtp server accepting one message
+func serveSMTPSink(listener net.Listener, received chan<- sinkMessage) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 localhost ESMTP sink")
+
+	var msg sinkMessage
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			_ = text.PrintfLine("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg.from = line[len("MAIL FROM:"):]
+			_ = text.PrintfLine("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.to = append(msg.to, line[len("RCPT TO:"):])
+			_ = text.PrintfLine("250 OK")
+		case cmd == "DATA":
+			_ = text.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := readData(text.R)
+			if err != nil {
+				return
+			}
+			msg.data = data
+			_ = text.PrintfLine("250 OK")
+			received <- msg
+		case cmd == "QUIT":
+			_ = text.PrintfLine("221 bye")
+			return
+		default:
+			_ = text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func readData(r *bufio.Reader) (string, error) {
+	var data strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if line == ".\r\n" {
+			return data.String(), nil
+		}
+		data.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"arch-homework/pkg/notification/app"
 
 	"encoding/json"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -15,6 +16,12 @@ const typeLotClosed = "lot.lot_closed"
 const typeLotSent = "lot.lot_sent"
 const typeLotReceived = "lot.lot_received"
 const typeBidOutbid = "lot.bid_outbid"
+const typeDeliveryDeadlineReminder = "delivery.deadline_reminder"
+const typeDeliveryLotCancelled = "delivery.lot_cancelled"
+const typeLoginLocked = "auth.login_locked"
+const typePasswordResetRequested = "auth.password_reset_requested"
+const typeUserDeleted = "user.user_deleted"
+const typeEmailVerificationRequested = "user.email_verification_requested"
 
 func NewEventParser() app.IntegrationEventParser {
 	return eventParser{}
@@ -35,6 +42,18 @@ func (e eventParser) ParseIntegrationEvent(event integrationevent.EventData) (ap
 		return parseLotReceivedEvent(event.Body)
 	case typeBidOutbid:
 		return parseBidOutbidEvent(event.Body)
+	case typeDeliveryDeadlineReminder:
+		return parseDeliveryDeadlineReminderEvent(event.Body)
+	case typeDeliveryLotCancelled:
+		return parseDeliveryLotCancelledEvent(event.Body)
+	case typeLoginLocked:
+		return parseLoginLockedEvent(event.Body)
+	case typePasswordResetRequested:
+		return parsePasswordResetRequestedEvent(event.Body)
+	case typeEmailVerificationRequested:
+		return parseEmailVerificationRequestedEvent(event.Body)
+	case typeUserDeleted:
+		return parseUserDeletedEvent(event.Body)
 	default:
 		return nil, nil
 	}
@@ -89,6 +108,122 @@ func parseBidOutbidEvent(strBody string) (app.HandledEvent, error) {
 	return app.NewBidOutbidEvent(app.LotID(body.LotID), app.UserID(body.UserID)), nil
 }
 
+func parseDeliveryDeadlineReminderEvent(strBody string) (app.HandledEvent, error) {
+	var body deliveryDeadlineReminderEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.LotID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var notificationType app.NotificationType
+	switch body.DeadlineType {
+	case "ship_by":
+		notificationType = app.TypeShipByReminder
+	case "confirm_by":
+		notificationType = app.TypeConfirmByReminder
+	default:
+		return nil, errors.Errorf("unknown deadline type %s", body.DeadlineType)
+	}
+	deadline, err := time.Parse(time.RFC3339, body.Deadline)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return app.NewDeliveryDeadlineReminderEvent(app.LotID(body.LotID), app.UserID(body.UserID), notificationType, deadline), nil
+}
+
+func parseDeliveryLotCancelledEvent(strBody string) (app.HandledEvent, error) {
+	body, err := parseLotEvent(strBody)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewDeliveryLotCancelledEvent(app.LotID(body.LotID), app.UserID(body.LotOwnerID), app.UserID(body.UserID)), nil
+}
+
+func parseLoginLockedEvent(strBody string) (app.HandledEvent, error) {
+	var body loginLockedEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lockedUntil, err := time.Parse(time.RFC3339, body.LockedUntil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return app.NewLoginLockedEvent(app.UserID(body.UserID), lockedUntil), nil
+}
+
+func parsePasswordResetRequestedEvent(strBody string) (app.HandledEvent, error) {
+	var body passwordResetRequestedEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if body.Email == "" {
+		return nil, errors.New("email required")
+	}
+	if body.TokenID != "" {
+		err = uuid.ValidateUUID(body.TokenID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	} else if body.Token == "" {
+		return nil, errors.New("token id required")
+	}
+	expiresAt, err := time.Parse(time.RFC3339, body.ExpiresAt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return app.NewPasswordResetRequestedEvent(app.UserID(body.UserID), app.Email(body.Email), app.PasswordResetTokenID(body.TokenID), body.Token, expiresAt), nil
+}
+
+func parseEmailVerificationRequestedEvent(strBody string) (app.HandledEvent, error) {
+	var body emailVerificationRequestedEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if body.Email == "" || body.Token == "" {
+		return nil, errors.New("email and token required")
+	}
+	expiresAt, err := time.Parse(time.RFC3339, body.ExpiresAt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return app.NewEmailVerificationRequestedEvent(app.UserID(body.UserID), app.Email(body.Email), body.Token, expiresAt), nil
+}
+
+func parseUserDeletedEvent(strBody string) (app.HandledEvent, error) {
+	var body userDeletedEventBody
+	err := json.Unmarshal([]byte(strBody), &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = uuid.ValidateUUID(body.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return app.NewUserDeletedEvent(app.UserID(body.UserID)), nil
+}
+
 func parseLotEvent(strBody string) (lotEventBody, error) {
 	var body lotEventBody
 	err := json.Unmarshal([]byte(strBody), &body)
@@ -109,6 +244,13 @@ func parseLotEvent(strBody string) (lotEventBody, error) {
 	return body, errors.WithStack(err)
 }
 
+type deliveryDeadlineReminderEventBody struct {
+	LotID        string `json:"lot_id"`
+	UserID       string `json:"user_id"`
+	DeadlineType string `json:"deadline_type"`
+	Deadline     string `json:"deadline"`
+}
+
 type lotEventBody struct {
 	LotID      string `json:"lot_id"`
 	UserID     string `json:"user_id,omitempty"`
@@ -119,3 +261,28 @@ type bidEventBody struct {
 	LotID  string `json:"lot_id"`
 	UserID string `json:"user_id"`
 }
+
+type loginLockedEventBody struct {
+	UserID      string `json:"user_id"`
+	LockedUntil string `json:"locked_until"`
+}
+
+type passwordResetRequestedEventBody struct {
+	UserID  string `json:"user_id"`
+	Email   string `json:"email"`
+	TokenID string `json:"token_id"`
+	// Token - raw token was passed by auth service before token issue by ID
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type emailVerificationRequestedEventBody struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type userDeletedEventBody struct {
+	UserID string `json:"user_id"`
+}
@@ -53,6 +53,12 @@ func (repo *notificationRepository) FindAllByUserID(userID app.UserID) ([]app.No
 	return res, nil
 }
 
+func (repo *notificationRepository) RemoveAllByUserID(userID app.UserID) error {
+	const query = `DELETE FROM notification WHERE user_id = $1`
+	_, err := repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
+
 func sqlxNotificationToNotification(notification *sqlxNotification) app.Notification {
 	var lotID *app.LotID
 	if notification.LotID.Valid {
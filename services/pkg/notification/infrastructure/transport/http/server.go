@@ -17,9 +17,11 @@ import (
 )
 
 const PathPrefix = "/api/v1/"
+const PathPrefixInternal = "/internal/api/v1/"
 
 const (
-	notificationsEndpoint = PathPrefix + "notifications"
+	notificationsEndpoint  = PathPrefix + "notifications"
+	userDataExportEndpoint = PathPrefixInternal + "user/{id}/export"
 )
 
 const (
@@ -52,6 +54,14 @@ func (s *Server) MakeHandler() http.Handler {
 	return router
 }
 
+func (s *Server) MakeInternalHandler() http.Handler {
+	router := mux.NewRouter()
+
+	router.Methods(http.MethodGet).Path(userDataExportEndpoint).Handler(s.makeHandlerFunc(s.exportUserDataHandler))
+
+	return router
+}
+
 func (s *Server) makeHandlerFunc(fn func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		_ = r.ParseForm()
@@ -97,7 +107,19 @@ func (s *Server) getNotificationsHandler(w http.ResponseWriter, r *http.Request)
 		return err
 	}
 
-	notifications, err := s.notificationRepo.FindAllByUserID(app.UserID(tokenData.UserID()))
+	return s.writeNotifications(w, app.UserID(tokenData.UserID()))
+}
+
+func (s *Server) exportUserDataHandler(w http.ResponseWriter, r *http.Request) error {
+	userID := mux.Vars(r)["id"]
+	if err := uuid.ValidateUUID(userID); err != nil {
+		return errors.WithStack(err)
+	}
+	return s.writeNotifications(w, app.UserID(userID))
+}
+
+func (s *Server) writeNotifications(w http.ResponseWriter, userID app.UserID) error {
+	notifications, err := s.notificationRepo.FindAllByUserID(userID)
 	if err != nil {
 		return err
 	}
@@ -125,6 +147,9 @@ func (s *Server) extractAuthorizationData(r *http.Request) (jwtauth.TokenData, e
 	if err = uuid.ValidateUUID(tokenData.UserID()); err != nil {
 		return nil, errors.WithStack(err)
 	}
+	if err = jwtauth.CheckScope(tokenData, r, jwtauth.NoScope, jwtauth.NoScope); err != nil {
+		return nil, errors.Wrap(errForbidden, err.Error())
+	}
 	return tokenData, nil
 }
 
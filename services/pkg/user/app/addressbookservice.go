@@ -0,0 +1,176 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"github.com/pkg/errors"
+)
+
+const maxAddressCount = 10
+
+func NewAddressBookService(dbDependency DBDependency, validator AddressValidator) *AddressBookService {
+	return &AddressBookService{
+		readRepo:        dbDependency.ShippingAddressRepositoryRead(),
+		profileReadRepo: dbDependency.UserProfileRepositoryRead(),
+		trUnitFactory:   dbDependency,
+		validator:       validator,
+	}
+}
+
+// AddressBookService - first added address becomes default, default address can be changed but not unset
+type AddressBookService struct {
+	readRepo        ShippingAddressRepositoryRead
+	profileReadRepo UserProfileRepositoryRead
+	trUnitFactory   TransactionalUnitFactory
+	validator       AddressValidator
+}
+
+func (s *AddressBookService) Addresses(userID UserID) ([]ShippingAddress, error) {
+	return s.readRepo.FindAllByUserID(userID)
+}
+
+func (s *AddressBookService) Address(userID UserID, id AddressID) (*ShippingAddress, error) {
+	return s.readRepo.FindByID(userID, id)
+}
+
+func (s *AddressBookService) DefaultAddress(userID UserID) (*ShippingAddress, error) {
+	return s.readRepo.FindDefault(userID)
+}
+
+func (s *AddressBookService) AddAddress(requestID RequestID, userID UserID, address ShippingAddress) (AddressID, error) {
+	address.normalize()
+	if err := s.validator.Validate(address); err != nil {
+		return "", err
+	}
+	user, err := s.profileReadRepo.FindByID(userID)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if user.IsDeleted() {
+		return "", errors.WithStack(ErrUserNotFound)
+	}
+
+	address.ID = AddressID(uuid.GenerateNew())
+	address.UserID = userID
+
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		alreadyProcessed, err := provider.ProcessedRequestRepository().SetRequestProcessed(requestID)
+		if err != nil {
+			return err
+		}
+		if alreadyProcessed {
+			return errors.WithStack(ErrAlreadyProcessed)
+		}
+
+		repo := provider.ShippingAddressRepository()
+		addresses, err := repo.FindAllByUserID(userID)
+		if err != nil {
+			return err
+		}
+		if len(addresses) >= maxAddressCount {
+			return errors.Wrapf(ErrAddressLimitExceeded, "max %d addresses", maxAddressCount)
+		}
+		if len(addresses) == 0 {
+			address.IsDefault = true
+		}
+		if address.IsDefault {
+			if err = unsetDefaultAddress(repo, addresses); err != nil {
+				return err
+			}
+		}
+		return repo.Store(&address)
+	})
+	if err != nil {
+		return "", err
+	}
+	return address.ID, nil
+}
+
+func (s *AddressBookService) UpdateAddress(userID UserID, address ShippingAddress) error {
+	address.normalize()
+	if err := s.validator.Validate(address); err != nil {
+		return err
+	}
+
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		repo := provider.ShippingAddressRepository()
+		stored, err := repo.FindByID(userID, address.ID)
+		if err != nil {
+			return err
+		}
+		address.UserID = stored.UserID
+		address.IsDefault = stored.IsDefault
+		return repo.Store(&address)
+	})
+}
+
+func (s *AddressBookService) SetDefaultAddress(userID UserID, id AddressID) error {
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		repo := provider.ShippingAddressRepository()
+		address, err := repo.FindByID(userID, id)
+		if err != nil {
+			return err
+		}
+		if address.IsDefault {
+			return nil
+		}
+		addresses, err := repo.FindAllByUserID(userID)
+		if err != nil {
+			return err
+		}
+		if err = unsetDefaultAddress(repo, addresses); err != nil {
+			return err
+		}
+		address.IsDefault = true
+		return repo.Store(address)
+	})
+}
+
+// RemoveAddress - when default address is removed, the first of remaining addresses becomes default
+func (s *AddressBookService) RemoveAddress(userID UserID, id AddressID) error {
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		repo := provider.ShippingAddressRepository()
+		address, err := repo.FindByID(userID, id)
+		if err != nil {
+			return err
+		}
+		if err = repo.Remove(userID, id); err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+		addresses, err := repo.FindAllByUserID(userID)
+		if err != nil || len(addresses) == 0 {
+			return err
+		}
+		addresses[0].IsDefault = true
+		return repo.Store(&addresses[0])
+	})
+}
+
+func (s *AddressBookService) executeInTransaction(f func(RepositoryProvider) error) (err error) {
+	var trUnit TransactionalUnit
+	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = trUnit.Complete(err)
+	}()
+	err = f(trUnit)
+	return err
+}
+
+func unsetDefaultAddress(repo ShippingAddressRepository, addresses []ShippingAddress) error {
+	for i := range addresses {
+		if !addresses[i].IsDefault {
+			continue
+		}
+		addresses[i].IsDefault = false
+		if err := repo.Store(&addresses[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
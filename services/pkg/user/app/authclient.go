@@ -3,4 +3,10 @@ package app
 type AuthServiceClient interface {
 	RegisterUser(login, password string) (UserID, error)
 	RemoveUser(userID UserID) error
+	RequestPasswordReset(userID UserID, email Email) error
+}
+
+type AuthUserFinder interface {
+	// FindUserIDByLogin - returns ErrUserNotFound if auth service has no user with this login
+	FindUserIDByLogin(login string) (UserID, error)
 }
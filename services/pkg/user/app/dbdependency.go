@@ -4,16 +4,21 @@ import "arch-homework/pkg/common/app/storedevent"
 
 type RepositoryProvider interface {
 	UserProfileRepository() UserProfileRepository
+	ShippingAddressRepository() ShippingAddressRepository
 	ProcessedRequestRepository() ProcessedRequestRepository
+	RegistrationSagaRepository() RegistrationSagaRepository
 	EventStore() storedevent.EventStore
 }
 
 type ReadRepositoryProvider interface {
 	UserProfileRepositoryRead() UserProfileRepositoryRead
+	ShippingAddressRepositoryRead() ShippingAddressRepositoryRead
+	RegistrationSagaRepositoryRead() RegistrationSagaRepositoryRead
 }
 
 type TransactionalUnit interface {
 	RepositoryProvider
+	AddLock(lockName string) error
 	Complete(err error) error
 }
 
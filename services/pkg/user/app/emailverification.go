@@ -0,0 +1,22 @@
+package app
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidVerificationToken = errors.New("email verification token is invalid or expired")
+var ErrEmailAlreadyVerified = errors.New("email already verified")
+
+// EmailVerificationToken - bound to email, so token issued for previous email can't confirm the new one
+type EmailVerificationToken struct {
+	UserID    UserID
+	Email     Email
+	ExpiresAt time.Time
+}
+
+type EmailVerificationTokenSigner interface {
+	Sign(token EmailVerificationToken) (string, error)
+	// Parse - checks signature only, expiration is checked by caller
+	Parse(signed string) (EmailVerificationToken, error)
+}
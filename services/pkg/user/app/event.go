@@ -4,9 +4,14 @@ import (
 	"arch-homework/pkg/common/app/integrationevent"
 	"arch-homework/pkg/common/app/uuid"
 	"encoding/json"
+	"time"
 )
 
 const typeUserRegistered = "user.user_registered"
+const typeUserDeleted = "user.user_deleted"
+const typeProfileUpdated = "user.profile_updated"
+const typeEmailVerificationRequested = "user.email_verification_requested"
+const typeEmailVerified = "user.email_verified"
 
 func NewUserRegisteredEvent(userID UserID, login string) integrationevent.EventData {
 	body, _ := json.Marshal(userRegisteredEventBody{
@@ -21,6 +26,63 @@ func NewUserRegisteredEvent(userID UserID, login string) integrationevent.EventD
 	}
 }
 
+func NewUserDeletedEvent(userID UserID) integrationevent.EventData {
+	body, _ := json.Marshal(userDeletedEventBody{
+		UserID: string(userID),
+	})
+
+	return integrationevent.EventData{
+		UID:  newUID(),
+		Type: typeUserDeleted,
+		Body: string(body),
+	}
+}
+
+// NewProfileUpdatedEvent - contains profile snapshot for read models in other services, email is not shared
+func NewProfileUpdatedEvent(profile UserProfile) integrationevent.EventData {
+	body, _ := json.Marshal(profileUpdatedEventBody{
+		UserID:    string(profile.UserID),
+		Login:     profile.Login,
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		Address:   string(profile.Address),
+	})
+
+	return integrationevent.EventData{
+		UID:  newUID(),
+		Type: typeProfileUpdated,
+		Body: string(body),
+	}
+}
+
+func NewEmailVerificationRequestedEvent(userID UserID, email Email, token string, expiresAt time.Time) integrationevent.EventData {
+	body, _ := json.Marshal(emailVerificationRequestedEventBody{
+		UserID:    string(userID),
+		Email:     string(email),
+		Token:     token,
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+	})
+
+	return integrationevent.EventData{
+		UID:  newUID(),
+		Type: typeEmailVerificationRequested,
+		Body: string(body),
+	}
+}
+
+func NewEmailVerifiedEvent(userID UserID, email Email) integrationevent.EventData {
+	body, _ := json.Marshal(emailVerifiedEventBody{
+		UserID: string(userID),
+		Email:  string(email),
+	})
+
+	return integrationevent.EventData{
+		UID:  newUID(),
+		Type: typeEmailVerified,
+		Body: string(body),
+	}
+}
+
 func newUID() integrationevent.EventUID {
 	return integrationevent.EventUID(uuid.GenerateNew())
 }
@@ -29,3 +91,27 @@ type userRegisteredEventBody struct {
 	UserID string `json:"user_id"`
 	Login  string `json:"login"`
 }
+
+type userDeletedEventBody struct {
+	UserID string `json:"user_id"`
+}
+
+type profileUpdatedEventBody struct {
+	UserID    string `json:"user_id"`
+	Login     string `json:"login"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Address   string `json:"address"`
+}
+
+type emailVerificationRequestedEventBody struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type emailVerifiedEventBody struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
@@ -0,0 +1,21 @@
+package app
+
+type UserActivity struct {
+	ActiveLotCount int
+	ActiveBidCount int
+}
+
+// SellerStats - ActiveLotCount is count of lots open for bids, SoldLotCount is count of lots with winner
+// and CompletedSaleCount is count of sold lots received by winners
+type SellerStats struct {
+	ActiveLotCount     int
+	SoldLotCount       int
+	CompletedSaleCount int
+}
+
+type LotServiceClient interface {
+	// StartUserDeletion - lot service rejects new lots and bids of user and returns user activity,
+	// user deletion must be refused if activity isn't empty
+	StartUserDeletion(userID UserID) (*UserActivity, error)
+	GetSellerStats(userID UserID) (*SellerStats, error)
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidSearchQuery = errors.New("login prefix is required")
+
+// MaxUserSearchResults - limit of profiles returned by login prefix search
+const MaxUserSearchResults = 20
+
+// PublicProfile - profile of user visible to anyone, without personal data
+type PublicProfile struct {
+	UserID UserID
+	Login  string
+	// MemberSince - registration time, nil if unknown
+	MemberSince *time.Time
+	SellerStats SellerStats
+	// Reputation - percent of sold lots received by winners, nil if user has no sales
+	Reputation *int
+}
+
+func newPublicProfile(profile UserProfile, stats SellerStats) PublicProfile {
+	var reputation *int
+	if stats.SoldLotCount > 0 {
+		value := stats.CompletedSaleCount * 100 / stats.SoldLotCount
+		reputation = &value
+	}
+	return PublicProfile{
+		UserID:      profile.UserID,
+		Login:       profile.Login,
+		MemberSince: profile.CreationTime,
+		SellerStats: stats,
+		Reputation:  reputation,
+	}
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"errors"
+	"time"
+)
+
+var ErrRegistrationSagaNotFound = errors.New("registration saga not found")
+var ErrRegistrationInProgress = errors.New("registration with this login is already in progress")
+
+const (
+	registrationSagaRetryBaseDelay = time.Second * 5
+	registrationSagaRetryMaxDelay  = time.Minute * 10
+)
+
+type RegistrationSagaID uuid.UUID
+type RegistrationSagaState string
+
+const (
+	// RegistrationSagaStarted - registration in auth service is requested, user id is unknown until response
+	RegistrationSagaStarted RegistrationSagaState = "started"
+	// RegistrationSagaAuthRegistered - credentials are created in auth service, profile is not stored yet
+	RegistrationSagaAuthRegistered RegistrationSagaState = "auth_registered"
+	// RegistrationSagaCompensating - profile can't be stored, credentials created in auth service have to be removed
+	RegistrationSagaCompensating RegistrationSagaState = "compensating"
+)
+
+// RegistrationSaga - state of unfinished registration, removed when profile is stored or credentials are removed.
+// Password isn't stored, so registration in auth service is never retried.
+// Saga is resumed by recovery when NextAttemptTime passes
+type RegistrationSaga struct {
+	ID              RegistrationSagaID
+	State           RegistrationSagaState
+	UserID          *UserID
+	Login           string
+	FirstName       string
+	LastName        string
+	Email           Email
+	Address         Address
+	EmailVerified   bool
+	Attempts        int
+	LastError       string
+	NextAttemptTime time.Time
+	CreationTime    time.Time
+}
+
+func newRegistrationSaga(login, firstName, lastName string, email Email, address Address, emailVerified bool, now time.Time, timeout time.Duration) RegistrationSaga {
+	return RegistrationSaga{
+		ID:              RegistrationSagaID(uuid.GenerateNew()),
+		State:           RegistrationSagaStarted,
+		Login:           login,
+		FirstName:       firstName,
+		LastName:        lastName,
+		Email:           email,
+		Address:         address,
+		EmailVerified:   emailVerified,
+		NextAttemptTime: now.Add(timeout),
+		CreationTime:    now,
+	}
+}
+
+func (s *RegistrationSaga) setAuthRegistered(userID UserID, now time.Time, timeout time.Duration) {
+	s.State = RegistrationSagaAuthRegistered
+	s.UserID = &userID
+	s.NextAttemptTime = now.Add(timeout)
+}
+
+// setCompensating - next compensation attempt is delayed with exponential backoff
+func (s *RegistrationSaga) setCompensating(cause error, now time.Time) {
+	s.State = RegistrationSagaCompensating
+	s.Attempts++
+	s.LastError = cause.Error()
+	s.NextAttemptTime = now.Add(RegistrationSagaRetryDelay(s.Attempts))
+}
+
+func (s *RegistrationSaga) profile() UserProfile {
+	creationTime := s.CreationTime
+	return UserProfile{
+		UserID:        *s.UserID,
+		Login:         s.Login,
+		FirstName:     s.FirstName,
+		LastName:      s.LastName,
+		Email:         s.Email,
+		Address:       s.Address,
+		EmailVerified: s.EmailVerified,
+		CreationTime:  &creationTime,
+	}
+}
+
+// RegistrationSagaRetryDelay - delay before attempt, doubled after each failed attempt up to 10 minutes
+func RegistrationSagaRetryDelay(attempt int) time.Duration {
+	delay := registrationSagaRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= registrationSagaRetryMaxDelay {
+			return registrationSagaRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+type RegistrationSagaRepositoryRead interface {
+	FindByID(id RegistrationSagaID) (*RegistrationSaga, error)
+	FindByLogin(login string) (*RegistrationSaga, error)
+	// FindAllReadyForAttempt - sagas with next attempt time before specified time ordered by next attempt time
+	FindAllReadyForAttempt(now time.Time, limit int) ([]RegistrationSaga, error)
+}
+
+type RegistrationSagaRepository interface {
+	RegistrationSagaRepositoryRead
+	Store(saga *RegistrationSaga) error
+	Remove(id RegistrationSagaID) error
+}
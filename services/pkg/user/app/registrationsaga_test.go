@@ -0,0 +1,503 @@
+package app_test
+
+import (
+	"arch-homework/pkg/common/app/integrationevent"
+	"arch-homework/pkg/common/app/storedevent"
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/user/app"
+	"arch-homework/pkg/user/infrastructure/encoding"
+
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errInjected = errors.New("injected failure")
+
+var testTokenSigner = encoding.NewEmailVerificationTokenSigner([]byte("secret"))
+
+func TestRegistrationCompleted(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+
+	userID, err := service.Add("user", "password", "First", "Last", "user@example.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, userID, auth.users["user"])
+	assert.Equal(t, "user", db.profiles[userID].Login)
+	assert.Empty(t, db.sagas)
+	assert.Equal(t, []string{"user.user_registered", "user.profile_updated", "user.email_verification_requested"}, db.eventTypes())
+}
+
+func TestRegistrationCompensatedWhenProfileNotStored(t *testing.T) {
+	db := newTestDB()
+	db.failProfileStore = true
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+
+	_, err := service.Add("user", "password", "First", "Last", "user@example.com", "")
+	assert.Error(t, err)
+	assert.Empty(t, auth.users, "credentials should be removed")
+	assert.Empty(t, db.profiles)
+	assert.Empty(t, db.sagas)
+	assert.Empty(t, db.events)
+}
+
+func TestRegistrationCompensatedWhenAuthResultUnknown(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	auth.failAfterRegister = true
+	service := newTestUserService(db, auth)
+
+	_, err := service.Add("user", "password", "First", "Last", "user@example.com", "")
+	assert.Error(t, err)
+	assert.Empty(t, auth.users, "credentials created before failure should be found by login and removed")
+	assert.Empty(t, db.sagas)
+}
+
+func TestFailedCompensationRetriedByRecovery(t *testing.T) {
+	db := newTestDB()
+	db.failProfileStore = true
+	auth := newTestAuthClient()
+	auth.failRemove = true
+	service := newTestUserService(db, auth)
+
+	_, err := service.Add("user", "password", "First", "Last", "user@example.com", "")
+	assert.Error(t, err)
+	assert.Len(t, auth.users, 1)
+	saga := db.singleSaga(t)
+	assert.Equal(t, app.RegistrationSagaCompensating, saga.State)
+	assert.Equal(t, 1, saga.Attempts)
+	assert.WithinDuration(t, time.Now().Add(app.RegistrationSagaRetryDelay(1)), saga.NextAttemptTime, time.Second)
+
+	// saga isn't retried before backoff delay passes
+	assert.NoError(t, service.RecoverRegistrationSagas())
+	assert.Equal(t, 1, db.singleSaga(t).Attempts)
+
+	db.expireSagas()
+	assert.Error(t, service.RecoverRegistrationSagas())
+	saga = db.singleSaga(t)
+	assert.Equal(t, 2, saga.Attempts)
+	assert.WithinDuration(t, time.Now().Add(app.RegistrationSagaRetryDelay(2)), saga.NextAttemptTime, time.Second)
+
+	auth.failRemove = false
+	db.expireSagas()
+	assert.NoError(t, service.RecoverRegistrationSagas())
+	assert.Empty(t, auth.users)
+	assert.Empty(t, db.sagas)
+}
+
+func TestRegistrationInterruptedAfterAuthCompletedByRecovery(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+	userID := auth.register("user")
+	db.addSaga(app.RegistrationSagaAuthRegistered, "user", &userID)
+
+	assert.NoError(t, service.RecoverRegistrationSagas())
+	assert.Equal(t, "user", db.profiles[userID].Login)
+	assert.Equal(t, userID, auth.users["user"])
+	assert.Empty(t, db.sagas)
+	assert.Equal(t, []string{"user.user_registered", "user.profile_updated", "user.email_verification_requested"}, db.eventTypes())
+}
+
+func TestRegistrationInterruptedBeforeAuthResponseCompensatedByRecovery(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+	auth.register("user")
+	db.addSaga(app.RegistrationSagaStarted, "user", nil)
+	db.addSaga(app.RegistrationSagaStarted, "unregistered", nil)
+
+	assert.NoError(t, service.RecoverRegistrationSagas())
+	assert.Empty(t, auth.users)
+	assert.Empty(t, db.sagas)
+	assert.Empty(t, db.profiles)
+}
+
+func TestRecoveryKeepsCredentialsOfRegisteredUser(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+	userID := auth.register("user")
+	db.profiles[userID] = app.UserProfile{UserID: userID, Login: "user"}
+	// registration with taken login failed before saga state was updated
+	db.addSaga(app.RegistrationSagaStarted, "user", nil)
+
+	assert.NoError(t, service.RecoverRegistrationSagas())
+	assert.Equal(t, userID, auth.users["user"])
+	assert.Empty(t, db.sagas)
+}
+
+func TestAuthRegistrationRequestedUnderSagaLock(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+	auth.onRegister = func() {
+		saga := db.singleSaga(t)
+		assert.Equal(t, []string{"registration-saga-" + string(saga.ID)}, db.locks)
+	}
+
+	_, err := service.Add("user", "password", "First", "Last", "user@example.com", "")
+	assert.NoError(t, err)
+}
+
+func TestRecoverySkipsSagaAdvancedByRunningRegistration(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+	db.addSaga(app.RegistrationSagaStarted, "user", nil)
+	saga := db.singleSaga(t)
+	// registration outlived saga timeout and stored auth result before releasing saga lock
+	db.onLock = func(lockName string) {
+		if lockName != "registration-saga-"+string(saga.ID) {
+			return
+		}
+		userID := auth.register("user")
+		advanced := db.sagas[saga.ID]
+		advanced.State = app.RegistrationSagaAuthRegistered
+		advanced.UserID = &userID
+		advanced.NextAttemptTime = time.Now().Add(time.Minute)
+		db.sagas[saga.ID] = advanced
+	}
+
+	assert.NoError(t, service.RecoverRegistrationSagas())
+	assert.Contains(t, auth.users, "user")
+	assert.Equal(t, app.RegistrationSagaAuthRegistered, db.singleSaga(t).State)
+}
+
+func TestRegistrationWithLoginInProgressRejected(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+	db.addSaga(app.RegistrationSagaStarted, "user", nil)
+
+	_, err := service.Add("user", "password", "First", "Last", "user@example.com", "")
+	assert.Equal(t, app.ErrRegistrationInProgress, errorsCause(err))
+	assert.Empty(t, auth.users)
+}
+
+func TestRegistrationSagaRetryDelay(t *testing.T) {
+	assert.Equal(t, 5*time.Second, app.RegistrationSagaRetryDelay(1))
+	assert.Equal(t, 10*time.Second, app.RegistrationSagaRetryDelay(2))
+	assert.Equal(t, 80*time.Second, app.RegistrationSagaRetryDelay(5))
+	assert.Equal(t, 10*time.Minute, app.RegistrationSagaRetryDelay(8))
+	assert.Equal(t, 10*time.Minute, app.RegistrationSagaRetryDelay(100))
+}
+
+func newTestUserService(db *testDB, auth *testAuthClient) *app.UserService {
+	return newTestUserServiceWithLotClient(db, auth, nil)
+}
+
+func newTestUserServiceWithLotClient(db *testDB, auth *testAuthClient, lotClient app.LotServiceClient) *app.UserService {
+	return app.NewUserService(
+		db,
+		testEventSender{},
+		auth,
+		auth,
+		lotClient,
+		nil,
+		testTokenSigner,
+		time.Hour,
+		time.Minute*5,
+	)
+}
+
+func errorsCause(err error) error {
+	type causer interface {
+		Cause() error
+	}
+	for err != nil {
+		c, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = c.Cause()
+	}
+	return err
+}
+
+type testAuthClient struct {
+	users             map[string]app.UserID
+	failAfterRegister bool
+	failRemove        bool
+	onRegister        func()
+}
+
+func newTestAuthClient() *testAuthClient {
+	return &testAuthClient{users: map[string]app.UserID{}}
+}
+
+func (c *testAuthClient) register(login string) app.UserID {
+	id := app.UserID(uuid.GenerateNew())
+	c.users[login] = id
+	return id
+}
+
+func (c *testAuthClient) RegisterUser(login, _ string) (app.UserID, error) {
+	if _, ok := c.users[login]; ok {
+		return "", errors.New("login already exists")
+	}
+	if c.onRegister != nil {
+		c.onRegister()
+	}
+	id := c.register(login)
+	if c.failAfterRegister {
+		return "", errInjected
+	}
+	return id, nil
+}
+
+func (c *testAuthClient) RemoveUser(userID app.UserID) error {
+	if c.failRemove {
+		return errInjected
+	}
+	for login, id := range c.users {
+		if id == userID {
+			delete(c.users, login)
+		}
+	}
+	return nil
+}
+
+func (c *testAuthClient) RequestPasswordReset(app.UserID, app.Email) error {
+	return nil
+}
+
+func (c *testAuthClient) FindUserIDByLogin(login string) (app.UserID, error) {
+	id, ok := c.users[login]
+	if !ok {
+		return "", app.ErrUserNotFound
+	}
+	return id, nil
+}
+
+type testEventSender struct {
+}
+
+func (s testEventSender) EventStored(integrationevent.EventUID) {
+}
+
+func (s testEventSender) SendStoredEvents() {
+}
+
+// testDB - changes are applied immediately, failures are injected before any change in transaction
+type testDB struct {
+	profiles         map[app.UserID]app.UserProfile
+	addressOwners    map[app.UserID]bool
+	sagas            map[app.RegistrationSagaID]app.RegistrationSaga
+	requests         map[app.RequestID]bool
+	events           []integrationevent.EventData
+	locks            []string
+	failProfileStore bool
+	// onLock - called after lock is taken, simulates changes made by lock holder which was waited for
+	onLock func(lockName string)
+}
+
+func newTestDB() *testDB {
+	return &testDB{
+		profiles:      map[app.UserID]app.UserProfile{},
+		addressOwners: map[app.UserID]bool{},
+		sagas:         map[app.RegistrationSagaID]app.RegistrationSaga{},
+		requests:      map[app.RequestID]bool{},
+	}
+}
+
+func (db *testDB) addSaga(state app.RegistrationSagaState, login string, userID *app.UserID) {
+	id := app.RegistrationSagaID(uuid.GenerateNew())
+	db.sagas[id] = app.RegistrationSaga{
+		ID:              id,
+		State:           state,
+		UserID:          userID,
+		Login:           login,
+		Email:           app.Email(login + "@example.com"),
+		NextAttemptTime: time.Now().Add(-time.Second),
+		CreationTime:    time.Now().Add(-time.Hour),
+	}
+}
+
+func (db *testDB) singleSaga(t *testing.T) app.RegistrationSaga {
+	assert.Len(t, db.sagas, 1)
+	for _, saga := range db.sagas {
+		return saga
+	}
+	return app.RegistrationSaga{}
+}
+
+func (db *testDB) expireSagas() {
+	for id, saga := range db.sagas {
+		saga.NextAttemptTime = time.Now().Add(-time.Second)
+		db.sagas[id] = saga
+	}
+}
+
+func (db *testDB) eventTypes() []string {
+	res := make([]string, 0, len(db.events))
+	for _, event := range db.events {
+		res = append(res, event.Type)
+	}
+	return res
+}
+
+func (db *testDB) NewTransactionalUnit() (app.TransactionalUnit, error) {
+	return db, nil
+}
+
+func (db *testDB) UserProfileRepositoryRead() app.UserProfileRepositoryRead {
+	return db.UserProfileRepository()
+}
+
+func (db *testDB) UserProfileRepository() app.UserProfileRepository {
+	return testProfileRepo{db: db}
+}
+
+func (db *testDB) ShippingAddressRepositoryRead() app.ShippingAddressRepositoryRead {
+	return nil
+}
+
+func (db *testDB) ShippingAddressRepository() app.ShippingAddressRepository {
+	return testAddressRepo{db: db}
+}
+
+func (db *testDB) ProcessedRequestRepository() app.ProcessedRequestRepository {
+	return db
+}
+
+func (db *testDB) SetRequestProcessed(uid app.RequestID) (bool, error) {
+	alreadyProcessed := db.requests[uid]
+	db.requests[uid] = true
+	return alreadyProcessed, nil
+}
+
+func (db *testDB) RegistrationSagaRepositoryRead() app.RegistrationSagaRepositoryRead {
+	return db.RegistrationSagaRepository()
+}
+
+func (db *testDB) RegistrationSagaRepository() app.RegistrationSagaRepository {
+	return testSagaRepo{db: db}
+}
+
+func (db *testDB) EventStore() storedevent.EventStore {
+	return db
+}
+
+func (db *testDB) AddLock(lockName string) error {
+	db.locks = append(db.locks, lockName)
+	if db.onLock != nil {
+		db.onLock(lockName)
+	}
+	return nil
+}
+
+func (db *testDB) Complete(err error) error {
+	return err
+}
+
+func (db *testDB) Add(event integrationevent.EventData) error {
+	db.events = append(db.events, event)
+	return nil
+}
+
+func (db *testDB) ConfirmDelivery(storedevent.EventID) error {
+	return nil
+}
+
+func (db *testDB) FindByUIDs([]integrationevent.EventUID) ([]storedevent.Event, error) {
+	return nil, nil
+}
+
+func (db *testDB) FindAllUnconfirmedBefore(time.Time) ([]storedevent.Event, error) {
+	return nil, nil
+}
+
+type testProfileRepo struct {
+	db *testDB
+}
+
+func (r testProfileRepo) FindByID(id app.UserID) (*app.UserProfile, error) {
+	profile, ok := r.db.profiles[id]
+	if !ok {
+		return nil, app.ErrUserNotFound
+	}
+	return &profile, nil
+}
+
+func (r testProfileRepo) FindByEmail(email app.Email) (*app.UserProfile, error) {
+	for _, profile := range r.db.profiles {
+		if profile.Email == email {
+			return &profile, nil
+		}
+	}
+	return nil, app.ErrUserNotFound
+}
+
+func (r testProfileRepo) FindByLoginPrefix(string, int) ([]app.UserProfile, error) {
+	return nil, nil
+}
+
+func (r testProfileRepo) Store(profile *app.UserProfile) error {
+	if r.db.failProfileStore {
+		return errInjected
+	}
+	r.db.profiles[profile.UserID] = *profile
+	return nil
+}
+
+func (r testProfileRepo) Remove(id app.UserID) error {
+	delete(r.db.profiles, id)
+	return nil
+}
+
+// testAddressRepo - only addresses removal on user deletion is implemented
+type testAddressRepo struct {
+	app.ShippingAddressRepository
+	db *testDB
+}
+
+func (r testAddressRepo) RemoveAllByUserID(userID app.UserID) error {
+	delete(r.db.addressOwners, userID)
+	return nil
+}
+
+type testSagaRepo struct {
+	db *testDB
+}
+
+func (r testSagaRepo) FindByID(id app.RegistrationSagaID) (*app.RegistrationSaga, error) {
+	saga, ok := r.db.sagas[id]
+	if !ok {
+		return nil, app.ErrRegistrationSagaNotFound
+	}
+	return &saga, nil
+}
+
+func (r testSagaRepo) FindByLogin(login string) (*app.RegistrationSaga, error) {
+	for _, saga := range r.db.sagas {
+		if saga.Login == login {
+			return &saga, nil
+		}
+	}
+	return nil, app.ErrRegistrationSagaNotFound
+}
+
+func (r testSagaRepo) FindAllReadyForAttempt(now time.Time, _ int) ([]app.RegistrationSaga, error) {
+	var res []app.RegistrationSaga
+	for _, saga := range r.db.sagas {
+		if !saga.NextAttemptTime.After(now) {
+			res = append(res, saga)
+		}
+	}
+	return res, nil
+}
+
+func (r testSagaRepo) Store(saga *app.RegistrationSaga) error {
+	r.db.sagas[saga.ID] = *saga
+	return nil
+}
+
+func (r testSagaRepo) Remove(id app.RegistrationSagaID) error {
+	delete(r.db.sagas, id)
+	return nil
+}
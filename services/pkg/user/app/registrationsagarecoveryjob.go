@@ -0,0 +1,44 @@
+package app
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"context"
+	"time"
+)
+
+func StartRegistrationSagaRecoveryJob(ctx context.Context, userService *UserService, interval time.Duration, logger *logrus.Logger) {
+	job := registrationSagaRecoveryJob{
+		userService: userService,
+		logger:      logger,
+	}
+	job.start(ctx, interval)
+}
+
+type registrationSagaRecoveryJob struct {
+	userService *UserService
+	logger      *logrus.Logger
+}
+
+func (job *registrationSagaRecoveryJob) start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				job.recover()
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (job *registrationSagaRecoveryJob) recover() {
+	err := job.userService.RecoverRegistrationSagas()
+	if err != nil {
+		job.logger.Error(err)
+	}
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+
+	"errors"
+	"strings"
+)
+
+var ErrAddressNotFound = errors.New("address not found")
+var ErrInvalidAddress = errors.New("address is invalid")
+var ErrAddressLimitExceeded = errors.New("address book limit exceeded")
+
+type AddressID uuid.UUID
+
+// ShippingAddress - Country is ISO 3166-1 alpha-2 code
+type ShippingAddress struct {
+	ID            AddressID
+	UserID        UserID
+	RecipientName string
+	Phone         string
+	Country       string
+	PostalCode    string
+	City          string
+	Street        string
+	IsDefault     bool
+}
+
+func (a *ShippingAddress) normalize() {
+	a.RecipientName = strings.TrimSpace(a.RecipientName)
+	a.Phone = strings.TrimSpace(a.Phone)
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.PostalCode = strings.TrimSpace(a.PostalCode)
+	a.City = strings.TrimSpace(a.City)
+	a.Street = strings.TrimSpace(a.Street)
+}
+
+// AddressValidator - checks country specific address rules, returns ErrInvalidAddress
+type AddressValidator interface {
+	Validate(address ShippingAddress) error
+}
+
+type ShippingAddressRepositoryRead interface {
+	FindByID(userID UserID, id AddressID) (*ShippingAddress, error)
+	FindDefault(userID UserID) (*ShippingAddress, error)
+	FindAllByUserID(userID UserID) ([]ShippingAddress, error)
+}
+
+type ShippingAddressRepository interface {
+	ShippingAddressRepositoryRead
+	Store(address *ShippingAddress) error
+	Remove(userID UserID, id AddressID) error
+	RemoveAllByUserID(userID UserID) error
+}
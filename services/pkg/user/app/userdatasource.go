@@ -0,0 +1,19 @@
+package app
+
+// UserDataSource - service, which stores part of user data
+type UserDataSource interface {
+	Name() string
+	// ExportUserData - returns user data in json
+	ExportUserData(userID UserID) ([]byte, error)
+}
+
+type UserDataPart struct {
+	Name string
+	Data []byte
+}
+
+type UserDataExport struct {
+	Profile   UserProfile
+	Addresses []ShippingAddress
+	Parts     []UserDataPart
+}
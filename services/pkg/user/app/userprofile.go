@@ -4,11 +4,14 @@ import (
 	"arch-homework/pkg/common/app/uuid"
 
 	"errors"
+	"time"
 )
 
 var ErrUserNotFound = errors.New("user not found")
 var ErrInvalidEmail = errors.New("email is invalid")
 var ErrEmailAlreadyExists = errors.New("email already exists")
+var ErrPasswordRequired = errors.New("password required")
+var ErrUserHasActiveLots = errors.New("user has active lots or bids")
 
 type UserID uuid.UUID
 type Email string
@@ -21,11 +24,34 @@ type UserProfile struct {
 	LastName  string
 	Email     Email
 	Address   Address
+	// EmailVerified - new or changed email stays unverified until user confirms it by token
+	EmailVerified bool
+	// CreationTime - nil for users registered before registration time was stored and not found in stored events
+	CreationTime *time.Time
+	// DeletionTime - set when profile is anonymized after account deletion
+	DeletionTime *time.Time
+}
+
+func (p *UserProfile) IsDeleted() bool {
+	return p.DeletionTime != nil
+}
+
+// anonymize - login and email are unique, so they are replaced with id based values
+func (p *UserProfile) anonymize(now time.Time) {
+	p.Login = "deleted-" + string(p.UserID)
+	p.Email = Email("deleted-" + string(p.UserID))
+	p.FirstName = ""
+	p.LastName = ""
+	p.Address = ""
+	p.EmailVerified = false
+	p.DeletionTime = &now
 }
 
 type UserProfileRepositoryRead interface {
 	FindByID(id UserID) (*UserProfile, error)
 	FindByEmail(email Email) (*UserProfile, error)
+	// FindByLoginPrefix - profiles of not deleted users ordered by login
+	FindByLoginPrefix(prefix string, limit int) ([]UserProfile, error)
 }
 
 type UserProfileRepository interface {
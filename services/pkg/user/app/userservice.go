@@ -1,71 +1,124 @@
 package app
 
 import (
+	"arch-homework/pkg/common/app/integrationevent"
+	"arch-homework/pkg/common/app/joblock"
 	"arch-homework/pkg/common/app/storedevent"
+	"fmt"
 	"net/mail"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 var ErrAlreadyProcessed = errors.New("request with this id already processed")
 
-func NewUserService(dbDependency DBDependency, eventSender storedevent.Sender, authSvcClient AuthServiceClient) *UserService {
+const (
+	registrationSagaRecoveryLockName  = "registration-saga-recovery"
+	registrationSagaLockNameTpl       = "registration-saga-%s"
+	registrationSagaRecoveryBatchSize = 100
+)
+
+func NewUserService(
+	dbDependency DBDependency,
+	eventSender storedevent.Sender,
+	authSvcClient AuthServiceClient,
+	authUserFinder AuthUserFinder,
+	lotSvcClient LotServiceClient,
+	userDataSources []UserDataSource,
+	tokenSigner EmailVerificationTokenSigner,
+	verificationTokenTTL time.Duration,
+	registrationSagaTimeout time.Duration,
+) *UserService {
 	return &UserService{
-		readRepo:      dbDependency.UserProfileRepositoryRead(),
-		trUnitFactory: dbDependency,
-		eventSender:   eventSender,
-		authSvcClient: authSvcClient,
+		readRepo:                dbDependency.UserProfileRepositoryRead(),
+		addressReadRepo:         dbDependency.ShippingAddressRepositoryRead(),
+		sagaReadRepo:            dbDependency.RegistrationSagaRepositoryRead(),
+		trUnitFactory:           dbDependency,
+		eventSender:             eventSender,
+		authSvcClient:           authSvcClient,
+		authUserFinder:          authUserFinder,
+		lotSvcClient:            lotSvcClient,
+		userDataSources:         userDataSources,
+		tokenSigner:             tokenSigner,
+		verificationTokenTTL:    verificationTokenTTL,
+		registrationSagaTimeout: registrationSagaTimeout,
 	}
 }
 
 type UserService struct {
-	readRepo      UserProfileRepositoryRead
-	trUnitFactory TransactionalUnitFactory
-	eventSender   storedevent.Sender
-	authSvcClient AuthServiceClient
+	readRepo                UserProfileRepositoryRead
+	addressReadRepo         ShippingAddressRepositoryRead
+	sagaReadRepo            RegistrationSagaRepositoryRead
+	trUnitFactory           TransactionalUnitFactory
+	eventSender             storedevent.Sender
+	authSvcClient           AuthServiceClient
+	authUserFinder          AuthUserFinder
+	lotSvcClient            LotServiceClient
+	userDataSources         []UserDataSource
+	tokenSigner             EmailVerificationTokenSigner
+	verificationTokenTTL    time.Duration
+	registrationSagaTimeout time.Duration
 }
 
 func (s *UserService) Add(login, password string, firstName, lastName string, email Email, address Address) (UserID, error) {
+	if password == "" {
+		return "", errors.WithStack(ErrPasswordRequired)
+	}
+	return s.add(login, password, firstName, lastName, email, address, false)
+}
+
+// AddExternal - registers user authenticated by external identity provider, user has no password.
+// Email is already verified by provider
+func (s *UserService) AddExternal(login, firstName, lastName string, email Email) (UserID, error) {
+	return s.add(login, "", firstName, lastName, email, "", true)
+}
+
+// add - registration saga: credentials are created in auth service, then profile is stored.
+// Saga state is stored before each step, so registration interrupted by crash is resumed by RecoverRegistrationSagas.
+// Saga lock is held until registration finishes, so recovery doesn't compensate registration waiting for auth service
+func (s *UserService) add(login, password string, firstName, lastName string, email Email, address Address, emailVerified bool) (UserID, error) {
 	if err := s.checkEmail(email, nil); err != nil {
 		return "", errors.WithStack(err)
 	}
 
-	id, err := s.authSvcClient.RegisterUser(login, password)
+	saga := newRegistrationSaga(login, firstName, lastName, email, address, emailVerified, time.Now(), s.registrationSagaTimeout)
+	var id UserID
+	err := s.executeWithLock(registrationSagaLockName(saga.ID), func() error {
+		var err error
+		id, err = s.register(&saga, password)
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
+	return id, nil
+}
 
-	userProfile := UserProfile{
-		UserID:    id,
-		Login:     login,
-		FirstName: firstName,
-		LastName:  lastName,
-		Email:     email,
-		Address:   address,
+func (s *UserService) register(saga *RegistrationSaga, password string) (UserID, error) {
+	err := s.startRegistrationSaga(saga)
+	if err != nil {
+		return "", err
 	}
 
-	err = s.executeInTransaction(func(provider RepositoryProvider) error {
-		err2 := provider.UserProfileRepository().Store(&userProfile)
-		if err2 != nil {
-			return err2
-		}
-
-		event := NewUserRegisteredEvent(id, login)
-		err2 = provider.EventStore().Add(event)
-		if err2 != nil {
-			return err2
-		}
-		s.eventSender.EventStored(event.UID)
-
-		return nil
-	})
+	id, err := s.authSvcClient.RegisterUser(saga.Login, password)
+	if err == nil {
+		saga.setAuthRegistered(id, time.Now(), s.registrationSagaTimeout)
+		err = s.executeInTransaction(func(provider RepositoryProvider) error {
+			return provider.RegistrationSagaRepository().Store(saga)
+		})
+	}
+	if err == nil {
+		err = s.completeRegistrationSaga(saga)
+	}
 	if err != nil {
-		_ = s.authSvcClient.RemoveUser(id)
+		if compensationErr := s.failRegistrationSaga(saga, err); compensationErr != nil {
+			return "", errors.Wrap(err, compensationErr.Error())
+		}
 		return "", err
 	}
-
-	s.eventSender.SendStoredEvents()
-	return id, err
+	return id, nil
 }
 
 func (s *UserService) Update(requestID RequestID, id UserID, firstName, lastName *string, email *Email, address *Address) error {
@@ -75,7 +128,7 @@ func (s *UserService) Update(requestID RequestID, id UserID, firstName, lastName
 		}
 	}
 
-	return s.executeInTransaction(func(provider RepositoryProvider) error {
+	err := s.executeInTransaction(func(provider RepositoryProvider) error {
 		eventRepo := provider.ProcessedRequestRepository()
 		alreadyProcessed, err := eventRepo.SetRequestProcessed(requestID)
 		if err != nil {
@@ -90,20 +143,103 @@ func (s *UserService) Update(requestID RequestID, id UserID, firstName, lastName
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		if user.IsDeleted() {
+			return errors.WithStack(ErrUserNotFound)
+		}
 		if firstName != nil {
 			user.FirstName = *firstName
 		}
 		if lastName != nil {
 			user.LastName = *lastName
 		}
-		if email != nil {
+		emailChanged := email != nil && *email != user.Email
+		if emailChanged {
 			user.Email = *email
+			user.EmailVerified = false
 		}
 		if address != nil {
 			user.Address = *address
 		}
-		return profileRepo.Store(user)
+		if err = profileRepo.Store(user); err != nil {
+			return err
+		}
+		if err = s.storeEvent(provider, NewProfileUpdatedEvent(*user)); err != nil {
+			return err
+		}
+		if emailChanged {
+			return s.requestEmailVerification(provider, user)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.eventSender.SendStoredEvents()
+	return nil
+}
+
+// RequestEmailVerification - sends new verification token, previously issued tokens stay valid until expiration
+func (s *UserService) RequestEmailVerification(id UserID) error {
+	err := s.executeInTransaction(func(provider RepositoryProvider) error {
+		user, err := provider.UserProfileRepository().FindByID(id)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if user.IsDeleted() {
+			return errors.WithStack(ErrUserNotFound)
+		}
+		if user.EmailVerified {
+			return errors.WithStack(ErrEmailAlreadyVerified)
+		}
+		return s.requestEmailVerification(provider, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.eventSender.SendStoredEvents()
+	return nil
+}
+
+// VerifyEmail - token confirms only the email it was issued for, repeated verification is not an error
+func (s *UserService) VerifyEmail(signedToken string) error {
+	token, err := s.tokenSigner.Parse(signedToken)
+	if err != nil {
+		return errors.Wrap(ErrInvalidVerificationToken, err.Error())
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return errors.Wrap(ErrInvalidVerificationToken, "token expired")
+	}
+
+	err = s.executeInTransaction(func(provider RepositoryProvider) error {
+		profileRepo := provider.UserProfileRepository()
+		user, err2 := profileRepo.FindByID(token.UserID)
+		if err2 != nil {
+			if errors.Cause(err2) == ErrUserNotFound {
+				return errors.Wrap(ErrInvalidVerificationToken, "user not found")
+			}
+			return errors.WithStack(err2)
+		}
+		if user.IsDeleted() || user.Email != token.Email {
+			return errors.Wrap(ErrInvalidVerificationToken, "email changed")
+		}
+		if user.EmailVerified {
+			return nil
+		}
+
+		user.EmailVerified = true
+		if err2 = profileRepo.Store(user); err2 != nil {
+			return err2
+		}
+		return s.storeEvent(provider, NewEmailVerifiedEvent(user.UserID, user.Email))
 	})
+	if err != nil {
+		return err
+	}
+
+	s.eventSender.SendStoredEvents()
+	return nil
 }
 
 func (s *UserService) GetUserProfile(id UserID) (*UserProfile, error) {
@@ -111,6 +247,302 @@ func (s *UserService) GetUserProfile(id UserID) (*UserProfile, error) {
 	return user, errors.WithStack(err)
 }
 
+// GetPublicProfile - profile of deleted user is not shown
+func (s *UserService) GetPublicProfile(id UserID) (*PublicProfile, error) {
+	user, err := s.readRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if user.IsDeleted() {
+		return nil, errors.WithStack(ErrUserNotFound)
+	}
+	stats, err := s.lotSvcClient.GetSellerStats(id)
+	if err != nil {
+		return nil, err
+	}
+	profile := newPublicProfile(*user, *stats)
+	return &profile, nil
+}
+
+func (s *UserService) SearchUsers(loginPrefix string) ([]UserProfile, error) {
+	loginPrefix = strings.TrimSpace(loginPrefix)
+	if loginPrefix == "" {
+		return nil, errors.WithStack(ErrInvalidSearchQuery)
+	}
+	return s.readRepo.FindByLoginPrefix(loginPrefix, MaxUserSearchResults)
+}
+
+// RequestPasswordReset - unknown email is not reported, so registered emails can't be enumerated
+func (s *UserService) RequestPasswordReset(email Email) error {
+	user, err := s.readRepo.FindByEmail(email)
+	if err != nil {
+		if errors.Cause(err) == ErrUserNotFound {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	if user.IsDeleted() {
+		return nil
+	}
+	return s.authSvcClient.RequestPasswordReset(user.UserID, user.Email)
+}
+
+// Delete - anonymizes profile and notifies other services with user.user_deleted event, then removes credentials.
+// Repeated call completes deletion interrupted after profile anonymization
+func (s *UserService) Delete(id UserID) error {
+	user, err := s.readRepo.FindByID(id)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !user.IsDeleted() {
+		// lot service rejects new lots and bids of user before counting activity, so activity can't appear after check
+		activity, err := s.lotSvcClient.StartUserDeletion(id)
+		if err != nil {
+			return err
+		}
+		if activity.ActiveLotCount > 0 || activity.ActiveBidCount > 0 {
+			return errors.Wrapf(ErrUserHasActiveLots, "%d lots, %d bids", activity.ActiveLotCount, activity.ActiveBidCount)
+		}
+
+		err = s.executeInTransaction(func(provider RepositoryProvider) error {
+			profileRepo := provider.UserProfileRepository()
+			user, err2 := profileRepo.FindByID(id)
+			if err2 != nil {
+				return errors.WithStack(err2)
+			}
+			if user.IsDeleted() {
+				return nil
+			}
+			user.anonymize(time.Now())
+			if err2 = profileRepo.Store(user); err2 != nil {
+				return err2
+			}
+			if err2 = provider.ShippingAddressRepository().RemoveAllByUserID(id); err2 != nil {
+				return err2
+			}
+			if err2 = s.storeEvent(provider, NewProfileUpdatedEvent(*user)); err2 != nil {
+				return err2
+			}
+			return s.storeEvent(provider, NewUserDeletedEvent(id))
+		})
+		if err != nil {
+			return err
+		}
+		s.eventSender.SendStoredEvents()
+	}
+
+	return s.authSvcClient.RemoveUser(id)
+}
+
+// ExportUserData - collects user data from all services, fails if any service is unavailable
+func (s *UserService) ExportUserData(id UserID) (*UserDataExport, error) {
+	user, err := s.readRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if user.IsDeleted() {
+		return nil, errors.WithStack(ErrUserNotFound)
+	}
+
+	addresses, err := s.addressReadRepo.FindAllByUserID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := UserDataExport{
+		Profile:   *user,
+		Addresses: addresses,
+		Parts:     make([]UserDataPart, 0, len(s.userDataSources)),
+	}
+	for _, source := range s.userDataSources {
+		data, err := source.ExportUserData(id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "export from %s", source.Name())
+		}
+		res.Parts = append(res.Parts, UserDataPart{Name: source.Name(), Data: data})
+	}
+	return &res, nil
+}
+
+// RecoverRegistrationSagas - resumes registrations interrupted by crash and retries failed compensations.
+// Lock is held until all sagas are processed, so sagas aren't processed by several instances at once,
+// each saga is processed under its own lock, so running registration is waited for
+func (s *UserService) RecoverRegistrationSagas() error {
+	return s.executeWithLock(registrationSagaRecoveryLockName, s.recoverRegistrationSagas)
+}
+
+func (s *UserService) recoverRegistrationSagas() error {
+	sagas, err := s.sagaReadRepo.FindAllReadyForAttempt(time.Now(), registrationSagaRecoveryBatchSize)
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for i := range sagas {
+		sagaID := sagas[i].ID
+		err2 := s.executeWithLock(registrationSagaLockName(sagaID), func() error {
+			return s.recoverRegistrationSaga(sagaID)
+		})
+		if err2 != nil {
+			errs = append(errs, fmt.Sprintf("saga %s: %s", sagas[i].ID, err2))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("registration saga recovery failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// recoverRegistrationSaga - profile is stored if credentials are known to be created, otherwise registration is compensated.
+// Saga is read again under saga lock, it could be finished or changed by registration which held the lock
+func (s *UserService) recoverRegistrationSaga(sagaID RegistrationSagaID) error {
+	saga, err := s.sagaReadRepo.FindByID(sagaID)
+	if err != nil {
+		if errors.Cause(err) == ErrRegistrationSagaNotFound {
+			return nil
+		}
+		return err
+	}
+	if saga.NextAttemptTime.After(time.Now()) {
+		return nil
+	}
+
+	if saga.State == RegistrationSagaAuthRegistered {
+		err := s.completeRegistrationSaga(saga)
+		if err == nil {
+			return nil
+		}
+		if compensationErr := s.failRegistrationSaga(saga, err); compensationErr != nil {
+			return errors.Wrap(err, compensationErr.Error())
+		}
+		return nil
+	}
+	if saga.State == RegistrationSagaStarted {
+		return s.failRegistrationSaga(saga, errors.New("registration interrupted"))
+	}
+
+	err = s.compensateRegistration(saga)
+	if err == nil {
+		return nil
+	}
+	saga.setCompensating(err, time.Now())
+	storeErr := s.executeInTransaction(func(provider RepositoryProvider) error {
+		return provider.RegistrationSagaRepository().Store(saga)
+	})
+	if storeErr != nil {
+		return errors.Wrap(err, storeErr.Error())
+	}
+	return err
+}
+
+func (s *UserService) startRegistrationSaga(saga *RegistrationSaga) error {
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		sagaRepo := provider.RegistrationSagaRepository()
+		_, err := sagaRepo.FindByLogin(saga.Login)
+		if err == nil {
+			return errors.WithStack(ErrRegistrationInProgress)
+		}
+		if errors.Cause(err) != ErrRegistrationSagaNotFound {
+			return err
+		}
+		return sagaRepo.Store(saga)
+	})
+}
+
+// completeRegistrationSaga - profile is stored and saga is removed in one transaction
+func (s *UserService) completeRegistrationSaga(saga *RegistrationSaga) error {
+	userProfile := saga.profile()
+	err := s.executeInTransaction(func(provider RepositoryProvider) error {
+		err := provider.UserProfileRepository().Store(&userProfile)
+		if err != nil {
+			return err
+		}
+		if err = provider.RegistrationSagaRepository().Remove(saga.ID); err != nil {
+			return err
+		}
+
+		if err = s.storeEvent(provider, NewUserRegisteredEvent(userProfile.UserID, userProfile.Login)); err != nil {
+			return err
+		}
+		if err = s.storeEvent(provider, NewProfileUpdatedEvent(userProfile)); err != nil {
+			return err
+		}
+		if userProfile.EmailVerified {
+			return s.storeEvent(provider, NewEmailVerifiedEvent(userProfile.UserID, userProfile.Email))
+		}
+		return s.requestEmailVerification(provider, &userProfile)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.eventSender.SendStoredEvents()
+	return nil
+}
+
+// failRegistrationSaga - stores compensating state before compensation, so compensation is retried if it fails
+func (s *UserService) failRegistrationSaga(saga *RegistrationSaga, cause error) error {
+	saga.setCompensating(cause, time.Now())
+	err := s.executeInTransaction(func(provider RepositoryProvider) error {
+		return provider.RegistrationSagaRepository().Store(saga)
+	})
+	if err != nil {
+		return err
+	}
+	return s.compensateRegistration(saga)
+}
+
+// compensateRegistration - removes credentials created by saga. If registration result is unknown, credentials are found by login.
+// Credentials of user with stored profile aren't removed, such login belongs to another user
+func (s *UserService) compensateRegistration(saga *RegistrationSaga) error {
+	var userID UserID
+	if saga.UserID != nil {
+		userID = *saga.UserID
+	} else {
+		id, err := s.authUserFinder.FindUserIDByLogin(saga.Login)
+		if err != nil && errors.Cause(err) != ErrUserNotFound {
+			return err
+		}
+		userID = id
+	}
+
+	if userID != "" {
+		_, err := s.readRepo.FindByID(userID)
+		if err != nil && errors.Cause(err) != ErrUserNotFound {
+			return errors.WithStack(err)
+		}
+		if err != nil {
+			if err = s.authSvcClient.RemoveUser(userID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.executeInTransaction(func(provider RepositoryProvider) error {
+		return provider.RegistrationSagaRepository().Remove(saga.ID)
+	})
+}
+
+func (s *UserService) requestEmailVerification(provider RepositoryProvider, user *UserProfile) error {
+	expiresAt := time.Now().Add(s.verificationTokenTTL)
+	token, err := s.tokenSigner.Sign(EmailVerificationToken{
+		UserID:    user.UserID,
+		Email:     user.Email,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	return s.storeEvent(provider, NewEmailVerificationRequestedEvent(user.UserID, user.Email, token, expiresAt))
+}
+
+func (s *UserService) storeEvent(provider RepositoryProvider, event integrationevent.EventData) error {
+	if err := provider.EventStore().Add(event); err != nil {
+		return err
+	}
+	s.eventSender.EventStored(event.UID)
+	return nil
+}
+
 func (s *UserService) checkEmail(email Email, userID *UserID) error {
 	if _, err := mail.ParseAddress(string(email)); err != nil {
 		return errors.Wrap(ErrInvalidEmail, err.Error())
@@ -125,6 +557,16 @@ func (s *UserService) checkEmail(email Email, userID *UserID) error {
 	return nil
 }
 
+func (s *UserService) executeWithLock(lockName string, f func() error) error {
+	return joblock.Run(func() (joblock.LockTransaction, error) {
+		return s.trUnitFactory.NewTransactionalUnit()
+	}, lockName, f)
+}
+
+func registrationSagaLockName(id RegistrationSagaID) string {
+	return fmt.Sprintf(registrationSagaLockNameTpl, id)
+}
+
 func (s *UserService) executeInTransaction(f func(RepositoryProvider) error) (err error) {
 	var trUnit TransactionalUnit
 	trUnit, err = s.trUnitFactory.NewTransactionalUnit()
@@ -0,0 +1,169 @@
+package app_test
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/user/app"
+
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserDeleted(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	lotClient := newTestLotClient()
+	service := newTestUserServiceWithLotClient(db, auth, lotClient)
+	userID := addTestUser(db, auth, "user")
+
+	assert.NoError(t, service.Delete(userID))
+	assert.Equal(t, []app.UserID{userID}, lotClient.deletionStarted)
+	assert.NotNil(t, db.profiles[userID].DeletionTime)
+	assert.Equal(t, "deleted-"+string(userID), db.profiles[userID].Login)
+	assert.NotContains(t, db.addressOwners, userID)
+	assert.Empty(t, auth.users)
+	assert.Equal(t, []string{"user.profile_updated", "user.user_deleted"}, db.eventTypes())
+}
+
+func TestUserWithActiveLotsNotDeleted(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	lotClient := newTestLotClient()
+	service := newTestUserServiceWithLotClient(db, auth, lotClient)
+	sellerID := addTestUser(db, auth, "seller")
+	bidderID := addTestUser(db, auth, "bidder")
+	lotClient.activity[sellerID] = app.UserActivity{ActiveLotCount: 1}
+	lotClient.activity[bidderID] = app.UserActivity{ActiveBidCount: 1}
+
+	for _, userID := range []app.UserID{sellerID, bidderID} {
+		err := service.Delete(userID)
+		assert.Equal(t, app.ErrUserHasActiveLots, errorsCause(err))
+		assert.Nil(t, db.profiles[userID].DeletionTime)
+		assert.Contains(t, db.addressOwners, userID)
+	}
+	assert.Len(t, auth.users, 2)
+	assert.Empty(t, db.events)
+}
+
+func TestInterruptedUserDeletionCompletedByRepeatedCall(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	lotClient := newTestLotClient()
+	service := newTestUserServiceWithLotClient(db, auth, lotClient)
+	userID := addTestUser(db, auth, "user")
+
+	auth.failRemove = true
+	assert.Error(t, service.Delete(userID))
+	assert.NotNil(t, db.profiles[userID].DeletionTime)
+	assert.Len(t, auth.users, 1)
+
+	// lots created after first call are rejected by lot service, so repeated call doesn't check activity
+	auth.failRemove = false
+	lotClient.activity[userID] = app.UserActivity{ActiveLotCount: 1}
+	assert.NoError(t, service.Delete(userID))
+	assert.Empty(t, auth.users)
+	assert.Len(t, lotClient.deletionStarted, 1)
+	assert.Equal(t, []string{"user.profile_updated", "user.user_deleted"}, db.eventTypes())
+}
+
+func TestPublicProfileMemberSince(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserServiceWithLotClient(db, auth, newTestLotClient())
+
+	before := time.Now()
+	userID, err := service.Add("user", "password", "First", "Last", "user@example.com", "")
+	assert.NoError(t, err)
+	profile, err := service.GetPublicProfile(userID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, profile.MemberSince) {
+		assert.False(t, profile.MemberSince.Before(before))
+	}
+
+	// registration time of old user wasn't stored and wasn't found in stored events
+	oldUserID := addTestUser(db, auth, "old")
+	profile, err = service.GetPublicProfile(oldUserID)
+	assert.NoError(t, err)
+	assert.Nil(t, profile.MemberSince)
+}
+
+func TestEmailVerified(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+	userID := addTestUser(db, auth, "user")
+
+	token := signTestToken(t, userID, "user@example.com", time.Now().Add(time.Hour))
+	assert.NoError(t, service.VerifyEmail(token))
+	assert.True(t, db.profiles[userID].EmailVerified)
+	assert.Equal(t, []string{"user.email_verified"}, db.eventTypes())
+
+	// repeated verification is not an error and doesn't send event again
+	assert.NoError(t, service.VerifyEmail(token))
+	assert.Len(t, db.events, 1)
+}
+
+func TestExpiredEmailVerificationTokenRejected(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+	userID := addTestUser(db, auth, "user")
+
+	token := signTestToken(t, userID, "user@example.com", time.Now().Add(-time.Minute))
+	assert.Equal(t, app.ErrInvalidVerificationToken, errorsCause(service.VerifyEmail(token)))
+	assert.False(t, db.profiles[userID].EmailVerified)
+	assert.Empty(t, db.events)
+}
+
+func TestEmailVerificationTokenOfChangedEmailRejected(t *testing.T) {
+	db := newTestDB()
+	auth := newTestAuthClient()
+	service := newTestUserService(db, auth)
+	userID := addTestUser(db, auth, "user")
+
+	token := signTestToken(t, userID, "user@example.com", time.Now().Add(time.Hour))
+	newEmail := app.Email("new@example.com")
+	assert.NoError(t, service.Update(app.RequestID(uuid.GenerateNew()), userID, nil, nil, &newEmail, nil))
+
+	assert.Equal(t, app.ErrInvalidVerificationToken, errorsCause(service.VerifyEmail(token)))
+	assert.False(t, db.profiles[userID].EmailVerified)
+	assert.Equal(t, []string{"user.profile_updated", "user.email_verification_requested"}, db.eventTypes())
+}
+
+func signTestToken(t *testing.T, userID app.UserID, email app.Email, expiresAt time.Time) string {
+	token, err := testTokenSigner.Sign(app.EmailVerificationToken{UserID: userID, Email: email, ExpiresAt: expiresAt})
+	assert.NoError(t, err)
+	return token
+}
+
+func addTestUser(db *testDB, auth *testAuthClient, login string) app.UserID {
+	userID := auth.register(login)
+	db.profiles[userID] = app.UserProfile{
+		UserID:    userID,
+		Login:     login,
+		FirstName: "First",
+		Email:     app.Email(login + "@example.com"),
+	}
+	db.addressOwners[userID] = true
+	return userID
+}
+
+type testLotClient struct {
+	activity        map[app.UserID]app.UserActivity
+	deletionStarted []app.UserID
+}
+
+func newTestLotClient() *testLotClient {
+	return &testLotClient{activity: map[app.UserID]app.UserActivity{}}
+}
+
+func (c *testLotClient) StartUserDeletion(userID app.UserID) (*app.UserActivity, error) {
+	c.deletionStarted = append(c.deletionStarted, userID)
+	activity := c.activity[userID]
+	return &activity, nil
+}
+
+func (c *testLotClient) GetSellerStats(app.UserID) (*app.SellerStats, error) {
+	return &app.SellerStats{}, nil
+}
@@ -0,0 +1,65 @@
+package addressvalidation
+
+import (
+	"arch-homework/pkg/user/app"
+
+	"github.com/pkg/errors"
+
+	"regexp"
+)
+
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,19}$`)
+
+// NewAddressValidator - checks fields required for any country, then rules of address country if they are registered.
+// Addresses of countries without registered rules are accepted after common checks
+func NewAddressValidator(countryValidators map[string]app.AddressValidator) app.AddressValidator {
+	return &addressValidator{countryValidators: countryValidators}
+}
+
+// DefaultCountryValidators - postal code formats of supported countries
+func DefaultCountryValidators() map[string]app.AddressValidator {
+	return map[string]app.AddressValidator{
+		"RU": NewPostalCodeValidator(`^[0-9]{6}$`),
+		"BY": NewPostalCodeValidator(`^[0-9]{6}$`),
+		"KZ": NewPostalCodeValidator(`^([0-9]{6}|[A-Z][0-9]{2}[A-Z][0-9][A-Z][0-9])$`),
+		"DE": NewPostalCodeValidator(`^[0-9]{5}$`),
+		"US": NewPostalCodeValidator(`^[0-9]{5}(-[0-9]{4})?$`),
+		"GB": NewPostalCodeValidator(`^[A-Z]{1,2}[0-9][A-Z0-9]? ?[0-9][A-Z]{2}$`),
+	}
+}
+
+type addressValidator struct {
+	countryValidators map[string]app.AddressValidator
+}
+
+func (v *addressValidator) Validate(address app.ShippingAddress) error {
+	if address.RecipientName == "" || address.City == "" || address.Street == "" {
+		return errors.Wrap(app.ErrInvalidAddress, "recipient name, city and street required")
+	}
+	if !countryCodePattern.MatchString(address.Country) {
+		return errors.Wrap(app.ErrInvalidAddress, "country must be ISO 3166-1 alpha-2 code")
+	}
+	if !phonePattern.MatchString(address.Phone) {
+		return errors.Wrap(app.ErrInvalidAddress, "invalid phone")
+	}
+	if countryValidator, ok := v.countryValidators[address.Country]; ok {
+		return countryValidator.Validate(address)
+	}
+	return nil
+}
+
+func NewPostalCodeValidator(pattern string) app.AddressValidator {
+	return &postalCodeValidator{pattern: regexp.MustCompile(pattern)}
+}
+
+type postalCodeValidator struct {
+	pattern *regexp.Regexp
+}
+
+func (v *postalCodeValidator) Validate(address app.ShippingAddress) error {
+	if !v.pattern.MatchString(address.PostalCode) {
+		return errors.Wrapf(app.ErrInvalidAddress, "invalid postal code for %s", address.Country)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package addressvalidation
+
+import (
+	"arch-homework/pkg/user/app"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+)
+
+func TestValidateAddress(t *testing.T) {
+	validator := NewAddressValidator(DefaultCountryValidators())
+	address := app.ShippingAddress{
+		RecipientName: "Ivan Ivanov",
+		Phone:         "+7 (900) 123-45-67",
+		Country:       "RU",
+		PostalCode:    "101000",
+		City:          "Moscow",
+		Street:        "Tverskaya 1",
+	}
+	assert.NoError(t, validator.Validate(address))
+
+	invalidPostalCode := address
+	invalidPostalCode.PostalCode = "1010"
+	assert.Equal(t, app.ErrInvalidAddress, errors.Cause(validator.Validate(invalidPostalCode)))
+
+	invalidPhone := address
+	invalidPhone.Phone = "phone"
+	assert.Equal(t, app.ErrInvalidAddress, errors.Cause(validator.Validate(invalidPhone)))
+
+	noStreet := address
+	noStreet.Street = ""
+	assert.Equal(t, app.ErrInvalidAddress, errors.Cause(validator.Validate(noStreet)))
+}
+
+func TestValidateAddressOfCountryWithoutRules(t *testing.T) {
+	validator := NewAddressValidator(DefaultCountryValidators())
+	address := app.ShippingAddress{
+		RecipientName: "John Doe",
+		Phone:         "+353 1 234 5678",
+		Country:       "IE",
+		City:          "Dublin",
+		Street:        "O'Connell Street 1",
+	}
+	assert.NoError(t, validator.Validate(address))
+
+	address.Country = "Ireland"
+	assert.Equal(t, app.ErrInvalidAddress, errors.Cause(validator.Validate(address)))
+}
+
+func TestValidateUSPostalCode(t *testing.T) {
+	validator := NewAddressValidator(DefaultCountryValidators())
+	address := app.ShippingAddress{
+		RecipientName: "John Doe",
+		Phone:         "+1 212 555 0100",
+		Country:       "US",
+		PostalCode:    "10001-1234",
+		City:          "New York",
+		Street:        "5th Avenue 1",
+	}
+	assert.NoError(t, validator.Validate(address))
+
+	address.PostalCode = "1000"
+	assert.Error(t, validator.Validate(address))
+}
@@ -0,0 +1,74 @@
+package encoding
+
+import (
+	"arch-homework/pkg/user/app"
+
+	"github.com/pkg/errors"
+
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+var errInvalidSignature = errors.New("invalid token signature")
+
+// NewEmailVerificationTokenSigner - token is base64url(payload).base64url(HMAC-SHA256(payload))
+func NewEmailVerificationTokenSigner(secret []byte) app.EmailVerificationTokenSigner {
+	return &tokenSigner{secret: secret}
+}
+
+type tokenSigner struct {
+	secret []byte
+}
+
+func (s *tokenSigner) Sign(token app.EmailVerificationToken) (string, error) {
+	payload, err := json.Marshal(tokenPayload{
+		UserID:    string(token.UserID),
+		Email:     string(token.Email),
+		ExpiresAt: token.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	return encodedPayload + "." + base64.RawURLEncoding.EncodeToString(s.sign(encodedPayload)), nil
+}
+
+func (s *tokenSigner) Parse(signed string) (app.EmailVerificationToken, error) {
+	parts := strings.Split(signed, ".")
+	if len(parts) != 2 {
+		return app.EmailVerificationToken{}, errors.WithStack(errInvalidSignature)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, s.sign(parts[0])) {
+		return app.EmailVerificationToken{}, errors.WithStack(errInvalidSignature)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return app.EmailVerificationToken{}, errors.WithStack(err)
+	}
+	var p tokenPayload
+	if err = json.Unmarshal(payload, &p); err != nil {
+		return app.EmailVerificationToken{}, errors.WithStack(err)
+	}
+	return app.EmailVerificationToken{
+		UserID:    app.UserID(p.UserID),
+		Email:     app.Email(p.Email),
+		ExpiresAt: time.Unix(p.ExpiresAt, 0),
+	}, nil
+}
+
+func (s *tokenSigner) sign(encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
+
+type tokenPayload struct {
+	UserID    string `json:"uid"`
+	Email     string `json:"email"`
+	ExpiresAt int64  `json:"exp"`
+}
@@ -0,0 +1,52 @@
+package encoding
+
+import (
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/user/app"
+
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+	"time"
+)
+
+func TestSignAndParseEmailVerificationToken(t *testing.T) {
+	signer := NewEmailVerificationTokenSigner([]byte("secret"))
+	token := app.EmailVerificationToken{
+		UserID:    app.UserID(uuid.GenerateNew()),
+		Email:     "user@example.com",
+		ExpiresAt: time.Unix(time.Now().Add(time.Hour).Unix(), 0),
+	}
+
+	signed, err := signer.Sign(token)
+	assert.NoError(t, err)
+
+	parsed, err := signer.Parse(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, token, parsed)
+}
+
+func TestParseTamperedEmailVerificationToken(t *testing.T) {
+	signer := NewEmailVerificationTokenSigner([]byte("secret"))
+	signed, err := signer.Sign(app.EmailVerificationToken{
+		UserID:    app.UserID(uuid.GenerateNew()),
+		Email:     "user@example.com",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.NoError(t, err)
+
+	_, err = NewEmailVerificationTokenSigner([]byte("other secret")).Parse(signed)
+	assert.Error(t, err)
+
+	otherSigned, err := signer.Sign(app.EmailVerificationToken{
+		UserID:    app.UserID(uuid.GenerateNew()),
+		Email:     "other@example.com",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	_, err = signer.Parse(otherSigned[:len(otherSigned)/2] + signed[len(signed)/2:])
+	assert.Error(t, err)
+
+	_, err = signer.Parse("invalid")
+	assert.Error(t, err)
+}
@@ -28,6 +28,14 @@ func (d *dbDependency) UserProfileRepositoryRead() app.UserProfileRepositoryRead
 	return NewUserProfileRepository(d.client)
 }
 
+func (d *dbDependency) ShippingAddressRepositoryRead() app.ShippingAddressRepositoryRead {
+	return NewShippingAddressRepository(d.client)
+}
+
+func (d *dbDependency) RegistrationSagaRepositoryRead() app.RegistrationSagaRepositoryRead {
+	return NewRegistrationSagaRepository(d.client)
+}
+
 type transactionalUnit struct {
 	transaction postgres.Transaction
 }
@@ -40,10 +48,24 @@ func (t *transactionalUnit) UserProfileRepository() app.UserProfileRepository {
 	return NewUserProfileRepository(t.transaction)
 }
 
+func (t *transactionalUnit) ShippingAddressRepository() app.ShippingAddressRepository {
+	return NewShippingAddressRepository(t.transaction)
+}
+
 func (t *transactionalUnit) ProcessedRequestRepository() app.ProcessedRequestRepository {
 	return NewProcessedRequestRepository(t.transaction)
 }
 
+func (t *transactionalUnit) RegistrationSagaRepository() app.RegistrationSagaRepository {
+	return NewRegistrationSagaRepository(t.transaction)
+}
+
+func (t *transactionalUnit) AddLock(lockName string) error {
+	const lockQuery = "SELECT pg_advisory_xact_lock(hashtext($1))"
+	_, err := t.transaction.Exec(lockQuery, lockName)
+	return errors.WithStack(err)
+}
+
 func (t *transactionalUnit) Complete(err error) error {
 	if err != nil {
 		rollbackErr := t.transaction.Rollback()
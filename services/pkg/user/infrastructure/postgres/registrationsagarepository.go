@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/user/app"
+
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const registrationSagaColumns = `id, state, user_id, login, first_name, last_name, email, address, email_verified,
+				attempts, last_error, next_attempt_at, created_at`
+
+func NewRegistrationSagaRepository(client postgres.Client) app.RegistrationSagaRepository {
+	return &registrationSagaRepository{client: client}
+}
+
+type registrationSagaRepository struct {
+	client postgres.Client
+}
+
+func (repo *registrationSagaRepository) FindByID(id app.RegistrationSagaID) (*app.RegistrationSaga, error) {
+	const query = `SELECT ` + registrationSagaColumns + ` FROM registration_saga WHERE id = $1`
+
+	var saga sqlxRegistrationSaga
+	err := repo.client.Get(&saga, query, string(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrRegistrationSagaNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	res := sqlxSagaToSaga(saga)
+	return &res, nil
+}
+
+func (repo *registrationSagaRepository) FindByLogin(login string) (*app.RegistrationSaga, error) {
+	const query = `SELECT ` + registrationSagaColumns + ` FROM registration_saga WHERE login = $1`
+
+	var saga sqlxRegistrationSaga
+	err := repo.client.Get(&saga, query, login)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrRegistrationSagaNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	res := sqlxSagaToSaga(saga)
+	return &res, nil
+}
+
+func (repo *registrationSagaRepository) FindAllReadyForAttempt(now time.Time, limit int) ([]app.RegistrationSaga, error) {
+	const query = `
+			SELECT ` + registrationSagaColumns + ` FROM registration_saga
+			WHERE next_attempt_at <= $1 ORDER BY next_attempt_at LIMIT $2
+		`
+
+	var sagas []sqlxRegistrationSaga
+	err := repo.client.Select(&sagas, query, now.UTC(), limit)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.RegistrationSaga, 0, len(sagas))
+	for _, saga := range sagas {
+		res = append(res, sqlxSagaToSaga(saga))
+	}
+	return res, nil
+}
+
+func (repo *registrationSagaRepository) Store(saga *app.RegistrationSaga) error {
+	const query = `
+			INSERT INTO registration_saga (` + registrationSagaColumns + `)
+			VALUES (:id, :state, :user_id, :login, :first_name, :last_name, :email, :address, :email_verified,
+				:attempts, :last_error, :next_attempt_at, :created_at)
+			ON CONFLICT (id) DO UPDATE SET
+				state = excluded.state,
+				user_id = excluded.user_id,
+				attempts = excluded.attempts,
+				last_error = excluded.last_error,
+				next_attempt_at = excluded.next_attempt_at;
+		`
+
+	sagax := sqlxRegistrationSaga{
+		ID:              string(saga.ID),
+		State:           string(saga.State),
+		Login:           saga.Login,
+		FirstName:       saga.FirstName,
+		LastName:        saga.LastName,
+		Email:           string(saga.Email),
+		Address:         string(saga.Address),
+		EmailVerified:   saga.EmailVerified,
+		Attempts:        saga.Attempts,
+		LastError:       saga.LastError,
+		NextAttemptTime: saga.NextAttemptTime.UTC(),
+		CreationTime:    saga.CreationTime.UTC(),
+	}
+	if saga.UserID != nil {
+		sagax.UserID.String = string(*saga.UserID)
+		sagax.UserID.Valid = true
+	}
+
+	_, err := repo.client.NamedExec(query, &sagax)
+	return errors.WithStack(err)
+}
+
+func (repo *registrationSagaRepository) Remove(id app.RegistrationSagaID) error {
+	const query = `DELETE FROM registration_saga WHERE id = $1`
+	_, err := repo.client.Exec(query, string(id))
+	return errors.WithStack(err)
+}
+
+func sqlxSagaToSaga(saga sqlxRegistrationSaga) app.RegistrationSaga {
+	var userID *app.UserID
+	if saga.UserID.Valid {
+		id := app.UserID(saga.UserID.String)
+		userID = &id
+	}
+	return app.RegistrationSaga{
+		ID:              app.RegistrationSagaID(saga.ID),
+		State:           app.RegistrationSagaState(saga.State),
+		UserID:          userID,
+		Login:           saga.Login,
+		FirstName:       saga.FirstName,
+		LastName:        saga.LastName,
+		Email:           app.Email(saga.Email),
+		Address:         app.Address(saga.Address),
+		EmailVerified:   saga.EmailVerified,
+		Attempts:        saga.Attempts,
+		LastError:       saga.LastError,
+		NextAttemptTime: saga.NextAttemptTime,
+		CreationTime:    saga.CreationTime,
+	}
+}
+
+type sqlxRegistrationSaga struct {
+	ID              string         `db:"id"`
+	State           string         `db:"state"`
+	UserID          sql.NullString `db:"user_id"`
+	Login           string         `db:"login"`
+	FirstName       string         `db:"first_name"`
+	LastName        string         `db:"last_name"`
+	Email           string         `db:"email"`
+	Address         string         `db:"address"`
+	EmailVerified   bool           `db:"email_verified"`
+	Attempts        int            `db:"attempts"`
+	LastError       string         `db:"last_error"`
+	NextAttemptTime time.Time      `db:"next_attempt_at"`
+	CreationTime    time.Time      `db:"created_at"`
+}
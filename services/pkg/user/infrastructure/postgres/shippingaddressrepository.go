@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"arch-homework/pkg/common/infrastructure/postgres"
+	"arch-homework/pkg/user/app"
+
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+const shippingAddressColumns = `id, user_id, recipient_name, phone, country, postal_code, city, street, is_default`
+
+func NewShippingAddressRepository(client postgres.Client) app.ShippingAddressRepository {
+	return &shippingAddressRepository{client: client}
+}
+
+type shippingAddressRepository struct {
+	client postgres.Client
+}
+
+func (repo *shippingAddressRepository) Store(address *app.ShippingAddress) error {
+	const query = `
+			INSERT INTO shipping_address (` + shippingAddressColumns + `)
+			VALUES (:id, :user_id, :recipient_name, :phone, :country, :postal_code, :city, :street, :is_default)
+			ON CONFLICT (id) DO UPDATE SET
+				recipient_name = excluded.recipient_name,
+				phone = excluded.phone,
+				country = excluded.country,
+				postal_code = excluded.postal_code,
+				city = excluded.city,
+				street = excluded.street,
+				is_default = excluded.is_default;
+		`
+
+	addressx := sqlxShippingAddress{
+		ID:            string(address.ID),
+		UserID:        string(address.UserID),
+		RecipientName: address.RecipientName,
+		Phone:         address.Phone,
+		Country:       address.Country,
+		PostalCode:    address.PostalCode,
+		City:          address.City,
+		Street:        address.Street,
+		IsDefault:     address.IsDefault,
+	}
+
+	_, err := repo.client.NamedExec(query, &addressx)
+	return errors.WithStack(err)
+}
+
+func (repo *shippingAddressRepository) Remove(userID app.UserID, id app.AddressID) error {
+	const query = `DELETE FROM shipping_address WHERE id = $1 AND user_id = $2`
+	_, err := repo.client.Exec(query, string(id), string(userID))
+	return errors.WithStack(err)
+}
+
+func (repo *shippingAddressRepository) RemoveAllByUserID(userID app.UserID) error {
+	const query = `DELETE FROM shipping_address WHERE user_id = $1`
+	_, err := repo.client.Exec(query, string(userID))
+	return errors.WithStack(err)
+}
+
+func (repo *shippingAddressRepository) FindByID(userID app.UserID, id app.AddressID) (*app.ShippingAddress, error) {
+	const query = `SELECT ` + shippingAddressColumns + ` FROM shipping_address WHERE id = $1 AND user_id = $2`
+	return repo.findOne(query, string(id), string(userID))
+}
+
+func (repo *shippingAddressRepository) FindDefault(userID app.UserID) (*app.ShippingAddress, error) {
+	const query = `SELECT ` + shippingAddressColumns + ` FROM shipping_address WHERE user_id = $1 AND is_default`
+	return repo.findOne(query, string(userID))
+}
+
+func (repo *shippingAddressRepository) FindAllByUserID(userID app.UserID) ([]app.ShippingAddress, error) {
+	const query = `SELECT ` + shippingAddressColumns + ` FROM shipping_address WHERE user_id = $1 ORDER BY created_at, id`
+
+	var addresses []sqlxShippingAddress
+	err := repo.client.Select(&addresses, query, string(userID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.ShippingAddress, 0, len(addresses))
+	for _, address := range addresses {
+		res = append(res, sqlxAddressToAddress(address))
+	}
+	return res, nil
+}
+
+func (repo *shippingAddressRepository) findOne(query string, args ...interface{}) (*app.ShippingAddress, error) {
+	var address sqlxShippingAddress
+	err := repo.client.Get(&address, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.WithStack(app.ErrAddressNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	res := sqlxAddressToAddress(address)
+	return &res, nil
+}
+
+func sqlxAddressToAddress(address sqlxShippingAddress) app.ShippingAddress {
+	return app.ShippingAddress{
+		ID:            app.AddressID(address.ID),
+		UserID:        app.UserID(address.UserID),
+		RecipientName: address.RecipientName,
+		Phone:         address.Phone,
+		Country:       address.Country,
+		PostalCode:    address.PostalCode,
+		City:          address.City,
+		Street:        address.Street,
+		IsDefault:     address.IsDefault,
+	}
+}
+
+type sqlxShippingAddress struct {
+	ID            string `db:"id"`
+	UserID        string `db:"user_id"`
+	RecipientName string `db:"recipient_name"`
+	Phone         string `db:"phone"`
+	Country       string `db:"country"`
+	PostalCode    string `db:"postal_code"`
+	City          string `db:"city"`
+	Street        string `db:"street"`
+	IsDefault     bool   `db:"is_default"`
+}
@@ -5,10 +5,14 @@ import (
 	"arch-homework/pkg/user/app"
 
 	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
+const userProfileColumns = `id, login, first_name, last_name, email, email_verified, address, created_at, deleted_at`
+
 func NewUserProfileRepository(client postgres.Client) app.UserProfileRepository {
 	return &userProfileRepository{client: client}
 }
@@ -19,22 +23,34 @@ type userProfileRepository struct {
 
 func (repo *userProfileRepository) Store(profile *app.UserProfile) error {
 	const query = `
-			INSERT INTO user_profile (id, login, first_name, last_name, email, address)
-			VALUES (:id, :login, :first_name, :last_name, :email, :address)
+			INSERT INTO user_profile (id, login, first_name, last_name, email, email_verified, address, created_at, deleted_at)
+			VALUES (:id, :login, :first_name, :last_name, :email, :email_verified, :address, :created_at, :deleted_at)
 			ON CONFLICT (id) DO UPDATE SET
+				login = excluded.login,
 				first_name = excluded.first_name,
 				last_name = excluded.last_name,
 				email = excluded.email,
-				address = excluded.address;
+				email_verified = excluded.email_verified,
+				address = excluded.address,
+				deleted_at = excluded.deleted_at;
 		`
 
 	profilex := sqlxUserProfile{
-		ID:        string(profile.UserID),
-		Login:     profile.Login,
-		FirstName: profile.FirstName,
-		LastName:  profile.LastName,
-		Email:     string(profile.Email),
-		Address:   string(profile.Address),
+		ID:            string(profile.UserID),
+		Login:         profile.Login,
+		FirstName:     profile.FirstName,
+		LastName:      profile.LastName,
+		Email:         string(profile.Email),
+		Address:       string(profile.Address),
+		EmailVerified: profile.EmailVerified,
+	}
+	if profile.CreationTime != nil {
+		profilex.CreationTime.Time = profile.CreationTime.UTC()
+		profilex.CreationTime.Valid = true
+	}
+	if profile.DeletionTime != nil {
+		profilex.DeletionTime.Time = profile.DeletionTime.UTC()
+		profilex.DeletionTime.Valid = true
 	}
 
 	_, err := repo.client.NamedExec(query, &profilex)
@@ -48,7 +64,7 @@ func (repo *userProfileRepository) Remove(id app.UserID) error {
 }
 
 func (repo *userProfileRepository) FindByID(id app.UserID) (*app.UserProfile, error) {
-	const query = `SELECT id, login, first_name, last_name, email, address FROM user_profile WHERE id = $1`
+	const query = `SELECT ` + userProfileColumns + ` FROM user_profile WHERE id = $1`
 
 	var profile sqlxUserProfile
 	err := repo.client.Get(&profile, query, string(id))
@@ -63,7 +79,7 @@ func (repo *userProfileRepository) FindByID(id app.UserID) (*app.UserProfile, er
 }
 
 func (repo *userProfileRepository) FindByEmail(email app.Email) (*app.UserProfile, error) {
-	const query = `SELECT id, login, first_name, last_name, email, address FROM user_profile WHERE email = $1`
+	const query = `SELECT ` + userProfileColumns + ` FROM user_profile WHERE email = $1`
 
 	var profile sqlxUserProfile
 	err := repo.client.Get(&profile, query, string(email))
@@ -77,22 +93,56 @@ func (repo *userProfileRepository) FindByEmail(email app.Email) (*app.UserProfil
 	return &res, nil
 }
 
+func (repo *userProfileRepository) FindByLoginPrefix(prefix string, limit int) ([]app.UserProfile, error) {
+	const query = `
+			SELECT ` + userProfileColumns + ` FROM user_profile
+			WHERE deleted_at IS NULL AND lower(login) LIKE $1
+			ORDER BY login LIMIT $2
+		`
+
+	var profiles []sqlxUserProfile
+	pattern := strings.NewReplacer("%", "\\%", "_", "\\_").Replace(strings.ToLower(prefix)) + "%"
+	err := repo.client.Select(&profiles, query, pattern, limit)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := make([]app.UserProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		res = append(res, sqlxProfileToProfile(profile))
+	}
+	return res, nil
+}
+
 func sqlxProfileToProfile(profile sqlxUserProfile) app.UserProfile {
+	var creationTime *time.Time
+	if profile.CreationTime.Valid {
+		creationTime = &profile.CreationTime.Time
+	}
+	var deletionTime *time.Time
+	if profile.DeletionTime.Valid {
+		deletionTime = &profile.DeletionTime.Time
+	}
 	return app.UserProfile{
-		UserID:    app.UserID(profile.ID),
-		Login:     profile.Login,
-		FirstName: profile.FirstName,
-		LastName:  profile.LastName,
-		Email:     app.Email(profile.Email),
-		Address:   app.Address(profile.Address),
+		UserID:        app.UserID(profile.ID),
+		Login:         profile.Login,
+		FirstName:     profile.FirstName,
+		LastName:      profile.LastName,
+		Email:         app.Email(profile.Email),
+		Address:       app.Address(profile.Address),
+		DeletionTime:  deletionTime,
+		EmailVerified: profile.EmailVerified,
+		CreationTime:  creationTime,
 	}
 }
 
 type sqlxUserProfile struct {
-	ID        string `db:"id"`
-	Login     string `db:"login"`
-	FirstName string `db:"first_name"`
-	LastName  string `db:"last_name"`
-	Email     string `db:"email"`
-	Address   string `db:"address"`
+	ID            string       `db:"id"`
+	Login         string       `db:"login"`
+	FirstName     string       `db:"first_name"`
+	LastName      string       `db:"last_name"`
+	Email         string       `db:"email"`
+	EmailVerified bool         `db:"email_verified"`
+	Address       string       `db:"address"`
+	CreationTime  sql.NullTime `db:"created_at"`
+	DeletionTime  sql.NullTime `db:"deleted_at"`
 }
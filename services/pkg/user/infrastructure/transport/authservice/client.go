@@ -10,15 +10,23 @@ import (
 	"github.com/pkg/errors"
 
 	"net/http"
+	neturl "net/url"
 )
 
 const registerUserURL = "/internal/api/v1/register"
+const findUserURLTemplate = "/internal/api/v1/user?login=%s"
 const removeUserURLTemplate = "/internal/api/v1/user/%s"
+const requestPasswordResetURLTemplate = "/internal/api/v1/user/%s/password/reset"
 
 func NewClient(client http.Client, serviceHost string) app.AuthServiceClient {
 	return &authServiceClient{httpClient: httpclient.NewClient(client, serviceHost)}
 }
 
+// NewUserFinder - lookup is available only over http
+func NewUserFinder(client http.Client, serviceHost string) app.AuthUserFinder {
+	return &authServiceClient{httpClient: httpclient.NewClient(client, serviceHost)}
+}
+
 type authServiceClient struct {
 	httpClient httpclient.Client
 }
@@ -47,12 +55,35 @@ func (c *authServiceClient) RegisterUser(login, password string) (app.UserID, er
 	return app.UserID(response.ID), nil
 }
 
+func (c *authServiceClient) FindUserIDByLogin(login string) (app.UserID, error) {
+	url := fmt.Sprintf(findUserURLTemplate, neturl.QueryEscape(login))
+	response := registerUserResponse{}
+	err := c.httpClient.MakeJSONRequest(nil, &response, http.MethodGet, url, nil)
+	if err != nil {
+		if e, ok := errors.Cause(err).(*httpclient.HTTPError); ok && e.StatusCode == http.StatusNotFound {
+			return "", errors.WithStack(app.ErrUserNotFound)
+		}
+		return "", errors.WithStack(err)
+	}
+	if err = uuid.ValidateUUID(response.ID); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return app.UserID(response.ID), nil
+}
+
 func (c *authServiceClient) RemoveUser(userID app.UserID) error {
 	url := fmt.Sprintf(removeUserURLTemplate, string(userID))
 	err := c.httpClient.MakeJSONRequest(nil, nil, http.MethodDelete, url, nil)
 	return errors.WithStack(err)
 }
 
+func (c *authServiceClient) RequestPasswordReset(userID app.UserID, email app.Email) error {
+	url := fmt.Sprintf(requestPasswordResetURLTemplate, string(userID))
+	request := passwordResetRequest{Email: string(email)}
+	err := c.httpClient.MakeJSONRequest(request, nil, http.MethodPost, url, nil)
+	return errors.WithStack(err)
+}
+
 type registerUserRequest struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
@@ -62,6 +93,10 @@ type registerUserResponse struct {
 	ID string `json:"id"`
 }
 
+type passwordResetRequest struct {
+	Email string `json:"email"`
+}
+
 type errorInfo struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -0,0 +1,56 @@
+package authservice
+
+import (
+	"arch-homework/api/authpb"
+	"arch-homework/pkg/common/app/uuid"
+	"arch-homework/pkg/common/infrastructure/grpcclient"
+	"arch-homework/pkg/user/app"
+
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+func NewGRPCClient(conn *grpc.ClientConn) app.AuthServiceClient {
+	return &authServiceGRPCClient{client: authpb.NewAuthServiceClient(conn)}
+}
+
+type authServiceGRPCClient struct {
+	client authpb.AuthServiceClient
+}
+
+func (c *authServiceGRPCClient) RegisterUser(login, password string) (app.UserID, error) {
+	// registration is not idempotent, so it is not retried
+	response, err := c.client.RegisterUser(context.Background(), &authpb.RegisterUserRequest{
+		Login:    login,
+		Password: password,
+	})
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			return "", errors.WithStack(errors.New(s.Message()))
+		}
+		return "", errors.WithStack(err)
+	}
+
+	if err = uuid.ValidateUUID(response.UserId); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return app.UserID(response.UserId), nil
+}
+
+func (c *authServiceGRPCClient) RemoveUser(userID app.UserID) error {
+	_, err := c.client.RemoveUser(context.Background(), &authpb.RemoveUserRequest{UserId: string(userID)}, grpcclient.Idempotent())
+	return errors.WithStack(err)
+}
+
+func (c *authServiceGRPCClient) RequestPasswordReset(userID app.UserID, email app.Email) error {
+	// each request issues new token, so it is not retried
+	_, err := c.client.RequestPasswordReset(context.Background(), &authpb.RequestPasswordResetRequest{
+		UserId: string(userID),
+		Email:  string(email),
+	})
+	return errors.WithStack(err)
+}
@@ -0,0 +1,61 @@
+package grpc
+
+import (
+	"arch-homework/api/userpb"
+	"arch-homework/pkg/common/infrastructure/grpcserver"
+	"arch-homework/pkg/user/app"
+
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func NewServer(userService *app.UserService) userpb.UserServiceServer {
+	return &server{userService: userService}
+}
+
+type server struct {
+	userpb.UnimplementedUserServiceServer
+	userService *app.UserService
+}
+
+func (s *server) GetUserProfile(_ context.Context, req *userpb.GetUserProfileRequest) (*userpb.UserProfile, error) {
+	if err := grpcserver.ValidateUUID("user id", req.UserId); err != nil {
+		return nil, err
+	}
+	profile, err := s.userService.GetUserProfile(app.UserID(req.UserId))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &userpb.UserProfile{
+		UserId:    string(profile.UserID),
+		Login:     profile.Login,
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		Email:     string(profile.Email),
+		Address:   string(profile.Address),
+	}, nil
+}
+
+func (s *server) RegisterExternalUser(_ context.Context, req *userpb.RegisterExternalUserRequest) (*userpb.RegisterExternalUserResponse, error) {
+	userID, err := s.userService.AddExternal(req.Login, req.FirstName, req.LastName, app.Email(req.Email))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &userpb.RegisterExternalUserResponse{UserId: string(userID)}, nil
+}
+
+func toStatusError(err error) error {
+	switch errors.Cause(err) {
+	case app.ErrUserNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case app.ErrEmailAlreadyExists, app.ErrRegistrationInProgress:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case app.ErrInvalidEmail:
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"archive/zip"
 	"bytes"
 	"encoding/json"
 	"io"
@@ -17,25 +18,51 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 )
 
 const PathPrefix = "/api/v1/"
 const PathPrefixInternal = "/internal/api/v1/"
 
 const (
-	registerUserEndpoint = PathPrefix + "register"
-	userProfileEndpoint  = PathPrefix + "user/profile"
-
-	internalSpecificUserProfileEndpoint = PathPrefixInternal + "user/{id}/profile"
+	registerUserEndpoint  = PathPrefix + "register"
+	userEndpoint          = PathPrefix + "user"
+	userProfileEndpoint   = PathPrefix + "user/profile"
+	userExportEndpoint    = PathPrefix + "user/export"
+	passwordResetEndpoint = PathPrefix + "password/reset"
+
+	usersEndpoint             = PathPrefix + "users"
+	publicUserProfileEndpoint = PathPrefix + "users/{id}"
+
+	emailVerifyEndpoint       = PathPrefix + "user/email/verify"
+	emailVerificationEndpoint = PathPrefix + "user/email/verification"
+
+	addressesEndpoint       = PathPrefix + "user/addresses"
+	specificAddressEndpoint = PathPrefix + "user/addresses/{addressId}"
+	defaultAddressEndpoint  = PathPrefix + "user/addresses/{addressId}/default"
+
+	internalSpecificUserProfileEndpoint  = PathPrefixInternal + "user/{id}/profile"
+	internalRegisterExternalUserEndpoint = PathPrefixInternal + "register/external"
+	internalDefaultAddressEndpoint       = PathPrefixInternal + "user/{id}/addresses/default"
+	internalSpecificAddressEndpoint      = PathPrefixInternal + "user/{id}/addresses/{addressId}"
 )
 
 const (
-	errorCodeUnknown          = 0
-	errorCodeInvalidRequestID = 1
-	errorCodeAlreadyProcessed = 2
-	errorCodeUserNotFound     = 3
-	errorEmailAlreadyExists   = 4
-	errorInvalidEmail         = 5
+	errorCodeUnknown            = 0
+	errorCodeInvalidRequestID   = 1
+	errorCodeAlreadyProcessed   = 2
+	errorCodeUserNotFound       = 3
+	errorEmailAlreadyExists     = 4
+	errorInvalidEmail           = 5
+	errorPasswordRequired       = 6
+	errorUserHasActiveLots      = 7
+	errorInvalidVerification    = 8
+	errorEmailAlreadyVerified   = 9
+	errorAddressNotFound        = 10
+	errorInvalidAddress         = 11
+	errorAddressLimitExceeded   = 12
+	errorInvalidSearchQuery     = 13
+	errorRegistrationInProgress = 14
 )
 
 const authTokenHeader = "X-Auth-Token"
@@ -57,34 +84,65 @@ func (e endpointLabelCollector) EndpointLabelForURI(uri string) string {
 		if r.MatchString(uri) {
 			return internalSpecificUserProfileEndpoint
 		}
+		r, _ = regexp.Compile("^" + PathPrefixInternal + "user/[a-f0-9-]+/addresses/default$")
+		if r.MatchString(uri) {
+			return internalDefaultAddressEndpoint
+		}
+		r, _ = regexp.Compile("^" + PathPrefixInternal + "user/[a-f0-9-]+/addresses/[a-f0-9-]+$")
+		if r.MatchString(uri) {
+			return internalSpecificAddressEndpoint
+		}
+	} else if strings.HasPrefix(uri, PathPrefix+"user/addresses/") {
+		r, _ := regexp.Compile("^" + PathPrefix + "user/addresses/[a-f0-9-]+/default$")
+		if r.MatchString(uri) {
+			return defaultAddressEndpoint
+		}
+		return specificAddressEndpoint
+	} else if strings.HasPrefix(uri, usersEndpoint+"/") {
+		return publicUserProfileEndpoint
 	}
 	return uri
 }
 
 func NewServer(
 	userService *app.UserService,
+	addressBookService *app.AddressBookService,
 	tokenParser jwtauth.TokenParser,
 	logger *logrus.Logger,
 ) *Server {
 	return &Server{
-		userService: userService,
-		tokenParser: tokenParser,
-		logger:      logger,
+		userService:        userService,
+		addressBookService: addressBookService,
+		tokenParser:        tokenParser,
+		logger:             logger,
 	}
 }
 
 type Server struct {
-	userService *app.UserService
-	tokenParser jwtauth.TokenParser
-	logger      *logrus.Logger
+	userService        *app.UserService
+	addressBookService *app.AddressBookService
+	tokenParser        jwtauth.TokenParser
+	logger             *logrus.Logger
 }
 
 func (s *Server) MakeHandler() http.Handler {
 	router := mux.NewRouter()
 
 	router.Methods(http.MethodPost).Path(registerUserEndpoint).Handler(s.makeHandlerFunc(s.registerUserHandler))
+	router.Methods(http.MethodPost).Path(passwordResetEndpoint).Handler(s.makeHandlerFunc(s.requestPasswordResetHandler))
+	router.Methods(http.MethodPost).Path(emailVerifyEndpoint).Handler(s.makeHandlerFunc(s.verifyEmailHandler))
+	router.Methods(http.MethodPost).Path(emailVerificationEndpoint).Handler(s.makeHandlerFunc(s.requestEmailVerificationHandler))
+	router.Methods(http.MethodGet).Path(usersEndpoint).Handler(s.makeHandlerFunc(s.searchUsersHandler))
+	router.Methods(http.MethodGet).Path(publicUserProfileEndpoint).Handler(s.makeHandlerFunc(s.getPublicUserProfileHandler))
 	router.Methods(http.MethodGet).Path(userProfileEndpoint).Handler(s.makeHandlerFunc(s.getUserProfileHandler))
 	router.Methods(http.MethodPut).Path(userProfileEndpoint).Handler(s.makeHandlerFunc(s.updateUserProfileHandler))
+	router.Methods(http.MethodDelete).Path(userEndpoint).Handler(s.makeHandlerFunc(s.deleteUserHandler))
+	router.Methods(http.MethodGet).Path(userExportEndpoint).Handler(s.makeHandlerFunc(s.exportUserDataHandler))
+	router.Methods(http.MethodGet).Path(addressesEndpoint).Handler(s.makeHandlerFunc(s.getAddressesHandler))
+	router.Methods(http.MethodPost).Path(addressesEndpoint).Handler(s.makeHandlerFunc(s.addAddressHandler))
+	router.Methods(http.MethodPut).Path(specificAddressEndpoint).Handler(s.makeHandlerFunc(s.updateAddressHandler))
+	router.Methods(http.MethodDelete).Path(specificAddressEndpoint).Handler(s.makeHandlerFunc(s.removeAddressHandler))
+	router.Methods(http.MethodPost).Path(defaultAddressEndpoint).Handler(s.makeHandlerFunc(s.setDefaultAddressHandler))
 
 	return router
 }
@@ -92,6 +150,9 @@ func (s *Server) MakeHandler() http.Handler {
 func (s *Server) MakeInternalHandler() http.Handler {
 	router := mux.NewRouter()
 	router.Methods(http.MethodGet).Path(internalSpecificUserProfileEndpoint).Handler(s.makeHandlerFunc(s.getUserProfileInternalHandler))
+	router.Methods(http.MethodPost).Path(internalRegisterExternalUserEndpoint).Handler(s.makeHandlerFunc(s.registerExternalUserHandler))
+	router.Methods(http.MethodGet).Path(internalDefaultAddressEndpoint).Handler(s.makeHandlerFunc(s.getDefaultAddressInternalHandler))
+	router.Methods(http.MethodGet).Path(internalSpecificAddressEndpoint).Handler(s.makeHandlerFunc(s.getAddressInternalHandler))
 	return router
 }
 
@@ -160,6 +221,82 @@ func (s *Server) registerUserHandler(w http.ResponseWriter, r *http.Request) err
 	return nil
 }
 
+func (s *Server) registerExternalUserHandler(w http.ResponseWriter, r *http.Request) error {
+	var registerData registerExternalUserData
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &registerData); err != nil {
+		return errors.WithStack(err)
+	}
+
+	userID, err := s.userService.AddExternal(
+		registerData.Login,
+		registerData.FirstName,
+		registerData.LastName,
+		app.Email(registerData.Email),
+	)
+	if err != nil {
+		return err
+	}
+	writeResponse(w, createdUserInfo{UserID: string(userID)})
+	return nil
+}
+
+func (s *Server) requestPasswordResetHandler(w http.ResponseWriter, r *http.Request) error {
+	var info passwordResetData
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &info); err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = s.userService.RequestPasswordReset(app.Email(info.Email))
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) verifyEmailHandler(w http.ResponseWriter, r *http.Request) error {
+	var info emailVerificationData
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &info); err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = s.userService.VerifyEmail(info.Token)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) requestEmailVerificationHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+
+	err = s.userService.RequestEmailVerification(app.UserID(tokenData.UserID()))
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
 func (s *Server) getUserProfileHandler(w http.ResponseWriter, r *http.Request) error {
 	tokenData, err := s.extractAuthorizationData(r)
 	if err != nil {
@@ -188,6 +325,36 @@ func (s *Server) getUserProfileInternalHandler(w http.ResponseWriter, r *http.Re
 	return nil
 }
 
+func (s *Server) getPublicUserProfileHandler(w http.ResponseWriter, r *http.Request) error {
+	id, err := getUserIDFromRequest(r)
+	if err != nil {
+		return errors.Wrap(app.ErrUserNotFound, err.Error())
+	}
+	profile, err := s.userService.GetPublicProfile(id)
+	if err != nil {
+		return err
+	}
+	writeResponse(w, toPublicUserProfileInfo(*profile))
+	return nil
+}
+
+func (s *Server) searchUsersHandler(w http.ResponseWriter, r *http.Request) error {
+	profiles, err := s.userService.SearchUsers(r.URL.Query().Get("login"))
+	if err != nil {
+		return err
+	}
+	res := make([]foundUserInfo, 0, len(profiles))
+	for _, profile := range profiles {
+		res = append(res, foundUserInfo{
+			UserID:      string(profile.UserID),
+			Login:       profile.Login,
+			MemberSince: formatOptionalTime(profile.CreationTime),
+		})
+	}
+	writeResponse(w, res)
+	return nil
+}
+
 func (s *Server) updateUserProfileHandler(w http.ResponseWriter, r *http.Request) error {
 	tokenData, err := s.extractAuthorizationData(r)
 	if err != nil {
@@ -230,6 +397,204 @@ func (s *Server) updateUserProfileHandler(w http.ResponseWriter, r *http.Request
 	return nil
 }
 
+func (s *Server) deleteUserHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+
+	err = s.userService.Delete(app.UserID(tokenData.UserID()))
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, http.StatusText(http.StatusOK))
+	return nil
+}
+
+// exportUserDataHandler - writes zip archive with profile.json and <service>.json for each service storing user data
+func (s *Server) exportUserDataHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+
+	export, err := s.userService.ExportUserData(app.UserID(tokenData.UserID()))
+	if err != nil {
+		return err
+	}
+	profile, err := json.Marshal(toUserProfileInfo(export.Profile))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	addresses, err := json.Marshal(toAddressInfos(export.Addresses))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var archive bytes.Buffer
+	zipWriter := zip.NewWriter(&archive)
+	files := append([]app.UserDataPart{{Name: "profile", Data: profile}, {Name: "addresses", Data: addresses}}, export.Parts...)
+	for _, file := range files {
+		fileWriter, err := zipWriter.Create(file.Name + ".json")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err = fileWriter.Write(file.Data); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err = zipWriter.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="user-data.zip"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive.Bytes())
+	return nil
+}
+
+func (s *Server) getAddressesHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+
+	addresses, err := s.addressBookService.Addresses(app.UserID(tokenData.UserID()))
+	if err != nil {
+		return err
+	}
+	writeResponse(w, toAddressInfos(addresses))
+	return nil
+}
+
+func (s *Server) addAddressHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+	requestID, err := s.getRequestIDHeader(r)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var info addressData
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &info); err != nil {
+		return errors.WithStack(err)
+	}
+
+	addressID, err := s.addressBookService.AddAddress(requestID, app.UserID(tokenData.UserID()), toShippingAddress(info))
+	if err != nil {
+		return err
+	}
+	writeResponse(w, createdAddressInfo{AddressID: string(addressID)})
+	return nil
+}
+
+func (s *Server) updateAddressHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+	addressID, err := getAddressIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	var info addressData
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_ = r.Body.Close()
+	if err = json.Unmarshal(bytesBody, &info); err != nil {
+		return errors.WithStack(err)
+	}
+
+	address := toShippingAddress(info)
+	address.ID = addressID
+	err = s.addressBookService.UpdateAddress(app.UserID(tokenData.UserID()), address)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) removeAddressHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+	addressID, err := getAddressIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	err = s.addressBookService.RemoveAddress(app.UserID(tokenData.UserID()), addressID)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) setDefaultAddressHandler(w http.ResponseWriter, r *http.Request) error {
+	tokenData, err := s.extractAuthorizationData(r)
+	if err != nil {
+		return err
+	}
+	addressID, err := getAddressIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	err = s.addressBookService.SetDefaultAddress(app.UserID(tokenData.UserID()), addressID)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) getAddressInternalHandler(w http.ResponseWriter, r *http.Request) error {
+	userID, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+	addressID, err := getAddressIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	address, err := s.addressBookService.Address(userID, addressID)
+	if err != nil {
+		return err
+	}
+	writeResponse(w, toAddressInfo(*address))
+	return nil
+}
+
+func (s *Server) getDefaultAddressInternalHandler(w http.ResponseWriter, r *http.Request) error {
+	userID, err := getUserIDFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	address, err := s.addressBookService.DefaultAddress(userID)
+	if err != nil {
+		return err
+	}
+	writeResponse(w, toAddressInfo(*address))
+	return nil
+}
+
 func (s *Server) extractAuthorizationData(r *http.Request) (jwtauth.TokenData, error) {
 	token := r.Header.Get(authTokenHeader)
 	if token == "" {
@@ -242,6 +607,9 @@ func (s *Server) extractAuthorizationData(r *http.Request) (jwtauth.TokenData, e
 	if err = uuid.ValidateUUID(tokenData.UserID()); err != nil {
 		return nil, errors.WithStack(err)
 	}
+	if err = jwtauth.CheckScope(tokenData, r, jwtauth.NoScope, jwtauth.NoScope); err != nil {
+		return nil, errors.Wrap(errForbidden, err.Error())
+	}
 	return tokenData, nil
 }
 
@@ -266,6 +634,14 @@ func getUserIDFromRequest(r *http.Request) (app.UserID, error) {
 	return app.UserID(id), nil
 }
 
+func getAddressIDFromRequest(r *http.Request) (app.AddressID, error) {
+	id := mux.Vars(r)["addressId"]
+	if err := uuid.ValidateUUID(id); err != nil {
+		return "", errors.Wrap(app.ErrAddressNotFound, err.Error())
+	}
+	return app.AddressID(id), nil
+}
+
 func writeResponse(w http.ResponseWriter, response interface{}) {
 	js, err := json.Marshal(response)
 	if err != nil {
@@ -289,6 +665,33 @@ func writeErrorResponse(w http.ResponseWriter, err error) {
 	case app.ErrEmailAlreadyExists:
 		info.Code = errorEmailAlreadyExists
 		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrPasswordRequired:
+		info.Code = errorPasswordRequired
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrInvalidVerificationToken:
+		info.Code = errorInvalidVerification
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrEmailAlreadyVerified:
+		info.Code = errorEmailAlreadyVerified
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrAddressNotFound:
+		info.Code = errorAddressNotFound
+		w.WriteHeader(http.StatusNotFound)
+	case app.ErrInvalidAddress:
+		info.Code = errorInvalidAddress
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrAddressLimitExceeded:
+		info.Code = errorAddressLimitExceeded
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrInvalidSearchQuery:
+		info.Code = errorInvalidSearchQuery
+		w.WriteHeader(http.StatusBadRequest)
+	case app.ErrRegistrationInProgress:
+		info.Code = errorRegistrationInProgress
+		w.WriteHeader(http.StatusConflict)
+	case app.ErrUserHasActiveLots:
+		info.Code = errorUserHasActiveLots
+		w.WriteHeader(http.StatusConflict)
 	case app.ErrAlreadyProcessed:
 		info.Code = errorCodeAlreadyProcessed
 		w.WriteHeader(http.StatusConflict)
@@ -306,15 +709,69 @@ func writeErrorResponse(w http.ResponseWriter, err error) {
 
 func toUserProfileInfo(profile app.UserProfile) userInfo {
 	return userInfo{
-		UserID:    string(profile.UserID),
-		Login:     profile.Login,
-		FirstName: profile.FirstName,
-		LastName:  profile.LastName,
-		Email:     string(profile.Email),
-		Address:   string(profile.Address),
+		UserID:        string(profile.UserID),
+		Login:         profile.Login,
+		FirstName:     profile.FirstName,
+		LastName:      profile.LastName,
+		Email:         string(profile.Email),
+		Address:       string(profile.Address),
+		EmailVerified: profile.EmailVerified,
+	}
+}
+
+func toPublicUserProfileInfo(profile app.PublicProfile) publicUserInfo {
+	return publicUserInfo{
+		UserID:             string(profile.UserID),
+		Login:              profile.Login,
+		MemberSince:        formatOptionalTime(profile.MemberSince),
+		ActiveLotCount:     profile.SellerStats.ActiveLotCount,
+		SoldLotCount:       profile.SellerStats.SoldLotCount,
+		CompletedSaleCount: profile.SellerStats.CompletedSaleCount,
+		Reputation:         profile.Reputation,
+	}
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	res := t.Format(time.RFC3339)
+	return &res
+}
+
+func toShippingAddress(info addressData) app.ShippingAddress {
+	return app.ShippingAddress{
+		RecipientName: info.RecipientName,
+		Phone:         info.Phone,
+		Country:       info.Country,
+		PostalCode:    info.PostalCode,
+		City:          info.City,
+		Street:        info.Street,
+		IsDefault:     info.IsDefault,
+	}
+}
+
+func toAddressInfo(address app.ShippingAddress) addressInfo {
+	return addressInfo{
+		AddressID:     string(address.ID),
+		RecipientName: address.RecipientName,
+		Phone:         address.Phone,
+		Country:       address.Country,
+		PostalCode:    address.PostalCode,
+		City:          address.City,
+		Street:        address.Street,
+		IsDefault:     address.IsDefault,
 	}
 }
 
+func toAddressInfos(addresses []app.ShippingAddress) []addressInfo {
+	res := make([]addressInfo, 0, len(addresses))
+	for _, address := range addresses {
+		res = append(res, toAddressInfo(address))
+	}
+	return res
+}
+
 type errorInfo struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -329,13 +786,45 @@ type registerUserData struct {
 	Address   string `json:"address"`
 }
 
-type userInfo struct {
-	UserID    string `json:"id"`
+type registerExternalUserData struct {
 	Login     string `json:"login"`
 	FirstName string `json:"firstName"`
 	LastName  string `json:"lastName"`
 	Email     string `json:"email"`
-	Address   string `json:"address"`
+}
+
+type passwordResetData struct {
+	Email string `json:"email"`
+}
+
+type emailVerificationData struct {
+	Token string `json:"token"`
+}
+
+type userInfo struct {
+	UserID        string `json:"id"`
+	Login         string `json:"login"`
+	FirstName     string `json:"firstName"`
+	LastName      string `json:"lastName"`
+	Email         string `json:"email"`
+	Address       string `json:"address"`
+	EmailVerified bool   `json:"emailVerified"`
+}
+
+type publicUserInfo struct {
+	UserID             string  `json:"id"`
+	Login              string  `json:"login"`
+	MemberSince        *string `json:"memberSince,omitempty"`
+	ActiveLotCount     int     `json:"activeLotCount"`
+	SoldLotCount       int     `json:"soldLotCount"`
+	CompletedSaleCount int     `json:"completedSaleCount"`
+	Reputation         *int    `json:"reputation,omitempty"`
+}
+
+type foundUserInfo struct {
+	UserID      string  `json:"id"`
+	Login       string  `json:"login"`
+	MemberSince *string `json:"memberSince,omitempty"`
 }
 
 type userInfoUpdate struct {
@@ -349,3 +838,28 @@ type userInfoUpdate struct {
 type createdUserInfo struct {
 	UserID string `json:"id"`
 }
+
+type addressData struct {
+	RecipientName string `json:"recipientName"`
+	Phone         string `json:"phone"`
+	Country       string `json:"country"`
+	PostalCode    string `json:"postalCode"`
+	City          string `json:"city"`
+	Street        string `json:"street"`
+	IsDefault     bool   `json:"isDefault"`
+}
+
+type addressInfo struct {
+	AddressID     string `json:"id"`
+	RecipientName string `json:"recipientName"`
+	Phone         string `json:"phone"`
+	Country       string `json:"country"`
+	PostalCode    string `json:"postalCode"`
+	City          string `json:"city"`
+	Street        string `json:"street"`
+	IsDefault     bool   `json:"isDefault"`
+}
+
+type createdAddressInfo struct {
+	AddressID string `json:"id"`
+}
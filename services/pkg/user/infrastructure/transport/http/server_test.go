@@ -0,0 +1,23 @@
+package http
+
+import (
+	"arch-homework/pkg/user/app"
+
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicUserProfileMemberSince(t *testing.T) {
+	memberSince := time.Date(2021, 3, 15, 10, 30, 0, 0, time.UTC)
+	js, err := json.Marshal(toPublicUserProfileInfo(app.PublicProfile{UserID: "id", Login: "seller", MemberSince: &memberSince}))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"id","login":"seller","memberSince":"2021-03-15T10:30:00Z","activeLotCount":0,"soldLotCount":0,"completedSaleCount":0}`, string(js))
+
+	// unknown registration time is omitted instead of showing zero time
+	js, err = json.Marshal(toPublicUserProfileInfo(app.PublicProfile{UserID: "id", Login: "seller"}))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"id","login":"seller","activeLotCount":0,"soldLotCount":0,"completedSaleCount":0}`, string(js))
+}
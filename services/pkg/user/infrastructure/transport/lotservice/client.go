@@ -0,0 +1,60 @@
+package lotservice
+
+import (
+	"arch-homework/pkg/common/infrastructure/httpclient"
+	"arch-homework/pkg/user/app"
+
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const userDeletionURLTemplate = "/internal/api/v1/user/%s/deletion"
+const sellerStatsURLTemplate = "/internal/api/v1/user/%s/seller-stats"
+
+func NewClient(client http.Client, serviceHost string) app.LotServiceClient {
+	return &lotServiceClient{httpClient: httpclient.NewClient(client, serviceHost)}
+}
+
+type lotServiceClient struct {
+	httpClient httpclient.Client
+}
+
+func (c *lotServiceClient) StartUserDeletion(userID app.UserID) (*app.UserActivity, error) {
+	url := fmt.Sprintf(userDeletionURLTemplate, string(userID))
+	response := userActivityResponse{}
+	err := c.httpClient.MakeJSONRequest(nil, &response, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &app.UserActivity{
+		ActiveLotCount: response.ActiveLotCount,
+		ActiveBidCount: response.ActiveBidCount,
+	}, nil
+}
+
+func (c *lotServiceClient) GetSellerStats(userID app.UserID) (*app.SellerStats, error) {
+	url := fmt.Sprintf(sellerStatsURLTemplate, string(userID))
+	response := sellerStatsResponse{}
+	err := c.httpClient.MakeJSONRequest(nil, &response, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &app.SellerStats{
+		ActiveLotCount:     response.ActiveLotCount,
+		SoldLotCount:       response.SoldLotCount,
+		CompletedSaleCount: response.CompletedSaleCount,
+	}, nil
+}
+
+type userActivityResponse struct {
+	ActiveLotCount int `json:"activeLotCount"`
+	ActiveBidCount int `json:"activeBidCount"`
+}
+
+type sellerStatsResponse struct {
+	ActiveLotCount     int `json:"activeLotCount"`
+	SoldLotCount       int `json:"soldLotCount"`
+	CompletedSaleCount int `json:"completedSaleCount"`
+}
@@ -0,0 +1,38 @@
+package userdata
+
+import (
+	"arch-homework/pkg/common/infrastructure/httpclient"
+	"arch-homework/pkg/user/app"
+
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const exportURLTemplate = "/internal/api/v1/user/%s/export"
+
+// NewHTTPSource - source for services, which export user data with internal export endpoint
+func NewHTTPSource(name string, client http.Client, serviceHost string) app.UserDataSource {
+	return &httpSource{name: name, httpClient: httpclient.NewClient(client, serviceHost)}
+}
+
+type httpSource struct {
+	name       string
+	httpClient httpclient.Client
+}
+
+func (s *httpSource) Name() string {
+	return s.name
+}
+
+func (s *httpSource) ExportUserData(userID app.UserID) ([]byte, error) {
+	url := fmt.Sprintf(exportURLTemplate, string(userID))
+	var response json.RawMessage
+	err := s.httpClient.MakeJSONRequest(nil, &response, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return response, nil
+}